@@ -0,0 +1,754 @@
+// Package client est le SDK Go typé de l'API HTTP TrackMyTime (/api/v1).
+//
+//	c := client.New("http://127.0.0.1:8787", client.WithToken(token))
+//	stats, err := c.Stats(ctx, client.Week())
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL est l'adresse par défaut de l'agent
+const DefaultBaseURL = "http://127.0.0.1:8787"
+
+// Client appelle l'API d'un agent TrackMyTime
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// Option configure un Client
+type Option func(*Client)
+
+// WithToken envoie le jeton d'accès dans l'en-tête Authorization
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithHTTPClient remplace le client HTTP utilisé (timeouts, transport...)
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// New crée un client pour l'agent joignable à baseURL (DefaultBaseURL si vide)
+func New(baseURL string, opts ...Option) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Error est une erreur renvoyée par l'API
+type Error struct {
+	Status  int    `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("trackmytime: %d %s: %s", e.Status, e.Code, e.Message)
+}
+
+// Period sélectionne la période d'une requête de stats
+type Period struct {
+	Name  string    // today, week, month ou custom
+	Start time.Time // période custom uniquement (date incluse)
+	End   time.Time // période custom uniquement (date incluse)
+}
+
+// Today retourne la période du jour
+func Today() Period { return Period{Name: "today"} }
+
+// Week retourne la période de la semaine en cours
+func Week() Period { return Period{Name: "week"} }
+
+// Month retourne la période du mois en cours
+func Month() Period { return Period{Name: "month"} }
+
+// Custom retourne la période du jour start au jour end inclus
+func Custom(start, end time.Time) Period {
+	return Period{Name: "custom", Start: start, End: end}
+}
+
+// values encode la période en paramètres de requête
+func (p Period) values() url.Values {
+	v := url.Values{}
+	if p.Name == "" {
+		return v
+	}
+	v.Set("period", p.Name)
+	if p.Name == "custom" {
+		v.Set("start", p.Start.Format("2006-01-02"))
+		v.Set("end", p.End.Format("2006-01-02"))
+	}
+	return v
+}
+
+// Health vérifie l'état de l'agent
+func (c *Client) Health(ctx context.Context) (*Health, error) {
+	var out Health
+	return &out, c.getJSON(ctx, "/health", nil, &out)
+}
+
+// CurrentActivity retourne l'activité en cours
+func (c *Client) CurrentActivity(ctx context.Context) (*CurrentActivity, error) {
+	var out CurrentActivity
+	return &out, c.getJSON(ctx, "/activity/current", nil, &out)
+}
+
+// Stats retourne le temps actif par application
+func (c *Client) Stats(ctx context.Context, period Period) (*Stats, error) {
+	var out Stats
+	return &out, c.getJSON(ctx, "/stats", period.values(), &out)
+}
+
+// TaggedStats retourne le temps actif par application des activités portant le tag
+func (c *Client) TaggedStats(ctx context.Context, period Period, tag string) (*Stats, error) {
+	query := period.values()
+	query.Set("tag", tag)
+	var out Stats
+	return &out, c.getJSON(ctx, "/stats", query, &out)
+}
+
+// Timeline retourne le temps actif par heure (une journée) ou par jour
+func (c *Client) Timeline(ctx context.Context, period Period) (*Timeline, error) {
+	var out Timeline
+	return &out, c.getJSON(ctx, "/stats/timeline", period.values(), &out)
+}
+
+// GroupedStats retourne le temps par application puis par nom enrichi
+func (c *Client) GroupedStats(ctx context.Context, period Period) (*GroupedStats, error) {
+	var out GroupedStats
+	return &out, c.getJSON(ctx, "/stats/grouped", period.values(), &out)
+}
+
+// CategoryStats retourne le temps par catégorie et le score de productivité
+func (c *Client) CategoryStats(ctx context.Context, period Period) (*CategoryStats, error) {
+	var out CategoryStats
+	return &out, c.getJSON(ctx, "/stats/categories", period.values(), &out)
+}
+
+// Productivity retourne le temps productif, neutre et distrayant par jour
+func (c *Client) Productivity(ctx context.Context, period Period) (*Productivity, error) {
+	var out Productivity
+	return &out, c.getJSON(ctx, "/stats/productivity", period.values(), &out)
+}
+
+// ProjectStats retourne le temps par projet et par client, et le temps non attribué
+func (c *Client) ProjectStats(ctx context.Context, period Period) (*ProjectStats, error) {
+	var out ProjectStats
+	return &out, c.getJSON(ctx, "/stats/projects", period.values(), &out)
+}
+
+// UnassignedStats retourne le temps attribué à aucun projet
+func (c *Client) UnassignedStats(ctx context.Context, period Period) (*Unassigned, error) {
+	var out Unassigned
+	return &out, c.getJSON(ctx, "/stats/unassigned", period.values(), &out)
+}
+
+// ActivitiesOptions filtre les activités retournées par Activities
+type ActivitiesOptions struct {
+	AppName      string
+	EnrichedName string
+	Project      string // nom du projet
+	Unassigned   bool   // uniquement les activités sans projet
+	Tag          string // uniquement les activités portant ce tag
+	IncludeIdle  bool
+	Limit        int
+}
+
+// Activities retourne les activités brutes, les plus récentes d'abord
+func (c *Client) Activities(ctx context.Context, period Period, opts ActivitiesOptions) (*Activities, error) {
+	query := period.values()
+	if opts.AppName != "" {
+		query.Set("app", opts.AppName)
+	}
+	if opts.EnrichedName != "" {
+		query.Set("enriched", opts.EnrichedName)
+	}
+	if opts.Project != "" {
+		query.Set("project", opts.Project)
+	}
+	if opts.Unassigned {
+		query.Set("unassigned", "true")
+	}
+	if opts.Tag != "" {
+		query.Set("tag", opts.Tag)
+	}
+	if opts.IncludeIdle {
+		query.Set("include_idle", "true")
+	}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+
+	var out Activities
+	return &out, c.getJSON(ctx, "/activities", query, &out)
+}
+
+// ExportOptions configure Export
+type ExportOptions struct {
+	Format     string // csv (défaut) ou json
+	Aggregated bool   // agréger le temps par application
+	GroupBy    string // app (défaut), tag ou site, avec Aggregated
+	Tag        string // uniquement les activités portant ce tag, sauf avec GroupBy tag ou site
+}
+
+// Export retourne le fichier d'export ; l'appelant doit le fermer
+func (c *Client) Export(ctx context.Context, period Period, opts ExportOptions) (io.ReadCloser, error) {
+	query := period.values()
+	if opts.Format != "" {
+		query.Set("format", opts.Format)
+	}
+	if opts.Aggregated {
+		query.Set("aggregated", "true")
+	}
+	if opts.GroupBy != "" {
+		query.Set("group_by", opts.GroupBy)
+	}
+	if opts.Tag != "" {
+		query.Set("tag", opts.Tag)
+	}
+
+	resp, err := c.do(ctx, http.MethodGet, "/export", query, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// SendShellEvent signale le début ou la fin d'une commande de shell (jeton
+// shell requis)
+func (c *Client) SendShellEvent(ctx context.Context, event ShellEvent) (*ShellEventResult, error) {
+	var out ShellEventResult
+	return &out, c.sendJSON(ctx, http.MethodPost, "/shell/events", event, &out)
+}
+
+// ShellCommands retourne les commandes de shell lancées sur la période, les
+// plus récentes d'abord
+func (c *Client) ShellCommands(ctx context.Context, period Period, filter ShellCommandFilter) (*ShellCommands, error) {
+	query := period.values()
+	if filter.Program != "" {
+		query.Set("program", filter.Program)
+	}
+	if filter.Failed {
+		query.Set("failed", "true")
+	}
+	if filter.Limit > 0 {
+		query.Set("limit", strconv.Itoa(filter.Limit))
+	}
+	var out ShellCommands
+	return &out, c.getJSON(ctx, "/shell/commands", query, &out)
+}
+
+// CommandStats retourne le temps passé par programme dans les commandes de
+// shell ; limit vaut 20 si nul
+func (c *Client) CommandStats(ctx context.Context, period Period, limit int) (*CommandStats, error) {
+	query := period.values()
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	var out CommandStats
+	return &out, c.getJSON(ctx, "/stats/commands", query, &out)
+}
+
+// SendHeartbeats envoie des heartbeats WakaTime (jeton wakatime requis) et
+// retourne le code HTTP de chacun, dans l'ordre
+func (c *Client) SendHeartbeats(ctx context.Context, heartbeats []Heartbeat) ([]int, error) {
+	var out struct {
+		Responses [][2]json.RawMessage `json:"responses"`
+	}
+	if err := c.sendJSON(ctx, http.MethodPost, "/users/current/heartbeats.bulk", heartbeats, &out); err != nil {
+		return nil, err
+	}
+	statuses := make([]int, len(out.Responses))
+	for i, response := range out.Responses {
+		if err := json.Unmarshal(response[1], &statuses[i]); err != nil {
+			return nil, err
+		}
+	}
+	return statuses, nil
+}
+
+// WakaTimeToday retourne le résumé du jour affiché par les plugins WakaTime
+// (jeton wakatime requis)
+func (c *Client) WakaTimeToday(ctx context.Context) (*WakaTimeSummary, error) {
+	var out struct {
+		Data WakaTimeSummary `json:"data"`
+	}
+	return &out.Data, c.getJSON(ctx, "/users/current/statusbar/today", nil, &out)
+}
+
+// SendBrowserEvent envoie un signal navigateur (jeton browser requis)
+func (c *Client) SendBrowserEvent(ctx context.Context, event BrowserEvent) error {
+	_, err := c.SendBrowserEvents(ctx, []BrowserEvent{event})
+	return err
+}
+
+// SendBrowserEvents envoie un lot de signaux navigateur (jeton browser requis)
+func (c *Client) SendBrowserEvents(ctx context.Context, events []BrowserEvent) (*BrowserEventResult, error) {
+	var out BrowserEventResult
+	return &out, c.sendJSON(ctx, http.MethodPost, "/browser/events", events, &out)
+}
+
+// DomainStats retourne le temps passé par domaine ; limit vaut 20 si nul
+func (c *Client) DomainStats(ctx context.Context, period Period, limit int) (*DomainStats, error) {
+	query := period.values()
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	var out DomainStats
+	return &out, c.getJSON(ctx, "/stats/domains", query, &out)
+}
+
+// PathStats retourne le temps passé par page, d'un seul domaine si domain
+// n'est pas vide ; limit vaut 20 si nul
+func (c *Client) PathStats(ctx context.Context, period Period, domain string, limit int) (*PathStats, error) {
+	query := period.values()
+	if domain != "" {
+		query.Set("domain", domain)
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	var out PathStats
+	return &out, c.getJSON(ctx, "/stats/paths", query, &out)
+}
+
+// SiteStats retourne le temps actif par site (domaine enregistrable ou
+// sous-chemin selon les règles) ; limit vaut 20 si nul
+func (c *Client) SiteStats(ctx context.Context, period Period, limit int) (*SiteStats, error) {
+	query := period.values()
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	var out SiteStats
+	return &out, c.getJSON(ctx, "/stats/sites", query, &out)
+}
+
+// SiteDetail détaille le temps passé sur un site (github.com/golang) ou un
+// domaine (google.com) par hôte, sous-chemin et page ; limit borne les pages
+// et vaut 20 si nul
+func (c *Client) SiteDetail(ctx context.Context, name string, period Period, limit int) (*SiteDetail, error) {
+	query := period.values()
+	query.Set("name", name)
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	var out SiteDetail
+	return &out, c.getJSON(ctx, "/stats/site", query, &out)
+}
+
+// InputStats retourne les touches, clics et mouvements de souris signalés
+// par aw-watcher-input sur la période, au total et par heure
+func (c *Client) InputStats(ctx context.Context, period Period) (*InputStats, error) {
+	var out InputStats
+	return &out, c.getJSON(ctx, "/stats/input", period.values(), &out)
+}
+
+// SiteRules retourne les règles de regroupement des sites
+func (c *Client) SiteRules(ctx context.Context) ([]SiteRule, error) {
+	var out SiteRules
+	return out.Rules, c.getJSON(ctx, "/sites/rules", nil, &out)
+}
+
+// SetSiteRules remplace les règles de regroupement des sites (jeton write
+// requis) ; nil les réinitialise aux règles par défaut
+func (c *Client) SetSiteRules(ctx context.Context, rules []SiteRule) ([]SiteRule, error) {
+	var out SiteRules
+	if rules == nil {
+		return out.Rules, c.sendJSON(ctx, http.MethodDelete, "/sites/rules", nil, &out)
+	}
+	return out.Rules, c.sendJSON(ctx, http.MethodPut, "/sites/rules", SiteRules{Rules: rules}, &out)
+}
+
+// Rules retourne les règles d'enrichissement dans leur ordre d'évaluation
+func (c *Client) Rules(ctx context.Context) (*Rules, error) {
+	var out Rules
+	return &out, c.getJSON(ctx, "/rules", nil, &out)
+}
+
+// Rule retourne une règle d'enrichissement
+func (c *Client) Rule(ctx context.Context, id int64) (*Rule, error) {
+	var out Rule
+	return &out, c.getJSON(ctx, "/rules/"+strconv.FormatInt(id, 10), nil, &out)
+}
+
+// CreateRule ajoute une règle (jeton write requis) ; Position 0 la place en dernier
+func (c *Client) CreateRule(ctx context.Context, rule Rule) (*Rule, error) {
+	var out Rule
+	return &out, c.sendJSON(ctx, http.MethodPost, "/rules", rule, &out)
+}
+
+// UpdateRule remplace la règle rule.ID (jeton write requis)
+func (c *Client) UpdateRule(ctx context.Context, rule Rule) (*Rule, error) {
+	var out Rule
+	return &out, c.sendJSON(ctx, http.MethodPut, "/rules/"+strconv.FormatInt(rule.ID, 10), rule, &out)
+}
+
+// DeleteRule supprime une règle (jeton write requis)
+func (c *Client) DeleteRule(ctx context.Context, id int64) error {
+	return c.sendJSON(ctx, http.MethodDelete, "/rules/"+strconv.FormatInt(id, 10), nil, nil)
+}
+
+// ResetRules remplace toutes les règles par les règles par défaut (jeton write requis)
+func (c *Client) ResetRules(ctx context.Context) (*Rules, error) {
+	var out Rules
+	return &out, c.sendJSON(ctx, http.MethodPost, "/rules/reset", nil, &out)
+}
+
+// TestRules évalue les règles sur une fenêtre fictive ; si test.Rules est
+// vide, les règles enregistrées sont utilisées
+func (c *Client) TestRules(ctx context.Context, test RuleTest) (*RuleTestResult, error) {
+	var out RuleTestResult
+	return &out, c.sendJSON(ctx, http.MethodPost, "/rules/test", test, &out)
+}
+
+// Coverage retourne la part du temps nommée par les règles, les titres non
+// classés et des règles suggérées ; limit vaut 20 si nul
+func (c *Client) Coverage(ctx context.Context, period Period, limit int) (*Coverage, error) {
+	query := period.values()
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	var out Coverage
+	return &out, c.getJSON(ctx, "/stats/coverage", query, &out)
+}
+
+// Categories retourne les catégories et leur niveau de productivité
+func (c *Client) Categories(ctx context.Context) (*Categories, error) {
+	var out Categories
+	return &out, c.getJSON(ctx, "/categories", nil, &out)
+}
+
+// SetCategory crée une catégorie ou change sa productivité (jeton write
+// requis) ; productivity vaut productive, neutral ou distracting
+func (c *Client) SetCategory(ctx context.Context, name, productivity string) (*Category, error) {
+	var out Category
+	in := map[string]string{"productivity": productivity}
+	return &out, c.sendJSON(ctx, http.MethodPut, "/categories/"+url.PathEscape(name), in, &out)
+}
+
+// DeleteCategory supprime une catégorie (jeton write requis)
+func (c *Client) DeleteCategory(ctx context.Context, name string) error {
+	return c.sendJSON(ctx, http.MethodDelete, "/categories/"+url.PathEscape(name), nil, nil)
+}
+
+// Projects retourne les projets ; includeArchived inclut les projets archivés
+func (c *Client) Projects(ctx context.Context, includeArchived bool) (*Projects, error) {
+	var query url.Values
+	if includeArchived {
+		query = url.Values{"archived": {"true"}}
+	}
+	var out Projects
+	return &out, c.getJSON(ctx, "/projects", query, &out)
+}
+
+// Project retourne un projet
+func (c *Client) Project(ctx context.Context, id int64) (*Project, error) {
+	var out Project
+	return &out, c.getJSON(ctx, "/projects/"+strconv.FormatInt(id, 10), nil, &out)
+}
+
+// CreateProject crée un projet (jeton write requis) ; une couleur est
+// choisie si Color est vide
+func (c *Client) CreateProject(ctx context.Context, project Project) (*Project, error) {
+	var out Project
+	return &out, c.sendJSON(ctx, http.MethodPost, "/projects", project, &out)
+}
+
+// UpdateProject modifie un projet (jeton write requis) ; un renommage est
+// reporté sur l'historique et les règles
+func (c *Client) UpdateProject(ctx context.Context, project Project) (*Project, error) {
+	var out Project
+	return &out, c.sendJSON(ctx, http.MethodPut, "/projects/"+strconv.FormatInt(project.ID, 10), project, &out)
+}
+
+// DeleteProject supprime un projet (jeton write requis)
+func (c *Client) DeleteProject(ctx context.Context, id int64) error {
+	return c.sendJSON(ctx, http.MethodDelete, "/projects/"+strconv.FormatInt(id, 10), nil, nil)
+}
+
+// ProjectReport retourne le temps d'un projet par jour et par application
+func (c *Client) ProjectReport(ctx context.Context, id int64, period Period) (*ProjectReport, error) {
+	var out ProjectReport
+	return &out, c.getJSON(ctx, "/projects/"+strconv.FormatInt(id, 10)+"/stats", period.values(), &out)
+}
+
+// TagStats retourne le temps actif par tag
+func (c *Client) TagStats(ctx context.Context, period Period) (*TagStats, error) {
+	var out TagStats
+	return &out, c.getJSON(ctx, "/stats/tags", period.values(), &out)
+}
+
+// Tags retourne les tags et leur utilisation
+func (c *Client) Tags(ctx context.Context) (*Tags, error) {
+	var out Tags
+	return &out, c.getJSON(ctx, "/tags", nil, &out)
+}
+
+// DeleteTag retire un tag de toutes les activités (jeton write requis)
+func (c *Client) DeleteTag(ctx context.Context, name string) error {
+	return c.sendJSON(ctx, http.MethodDelete, "/tags/"+url.PathEscape(name), nil, nil)
+}
+
+// ActivityTags retourne les tags d'une activité
+func (c *Client) ActivityTags(ctx context.Context, id int64) (*ActivityTags, error) {
+	var out ActivityTags
+	return &out, c.getJSON(ctx, "/activities/"+strconv.FormatInt(id, 10)+"/tags", nil, &out)
+}
+
+// AddActivityTags pose des tags sur une activité (jeton write requis)
+func (c *Client) AddActivityTags(ctx context.Context, id int64, tags ...string) (*ActivityTags, error) {
+	var out ActivityTags
+	in := map[string][]string{"tags": tags}
+	return &out, c.sendJSON(ctx, http.MethodPost, "/activities/"+strconv.FormatInt(id, 10)+"/tags", in, &out)
+}
+
+// RemoveActivityTag retire un tag d'une activité (jeton write requis)
+func (c *Client) RemoveActivityTag(ctx context.Context, id int64, tag string) (*ActivityTags, error) {
+	var out ActivityTags
+	path := "/activities/" + strconv.FormatInt(id, 10) + "/tags/" + url.PathEscape(tag)
+	return &out, c.sendJSON(ctx, http.MethodDelete, path, nil, &out)
+}
+
+// TagRange pose (ou retire si remove) des tags sur les activités actives
+// qui chevauchent [start, end) (jeton write requis) et retourne le nombre
+// d'activités taguées ou de tags retirés
+func (c *Client) TagRange(ctx context.Context, start, end time.Time, tags []string, remove bool) (int64, error) {
+	in := TagRange{Start: start, End: end, Tags: tags, Remove: remove}
+	var out struct {
+		Updated int64 `json:"updated"`
+	}
+	return out.Updated, c.sendJSON(ctx, http.MethodPost, "/tags/range", in, &out)
+}
+
+// LanguageStats retourne le temps passé par langage dans les éditeurs, d'un
+// seul projet si project n'est pas vide
+func (c *Client) LanguageStats(ctx context.Context, period Period, project string) (*LanguageStats, error) {
+	query := period.values()
+	if project != "" {
+		query.Set("project", project)
+	}
+	var out LanguageStats
+	return &out, c.getJSON(ctx, "/stats/languages", query, &out)
+}
+
+// FileStats retourne les fichiers les plus travaillés, d'un seul projet si
+// project n'est pas vide ; limit vaut 20 si nul
+func (c *Client) FileStats(ctx context.Context, period Period, project string, limit int) (*FileStats, error) {
+	query := period.values()
+	if project != "" {
+		query.Set("project", project)
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	var out FileStats
+	return &out, c.getJSON(ctx, "/stats/files", query, &out)
+}
+
+// TerminalStats retourne le temps passé dans les terminaux regroupé par
+// groupBy (command, ssh_host ou cwd ; command si vide) ; limit vaut 20 si nul
+func (c *Client) TerminalStats(ctx context.Context, period Period, groupBy string, limit int) (*TerminalStats, error) {
+	query := period.values()
+	if groupBy != "" {
+		query.Set("group_by", groupBy)
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	var out TerminalStats
+	return &out, c.getJSON(ctx, "/stats/terminal", query, &out)
+}
+
+// RepoStats retourne le temps passé par dépôt git ; limit vaut 20 si nul
+func (c *Client) RepoStats(ctx context.Context, period Period, limit int) (*RepoStats, error) {
+	query := period.values()
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	var out RepoStats
+	return &out, c.getJSON(ctx, "/stats/repos", query, &out)
+}
+
+// BranchStats retourne le temps passé par branche git, d'un seul dépôt si
+// repo n'est pas vide (distant ou racine) ; limit vaut 20 si nul
+func (c *Client) BranchStats(ctx context.Context, period Period, repo string, limit int) (*BranchStats, error) {
+	query := period.values()
+	if repo != "" {
+		query.Set("repo", repo)
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	var out BranchStats
+	return &out, c.getJSON(ctx, "/stats/branches", query, &out)
+}
+
+// EntityStats retourne le temps passé par ticket, dépôt, PR ou MR sur la
+// période, d'un seul type si kind n'est pas vide ; limit vaut 50 si nul
+func (c *Client) EntityStats(ctx context.Context, period Period, kind string, limit int) (*EntityStats, error) {
+	query := period.values()
+	if kind != "" {
+		query.Set("kind", kind)
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	var out EntityStats
+	return &out, c.getJSON(ctx, "/stats/entities", query, &out)
+}
+
+// EntityReport détaille le temps passé sur une entité, par jour et par
+// application, sur tout l'historique
+func (c *Client) EntityReport(ctx context.Context, kind, value string) (*EntityReport, error) {
+	query := url.Values{"kind": {kind}, "value": {value}}
+	var out EntityReport
+	return &out, c.getJSON(ctx, "/stats/entity", query, &out)
+}
+
+// EntityPatterns retourne les motifs d'extraction d'entités
+func (c *Client) EntityPatterns(ctx context.Context) ([]EntityPattern, error) {
+	var out EntityPatterns
+	return out.Patterns, c.getJSON(ctx, "/entities/patterns", nil, &out)
+}
+
+// SetEntityPatterns remplace les motifs d'extraction d'entités (jeton write
+// requis) ; nil les réinitialise aux motifs par défaut
+func (c *Client) SetEntityPatterns(ctx context.Context, patterns []EntityPattern) ([]EntityPattern, error) {
+	var out EntityPatterns
+	if patterns == nil {
+		return out.Patterns, c.sendJSON(ctx, http.MethodDelete, "/entities/patterns", nil, &out)
+	}
+	return out.Patterns, c.sendJSON(ctx, http.MethodPut, "/entities/patterns", EntityPatterns{Patterns: patterns}, &out)
+}
+
+// PrivacyRules retourne les règles de confidentialité
+func (c *Client) PrivacyRules(ctx context.Context) ([]PrivacyRule, error) {
+	var out PrivacyRules
+	return out.Rules, c.getJSON(ctx, "/privacy/rules", nil, &out)
+}
+
+// SetPrivacyRules remplace les règles de confidentialité (jeton write
+// requis) ; nil les supprime toutes
+func (c *Client) SetPrivacyRules(ctx context.Context, rules []PrivacyRule) ([]PrivacyRule, error) {
+	var out PrivacyRules
+	if rules == nil {
+		return out.Rules, c.sendJSON(ctx, http.MethodDelete, "/privacy/rules", nil, &out)
+	}
+	return out.Rules, c.sendJSON(ctx, http.MethodPut, "/privacy/rules", PrivacyRules{Rules: rules}, &out)
+}
+
+// OpenAPI retourne la spécification OpenAPI 3 brute de l'agent
+func (c *Client) OpenAPI(ctx context.Context) (json.RawMessage, error) {
+	var out json.RawMessage
+	return out, c.getJSON(ctx, "/openapi.json", nil, &out)
+}
+
+// getJSON exécute un GET et décode la réponse JSON dans out
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, out any) error {
+	resp, err := c.do(ctx, http.MethodGet, path, query, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("trackmytime: réponse invalide pour %s: %w", path, err)
+	}
+	return nil
+}
+
+// sendJSON envoie in en JSON (si non nil) et décode la réponse dans out (si non nil)
+func (c *Client) sendJSON(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	resp, err := c.do(ctx, method, path, nil, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("trackmytime: réponse invalide pour %s: %w", path, err)
+	}
+	return nil
+}
+
+// do exécute une requête sur /api/v1 et convertit les réponses d'erreur en *Error
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body io.Reader) (*http.Response, error) {
+	u := c.baseURL + "/api/v1" + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		return nil, decodeError(resp)
+	}
+	return resp, nil
+}
+
+// decodeError lit l'enveloppe d'erreur JSON de l'API
+func decodeError(resp *http.Response) error {
+	var envelope struct {
+		Error *Error `json:"error"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err := json.Unmarshal(data, &envelope); err == nil && envelope.Error != nil {
+		return envelope.Error
+	}
+	return &Error{
+		Status:  resp.StatusCode,
+		Code:    "error",
+		Message: strings.TrimSpace(string(data)),
+	}
+}
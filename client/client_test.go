@@ -0,0 +1,222 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"trackmytime/client"
+	"trackmytime/internal/api"
+	"trackmytime/internal/auth"
+	"trackmytime/internal/storage"
+	"trackmytime/internal/tracker"
+)
+
+const (
+	readToken    = "tmt_read_test"
+	browserToken = "tmt_browser_test"
+)
+
+// newTestServer démarre l'API sur une base vide, protégée par un jeton read
+// et un jeton browser
+func newTestServer(t *testing.T) (*api.Server, *storage.DB, string) {
+	t.Helper()
+	db, err := storage.NewDB(filepath.Join(t.TempDir(), "activities.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	srv := api.NewServer(db, "127.0.0.1:0")
+	srv.SetTokens(&auth.TokenStore{Tokens: []auth.Token{
+		{Name: "read", Value: readToken, Scopes: []auth.Scope{auth.ScopeRead}},
+		{Name: "browser", Value: browserToken, Scopes: []auth.Scope{auth.ScopeBrowser}},
+	}})
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(func() {
+		srv.Shutdown(context.Background())
+		ts.Close()
+	})
+	return srv, db, ts.URL
+}
+
+// apiError vérifie que err est une erreur de l'API de statut et code attendus
+func apiError(t *testing.T, err error, status int, code string) {
+	t.Helper()
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("erreur %v (%T), *client.Error attendue", err, err)
+	}
+	if apiErr.Status != status || apiErr.Code != code || apiErr.Message == "" {
+		t.Fatalf("erreur %d %s %q, %d %s attendu", apiErr.Status, apiErr.Code, apiErr.Message, status, code)
+	}
+}
+
+func TestAuthFailure(t *testing.T) {
+	_, _, url := newTestServer(t)
+	ctx := context.Background()
+
+	_, err := client.New(url).Stats(ctx, client.Today())
+	apiError(t, err, http.StatusUnauthorized, "unauthorized")
+
+	_, err = client.New(url, client.WithToken("inconnu")).Stats(ctx, client.Today())
+	apiError(t, err, http.StatusUnauthorized, "unauthorized")
+
+	_, err = client.New(url, client.WithToken(browserToken)).Stats(ctx, client.Today())
+	apiError(t, err, http.StatusForbidden, "forbidden")
+
+	// /health reste public
+	if _, err := client.New(url).Health(ctx); err != nil {
+		t.Fatalf("Health: %v", err)
+	}
+}
+
+func TestErrorEnvelope(t *testing.T) {
+	_, _, url := newTestServer(t)
+	c := client.New(url, client.WithToken(readToken))
+
+	_, err := c.Stats(context.Background(), client.Period{Name: "decade"})
+	apiError(t, err, http.StatusBadRequest, "bad_request")
+
+	day := time.Date(2026, 10, 18, 0, 0, 0, 0, time.Local)
+	_, err = c.Stats(context.Background(), client.Custom(day, day.AddDate(0, 0, -1)))
+	apiError(t, err, http.StatusBadRequest, "bad_request")
+}
+
+func TestStats(t *testing.T) {
+	_, db, url := newTestServer(t)
+	day := time.Date(2026, 10, 18, 0, 0, 0, 0, time.Local)
+	for _, a := range []storage.Activity{
+		{AppName: "Firefox", WindowTitle: "Docs", StartTime: day.Add(9 * time.Hour), DurationSecs: 600},
+		{AppName: "Code", WindowTitle: "main.go", StartTime: day.Add(10 * time.Hour), DurationSecs: 300},
+		{AppName: "Firefox", WindowTitle: "Mail", StartTime: day.Add(11 * time.Hour), DurationSecs: 200},
+		{AppName: "IDLE", StartTime: day.Add(12 * time.Hour), DurationSecs: 120, IsIdle: true},
+		{AppName: "Firefox", WindowTitle: "Hier", StartTime: day.Add(-time.Hour), DurationSecs: 900},
+	} {
+		a.EnrichedName = a.AppName
+		a.EndTime = a.StartTime.Add(time.Duration(a.DurationSecs) * time.Second)
+		if err := db.InsertActivity(&a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats, err := client.New(url, client.WithToken(readToken)).Stats(context.Background(), client.Custom(day, day))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Period.Name != "custom" {
+		t.Errorf("période %q, custom attendue", stats.Period.Name)
+	}
+	if stats.TotalActivities != 4 || stats.TotalActiveSeconds != 1100 || stats.TotalIdleSeconds != 120 {
+		t.Errorf("totaux %d activités, %d s actives, %d s inactives ; 4, 1100 et 120 attendus",
+			stats.TotalActivities, stats.TotalActiveSeconds, stats.TotalIdleSeconds)
+	}
+	want := []client.AppStat{{AppName: "Firefox", TotalSeconds: 800}, {AppName: "Code", TotalSeconds: 300}}
+	if len(stats.Apps) != len(want) {
+		t.Fatalf("applications %+v, %+v attendues", stats.Apps, want)
+	}
+	for i := range want {
+		if stats.Apps[i] != want[i] {
+			t.Errorf("application %d: %+v, %+v attendue", i, stats.Apps[i], want[i])
+		}
+	}
+}
+
+// TestReadEndpoints appelle chaque lecture du client sur une base vide : les
+// routes existent, acceptent le jeton read et leurs réponses se décodent
+func TestReadEndpoints(t *testing.T) {
+	_, _, url := newTestServer(t)
+	c := client.New(url, client.WithToken(readToken))
+	week := client.Week()
+
+	for name, call := range map[string]func(context.Context) error{
+		"CurrentActivity": func(ctx context.Context) error { _, err := c.CurrentActivity(ctx); return err },
+		"TaggedStats":     func(ctx context.Context) error { _, err := c.TaggedStats(ctx, week, "client"); return err },
+		"Timeline":        func(ctx context.Context) error { _, err := c.Timeline(ctx, week); return err },
+		"GroupedStats":    func(ctx context.Context) error { _, err := c.GroupedStats(ctx, week); return err },
+		"CategoryStats":   func(ctx context.Context) error { _, err := c.CategoryStats(ctx, week); return err },
+		"Productivity":    func(ctx context.Context) error { _, err := c.Productivity(ctx, week); return err },
+		"ProjectStats":    func(ctx context.Context) error { _, err := c.ProjectStats(ctx, week); return err },
+		"UnassignedStats": func(ctx context.Context) error { _, err := c.UnassignedStats(ctx, week); return err },
+		"Activities": func(ctx context.Context) error {
+			_, err := c.Activities(ctx, week, client.ActivitiesOptions{})
+			return err
+		},
+		"CommandStats":   func(ctx context.Context) error { _, err := c.CommandStats(ctx, week, 10); return err },
+		"DomainStats":    func(ctx context.Context) error { _, err := c.DomainStats(ctx, week, 10); return err },
+		"PathStats":      func(ctx context.Context) error { _, err := c.PathStats(ctx, week, "", 10); return err },
+		"SiteStats":      func(ctx context.Context) error { _, err := c.SiteStats(ctx, week, 10); return err },
+		"InputStats":     func(ctx context.Context) error { _, err := c.InputStats(ctx, week); return err },
+		"SiteRules":      func(ctx context.Context) error { _, err := c.SiteRules(ctx); return err },
+		"Rules":          func(ctx context.Context) error { _, err := c.Rules(ctx); return err },
+		"Coverage":       func(ctx context.Context) error { _, err := c.Coverage(ctx, week, 10); return err },
+		"Categories":     func(ctx context.Context) error { _, err := c.Categories(ctx); return err },
+		"Projects":       func(ctx context.Context) error { _, err := c.Projects(ctx, true); return err },
+		"TagStats":       func(ctx context.Context) error { _, err := c.TagStats(ctx, week); return err },
+		"Tags":           func(ctx context.Context) error { _, err := c.Tags(ctx); return err },
+		"LanguageStats":  func(ctx context.Context) error { _, err := c.LanguageStats(ctx, week, ""); return err },
+		"FileStats":      func(ctx context.Context) error { _, err := c.FileStats(ctx, week, "", 10); return err },
+		"TerminalStats":  func(ctx context.Context) error { _, err := c.TerminalStats(ctx, week, "", 10); return err },
+		"RepoStats":      func(ctx context.Context) error { _, err := c.RepoStats(ctx, week, 10); return err },
+		"BranchStats":    func(ctx context.Context) error { _, err := c.BranchStats(ctx, week, "", 10); return err },
+		"EntityStats":    func(ctx context.Context) error { _, err := c.EntityStats(ctx, week, "", 10); return err },
+		"EntityPatterns": func(ctx context.Context) error { _, err := c.EntityPatterns(ctx); return err },
+		"PrivacyRules":   func(ctx context.Context) error { _, err := c.PrivacyRules(ctx); return err },
+		"OpenAPI":        func(ctx context.Context) error { _, err := c.OpenAPI(ctx); return err },
+	} {
+		t.Run(name, func(t *testing.T) {
+			if err := call(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+
+	// Une ressource absente est une erreur 404 dans l'enveloppe JSON
+	_, err := c.Project(context.Background(), 42)
+	apiError(t, err, http.StatusNotFound, "not_found")
+}
+
+func TestActivityStream(t *testing.T) {
+	srv, _, url := newTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := client.New(url).SubscribeActivity(ctx)
+	apiError(t, err, http.StatusUnauthorized, "unauthorized")
+
+	stream, err := client.New(url, client.WithToken(readToken)).SubscribeActivity(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+
+	// Le premier événement est l'activité courante
+	first, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Active {
+		t.Errorf("activité initiale %+v, inactive attendue", first)
+	}
+
+	start := time.Now().Add(-time.Minute)
+	srv.SetCurrentActivity(&tracker.WindowInfo{AppName: "Code", WindowTitle: "main.go"}, start)
+	next, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !next.Active || next.AppName != "Code" || next.WindowTitle != "main.go" || !next.StartTime.Equal(start) {
+		t.Errorf("activité %+v, Code main.go attendue", next)
+	}
+
+	// L'arrêt du serveur ferme le flux
+	srv.Shutdown(context.Background())
+	if _, err := stream.Recv(); !errors.Is(err, io.EOF) {
+		t.Errorf("Recv après l'arrêt: %v, io.EOF attendu", err)
+	}
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ActivityStream reçoit les changements d'activité envoyés par l'agent (SSE)
+type ActivityStream struct {
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+}
+
+// SubscribeActivity ouvre le flux des changements d'activité. Le premier
+// événement reçu est l'activité courante. Le flux se termine à l'annulation
+// de ctx, à l'appel de Close ou à l'arrêt de l'agent.
+func (c *Client) SubscribeActivity(ctx context.Context) (*ActivityStream, error) {
+	resp, err := c.do(ctx, http.MethodGet, "/activity/stream", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ActivityStream{
+		body:    resp.Body,
+		scanner: bufio.NewScanner(resp.Body),
+	}, nil
+}
+
+// Recv bloque jusqu'au prochain changement d'activité. Retourne io.EOF quand
+// l'agent ferme le flux.
+func (s *ActivityStream) Recv() (*CurrentActivity, error) {
+	var event string
+	var data strings.Builder
+
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+
+		switch {
+		case line == "":
+			// Fin d'un événement
+			if event == "activity" && data.Len() > 0 {
+				var activity CurrentActivity
+				if err := json.Unmarshal([]byte(data.String()), &activity); err != nil {
+					return nil, fmt.Errorf("trackmytime: événement invalide: %w", err)
+				}
+				return &activity, nil
+			}
+			event = ""
+			data.Reset()
+		case strings.HasPrefix(line, ":"):
+			// Commentaire (keep-alive)
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+
+	if err := s.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// Close ferme le flux
+func (s *ActivityStream) Close() error {
+	return s.body.Close()
+}
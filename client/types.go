@@ -0,0 +1,718 @@
+package client
+
+import "time"
+
+// PeriodInfo décrit la période effective d'une réponse (End exclue)
+type PeriodInfo struct {
+	Name  string    `json:"name"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Health est la réponse de Health
+type Health struct {
+	Status string    `json:"status"`
+	Time   time.Time `json:"time"`
+}
+
+// CurrentActivity décrit l'activité en cours
+type CurrentActivity struct {
+	Active          bool      `json:"active"`
+	AppName         string    `json:"app_name,omitempty"`
+	EnrichedName    string    `json:"enriched_name,omitempty"`
+	WindowTitle     string    `json:"window_title,omitempty"`
+	ProcessPath     string    `json:"process_path,omitempty"`
+	URL             string    `json:"url,omitempty"`
+	Domain          string    `json:"domain,omitempty"`
+	StartTime       time.Time `json:"start_time,omitzero"`
+	DurationSeconds int64     `json:"duration_seconds"`
+}
+
+// AppStat est le temps passé sur une application
+type AppStat struct {
+	AppName      string `json:"app_name"`
+	TotalSeconds int64  `json:"total_seconds"`
+}
+
+// Stats est la réponse de Stats
+type Stats struct {
+	Period             PeriodInfo `json:"period"`
+	TotalActivities    int        `json:"total_activities"`
+	TotalActiveSeconds int64      `json:"total_active_seconds"`
+	TotalIdleSeconds   int64      `json:"total_idle_seconds"`
+	Apps               []AppStat  `json:"apps"`
+}
+
+// Timeline est la réponse de Timeline
+type Timeline struct {
+	Period      PeriodInfo `json:"period"`
+	Granularity string     `json:"granularity"`
+	Labels      []string   `json:"labels"`
+	Seconds     []int64    `json:"seconds"`
+}
+
+// EnrichedApp est le temps passé sur un nom enrichi
+type EnrichedApp struct {
+	Name     string `json:"name"`
+	Duration int64  `json:"duration"`
+}
+
+// AppGroup regroupe les noms enrichis d'une application
+type AppGroup struct {
+	AppName      string        `json:"app_name"`
+	TotalSeconds int64         `json:"total_seconds"`
+	Children     []EnrichedApp `json:"children"`
+}
+
+// GroupedStats est la réponse de GroupedStats
+type GroupedStats struct {
+	Period PeriodInfo `json:"period"`
+	Groups []AppGroup `json:"groups"`
+}
+
+// CategoryStat est le temps actif d'une catégorie
+type CategoryStat struct {
+	Category     string `json:"category"`
+	Productivity string `json:"productivity"`
+	TotalSeconds int64  `json:"total_seconds"`
+}
+
+// ProductivitySummary est la répartition du temps actif par productivité
+type ProductivitySummary struct {
+	ProductiveSeconds  int64   `json:"productive_seconds"`
+	NeutralSeconds     int64   `json:"neutral_seconds"`
+	DistractingSeconds int64   `json:"distracting_seconds"`
+	Score              float64 `json:"score"`
+}
+
+// CategoryStats est la réponse de CategoryStats
+type CategoryStats struct {
+	Period     PeriodInfo          `json:"period"`
+	Categories []CategoryStat      `json:"categories"`
+	Summary    ProductivitySummary `json:"summary"`
+}
+
+// DayProductivity est la productivité d'une journée
+type DayProductivity struct {
+	Date               string  `json:"date"`
+	ProductiveSeconds  int64   `json:"productive_seconds"`
+	NeutralSeconds     int64   `json:"neutral_seconds"`
+	DistractingSeconds int64   `json:"distracting_seconds"`
+	Score              float64 `json:"score"`
+}
+
+// Productivity est la réponse de Productivity
+type Productivity struct {
+	Period  PeriodInfo          `json:"period"`
+	Days    []DayProductivity   `json:"days"`
+	Summary ProductivitySummary `json:"summary"`
+}
+
+// Category associe une catégorie à son niveau de productivité
+type Category struct {
+	Name         string `json:"name"`
+	Productivity string `json:"productivity"`
+}
+
+// Categories est la réponse de Categories
+type Categories struct {
+	Categories []Category `json:"categories"`
+}
+
+// Project est un projet auquel le temps est attribué
+type Project struct {
+	ID        int64     `json:"id,omitempty"`
+	Name      string    `json:"name"`
+	Client    string    `json:"client,omitempty"`
+	Color     string    `json:"color,omitempty"`
+	Archived  bool      `json:"archived"`
+	CreatedAt time.Time `json:"created_at,omitzero"`
+}
+
+// Projects est la réponse de Projects
+type Projects struct {
+	Projects []Project `json:"projects"`
+}
+
+// ProjectStat est le temps actif d'un projet
+type ProjectStat struct {
+	ProjectID    int64  `json:"project_id"`
+	Project      string `json:"project"`
+	Client       string `json:"client,omitempty"`
+	Color        string `json:"color"`
+	Archived     bool   `json:"archived"`
+	TotalSeconds int64  `json:"total_seconds"`
+}
+
+// ClientStat est le temps actif cumulé des projets d'un client
+type ClientStat struct {
+	Client       string `json:"client"`
+	TotalSeconds int64  `json:"total_seconds"`
+}
+
+// ProjectStats est la réponse de ProjectStats
+type ProjectStats struct {
+	Period            PeriodInfo    `json:"period"`
+	Projects          []ProjectStat `json:"projects"`
+	Clients           []ClientStat  `json:"clients"`
+	UnassignedSeconds int64         `json:"unassigned_seconds"`
+	TotalSeconds      int64         `json:"total_seconds"`
+}
+
+// DaySeconds est le temps actif d'une journée
+type DaySeconds struct {
+	Date    string `json:"date"`
+	Seconds int64  `json:"seconds"`
+}
+
+// ProjectReport est la réponse de ProjectReport
+type ProjectReport struct {
+	Period       PeriodInfo   `json:"period"`
+	Project      Project      `json:"project"`
+	TotalSeconds int64        `json:"total_seconds"`
+	Days         []DaySeconds `json:"days"`
+	Apps         []AppGroup   `json:"apps"`
+}
+
+// Unassigned est la réponse de UnassignedStats
+type Unassigned struct {
+	Period       PeriodInfo   `json:"period"`
+	TotalSeconds int64        `json:"total_seconds"`
+	Days         []DaySeconds `json:"days"`
+	Apps         []AppGroup   `json:"apps"`
+}
+
+// Tag est un tag et son utilisation
+type Tag struct {
+	Name         string `json:"name"`
+	Activities   int    `json:"activities"`
+	TotalSeconds int64  `json:"total_seconds"`
+}
+
+// Tags est la réponse de Tags
+type Tags struct {
+	Tags []Tag `json:"tags"`
+}
+
+// ActivityTags est la réponse de ActivityTags, AddActivityTags et RemoveActivityTag
+type ActivityTags struct {
+	ActivityID int64    `json:"activity_id"`
+	Tags       []string `json:"tags"`
+}
+
+// TagRange est le corps de TagRange
+type TagRange struct {
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Tags   []string  `json:"tags"`
+	Remove bool      `json:"remove,omitempty"`
+}
+
+// TagStat est le temps actif portant un tag
+type TagStat struct {
+	Tag          string `json:"tag"`
+	Activities   int    `json:"activities"`
+	TotalSeconds int64  `json:"total_seconds"`
+}
+
+// TagStats est la réponse de TagStats
+type TagStats struct {
+	Period          PeriodInfo `json:"period"`
+	Tags            []TagStat  `json:"tags"`
+	UntaggedSeconds int64      `json:"untagged_seconds"`
+}
+
+// LanguageStat est le temps actif passé sur les fichiers d'un langage
+// (Language vide pour les langages inconnus)
+type LanguageStat struct {
+	Language     string `json:"language"`
+	Files        int    `json:"files"`
+	Activities   int    `json:"activities"`
+	TotalSeconds int64  `json:"total_seconds"`
+}
+
+// LanguageStats est la réponse de LanguageStats
+type LanguageStats struct {
+	Period       PeriodInfo     `json:"period"`
+	Languages    []LanguageStat `json:"languages"`
+	TotalSeconds int64          `json:"total_seconds"`
+}
+
+// FileStat est le temps actif passé sur un fichier dans un éditeur
+type FileStat struct {
+	FileName     string    `json:"file_name"`
+	Extension    string    `json:"extension,omitempty"`
+	Language     string    `json:"language,omitempty"`
+	Project      string    `json:"project,omitempty"`
+	Activities   int       `json:"activities"`
+	TotalSeconds int64     `json:"total_seconds"`
+	LastSeen     time.Time `json:"last_seen,omitzero"`
+}
+
+// FileStats est la réponse de FileStats
+type FileStats struct {
+	Period PeriodInfo `json:"period"`
+	Files  []FileStat `json:"files"`
+}
+
+// TerminalStat est le temps actif passé dans les terminaux pour une
+// commande, un hôte ssh ou un dossier
+type TerminalStat struct {
+	Value        string    `json:"value"`
+	Activities   int       `json:"activities"`
+	TotalSeconds int64     `json:"total_seconds"`
+	LastSeen     time.Time `json:"last_seen,omitzero"`
+}
+
+// TerminalStats est la réponse de TerminalStats
+type TerminalStats struct {
+	Period  PeriodInfo     `json:"period"`
+	GroupBy string         `json:"group_by"`
+	Stats   []TerminalStat `json:"stats"`
+}
+
+// RepoStat est le temps actif passé dans un dépôt git ; Repo est le dépôt
+// distant ("github.com/acme/api"), sinon la racine locale
+type RepoStat struct {
+	Repo         string    `json:"repo"`
+	Root         string    `json:"root"`
+	Branches     int       `json:"branches"`
+	Activities   int       `json:"activities"`
+	TotalSeconds int64     `json:"total_seconds"`
+	LastSeen     time.Time `json:"last_seen,omitzero"`
+}
+
+// RepoStats est la réponse de RepoStats
+type RepoStats struct {
+	Period PeriodInfo `json:"period"`
+	Repos  []RepoStat `json:"repos"`
+}
+
+// BranchStat est le temps actif passé sur une branche d'un dépôt git
+type BranchStat struct {
+	Repo         string    `json:"repo"`
+	Branch       string    `json:"branch"`
+	Activities   int       `json:"activities"`
+	TotalSeconds int64     `json:"total_seconds"`
+	LastSeen     time.Time `json:"last_seen,omitzero"`
+}
+
+// BranchStats est la réponse de BranchStats
+type BranchStats struct {
+	Period   PeriodInfo   `json:"period"`
+	Branches []BranchStat `json:"branches"`
+}
+
+// EntityStat est le temps actif passé sur une entité ; Kind vaut ticket,
+// repo, pr ou mr
+type EntityStat struct {
+	Kind         string    `json:"kind"`
+	Value        string    `json:"value"`
+	Activities   int       `json:"activities"`
+	TotalSeconds int64     `json:"total_seconds"`
+	FirstSeen    time.Time `json:"first_seen,omitzero"`
+	LastSeen     time.Time `json:"last_seen,omitzero"`
+}
+
+// EntityStats est la réponse de EntityStats
+type EntityStats struct {
+	Period   PeriodInfo   `json:"period"`
+	Entities []EntityStat `json:"entities"`
+}
+
+// EntityReport est la réponse de EntityReport
+type EntityReport struct {
+	Entity EntityStat   `json:"entity"`
+	Days   []DaySeconds `json:"days"`
+	Apps   []AppGroup   `json:"apps"`
+}
+
+// EntityPattern décrit comment extraire un type d'entité d'un champ (title
+// ou url) ; Format reprend les groupes de Pattern ($1 par défaut)
+type EntityPattern struct {
+	Kind    string `json:"kind"`
+	Field   string `json:"field"`
+	Pattern string `json:"pattern"`
+	Format  string `json:"format,omitempty"`
+	Exclude string `json:"exclude,omitempty"`
+}
+
+// EntityPatterns est le corps et la réponse de SetEntityPatterns
+type EntityPatterns struct {
+	Patterns []EntityPattern `json:"patterns"`
+}
+
+// Activity est une activité brute
+type Activity struct {
+	ID              int64     `json:"id"`
+	AppName         string    `json:"app_name"`
+	EnrichedName    string    `json:"enriched_name"`
+	Category        string    `json:"category,omitempty"`
+	Productivity    string    `json:"productivity,omitempty"`
+	Project         string    `json:"project,omitempty"`
+	Client          string    `json:"client,omitempty"`
+	Tags            []string  `json:"tags,omitempty"`
+	InferredSite    string    `json:"inferred_site,omitempty"`
+	SiteConfidence  *float64  `json:"site_confidence,omitempty"`
+	FileName        string    `json:"file_name,omitempty"`
+	Language        string    `json:"language,omitempty"`
+	Command         string    `json:"command,omitempty"`
+	WorkingDir      string    `json:"cwd,omitempty"`
+	SSHHost         string    `json:"ssh_host,omitempty"`
+	Repo            string    `json:"repo,omitempty"`
+	RepoRoot        string    `json:"repo_root,omitempty"`
+	Branch          string    `json:"branch,omitempty"`
+	URL             string    `json:"url,omitempty"`
+	WindowTitle     string    `json:"window_title"`
+	ProcessPath     string    `json:"process_path"`
+	StartTime       time.Time `json:"start_time"`
+	EndTime         time.Time `json:"end_time"`
+	DurationSeconds int64     `json:"duration_seconds"`
+	IsIdle          bool      `json:"is_idle"`
+}
+
+// Activities est la réponse de Activities
+type Activities struct {
+	Period     PeriodInfo `json:"period"`
+	Activities []Activity `json:"activities"`
+}
+
+// ShellEvent est le début (Event "start") ou la fin ("end") d'une commande
+// de shell ; Session identifie le shell et Seq la commande dans ce shell
+type ShellEvent struct {
+	Event      string    `json:"event"`
+	Session    string    `json:"session"`
+	Seq        int64     `json:"seq"`
+	Shell      string    `json:"shell,omitempty"`
+	Command    string    `json:"command,omitempty"`
+	WorkingDir string    `json:"cwd,omitempty"`
+	ExitCode   int       `json:"exit_code,omitempty"`
+	DurationMs int64     `json:"duration_ms,omitempty"`
+	Timestamp  time.Time `json:"timestamp,omitzero"`
+}
+
+// ShellEventResult est la réponse de SendShellEvent
+type ShellEventResult struct {
+	Status string `json:"status"`
+	ID     int64  `json:"id,omitempty"`
+}
+
+// ShellCommandFilter restreint ShellCommands ; Limit vaut 100 si nul
+type ShellCommandFilter struct {
+	Program string
+	Failed  bool
+	Limit   int
+}
+
+// ShellCommand est une commande lancée dans un shell équipé des hooks
+type ShellCommand struct {
+	ID           int64     `json:"id"`
+	Shell        string    `json:"shell"`
+	Session      string    `json:"session"`
+	Command      string    `json:"command"`
+	Program      string    `json:"program"`
+	WorkingDir   string    `json:"cwd,omitempty"`
+	StartedAt    time.Time `json:"started_at"`
+	EndedAt      time.Time `json:"ended_at,omitzero"`
+	DurationMs   int64     `json:"duration_ms"`
+	ExitCode     *int      `json:"exit_code,omitempty"`
+	ActivityID   int64     `json:"activity_id,omitempty"`
+	FocusSeconds int64     `json:"focus_seconds"`
+}
+
+// ShellCommands est la réponse de ShellCommands
+type ShellCommands struct {
+	Period   PeriodInfo     `json:"period"`
+	Commands []ShellCommand `json:"commands"`
+}
+
+// CommandStat est le temps passé dans les commandes d'un programme
+type CommandStat struct {
+	Program      string    `json:"program"`
+	Runs         int       `json:"runs"`
+	Failures     int       `json:"failures"`
+	TotalSeconds int64     `json:"total_seconds"`
+	LongestMs    int64     `json:"longest_ms"`
+	LastRun      time.Time `json:"last_run,omitzero"`
+}
+
+// CommandStats est la réponse de CommandStats
+type CommandStats struct {
+	Period   PeriodInfo    `json:"period"`
+	Programs []CommandStat `json:"programs"`
+}
+
+// BrowserEvent est un signal d'une extension navigateur sur l'onglet actif :
+// Type vaut focus, heartbeat (défaut) ou blur ; Timestamp est au format
+// RFC 3339 (heure de réception si vide)
+type BrowserEvent struct {
+	Type        string  `json:"type,omitempty"`
+	URL         string  `json:"url"`
+	TabTitle    string  `json:"tab_title"`
+	BrowserName string  `json:"browser_name"`
+	Timestamp   string  `json:"timestamp"`
+	Duration    float64 `json:"duration,omitempty"`
+}
+
+// BrowserEventResult est la réponse de SendBrowserEvents
+type BrowserEventResult struct {
+	Status   string `json:"status"`
+	Received int    `json:"received"`
+	Visits   int    `json:"visits"`
+}
+
+// DomainStat est le temps passé sur un domaine d'après l'extension navigateur
+type DomainStat struct {
+	Domain       string    `json:"domain"`
+	Visits       int       `json:"visits"`
+	TotalSeconds int64     `json:"total_seconds"`
+	LastSeen     time.Time `json:"last_seen,omitzero"`
+}
+
+// DomainStats est la réponse de DomainStats
+type DomainStats struct {
+	Period  PeriodInfo   `json:"period"`
+	Domains []DomainStat `json:"domains"`
+}
+
+// PathStat est le temps passé sur une page (domaine et chemin)
+type PathStat struct {
+	Domain       string    `json:"domain"`
+	Path         string    `json:"path"`
+	Visits       int       `json:"visits"`
+	TotalSeconds int64     `json:"total_seconds"`
+	LastSeen     time.Time `json:"last_seen,omitzero"`
+}
+
+// PathStats est la réponse de PathStats
+type PathStats struct {
+	Period PeriodInfo `json:"period"`
+	Paths  []PathStat `json:"paths"`
+}
+
+// PrivacyRule masque les données d'une fenêtre avant son enregistrement :
+// Action vaut drop, app_only, redact (passages du titre correspondant à
+// Pattern) ou hash (empreinte du titre)
+type PrivacyRule struct {
+	Name       string          `json:"name"`
+	Conditions []RuleCondition `json:"conditions"`
+	Action     string          `json:"action"`
+	Pattern    string          `json:"pattern,omitempty"`
+}
+
+// PrivacyRules est le corps et la réponse de SetPrivacyRules
+type PrivacyRules struct {
+	Rules []PrivacyRule `json:"rules"`
+}
+
+// SiteStat est le temps actif passé sur un site, un hôte ou une page
+type SiteStat struct {
+	Name         string    `json:"name"`
+	Domain       string    `json:"domain"`
+	Hosts        []string  `json:"hosts,omitempty"`
+	Activities   int       `json:"activities"`
+	TotalSeconds int64     `json:"total_seconds"`
+	TotalHours   float64   `json:"total_hours"`
+	LastSeen     time.Time `json:"last_seen,omitzero"`
+}
+
+// SiteStats est la réponse de SiteStats
+type SiteStats struct {
+	Period PeriodInfo `json:"period"`
+	Sites  []SiteStat `json:"sites"`
+}
+
+// SiteDetail est la réponse de SiteDetail
+type SiteDetail struct {
+	Period PeriodInfo `json:"period"`
+	Site   SiteStat   `json:"site"`
+	Hosts  []SiteStat `json:"hosts"`
+	Sites  []SiteStat `json:"sites"`
+	Pages  []SiteStat `json:"pages"`
+}
+
+// SiteRule regroupe les URL d'un domaine enregistrable par les Segments
+// premiers segments de leur chemin (1 = github.com/<org>)
+type SiteRule struct {
+	Domain   string `json:"domain"`
+	Segments int    `json:"segments"`
+}
+
+// SiteRules est le corps et la réponse de SetSiteRules
+type SiteRules struct {
+	Rules []SiteRule `json:"rules"`
+}
+
+// RuleCondition teste un champ de la fenêtre : Field vaut app, title, path,
+// url, enriched, command, cwd ou ssh_host ; Match vaut contains, equals,
+// glob ou regex
+type RuleCondition struct {
+	Field   string `json:"field"`
+	Match   string `json:"match"`
+	Pattern string `json:"pattern"`
+	Negate  bool   `json:"negate,omitempty"`
+}
+
+// RuleAction décrit ce qu'une règle applique à l'activité
+type RuleAction struct {
+	EnrichedName string   `json:"enriched_name,omitempty"`
+	Category     string   `json:"category,omitempty"`
+	Project      string   `json:"project,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	Idle         bool     `json:"idle,omitempty"`
+	Ignore       bool     `json:"ignore,omitempty"`
+	InferSite    bool     `json:"infer_site,omitempty"`
+}
+
+// Rule est une règle d'enrichissement
+type Rule struct {
+	ID         int64           `json:"id"`
+	Name       string          `json:"name"`
+	Position   int             `json:"position"`
+	Enabled    bool            `json:"enabled"`
+	Conditions []RuleCondition `json:"conditions"`
+	Action     RuleAction      `json:"action"`
+}
+
+// Rules est la réponse de Rules et ResetRules
+type Rules struct {
+	Rules []Rule `json:"rules"`
+}
+
+// RuleTest est la fenêtre fictive évaluée par TestRules
+type RuleTest struct {
+	AppName     string `json:"app_name"`
+	WindowTitle string `json:"window_title,omitempty"`
+	ProcessPath string `json:"process_path,omitempty"`
+	URL         string `json:"url,omitempty"`
+	Command     string `json:"command,omitempty"`
+	WorkingDir  string `json:"cwd,omitempty"`
+	SSHHost     string `json:"ssh_host,omitempty"`
+	Rules       []Rule `json:"rules,omitempty"` // règles à essayer à la place des règles enregistrées
+}
+
+// SiteGuess est le site déduit d'un titre d'onglet ou d'une URL
+type SiteGuess struct {
+	Name       string  `json:"name"`
+	Confidence float64 `json:"confidence"`
+}
+
+// RuleResult est le résultat des règles sur une fenêtre
+type RuleResult struct {
+	EnrichedName string     `json:"enriched_name"`
+	Category     string     `json:"category,omitempty"`
+	Project      string     `json:"project,omitempty"`
+	Tags         []string   `json:"tags,omitempty"`
+	Idle         bool       `json:"idle,omitempty"`
+	Ignored      bool       `json:"ignored,omitempty"`
+	Site         *SiteGuess `json:"site,omitempty"`
+	Matched      []string   `json:"matched,omitempty"`
+	MatchedIDs   []int64    `json:"matched_ids,omitempty"`
+}
+
+// FiredRule est une règle qui a modifié le résultat
+type FiredRule struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Position int    `json:"position"`
+}
+
+// RuleTestResult est la réponse de TestRules
+type RuleTestResult struct {
+	Result       RuleResult  `json:"result"`
+	Fired        []FiredRule `json:"fired"`
+	Unclassified bool        `json:"unclassified"`
+}
+
+// UnclassifiedTitle est un titre de fenêtre qu'aucune règle n'a nommé
+type UnclassifiedTitle struct {
+	AppName      string `json:"app_name"`
+	EnrichedName string `json:"enriched_name"`
+	WindowTitle  string `json:"window_title"`
+	Activities   int    `json:"activities"`
+	TotalSeconds int64  `json:"total_seconds"`
+}
+
+// RuleSuggestion est une règle candidate tirée d'un mot fréquent des titres non classés
+type RuleSuggestion struct {
+	Token   string `json:"token"`
+	AppName string `json:"app_name"`
+	Titles  int    `json:"titles"`
+	Seconds int64  `json:"seconds"`
+	Rule    Rule   `json:"rule"`
+}
+
+// Coverage est la réponse de Coverage
+type Coverage struct {
+	Period            PeriodInfo          `json:"period"`
+	TotalSeconds      int64               `json:"total_seconds"`
+	ClassifiedSeconds int64               `json:"classified_seconds"`
+	FallbackSeconds   int64               `json:"fallback_seconds"`
+	OtherSeconds      int64               `json:"other_seconds"`
+	Coverage          float64             `json:"coverage"`
+	Titles            []UnclassifiedTitle `json:"titles"`
+	Suggestions       []RuleSuggestion    `json:"suggestions"`
+}
+
+// InputHour est l'activité du clavier et de la souris sur une heure locale
+type InputHour struct {
+	Hour    int   `json:"hour"`
+	Presses int64 `json:"presses"`
+	Clicks  int64 `json:"clicks"`
+}
+
+// InputStats est la réponse de InputStats
+type InputStats struct {
+	Period        PeriodInfo  `json:"period"`
+	Presses       int64       `json:"presses"`
+	Clicks        int64       `json:"clicks"`
+	MouseMoved    float64     `json:"mouse_moved"`
+	Scrolled      float64     `json:"scrolled"`
+	ActiveSeconds int64       `json:"active_seconds"`
+	Hours         []InputHour `json:"hours"`
+}
+
+// Heartbeat est un heartbeat au format de l'API WakaTime
+type Heartbeat struct {
+	Entity    string  `json:"entity"`
+	Type      string  `json:"type,omitempty"`
+	Category  string  `json:"category,omitempty"`
+	Time      float64 `json:"time"`
+	Project   string  `json:"project,omitempty"`
+	Branch    string  `json:"branch,omitempty"`
+	Language  string  `json:"language,omitempty"`
+	IsWrite   bool    `json:"is_write,omitempty"`
+	Lines     int     `json:"lines,omitempty"`
+	LineNo    int     `json:"lineno,omitempty"`
+	UserAgent string  `json:"user_agent,omitempty"`
+}
+
+// WakaTimeDuration est un temps au format des résumés WakaTime
+type WakaTimeDuration struct {
+	Name         string  `json:"name,omitempty"`
+	TotalSeconds float64 `json:"total_seconds"`
+	Percent      float64 `json:"percent,omitempty"`
+	Digital      string  `json:"digital"`
+	Decimal      string  `json:"decimal"`
+	Text         string  `json:"text"`
+	Hours        int     `json:"hours"`
+	Minutes      int     `json:"minutes"`
+	Seconds      int     `json:"seconds"`
+}
+
+// WakaTimeSummary est le résumé WakaTime d'une journée
+type WakaTimeSummary struct {
+	GrandTotal WakaTimeDuration   `json:"grand_total"`
+	Categories []WakaTimeDuration `json:"categories"`
+	Editors    []WakaTimeDuration `json:"editors"`
+	Languages  []WakaTimeDuration `json:"languages"`
+	Projects   []WakaTimeDuration `json:"projects"`
+	Branches   []WakaTimeDuration `json:"branches"`
+	Range      struct {
+		Date     string    `json:"date"`
+		Start    time.Time `json:"start"`
+		End      time.Time `json:"end"`
+		Text     string    `json:"text"`
+		Timezone string    `json:"timezone"`
+	} `json:"range"`
+}
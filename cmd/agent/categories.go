@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"trackmytime/config"
+	"trackmytime/internal/rules"
+	"trackmytime/internal/storage"
+)
+
+const categoriesUsage = `Usage: trackmytime categories <commande> [arguments]
+
+Commandes:
+  list                          Lister les catégories et leur productivité
+  set <nom> <productivité>      Créer ou reclasser une catégorie (productive, neutral, distracting)
+  rm <nom>                      Supprimer une catégorie (ses activités deviennent neutres)
+
+Les catégories sont attribuées aux activités par les règles (trackmytime rules).
+Un changement de productivité s'applique aussi à l'historique.
+`
+
+// runCategories exécute la commande "trackmytime categories" et retourne le code de sortie
+func runCategories(args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "help" {
+		fmt.Fprint(os.Stderr, categoriesUsage)
+		return 2
+	}
+
+	cfg := config.DefaultConfig()
+	db, err := storage.NewDB(cfg.DBPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Erreur connexion DB: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	if err := ensureDefaults(db); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Erreur installation des catégories par défaut: %v\n", err)
+		return 1
+	}
+
+	cmd, args := args[0], args[1:]
+	switch cmd {
+	case "list":
+		err = listCategories(db)
+	case "set":
+		err = setCategory(db, args)
+	case "rm":
+		if len(args) != 1 {
+			err = errors.New("usage: trackmytime categories rm <nom>")
+		} else {
+			err = db.DeleteCategory(args[0])
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "❌ Commande inconnue: %s\n\n%s", cmd, categoriesUsage)
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// listCategories affiche les catégories sous forme de tableau
+func listCategories(db *storage.DB) error {
+	categories, err := db.ListCategories()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CATÉGORIE\tPRODUCTIVITÉ")
+	for _, c := range categories {
+		fmt.Fprintf(w, "%s\t%s\n", c.Name, c.Productivity)
+	}
+	return w.Flush()
+}
+
+// setCategory crée ou reclasse une catégorie
+func setCategory(db *storage.DB, args []string) error {
+	if len(args) != 2 {
+		return errors.New("usage: trackmytime categories set <nom> <productive|neutral|distracting>")
+	}
+	productivity, err := rules.ParseProductivity(args[1])
+	if err != nil {
+		return err
+	}
+	if err := db.SetCategory(rules.Category{Name: args[0], Productivity: productivity}); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Catégorie %s: %s\n", args[0], productivity)
+	return nil
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"trackmytime/config"
+	"trackmytime/internal/storage"
+	"trackmytime/internal/vault"
+)
+
+const encryptionUsage = `Usage: trackmytime encryption <commande> [arguments]
+
+Commandes:
+  status                              Afficher l'état du chiffrement
+  enable  [-keyring | -passphrase-file <fichier>]
+                                      Chiffrer les titres, chemins et URL enregistrés
+  rotate  [-keyring | -passphrase-file <fichier>]
+                                      Rechiffrer avec une nouvelle clé
+  disable                             Remettre les titres, chemins et URL en clair
+
+Sont chiffrés en AES-256-GCM : les titres de fenêtres, chemins des
+processus et URL des activités, les URL et titres des onglets signalés par
+l'extension, les données des événements ActivityWatch de fenêtre, d'onglet
+et d'éditeur, et le dernier titre des sites candidats. La clé est gardée
+dans le trousseau du système (par défaut) ou dérivée d'une phrase de passe
+lue dans un fichier (chmod 600). Les statistiques n'utilisent que des
+colonnes en clair (application, nom enrichi, catégorie, projet, domaine et
+chemin des onglets) et un index des titres ; seules les statistiques par
+site déchiffrent les URL des activités. L'agent en cours d'exécution prend
+en compte les changements de clé.
+`
+
+// runEncryption exécute la commande "trackmytime encryption" et retourne le code de sortie
+func runEncryption(args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "help" {
+		fmt.Fprint(os.Stderr, encryptionUsage)
+		return 2
+	}
+
+	cfg := config.DefaultConfig()
+	db, err := storage.NewDB(cfg.DBPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Erreur connexion DB: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	cmd, args := args[0], args[1:]
+	switch cmd {
+	case "status":
+		err = encryptionStatus(db)
+	case "enable":
+		err = enableEncryption(db, args)
+	case "rotate":
+		err = rotateEncryption(db, args)
+	case "disable":
+		err = disableEncryption(db)
+	default:
+		fmt.Fprintf(os.Stderr, "❌ Commande inconnue: %s\n\n%s", cmd, encryptionUsage)
+		return 2
+	}
+
+	if errors.Is(err, flag.ErrHelp) {
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// encryptionStatus affiche la clé en vigueur et, par table, la part des
+// lignes chiffrées
+func encryptionStatus(db *storage.DB) error {
+	settings, err := db.Encryption()
+	if err != nil {
+		return err
+	}
+	counts, err := db.EncryptionCounts()
+	if err != nil {
+		return err
+	}
+	if settings == nil {
+		fmt.Println("🔓 Chiffrement désactivé")
+	} else {
+		fmt.Printf("🔐 Chiffrement activé : clé %s, %s\n", settings.KeyID, settings.Describe())
+		fmt.Printf("   Clé créée le %s\n", settings.CreatedAt.Local().Format("2006-01-02 15:04"))
+	}
+	for _, c := range counts {
+		fmt.Printf("   %-16s %d lignes à chiffrer, dont %d chiffrées\n", c.Table, c.Total, c.Sealed)
+	}
+	return nil
+}
+
+// keyFlags analyse la source de la nouvelle clé
+func keyFlags(name string, args []string) (vault.Source, string, error) {
+	fs := flag.NewFlagSet("encryption "+name, flag.ContinueOnError)
+	useKeyring := fs.Bool("keyring", false, "Garder la clé dans le trousseau du système (défaut)")
+	passphraseFile := fs.String("passphrase-file", "", "Dériver la clé de la phrase de passe de ce fichier")
+	if err := fs.Parse(args); err != nil {
+		return "", "", err
+	}
+	if fs.NArg() > 0 {
+		return "", "", fmt.Errorf("argument inattendu: %s", fs.Arg(0))
+	}
+	if *passphraseFile == "" {
+		return vault.SourceKeyring, "", nil
+	}
+	if *useKeyring {
+		return "", "", errors.New("-keyring et -passphrase-file sont exclusifs")
+	}
+	path, err := filepath.Abs(*passphraseFile)
+	if err != nil {
+		return "", "", err
+	}
+	return vault.SourcePassphrase, path, nil
+}
+
+// enableEncryption crée une clé et chiffre l'historique
+func enableEncryption(db *storage.DB, args []string) error {
+	source, path, err := keyFlags("enable", args)
+	if err != nil {
+		return err
+	}
+	current, err := db.Encryption()
+	if err != nil {
+		return err
+	}
+	if current != nil {
+		return fmt.Errorf("chiffrement déjà activé (clé %s) ; \"trackmytime encryption rotate\" change la clé", current.KeyID)
+	}
+
+	settings, err := replaceKey(db, source, path)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("   Clé %s : %s\n", settings.KeyID, settings.Describe())
+	if source == vault.SourcePassphrase {
+		fmt.Println("⚠️  Sans ce fichier, les titres, chemins et URL enregistrés sont perdus : gardez-en une copie.")
+	}
+	return nil
+}
+
+// rotateEncryption rechiffre l'historique avec une nouvelle clé et retire
+// l'ancienne du trousseau
+func rotateEncryption(db *storage.DB, args []string) error {
+	source, path, err := keyFlags("rotate", args)
+	if err != nil {
+		return err
+	}
+	current, err := db.Encryption()
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return errors.New("chiffrement désactivé ; \"trackmytime encryption enable\" l'active")
+	}
+
+	settings, err := replaceKey(db, source, path)
+	if err != nil {
+		return err
+	}
+	if err := current.Forget(); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Ancienne clé %s non retirée du trousseau: %v\n", current.KeyID, err)
+	}
+	fmt.Printf("   Clé %s remplacée par %s : %s\n", current.KeyID, settings.KeyID, settings.Describe())
+	return nil
+}
+
+// replaceKey crée une clé et y convertit l'historique ; la clé est retirée
+// du trousseau si la conversion échoue
+func replaceKey(db *storage.DB, source vault.Source, path string) (vault.Settings, error) {
+	settings, c, err := vault.NewKey(source, path)
+	if err != nil {
+		return vault.Settings{}, err
+	}
+	n, err := db.SetEncryption(&settings, c)
+	if err != nil {
+		settings.Forget()
+		return vault.Settings{}, err
+	}
+	fmt.Printf("🔐 %d lignes chiffrées\n", n)
+	return settings, nil
+}
+
+// disableEncryption remet l'historique en clair et retire la clé du trousseau
+func disableEncryption(db *storage.DB) error {
+	current, err := db.Encryption()
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		fmt.Println("🔓 Chiffrement déjà désactivé")
+		return nil
+	}
+	n, err := db.SetEncryption(nil, nil)
+	if err != nil {
+		return err
+	}
+	if err := current.Forget(); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Clé %s non retirée du trousseau: %v\n", current.KeyID, err)
+	}
+	fmt.Printf("🔓 %d lignes remises en clair, clé %s abandonnée\n", n, current.KeyID)
+	return nil
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"trackmytime/config"
+	"trackmytime/internal/entities"
+	"trackmytime/internal/storage"
+)
+
+const entitiesUsage = `Usage: trackmytime entities <commande> [arguments]
+
+Commandes:
+  top [-kind type] [-days 7] [-limit 20]
+                          Tickets, dépôts, PR et MR les plus travaillés
+  show <type> <valeur>    Temps passé sur une entité, par jour et par application
+  patterns [export]       Afficher les motifs d'extraction en JSON
+  patterns import <fichier>
+                          Remplacer les motifs d'extraction par ceux du fichier JSON
+  patterns reset          Revenir aux motifs d'extraction par défaut
+
+Types: ticket (PROJ-1234), repo (acme/web), pr (acme/web#567), mr (acme/web!89).
+Les nouveaux motifs s'appliquent aux activités suivantes ; "trackmytime
+reprocess" les applique à l'historique.
+`
+
+// runEntities exécute la commande "trackmytime entities" et retourne le code de sortie
+func runEntities(args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "help" {
+		fmt.Fprint(os.Stderr, entitiesUsage)
+		return 2
+	}
+
+	cfg := config.DefaultConfig()
+	db, err := storage.NewDB(cfg.DBPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Erreur connexion DB: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	cmd, args := args[0], args[1:]
+	switch cmd {
+	case "top":
+		err = topEntities(db, args)
+	case "show":
+		err = showEntity(db, args)
+	case "patterns":
+		err = entityPatterns(db, args)
+	default:
+		fmt.Fprintf(os.Stderr, "❌ Commande inconnue: %s\n\n%s", cmd, entitiesUsage)
+		return 2
+	}
+
+	if errors.Is(err, flag.ErrHelp) {
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// topEntities affiche les entités les plus travaillées des derniers jours
+func topEntities(db *storage.DB, args []string) error {
+	fs := flag.NewFlagSet("entities top", flag.ContinueOnError)
+	kind := fs.String("kind", "", "Un seul type d'entité (ticket, repo, pr, mr)")
+	days := fs.Int("days", 7, "Nombre de jours analysés, aujourd'hui compris")
+	limit := fs.Int("limit", 20, "Nombre d'entités affichées")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *days <= 0 || *limit <= 0 {
+		return errors.New("-days et -limit doivent être positifs")
+	}
+	if *kind != "" {
+		if err := checkEntityKind(*kind); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+	start := end.AddDate(0, 0, -*days)
+
+	stats, err := db.GetStatsByEntity(start, end, entities.Kind(*kind), *limit)
+	if err != nil {
+		return err
+	}
+	if len(stats) == 0 {
+		fmt.Printf("Aucune entité sur les %d derniers jours\n", *days)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tENTITÉ\tACTIVITÉS\tTEMPS ACTIF\tDERNIÈRE FOIS")
+	for _, s := range stats {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", s.Kind, s.Value, s.Activities,
+			time.Duration(s.Seconds)*time.Second, s.LastSeen.Local().Format("2006-01-02 15:04"))
+	}
+	return w.Flush()
+}
+
+// showEntity affiche le temps passé sur une entité sur tout l'historique
+func showEntity(db *storage.DB, args []string) error {
+	if len(args) != 2 {
+		return errors.New("usage: trackmytime entities show <type> <valeur>")
+	}
+	if err := checkEntityKind(args[0]); err != nil {
+		return err
+	}
+	kind, value := entities.Kind(args[0]), args[1]
+
+	stat, err := db.GetEntityStat(kind, value)
+	if err != nil {
+		return err
+	}
+	if stat.Activities == 0 {
+		return fmt.Errorf("entité introuvable: %s %s", kind, value)
+	}
+	daily, err := db.GetEntityDailyStats(kind, value)
+	if err != nil {
+		return err
+	}
+	grouped, err := db.GetEntityGroupedStats(kind, value)
+	if err != nil {
+		return err
+	}
+
+	duration := func(seconds int64) time.Duration { return time.Duration(seconds) * time.Second }
+	fmt.Printf("🔗 %s %s: %s sur %d activités (du %s au %s)\n", kind, value, duration(stat.Seconds), stat.Activities,
+		stat.FirstSeen.Local().Format("2006-01-02"), stat.LastSeen.Local().Format("2006-01-02"))
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "JOUR\tTEMPS ACTIF")
+	for _, d := range daily {
+		fmt.Fprintf(w, "%s\t%s\n", d.Date.Format("2006-01-02"), duration(d.Seconds))
+	}
+	w.Flush()
+
+	type appTime struct {
+		app, name string
+		seconds   int64
+	}
+	var apps []appTime
+	for app, names := range grouped {
+		for name, seconds := range names {
+			apps = append(apps, appTime{app, name, seconds})
+		}
+	}
+	sort.Slice(apps, func(i, j int) bool {
+		if apps[i].seconds != apps[j].seconds {
+			return apps[i].seconds > apps[j].seconds
+		}
+		return apps[i].name < apps[j].name
+	})
+
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "APPLICATION\tNOM ENRICHI\tTEMPS ACTIF")
+	for _, a := range apps {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", a.app, a.name, duration(a.seconds))
+	}
+	return w.Flush()
+}
+
+// entityPatterns affiche, importe ou réinitialise les motifs d'extraction
+func entityPatterns(db *storage.DB, args []string) error {
+	if len(args) == 0 || args[0] == "export" {
+		patterns, err := db.EntityPatterns()
+		if err != nil {
+			return err
+		}
+		return writeRulesJSON(os.Stdout, patterns)
+	}
+
+	switch args[0] {
+	case "import":
+		if len(args) != 2 {
+			return errors.New("usage: trackmytime entities patterns import <fichier>")
+		}
+		data, err := os.ReadFile(args[1])
+		if err != nil {
+			return err
+		}
+		var patterns []entities.Pattern
+		if err := json.Unmarshal(data, &patterns); err != nil {
+			return fmt.Errorf("fichier de motifs invalide: %w", err)
+		}
+		if patterns == nil {
+			patterns = []entities.Pattern{}
+		}
+		if _, err := entities.Compile(patterns); err != nil {
+			return err
+		}
+		if err := db.SetEntityPatterns(patterns); err != nil {
+			return err
+		}
+		fmt.Printf("✅ %d motifs importés\n", len(patterns))
+	case "reset":
+		if err := db.SetEntityPatterns(nil); err != nil {
+			return err
+		}
+		fmt.Println("✅ Motifs d'extraction par défaut rétablis")
+	default:
+		return fmt.Errorf("commande patterns inconnue: %s", args[0])
+	}
+	return nil
+}
+
+// checkEntityKind vérifie un type d'entité saisi
+func checkEntityKind(kind string) error {
+	for _, k := range entities.Kinds {
+		if entities.Kind(kind) == k {
+			return nil
+		}
+	}
+	return fmt.Errorf("type d'entité inconnu %q (ticket, repo, pr, mr)", kind)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"trackmytime/config"
+	"trackmytime/internal/storage"
+)
+
+const filesUsage = `Usage: trackmytime files <commande> [options]
+
+Commandes:
+  top [-project nom] [-days 7] [-limit 20]
+                          Fichiers les plus travaillés dans les éditeurs
+  languages [-project nom] [-days 7]
+                          Temps passé par langage de programmation
+
+Le fichier ouvert est lu dans le titre des fenêtres VSCode, Cursor, JetBrains,
+Vim et Emacs ; "trackmytime reprocess" l'extrait de l'historique.
+`
+
+// runFiles exécute la commande "trackmytime files" et retourne le code de sortie
+func runFiles(args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "help" {
+		fmt.Fprint(os.Stderr, filesUsage)
+		return 2
+	}
+
+	cfg := config.DefaultConfig()
+	db, err := storage.NewDB(cfg.DBPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Erreur connexion DB: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	cmd, args := args[0], args[1:]
+	switch cmd {
+	case "top":
+		err = topFiles(db, args)
+	case "languages":
+		err = languageStats(db, args)
+	default:
+		fmt.Fprintf(os.Stderr, "❌ Commande inconnue: %s\n\n%s", cmd, filesUsage)
+		return 2
+	}
+
+	if errors.Is(err, flag.ErrHelp) {
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// fileOptions sont les options communes de files top et files languages
+type fileOptions struct {
+	project    string
+	days       int
+	limit      int
+	start, end time.Time
+}
+
+// parseFileFlags lit les options d'une sous-commande de files
+func parseFileFlags(name string, args []string, withLimit bool) (fileOptions, error) {
+	var opts fileOptions
+	fs := flag.NewFlagSet("files "+name, flag.ContinueOnError)
+	fs.StringVar(&opts.project, "project", "", "Uniquement les fichiers de ce projet")
+	fs.IntVar(&opts.days, "days", 7, "Nombre de jours analysés, aujourd'hui compris")
+	if withLimit {
+		fs.IntVar(&opts.limit, "limit", 20, "Nombre de fichiers affichés")
+	}
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	if opts.days <= 0 || (withLimit && opts.limit <= 0) {
+		return opts, errors.New("-days et -limit doivent être positifs")
+	}
+
+	now := time.Now()
+	opts.end = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+	opts.start = opts.end.AddDate(0, 0, -opts.days)
+	return opts, nil
+}
+
+// topFiles affiche les fichiers les plus travaillés des derniers jours
+func topFiles(db *storage.DB, args []string) error {
+	opts, err := parseFileFlags("top", args, true)
+	if err != nil {
+		return err
+	}
+
+	stats, err := db.GetTopFiles(opts.start, opts.end, opts.project, opts.limit)
+	if err != nil {
+		return err
+	}
+	if len(stats) == 0 {
+		fmt.Printf("Aucun fichier sur les %d derniers jours\n", opts.days)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FICHIER\tLANGAGE\tPROJET\tTEMPS ACTIF\tDERNIÈRE FOIS")
+	for _, s := range stats {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", s.FileName, s.Language, s.Project,
+			time.Duration(s.Seconds)*time.Second, s.LastSeen.Local().Format("2006-01-02 15:04"))
+	}
+	return w.Flush()
+}
+
+// languageStats affiche le temps passé par langage des derniers jours
+func languageStats(db *storage.DB, args []string) error {
+	opts, err := parseFileFlags("languages", args, false)
+	if err != nil {
+		return err
+	}
+
+	stats, err := db.GetStatsByLanguage(opts.start, opts.end, opts.project)
+	if err != nil {
+		return err
+	}
+	if len(stats) == 0 {
+		fmt.Printf("Aucun fichier sur les %d derniers jours\n", opts.days)
+		return nil
+	}
+
+	var total int64
+	for _, s := range stats {
+		total += s.Seconds
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "LANGAGE\tFICHIERS\tTEMPS ACTIF\tPART")
+	for _, s := range stats {
+		language := s.Language
+		if language == "" {
+			language = "(inconnu)"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%.0f%%\n", language, s.Files,
+			time.Duration(s.Seconds)*time.Second, float64(s.Seconds)*100/float64(total))
+	}
+	return w.Flush()
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"trackmytime/config"
+	"trackmytime/internal/storage"
+)
+
+const gitUsage = `Usage: trackmytime git <commande> [options]
+
+Commandes:
+  repos [-days 7] [-limit 20]
+                          Temps passé par dépôt git
+  branches [-repo dépôt] [-days 7] [-limit 20]
+                          Temps passé par branche, d'un seul dépôt avec -repo
+                          (distant comme github.com/acme/api ou racine locale)
+
+Le dépôt est celui du dossier courant des terminaux ou de l'espace de travail
+des éditeurs ; racine, distant et branche sont lus localement dans .git.
+`
+
+// runGit exécute la commande "trackmytime git" et retourne le code de sortie
+func runGit(args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "help" {
+		fmt.Fprint(os.Stderr, gitUsage)
+		return 2
+	}
+
+	cfg := config.DefaultConfig()
+	db, err := storage.NewDB(cfg.DBPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Erreur connexion DB: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	cmd, args := args[0], args[1:]
+	switch cmd {
+	case "repos":
+		err = repoStats(db, args)
+	case "branches":
+		err = branchStats(db, args)
+	default:
+		fmt.Fprintf(os.Stderr, "❌ Commande inconnue: %s\n\n%s", cmd, gitUsage)
+		return 2
+	}
+
+	if errors.Is(err, flag.ErrHelp) {
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// gitOptions sont les options communes de git repos et git branches
+type gitOptions struct {
+	repo       string
+	days       int
+	limit      int
+	start, end time.Time
+}
+
+// parseGitFlags lit les options d'une sous-commande de git
+func parseGitFlags(name string, args []string, withRepo bool) (gitOptions, error) {
+	var opts gitOptions
+	fs := flag.NewFlagSet("git "+name, flag.ContinueOnError)
+	if withRepo {
+		fs.StringVar(&opts.repo, "repo", "", "Uniquement les branches de ce dépôt")
+	}
+	fs.IntVar(&opts.days, "days", 7, "Nombre de jours analysés, aujourd'hui compris")
+	fs.IntVar(&opts.limit, "limit", 20, "Nombre de lignes affichées")
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	if opts.days <= 0 || opts.limit <= 0 {
+		return opts, errors.New("-days et -limit doivent être positifs")
+	}
+
+	now := time.Now()
+	opts.end = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+	opts.start = opts.end.AddDate(0, 0, -opts.days)
+	return opts, nil
+}
+
+// repoStats affiche le temps passé par dépôt des derniers jours
+func repoStats(db *storage.DB, args []string) error {
+	opts, err := parseGitFlags("repos", args, false)
+	if err != nil {
+		return err
+	}
+
+	stats, err := db.GetRepoStats(opts.start, opts.end, opts.limit)
+	if err != nil {
+		return err
+	}
+	if len(stats) == 0 {
+		fmt.Printf("Aucun dépôt git sur les %d derniers jours\n", opts.days)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DÉPÔT\tRACINE\tBRANCHES\tTEMPS ACTIF\tDERNIÈRE FOIS")
+	for _, s := range stats {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", s.Repo, s.Root, s.Branches,
+			time.Duration(s.Seconds)*time.Second, s.LastSeen.Local().Format("2006-01-02 15:04"))
+	}
+	return w.Flush()
+}
+
+// branchStats affiche le temps passé par branche des derniers jours
+func branchStats(db *storage.DB, args []string) error {
+	opts, err := parseGitFlags("branches", args, true)
+	if err != nil {
+		return err
+	}
+
+	stats, err := db.GetBranchStats(opts.start, opts.end, opts.repo, opts.limit)
+	if err != nil {
+		return err
+	}
+	if len(stats) == 0 {
+		fmt.Printf("Aucune branche git sur les %d derniers jours\n", opts.days)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DÉPÔT\tBRANCHE\tACTIVITÉS\tTEMPS ACTIF\tDERNIÈRE FOIS")
+	for _, s := range stats {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", s.Repo, s.Branch, s.Activities,
+			time.Duration(s.Seconds)*time.Second, s.LastSeen.Local().Format("2006-01-02 15:04"))
+	}
+	return w.Flush()
+}
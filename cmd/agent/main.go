@@ -1,7 +1,11 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"log"
+	"net"
 	"os"
 	"os/signal"
 	"syscall"
@@ -9,253 +13,535 @@ import (
 
 	"trackmytime/config"
 	"trackmytime/internal/api"
+	"trackmytime/internal/auth"
+	"trackmytime/internal/privacy"
+	"trackmytime/internal/rules"
 	"trackmytime/internal/storage"
 	"trackmytime/internal/tracker"
 )
 
 func main() {
-	log.Println("🚀 TrackMyTime Agent démarrage...")
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "rules":
+			os.Exit(runRules(os.Args[2:]))
+		case "categories":
+			os.Exit(runCategories(os.Args[2:]))
+		case "projects":
+			os.Exit(runProjects(os.Args[2:]))
+		case "tags":
+			os.Exit(runTags(os.Args[2:]))
+		case "entities":
+			os.Exit(runEntities(os.Args[2:]))
+		case "files":
+			os.Exit(runFiles(os.Args[2:]))
+		case "terminal":
+			os.Exit(runTerminal(os.Args[2:]))
+		case "git":
+			os.Exit(runGit(os.Args[2:]))
+		case "shell-init":
+			os.Exit(runShellInit(os.Args[2:]))
+		case "shell-event":
+			os.Exit(runShellEvent(os.Args[2:]))
+		case "commands":
+			os.Exit(runCommands(os.Args[2:]))
+		case "wakatime":
+			os.Exit(runWakaTime(os.Args[2:]))
+		case "privacy":
+			os.Exit(runPrivacy(os.Args[2:]))
+		case "encryption":
+			os.Exit(runEncryption(os.Args[2:]))
+		case "reprocess":
+			os.Exit(runReprocess(os.Args[2:]))
+		}
+	}
+	os.Exit(run())
+}
 
+// run démarre l'agent et retourne le code de sortie du processus
+func run() int {
 	// Charger la configuration
 	cfg := config.DefaultConfig()
+
+	dev := flag.Bool("dev", false, "Servir le dashboard depuis le disque (développement frontend)")
+	flag.StringVar(&cfg.WebDir, "web-dir", "", "Dossier du dashboard à servir depuis le disque (implique -dev, défaut: web)")
+	flag.StringVar(&cfg.APIHost, "host", cfg.APIHost, "Adresse d'écoute de l'API (0.0.0.0 pour toutes les interfaces)")
+	flag.StringVar(&cfg.APIPort, "port", cfg.APIPort, "Port de l'API")
+	flag.StringVar(&cfg.ActivityWatchPort, "aw-port", cfg.ActivityWatchPort, "Port de l'API compatible ActivityWatch, ex: 5600 (désactivée par défaut)")
+	flag.StringVar(&cfg.DevToolsURL, "devtools-url", cfg.DevToolsURL, "Endpoint DevTools d'un navigateur lancé avec --remote-debugging-port, ex: http://127.0.0.1:9222 (désactivé par défaut)")
+	flag.Func("allow-origin", "Origine autorisée en CORS (répétable), ex: chrome-extension://<id>", func(origin string) error {
+		cfg.AllowedOrigins = append(cfg.AllowedOrigins, origin)
+		return nil
+	})
+	flag.Parse()
+	if *dev && cfg.WebDir == "" {
+		cfg.WebDir = "web"
+	}
+
+	log.Println("🚀 TrackMyTime Agent démarrage...")
 	log.Printf("📁 Base de données: %s", cfg.DBPath)
 	log.Printf("⏱️  Intervalle de vérification: %v", cfg.CheckInterval)
 	log.Printf("💤 Seuil d'inactivité: %v", cfg.IdleThreshold)
 
+	// Le contexte est annulé à la réception de SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Connexion à la base de données
 	db, err := storage.NewDB(cfg.DBPath)
 	if err != nil {
-		log.Fatalf("❌ Erreur connexion DB: %v", err)
+		log.Printf("❌ Erreur connexion DB: %v", err)
+		return 1
 	}
-	defer db.Close()
 	log.Println("✅ Base de données initialisée")
+	if settings, err := db.Encryption(); err != nil {
+		log.Printf("⚠️  Erreur lecture du chiffrement: %v", err)
+	} else if settings != nil {
+		log.Printf("🔐 Titres, chemins et URL chiffrés (clé %s, %s)", settings.KeyID, settings.Describe())
+	}
 
-	// Créer le détecteur d'inactivité
-	idleDetector := tracker.NewIdleDetector(cfg.IdleThreshold)
+	// Règles d'enrichissement : installées au premier lancement puis rechargées si modifiées
+	if err := ensureDefaults(db); err != nil {
+		log.Printf("⚠️  Erreur installation des règles par défaut: %v", err)
+	}
+	rl := &ruleLoader{db: db}
+	rl.reload()
+	if sites, err := db.ConfirmedSites(rules.SiteConfirmations); err != nil {
+		log.Printf("⚠️  Erreur lecture des sites appris: %v", err)
+	} else {
+		tracker.ConfirmSites(sites...)
+	}
 
 	// Démarrer le serveur API en arrière-plan
+	var apiServer *api.Server
+	var apiErrChan chan error
 	if cfg.EnableAPI {
-		apiServer := api.NewServer(db, cfg.APIPort)
+		tokens, err := auth.LoadOrCreateTokens(cfg.TokenPath)
+		if err != nil {
+			log.Printf("❌ Erreur jetons API: %v", err)
+			db.Close()
+			return 1
+		}
+
+		addr := net.JoinHostPort(cfg.APIHost, cfg.APIPort)
+		apiServer = api.NewServer(db, addr)
+		apiServer.SetTokens(tokens)
+		apiServer.SetAllowedOrigins(cfg.AllowedOrigins)
+		if cfg.WebDir != "" {
+			apiServer.UseWebDir(cfg.WebDir)
+			log.Printf("🛠️  Dashboard servi depuis le disque: %s", cfg.WebDir)
+		}
+		if cfg.ActivityWatchPort != "" {
+			apiServer.EnableActivityWatch(cfg.ActivityWatchPort)
+		}
+		// Le jeton n'est pas journalisé : les logs finissent dans journald ou syslog
+		if token := tokens.Find(string(auth.ScopeRead)); token != nil {
+			log.Printf("🔑 Dashboard web disponible sur http://%s/?token=<jeton>, avec le jeton %q de %s",
+				addr, token.Name, cfg.TokenPath)
+		}
+		apiErrChan = make(chan error, 1)
 		go func() {
-			if err := apiServer.Start(); err != nil {
-				log.Printf("⚠️  Erreur serveur API: %v", err)
-			}
+			apiErrChan <- apiServer.Start()
 		}()
 	}
 
-	// Variables pour le tracking
-	var currentWindow *tracker.WindowInfo
-	var activityStartTime time.Time
-	var wasIdle bool
-	var idleStartTime time.Time
+	t := &activityTracker{
+		db:           db,
+		idleDetector: tracker.NewIdleDetector(cfg.IdleThreshold),
+	}
+	if apiServer != nil {
+		t.onChange = apiServer.SetCurrentActivity
+	}
+	if cfg.DevToolsURL != "" {
+		t.devtools = tracker.NewDevToolsSource(cfg.DevToolsURL)
+		log.Printf("🌐 URL des onglets lue via DevTools: %s", cfg.DevToolsURL)
+	}
 
 	// Ticker pour vérifier la fenêtre active
 	ticker := time.NewTicker(cfg.CheckInterval)
 	defer ticker.Stop()
-
-	// Gérer l'arrêt propre
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	rulesTicker := time.NewTicker(cfg.RulesReloadInterval)
+	defer rulesTicker.Stop()
 
 	log.Println("🎯 Agent démarré - tracking en cours...")
 
 	// Boucle principale
+	var apiErr error
+loop:
 	for {
 		select {
 		case <-ticker.C:
-			// Vérifier si l'utilisateur est idle
-			isIdle, err := idleDetector.IsIdle()
+			t.tick()
+
+		case <-rulesTicker.C:
+			rl.reload()
+
+		case err := <-apiErrChan:
+			// Le tracking continue même si l'API n'a pas pu démarrer
 			if err != nil {
-				log.Printf("⚠️  Erreur détection idle: %v", err)
-				continue
+				log.Printf("⚠️  Erreur serveur API: %v", err)
+				apiErr = err
 			}
+			apiErrChan = nil
+			apiServer = nil
 
-			// Si l'utilisateur devient idle
-			if isIdle && !wasIdle {
-				if currentWindow != nil {
-					// Enregistrer l'activité avant l'idle
-					endTime := time.Now()
-					duration := endTime.Sub(activityStartTime)
-
-					activity := &storage.Activity{
-						AppName:      currentWindow.AppName,
-						WindowTitle:  currentWindow.WindowTitle,
-					EnrichedName: currentWindow.GetEnrichedName(),
-						ProcessPath:  currentWindow.ProcessPath,
-						StartTime:    activityStartTime,
-						EndTime:      endTime,
-						DurationSecs: int64(duration.Seconds()),
-						IsIdle:       false,
-					}
-
-					if err := db.InsertActivity(activity); err != nil {
-						log.Printf("❌ Erreur sauvegarde activité: %v", err)
-					} else {
-						log.Printf("💾 Activité sauvegardée: %s (%s) - %.0fs",
-							activity.AppName,
-							activity.WindowTitle,
-							duration.Seconds())
-					}
-
-					currentWindow = nil
-				}
-				wasIdle = true
-				idleStartTime = time.Now()
-				log.Println("💤 Utilisateur inactif")
-				continue
-			}
+		case <-ctx.Done():
+			log.Println("\n👋 Arrêt de l'agent...")
+			break loop
+		}
+	}
 
-			// Si l'utilisateur était idle et redevient actif
-			if !isIdle && wasIdle {
-				// Enregistrer la période d'inactivité
-				endTime := time.Now()
-				idleDuration := endTime.Sub(idleStartTime)
-				
-				idleActivity := &storage.Activity{
-					AppName:      "IDLE",
-					WindowTitle:  "Inactif",
-					ProcessPath:  "",
-					StartTime:    idleStartTime,
-					EndTime:      endTime,
-					DurationSecs: int64(idleDuration.Seconds()),
-					IsIdle:       true,
-				}
-				
-				if err := db.InsertActivity(idleActivity); err != nil {
-					log.Printf("❌ Erreur sauvegarde période idle: %v", err)
-				} else {
-					log.Printf("💾 Période idle sauvegardée: %.0fs", idleDuration.Seconds())
-				}
-				
-				wasIdle = false
-				log.Println("👋 Utilisateur de retour")
-			}
+	if err := shutdown(cfg.ShutdownTimeout, apiServer, t, db); err != nil {
+		log.Printf("❌ Arrêt incomplet: %v", err)
+		return 1
+	}
+	if apiErr != nil {
+		return 1
+	}
 
-			// Si l'utilisateur devient idle
-			if isIdle && !wasIdle {
-				if currentWindow != nil {
-					// Enregistrer l'activité avant l'idle
-					endTime := time.Now()
-					duration := endTime.Sub(activityStartTime)
-
-					activity := &storage.Activity{
-						AppName:      currentWindow.AppName,
-						WindowTitle:  currentWindow.WindowTitle,
-					EnrichedName: currentWindow.GetEnrichedName(),
-						ProcessPath:  currentWindow.ProcessPath,
-						StartTime:    activityStartTime,
-						EndTime:      endTime,
-						DurationSecs: int64(duration.Seconds()),
-						IsIdle:       false,
-					}
-
-					if err := db.InsertActivity(activity); err != nil {
-						log.Printf("❌ Erreur sauvegarde activité: %v", err)
-					} else {
-						log.Printf("💾 Activité sauvegardée: %s (%s) - %.0fs",
-							activity.AppName,
-							activity.WindowTitle,
-							duration.Seconds())
-					}
-
-					currentWindow = nil
-				}
-				wasIdle = true
-				log.Println("💤 Utilisateur inactif")
-				continue
-			}
+	log.Println("✅ Agent arrêté proprement")
+	return 0
+}
 
-			// Si l'utilisateur était idle et redevient actif
-			if !isIdle && wasIdle {
-				wasIdle = false
-				log.Println("👋 Utilisateur de retour")
-			}
+// shutdown arrête l'agent dans l'ordre : drainage des requêtes HTTP en cours,
+// écriture de l'activité en attente puis fermeture de la base de données
+func shutdown(timeout time.Duration, apiServer *api.Server, t *activityTracker, db *storage.DB) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-			// Si l'utilisateur n'est pas idle, vérifier la fenêtre active
-			if !isIdle {
-				window, err := tracker.GetActiveWindow()
-				if err != nil {
-					log.Printf("⚠️  Erreur récupération fenêtre: %v", err)
-					continue
-				}
-
-				// Si la fenêtre a changé
-				if currentWindow == nil ||
-					window.AppName != currentWindow.AppName ||
-					window.WindowTitle != currentWindow.WindowTitle {
-
-					// Sauvegarder l'activité précédente
-					if currentWindow != nil {
-						endTime := time.Now()
-						duration := endTime.Sub(activityStartTime)
-
-					activity := &storage.Activity{
-						AppName:      currentWindow.AppName,
-						EnrichedName: currentWindow.GetEnrichedName(),
-						WindowTitle:  currentWindow.WindowTitle,
-						ProcessPath:  currentWindow.ProcessPath,
-						StartTime:    activityStartTime,
-						EndTime:      endTime,
-						DurationSecs: int64(duration.Seconds()),
-						IsIdle:       false,
-					}
-
-						if err := db.InsertActivity(activity); err != nil {
-							log.Printf("❌ Erreur sauvegarde activité: %v", err)
-						} else {
-							log.Printf("💾 Activité sauvegardée: %s (%s) - %.0fs",
-								activity.AppName,
-								activity.WindowTitle,
-								duration.Seconds())
-						}
-					}
-
-					// Commencer le tracking de la nouvelle activité
-					currentWindow = window
-					activityStartTime = time.Now()
-					log.Printf("🔄 Changement d'activité: %s - %s",
-						window.AppName,
-						window.WindowTitle)
-
-					// Mettre à jour l'API avec l'activité courante
-					if cfg.EnableAPI {
-						// Note: Pour accéder à apiServer ici, il faudrait le passer via un channel
-						// ou le rendre accessible globalement. Pour simplifier, on skip cette partie.
-					}
-				}
-			}
+	var errs []error
 
-		case <-sigChan:
-			log.Println("\n👋 Arrêt de l'agent...")
+	if apiServer != nil {
+		if err := apiServer.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
 
-			// Sauvegarder l'activité courante avant de quitter
-			if currentWindow != nil && !wasIdle {
-				endTime := time.Now()
-				duration := endTime.Sub(activityStartTime)
-
-				activity := &storage.Activity{
-					AppName:      currentWindow.AppName,
-					EnrichedName: currentWindow.GetEnrichedName(),
-					WindowTitle:  currentWindow.WindowTitle,
-					ProcessPath:  currentWindow.ProcessPath,
-					StartTime:    activityStartTime,
-					EndTime:      endTime,
-					DurationSecs: int64(duration.Seconds()),
-					IsIdle:       false,
-				}
-
-				if err := db.InsertActivity(activity); err != nil {
-					log.Printf("❌ Erreur sauvegarde activité finale: %v", err)
-				} else {
-					log.Printf("💾 Activité finale sauvegardée: %s (%s) - %.0fs",
-						activity.AppName,
-						activity.WindowTitle,
-						duration.Seconds())
-				}
-			}
+	if err := t.flush(time.Now()); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := db.Close(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// activityTracker contient l'état du tracking entre deux ticks
+type activityTracker struct {
+	db           *storage.DB
+	idleDetector *tracker.IdleDetector
+
+	currentWindow     *tracker.WindowInfo
+	activityStartTime time.Time
+	wasIdle           bool
+	idleStartTime     time.Time
+
+	// onChange est appelé à chaque changement d'activité (window nil = inactif)
+	onChange func(window *tracker.WindowInfo, startTime time.Time)
+
+	// devtools lit l'URL de l'onglet actif des navigateurs Chromium (nil =
+	// désactivé) ; devtoolsErr est la dernière erreur signalée, pour ne pas
+	// la répéter à chaque relevé
+	devtools    *tracker.DevToolsSource
+	devtoolsErr string
+}
+
+// tick vérifie l'inactivité et la fenêtre active, et enregistre les activités terminées
+func (t *activityTracker) tick() {
+	// Vérifier si l'utilisateur est idle
+	isIdle, err := t.idleDetector.IsIdle()
+	if err != nil {
+		log.Printf("⚠️  Erreur détection idle: %v", err)
+		return
+	}
+
+	// Si l'utilisateur devient idle
+	if isIdle && !t.wasIdle {
+		now := time.Now()
+		// Enregistrer l'activité avant l'idle
+		if err := t.saveCurrentActivity(now); err != nil {
+			log.Printf("❌ Erreur sauvegarde activité: %v", err)
+		}
+		t.wasIdle = true
+		t.idleStartTime = now
+		t.notifyChange(nil, now)
+		log.Println("💤 Utilisateur inactif")
+		return
+	}
 
-			log.Println("✅ Agent arrêté proprement")
-			return
+	// Si l'utilisateur était idle et redevient actif
+	if !isIdle && t.wasIdle {
+		// Enregistrer la période d'inactivité
+		if err := t.saveIdlePeriod(time.Now()); err != nil {
+			log.Printf("❌ Erreur sauvegarde période idle: %v", err)
 		}
+		t.wasIdle = false
+		log.Println("👋 Utilisateur de retour")
 	}
+
+	if isIdle {
+		return
+	}
+
+	// L'utilisateur n'est pas idle, vérifier la fenêtre active
+	window, err := tracker.GetActiveWindow()
+	if err != nil {
+		log.Printf("⚠️  Erreur récupération fenêtre: %v", err)
+		return
+	}
+
+	// Si la fenêtre n'a pas changé, rien à faire
+	if t.currentWindow != nil && window.SameActivity(t.currentWindow) {
+		return
+	}
+	t.attachTabURL(window)
+
+	// Sauvegarder l'activité précédente
+	now := time.Now()
+	if err := t.saveCurrentActivity(now); err != nil {
+		log.Printf("❌ Erreur sauvegarde activité: %v", err)
+	}
+
+	// Commencer le tracking de la nouvelle activité
+	t.currentWindow = window
+	t.activityStartTime = now
+	shown := protectedCopy(window)
+	t.notifyChange(shown, now)
+	if t.titlesSealed() {
+		log.Printf("🔄 Changement d'activité: %s", shown.AppName)
+		return
+	}
+	log.Printf("🔄 Changement d'activité: %s - %s",
+		shown.AppName,
+		shown.WindowTitle)
+}
+
+// titlesSealed indique si les titres sont chiffrés en base : ils ne doivent
+// alors pas finir en clair dans les logs (journald, syslog). Une erreur de
+// lecture compte comme un chiffrement actif.
+func (t *activityTracker) titlesSealed() bool {
+	settings, err := t.db.Encryption()
+	return err != nil || settings != nil
+}
+
+// protectedCopy retourne la fenêtre telle qu'elle peut être affichée et
+// journalisée : règles de confidentialité appliquées, et contenu effacé si
+// l'activité sera exclue
+func protectedCopy(window *tracker.WindowInfo) *tracker.WindowInfo {
+	shown := *window
+	if shown.Protect().Action == privacy.ActionDrop {
+		shown.WindowTitle, shown.URL, shown.Domain = "", "", ""
+		shown.Command, shown.WorkingDir, shown.SSHHost = "", "", ""
+	}
+	return &shown
+}
+
+// attachTabURL renseigne l'URL de l'onglet actif via DevTools ; une erreur
+// n'est journalisée qu'à son apparition
+func (t *activityTracker) attachTabURL(window *tracker.WindowInfo) {
+	if t.devtools == nil {
+		return
+	}
+	message := ""
+	if err := t.devtools.Attach(window); err != nil {
+		message = err.Error()
+	}
+	if message != t.devtoolsErr {
+		if message != "" {
+			log.Printf("⚠️  DevTools injoignable (navigateur lancé sans --remote-debugging-port ?): %s", message)
+		} else {
+			log.Println("🌐 DevTools joignable")
+		}
+		t.devtoolsErr = message
+	}
+}
+
+// notifyChange signale le changement d'activité, par exemple à l'API
+func (t *activityTracker) notifyChange(window *tracker.WindowInfo, startTime time.Time) {
+	if t.onChange != nil {
+		t.onChange(window, startTime)
+	}
+}
+
+// flush enregistre l'activité ou la période d'inactivité en cours
+func (t *activityTracker) flush(endTime time.Time) error {
+	if t.wasIdle {
+		return t.saveIdlePeriod(endTime)
+	}
+	return t.saveCurrentActivity(endTime)
+}
+
+// saveCurrentActivity enregistre la fenêtre courante comme activité terminée à endTime
+func (t *activityTracker) saveCurrentActivity(endTime time.Time) error {
+	if t.currentWindow == nil {
+		return nil
+	}
+
+	// L'extension navigateur signale l'URL réelle de l'onglet, plus sûre
+	// que le site deviné d'après le titre
+	if t.currentWindow.URL == "" && rules.IsBrowser(t.currentWindow.AppName) {
+		rawURL, err := t.db.BrowserURL(t.activityStartTime, endTime)
+		if err != nil {
+			log.Printf("⚠️  Erreur lecture des onglets: %v", err)
+		}
+		t.currentWindow.URL = rawURL
+	}
+
+	// Les règles de confidentialité s'appliquent avant l'enrichissement, qui
+	// ne voit que le titre et l'URL conservés
+	duration := endTime.Sub(t.activityStartTime)
+	if decision := t.currentWindow.Protect(); decision.Action == privacy.ActionDrop {
+		log.Printf("🔒 Activité exclue par la règle de confidentialité %q: %s (%.0fs)",
+			decision.Rule, t.currentWindow.AppName, duration.Seconds())
+		t.currentWindow = nil
+		return nil
+	}
+	enriched := t.currentWindow.Enrich()
+	activity := &storage.Activity{
+		AppName:      t.currentWindow.AppName,
+		EnrichedName: enriched.EnrichedName,
+		Category:     enriched.Category,
+		Project:      enriched.Project,
+		Tags:         enriched.Tags,
+		Entities:     t.currentWindow.Entities(),
+		Document:     t.currentWindow.Document(),
+		Command:      t.currentWindow.Command,
+		WorkingDir:   t.currentWindow.WorkingDir,
+		SSHHost:      t.currentWindow.SSHHost,
+		Repo:         t.currentWindow.Repo,
+		URL:          t.currentWindow.URL,
+		WindowTitle:  t.currentWindow.WindowTitle,
+		ProcessPath:  t.currentWindow.ProcessPath,
+		StartTime:    t.activityStartTime,
+		EndTime:      endTime,
+		DurationSecs: int64(duration.Seconds()),
+		IsIdle:       enriched.Idle,
+	}
+	if site := enriched.Site; site != nil {
+		activity.InferredSite = site.Name
+		activity.Confidence = &site.Confidence
+	}
+	title := t.currentWindow.WindowTitle
+	t.currentWindow = nil
+
+	if enriched.Ignored {
+		log.Printf("🙈 Activité ignorée par les règles: %s (%.0fs)", activity.AppName, duration.Seconds())
+		return nil
+	}
+
+	if err := t.db.InsertActivity(activity); err != nil {
+		return err
+	}
+
+	if t.titlesSealed() {
+		log.Printf("💾 Activité sauvegardée: %s - %.0fs", activity.AppName, duration.Seconds())
+	} else {
+		log.Printf("💾 Activité sauvegardée: %s (%s) - %.0fs",
+			activity.AppName,
+			activity.WindowTitle,
+			duration.Seconds())
+	}
+
+	// Les heartbeats des plugins WakaTime reçus pendant l'activité précisent
+	// le fichier, le langage et le projet
+	if _, err := t.db.MergeHeartbeats(activity.StartTime, activity.EndTime); err != nil {
+		log.Printf("⚠️  Erreur fusion des heartbeats: %v", err)
+	}
+
+	if activity.InferredSite != "" {
+		t.learnSite(activity.InferredSite, title, duration)
+	}
+	return nil
+}
+
+// learnSite comptabilise un site déduit et le confirme une fois revu dans
+// assez de titres différents
+func (t *activityTracker) learnSite(name, title string, duration time.Duration) {
+	occurrences, err := t.db.RecordSiteCandidate(name, title, duration)
+	if err != nil {
+		log.Printf("⚠️  Erreur apprentissage du site %s: %v", name, err)
+		return
+	}
+	if occurrences == rules.SiteConfirmations {
+		tracker.ConfirmSites(name)
+		log.Printf("🧠 Site appris: %s", name)
+	}
+}
+
+// saveIdlePeriod enregistre la période d'inactivité terminée à endTime
+func (t *activityTracker) saveIdlePeriod(endTime time.Time) error {
+	idleDuration := endTime.Sub(t.idleStartTime)
+	idleActivity := &storage.Activity{
+		AppName:      "IDLE",
+		WindowTitle:  "Inactif",
+		ProcessPath:  "",
+		StartTime:    t.idleStartTime,
+		EndTime:      endTime,
+		DurationSecs: int64(idleDuration.Seconds()),
+		IsIdle:       true,
+	}
+
+	if err := t.db.InsertActivity(idleActivity); err != nil {
+		return err
+	}
+
+	log.Printf("💾 Période idle sauvegardée: %.0fs", idleDuration.Seconds())
+	return nil
+}
+
+// ensureDefaults installe les règles et catégories par défaut manquantes
+func ensureDefaults(db *storage.DB) error {
+	if err := db.EnsureDefaultRules(); err != nil {
+		return err
+	}
+	return db.EnsureDefaultCategories()
+}
+
+// ruleLoader recharge les règles d'enrichissement quand leur version en base
+// change (modification par l'API ou par la commande rules)
+type ruleLoader struct {
+	db      *storage.DB
+	version string
+	loaded  bool
+}
+
+// reload applique les règles stockées si elles ont changé depuis le dernier chargement
+func (l *ruleLoader) reload() {
+	version, err := l.db.RulesVersion()
+	if err != nil {
+		log.Printf("⚠️  Erreur lecture des règles: %v", err)
+		return
+	}
+	if l.loaded && version == l.version {
+		return
+	}
+
+	rs, err := l.db.ListRules()
+	if err == nil {
+		err = tracker.SetRules(rs)
+	}
+	if err != nil {
+		log.Printf("⚠️  Règles non appliquées: %v", err)
+		return
+	}
+	patterns, err := l.db.EntityPatterns()
+	if err == nil {
+		err = tracker.SetEntityPatterns(patterns)
+	}
+	if err != nil {
+		log.Printf("⚠️  Motifs d'entités non appliqués: %v", err)
+	}
+	privacyRules, err := l.db.PrivacyRules()
+	if err == nil {
+		err = tracker.SetPrivacyRules(privacyRules)
+	}
+	if err != nil {
+		log.Printf("⚠️  Règles de confidentialité non appliquées: %v", err)
+	}
+
+	l.version = version
+	l.loaded = true
+	log.Printf("📐 %d règles d'enrichissement et %d règles de confidentialité chargées", len(rs), len(privacyRules))
 }
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"trackmytime/config"
+	"trackmytime/internal/privacy"
+	"trackmytime/internal/storage"
+	"trackmytime/internal/tracker"
+)
+
+const privacyUsage = `Usage: trackmytime privacy <commande> [arguments]
+
+Commandes:
+  rules [export]          Afficher les règles de confidentialité en JSON
+  rules import <fichier>  Remplacer les règles par celles du fichier JSON
+  rules reset             Supprimer toutes les règles
+  scrub [-from YYYY-MM-DD] [-to YYYY-MM-DD] [-dry-run]
+                          Appliquer les règles actuelles à l'historique :
+                          activités, onglets, événements ActivityWatch,
+                          heartbeats, commandes de shell, sites candidats
+
+Les règles sont évaluées dans l'ordre avant l'enregistrement de chaque
+activité ; la première dont les conditions sont vraies s'applique :
+  drop      l'activité n'est pas enregistrée
+  app_only  seule l'application est gardée (ni titre, ni URL, ni commande)
+  redact    les passages du titre correspondant à pattern sont masqués
+  hash      le titre est remplacé par son empreinte
+Les fenêtres de navigation privée ne gardent jamais leur titre.
+`
+
+// runPrivacy exécute la commande "trackmytime privacy" et retourne le code de sortie
+func runPrivacy(args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "help" {
+		fmt.Fprint(os.Stderr, privacyUsage)
+		return 2
+	}
+
+	cfg := config.DefaultConfig()
+	db, err := storage.NewDB(cfg.DBPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Erreur connexion DB: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	cmd, args := args[0], args[1:]
+	switch cmd {
+	case "rules":
+		err = privacyRules(db, args)
+	case "scrub":
+		err = scrubHistory(db, args)
+	default:
+		fmt.Fprintf(os.Stderr, "❌ Commande inconnue: %s\n\n%s", cmd, privacyUsage)
+		return 2
+	}
+
+	if errors.Is(err, flag.ErrHelp) {
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// privacyRules affiche, importe ou supprime les règles de confidentialité
+func privacyRules(db *storage.DB, args []string) error {
+	if len(args) == 0 || args[0] == "export" {
+		rs, err := db.PrivacyRules()
+		if err != nil {
+			return err
+		}
+		return writeRulesJSON(os.Stdout, rs)
+	}
+
+	switch args[0] {
+	case "import":
+		if len(args) != 2 {
+			return errors.New("usage: trackmytime privacy rules import <fichier>")
+		}
+		data, err := os.ReadFile(args[1])
+		if err != nil {
+			return err
+		}
+		var rs []privacy.Rule
+		if err := json.Unmarshal(data, &rs); err != nil {
+			return fmt.Errorf("fichier de règles invalide: %w", err)
+		}
+		if _, err := privacy.Compile(rs); err != nil {
+			return err
+		}
+		if err := db.SetPrivacyRules(rs); err != nil {
+			return err
+		}
+		fmt.Printf("✅ %d règles de confidentialité importées\n", len(rs))
+		fmt.Println("   \"trackmytime privacy scrub\" les applique à l'historique.")
+	case "reset":
+		if err := db.SetPrivacyRules(nil); err != nil {
+			return err
+		}
+		fmt.Println("✅ Règles de confidentialité supprimées")
+	default:
+		return fmt.Errorf("commande rules inconnue: %s", args[0])
+	}
+	return nil
+}
+
+// scrubRuleCount est le nombre d'activités modifiées par une règle
+type scrubRuleCount struct {
+	rule       string
+	action     privacy.Action
+	activities int
+	seconds    int64
+}
+
+// scrubReport résume l'application des règles à l'historique
+type scrubReport struct {
+	processed int
+	updated   int
+	deleted   int
+	rules     map[string]*scrubRuleCount
+}
+
+// scrubHistory applique les règles de confidentialité actuelles aux
+// activités enregistrées, lot par lot
+func scrubHistory(db *storage.DB, args []string) error {
+	fs := flag.NewFlagSet("privacy scrub", flag.ContinueOnError)
+	from := fs.String("from", "", "Premier jour traité, YYYY-MM-DD (défaut: début de l'historique)")
+	to := fs.String("to", "", "Dernier jour traité, inclus, YYYY-MM-DD (défaut: aujourd'hui)")
+	dryRun := fs.Bool("dry-run", false, "Afficher les changements sans les enregistrer")
+	batch := fs.Int("batch", 500, "Nombre d'activités par transaction")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("argument inattendu: %s", fs.Arg(0))
+	}
+	if *batch <= 0 {
+		return errors.New("-batch doit être positif")
+	}
+	var start, end time.Time
+	var err error
+	if *from != "" {
+		if start, err = time.ParseInLocation("2006-01-02", *from, time.Local); err != nil {
+			return fmt.Errorf("date -from invalide (format YYYY-MM-DD): %s", *from)
+		}
+	}
+	if *to != "" {
+		if end, err = time.ParseInLocation("2006-01-02", *to, time.Local); err != nil {
+			return fmt.Errorf("date -to invalide (format YYYY-MM-DD): %s", *to)
+		}
+		end = end.AddDate(0, 0, 1)
+	}
+
+	if err := ensureDefaults(db); err != nil {
+		return err
+	}
+	if err := loadStoredRules(db); err != nil {
+		return fmt.Errorf("règles non applicables: %w", err)
+	}
+
+	report := &scrubReport{rules: map[string]*scrubRuleCount{}}
+	var afterID int64
+	for {
+		activities, err := db.ReprocessActivities(start, end, afterID, *batch)
+		if err != nil {
+			return err
+		}
+		if len(activities) == 0 {
+			break
+		}
+		afterID = activities[len(activities)-1].ID
+
+		var updates []storage.Activity
+		var deleted []int64
+		for _, before := range activities {
+			after, action := report.add(before)
+			switch action {
+			case privacy.ActionDrop:
+				deleted = append(deleted, before.ID)
+			case "":
+			default:
+				updates = append(updates, after)
+			}
+		}
+		if !*dryRun {
+			if err := db.ScrubActivities(updates, deleted); err != nil {
+				return err
+			}
+		}
+	}
+
+	printScrubReport(report, *dryRun)
+
+	// Les mêmes règles protègent les données enregistrées hors des activités
+	counts, err := db.ScrubRecords(start, end, tracker.Protect, *dryRun)
+	if err != nil {
+		return err
+	}
+	for _, c := range counts {
+		if c.Updated+c.Deleted > 0 {
+			fmt.Printf("   %s : %d lignes protégées, dont %d supprimées ou effacées\n", c.Table, c.Updated+c.Deleted, c.Deleted)
+		}
+	}
+	return nil
+}
+
+// add applique les règles à une activité enregistrée ; retourne l'activité
+// protégée et réenrichie, et l'action à enregistrer (vide si l'activité
+// est déjà protégée)
+func (r *scrubReport) add(before storage.Activity) (storage.Activity, privacy.Action) {
+	r.processed++
+
+	after, decision, _ := tracker.Reprotect(before)
+	if decision.Action == "" {
+		return before, ""
+	}
+	if decision.Action != privacy.ActionDrop && after.WindowTitle == before.WindowTitle && after.URL == before.URL &&
+		after.Command == before.Command && after.WorkingDir == before.WorkingDir && after.SSHHost == before.SSHHost &&
+		after.Repo == before.Repo {
+		return before, ""
+	}
+
+	c := r.rules[decision.Rule]
+	if c == nil {
+		c = &scrubRuleCount{rule: decision.Rule, action: decision.Action}
+		r.rules[decision.Rule] = c
+	}
+	c.activities++
+	c.seconds += before.DurationSecs
+
+	if decision.Action == privacy.ActionDrop {
+		r.deleted++
+		return before, decision.Action
+	}
+	r.updated++
+	return after, decision.Action
+}
+
+// printScrubReport affiche les activités modifiées par règle, le temps le
+// plus long d'abord
+func printScrubReport(r *scrubReport, dryRun bool) {
+	counts := make([]*scrubRuleCount, 0, len(r.rules))
+	for _, c := range r.rules {
+		counts = append(counts, c)
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].seconds != counts[j].seconds {
+			return counts[i].seconds > counts[j].seconds
+		}
+		return counts[i].rule < counts[j].rule
+	})
+
+	if len(counts) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "RÈGLE\tACTION\tACTIVITÉS\tDURÉE")
+		for _, c := range counts {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", c.rule, c.action, c.activities, time.Duration(c.seconds)*time.Second)
+		}
+		w.Flush()
+		fmt.Println()
+	}
+
+	verb := "protégées"
+	if dryRun {
+		verb = "à protéger (dry-run, rien n'est enregistré)"
+	}
+	fmt.Printf("🔒 %d activités examinées : %d %s, dont %d supprimées\n",
+		r.processed, r.updated+r.deleted, verb, r.deleted)
+}
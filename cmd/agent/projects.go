@@ -0,0 +1,218 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"trackmytime/config"
+	"trackmytime/internal/rules"
+	"trackmytime/internal/storage"
+)
+
+const projectsUsage = `Usage: trackmytime projects <commande> [arguments]
+
+Commandes:
+  list [-archived]                      Lister les projets
+  add <nom> [-client C] [-color #hex]   Créer un projet
+  set <id> [-name N] [-client C] [-color #hex]
+                                        Modifier un projet (un renommage est reporté sur l'historique)
+  archive <id>                          Archiver un projet (masqué des listes)
+  unarchive <id>                        Désarchiver un projet
+  rm <id>                               Supprimer un projet (son temps redevient non attribué)
+  assign <nom> [conditions]             Ajouter une règle attribuant les activités au projet
+
+Les conditions de assign sont celles de trackmytime rules add (-app, -title,
+-path, -url, -enriched). Exemples :
+  trackmytime projects assign Acme -url "glob:*github.com/acme/*"
+  trackmytime projects assign Acme -app Terminal -title "regex:~/code/acme"
+`
+
+// runProjects exécute la commande "trackmytime projects" et retourne le code de sortie
+func runProjects(args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "help" {
+		fmt.Fprint(os.Stderr, projectsUsage)
+		return 2
+	}
+
+	cfg := config.DefaultConfig()
+	db, err := storage.NewDB(cfg.DBPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Erreur connexion DB: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	// Les règles par défaut doivent exister avant d'y ajouter celles de assign
+	if err := ensureDefaults(db); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Erreur installation des règles par défaut: %v\n", err)
+		return 1
+	}
+
+	cmd, args := args[0], args[1:]
+	switch cmd {
+	case "list":
+		err = listProjects(db, args)
+	case "add":
+		err = addProject(db, args)
+	case "set":
+		err = setProject(db, args)
+	case "archive", "unarchive":
+		err = withProjectID(args, func(id int64) error {
+			p, err := db.GetProject(id)
+			if err != nil {
+				return err
+			}
+			p.Archived = cmd == "archive"
+			return db.UpdateProject(p)
+		})
+	case "rm":
+		err = withProjectID(args, db.DeleteProject)
+	case "assign":
+		err = assignProject(db, args)
+	default:
+		fmt.Fprintf(os.Stderr, "❌ Commande inconnue: %s\n\n%s", cmd, projectsUsage)
+		return 2
+	}
+
+	if errors.Is(err, flag.ErrHelp) {
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// listProjects affiche les projets sous forme de tableau
+func listProjects(db *storage.DB, args []string) error {
+	fs := flag.NewFlagSet("projects list", flag.ContinueOnError)
+	archived := fs.Bool("archived", false, "Inclure les projets archivés")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	projects, err := db.ListProjects(*archived)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tPROJET\tCLIENT\tCOULEUR\tARCHIVÉ")
+	for _, p := range projects {
+		archived := ""
+		if p.Archived {
+			archived = "oui"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", p.ID, p.Name, p.Client, p.Color, archived)
+	}
+	return w.Flush()
+}
+
+// addProject crée un projet
+func addProject(db *storage.DB, args []string) error {
+	if len(args) == 0 || args[0] == "" || args[0][0] == '-' {
+		return errors.New("usage: trackmytime projects add <nom> [-client C] [-color #hex]")
+	}
+	p := storage.Project{Name: args[0]}
+
+	fs := flag.NewFlagSet("projects add", flag.ContinueOnError)
+	fs.StringVar(&p.Client, "client", "", "Client")
+	fs.StringVar(&p.Color, "color", "", "Couleur #rrggbb (défaut: automatique)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if err := storage.ValidateProject(p); err != nil {
+		return err
+	}
+
+	if err := db.CreateProject(&p); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Projet %d créé: %s\n", p.ID, p.Name)
+	return nil
+}
+
+// setProject modifie le nom, le client ou la couleur d'un projet
+func setProject(db *storage.DB, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: trackmytime projects set <id> [-name N] [-client C] [-color #hex]")
+	}
+	return withProjectID(args[:1], func(id int64) error {
+		p, err := db.GetProject(id)
+		if err != nil {
+			return err
+		}
+
+		fs := flag.NewFlagSet("projects set", flag.ContinueOnError)
+		fs.StringVar(&p.Name, "name", p.Name, "Nouveau nom")
+		fs.StringVar(&p.Client, "client", p.Client, "Client (vide pour retirer)")
+		fs.StringVar(&p.Color, "color", p.Color, "Couleur #rrggbb")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if err := storage.ValidateProject(p); err != nil {
+			return err
+		}
+		return db.UpdateProject(p)
+	})
+}
+
+// assignProject ajoute une règle attribuant au projet les activités qui
+// correspondent aux conditions. La règle est placée en tête pour l'emporter
+// sur la détection automatique des espaces de travail.
+func assignProject(db *storage.DB, args []string) error {
+	if len(args) == 0 || args[0] == "" || args[0][0] == '-' {
+		return errors.New("usage: trackmytime projects assign <nom> [conditions]")
+	}
+	project := args[0]
+	r := rules.Rule{
+		Name:     "Projet " + project,
+		Position: 1,
+		Enabled:  true,
+		Action:   rules.Action{Project: project},
+	}
+
+	fs := flag.NewFlagSet("projects assign", flag.ContinueOnError)
+	fs.StringVar(&r.Name, "name", r.Name, "Nom de la règle")
+	fs.IntVar(&r.Position, "position", r.Position, "Position d'évaluation")
+	conditionFlag(fs, &r.Conditions, rules.FieldApp, "Condition sur le nom de l'application")
+	conditionFlag(fs, &r.Conditions, rules.FieldTitle, "Condition sur le titre de la fenêtre")
+	conditionFlag(fs, &r.Conditions, rules.FieldPath, "Condition sur le chemin de l'exécutable")
+	conditionFlag(fs, &r.Conditions, rules.FieldURL, "Condition sur l'URL de l'onglet")
+	conditionFlag(fs, &r.Conditions, rules.FieldEnriched, "Condition sur le nom enrichi (site, espace de travail...)")
+	conditionFlag(fs, &r.Conditions, rules.FieldCommand, "Condition sur la commande au premier plan du terminal")
+	conditionFlag(fs, &r.Conditions, rules.FieldCwd, "Condition sur le dossier courant du terminal")
+	conditionFlag(fs, &r.Conditions, rules.FieldSSHHost, "Condition sur l'hôte distant de la session ssh")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if len(r.Conditions) == 0 {
+		return errors.New("au moins une condition est requise (-app, -title, -path, -url ou -enriched)")
+	}
+
+	if err := rules.Validate(r); err != nil {
+		return err
+	}
+	if err := db.CreateRule(&r); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Règle %d ajoutée: les activités correspondantes sont attribuées à %s\n", r.ID, project)
+	return nil
+}
+
+// withProjectID appelle fn avec l'identifiant de projet passé en argument
+func withProjectID(args []string, fn func(id int64) error) error {
+	if len(args) != 1 {
+		return errors.New("identifiant de projet requis")
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || id <= 0 {
+		return fmt.Errorf("identifiant de projet invalide: %s", args[0])
+	}
+	return fn(id)
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"trackmytime/config"
+	"trackmytime/internal/storage"
+	"trackmytime/internal/tracker"
+)
+
+const reprocessUsage = `Usage: trackmytime reprocess [options]
+
+Réapplique les règles d'enrichissement actuelles à l'historique : nom enrichi,
+catégorie, projet, site déduit, inactivité, entités (tickets, dépôts, PR, MR),
+fichier et langage des éditeurs, et tags posés par les règles (les tags posés
+à la main sont conservés). Les activités sont traitées par lots, chacun dans
+une transaction ; un retraitement interrompu reprend là où il s'est arrêté
+lorsqu'il est relancé sur la même plage.
+
+Options:
+`
+
+// reprocessOptions configure un retraitement de l'historique
+type reprocessOptions struct {
+	start, end time.Time // zéro = pas de borne
+	dryRun     bool
+	batch      int
+	restart    bool
+}
+
+// nameChange est un changement de nom enrichi et le temps concerné
+type nameChange struct {
+	before, after string
+	activities    int
+	seconds       int64
+}
+
+// reprocessReport résume les changements d'un retraitement
+type reprocessReport struct {
+	processed  int
+	changed    int
+	ignored    int // activités que les règles actuelles ignoreraient (conservées)
+	names      map[[2]string]*nameChange
+	categories int
+	projects   int
+	tags       int
+	entities   int
+	files      int
+	idle       int
+}
+
+// runReprocess exécute la commande "trackmytime reprocess" et retourne le code de sortie
+func runReprocess(args []string) int {
+	opts, err := parseReprocessFlags(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 2
+	}
+
+	cfg := config.DefaultConfig()
+	db, err := storage.NewDB(cfg.DBPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Erreur connexion DB: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	if err := ensureDefaults(db); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Erreur installation des règles par défaut: %v\n", err)
+		return 1
+	}
+	if err := loadStoredRules(db); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Règles non applicables: %v\n", err)
+		return 1
+	}
+
+	report, err := reprocess(db, opts)
+	if report != nil {
+		printReprocessReport(report, opts.dryRun)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		if !opts.dryRun {
+			fmt.Fprintln(os.Stderr, "   Relancer la même commande pour reprendre.")
+		}
+		return 1
+	}
+	return 0
+}
+
+// parseReprocessFlags lit les options de la commande reprocess
+func parseReprocessFlags(args []string) (reprocessOptions, error) {
+	opts := reprocessOptions{}
+	fs := flag.NewFlagSet("reprocess", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, reprocessUsage)
+		fs.PrintDefaults()
+	}
+	from := fs.String("from", "", "Premier jour à retraiter, YYYY-MM-DD (défaut: début de l'historique)")
+	to := fs.String("to", "", "Dernier jour à retraiter, inclus, YYYY-MM-DD (défaut: aujourd'hui)")
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "Afficher les changements sans les enregistrer")
+	fs.IntVar(&opts.batch, "batch", 500, "Nombre d'activités par transaction")
+	fs.BoolVar(&opts.restart, "restart", false, "Ignorer le point de reprise et tout retraiter")
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	if fs.NArg() > 0 {
+		return opts, fmt.Errorf("argument inattendu: %s", fs.Arg(0))
+	}
+	if opts.batch <= 0 {
+		return opts, errors.New("-batch doit être positif")
+	}
+
+	var err error
+	if *from != "" {
+		if opts.start, err = time.ParseInLocation("2006-01-02", *from, time.Local); err != nil {
+			return opts, fmt.Errorf("date -from invalide (format YYYY-MM-DD): %s", *from)
+		}
+	}
+	if *to != "" {
+		if opts.end, err = time.ParseInLocation("2006-01-02", *to, time.Local); err != nil {
+			return opts, fmt.Errorf("date -to invalide (format YYYY-MM-DD): %s", *to)
+		}
+		opts.end = opts.end.AddDate(0, 0, 1)
+	}
+	if !opts.start.IsZero() && !opts.end.IsZero() && !opts.end.After(opts.start) {
+		return opts, errors.New("-to doit être postérieur ou égal à -from")
+	}
+	return opts, nil
+}
+
+// reprocess réévalue les règles sur les activités de la plage, lot par lot.
+// Hors dry-run, chaque lot est enregistré avec le point de reprise dans une
+// même transaction.
+func reprocess(db *storage.DB, opts reprocessOptions) (*reprocessReport, error) {
+	var afterID int64
+	if !opts.dryRun && !opts.restart {
+		checkpoint, err := db.GetReprocessCheckpoint()
+		if err != nil {
+			return nil, err
+		}
+		if checkpoint != nil && checkpoint.Start.Equal(opts.start) && checkpoint.End.Equal(opts.end) {
+			afterID = checkpoint.LastID
+			fmt.Printf("↩️  Reprise du retraitement après l'activité %d\n", afterID)
+		}
+	}
+
+	remaining, err := db.CountReprocessActivities(opts.start, opts.end, afterID)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("🔁 %d activités à retraiter\n", remaining)
+
+	report := &reprocessReport{names: map[[2]string]*nameChange{}}
+	for {
+		activities, err := db.ReprocessActivities(opts.start, opts.end, afterID, opts.batch)
+		if err != nil {
+			return report, err
+		}
+		if len(activities) == 0 {
+			break
+		}
+
+		var updates []storage.Activity
+		for _, before := range activities {
+			if after, ok := report.add(before); ok {
+				updates = append(updates, after)
+			}
+		}
+		afterID = activities[len(activities)-1].ID
+
+		if !opts.dryRun {
+			checkpoint := storage.ReprocessCheckpoint{Start: opts.start, End: opts.end, LastID: afterID}
+			if err := db.UpdateEnrichment(updates, checkpoint); err != nil {
+				return report, err
+			}
+		}
+		fmt.Printf("⏳ %d/%d activités traitées (%d modifiées)\n", report.processed, remaining, report.changed)
+	}
+
+	if !opts.dryRun {
+		if err := db.ClearReprocessCheckpoint(); err != nil {
+			return report, err
+		}
+		// Rétablir les projets et dépôts apportés par les heartbeats WakaTime
+		end := opts.end
+		if end.IsZero() {
+			end = time.Now()
+		}
+		if _, err := db.MergeHeartbeats(opts.start, end); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// add réévalue une activité, comptabilise ses changements et retourne
+// l'activité réenrichie si elle a changé
+func (r *reprocessReport) add(before storage.Activity) (storage.Activity, bool) {
+	r.processed++
+
+	after, ignored := tracker.Reenrich(before)
+	if ignored {
+		r.ignored++
+		return before, false
+	}
+
+	changed := false
+	if after.EnrichedName != before.EnrichedName {
+		key := [2]string{before.EnrichedName, after.EnrichedName}
+		c := r.names[key]
+		if c == nil {
+			c = &nameChange{before: key[0], after: key[1]}
+			r.names[key] = c
+		}
+		c.activities++
+		c.seconds += before.DurationSecs
+		changed = true
+	}
+	if after.Category != before.Category {
+		r.categories++
+		changed = true
+	}
+	if after.Project != before.Project {
+		r.projects++
+		changed = true
+	}
+	if !slices.Equal(after.Tags, before.Tags) {
+		r.tags++
+		changed = true
+	}
+	if !slices.Equal(after.Entities, before.Entities) {
+		r.entities++
+		changed = true
+	}
+	if after.Document != before.Document {
+		r.files++
+		changed = true
+	}
+	if after.IsIdle != before.IsIdle {
+		r.idle++
+		changed = true
+	}
+	if after.InferredSite != before.InferredSite || !sameConfidence(after.Confidence, before.Confidence) {
+		changed = true
+	}
+
+	if changed {
+		r.changed++
+	}
+	return after, changed
+}
+
+// sameConfidence compare deux confiances de site déduit (nil = pas de déduction)
+func sameConfidence(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// printReprocessReport affiche les changements de noms enrichis, le temps le
+// plus long d'abord, puis le nombre de changements des autres champs
+func printReprocessReport(r *reprocessReport, dryRun bool) {
+	changes := make([]*nameChange, 0, len(r.names))
+	for _, c := range r.names {
+		changes = append(changes, c)
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].seconds != changes[j].seconds {
+			return changes[i].seconds > changes[j].seconds
+		}
+		return changes[i].before < changes[j].before
+	})
+
+	if len(changes) > 0 {
+		fmt.Println()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "AVANT\tAPRÈS\tACTIVITÉS\tDURÉE")
+		for _, c := range changes {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", c.before, c.after, c.activities, time.Duration(c.seconds)*time.Second)
+		}
+		w.Flush()
+	}
+
+	fmt.Println()
+	verb := "modifiées"
+	if dryRun {
+		verb = "à modifier (dry-run, rien n'est enregistré)"
+	}
+	fmt.Printf("📊 %d activités traitées, %d %s\n", r.processed, r.changed, verb)
+	fmt.Printf("   Catégorie: %d · Projet: %d · Tags: %d · Entités: %d · Fichier: %d · Inactivité: %d\n",
+		r.categories, r.projects, r.tags, r.entities, r.files, r.idle)
+	if r.ignored > 0 {
+		fmt.Printf("🙈 %d activités seraient ignorées par les règles actuelles (conservées telles quelles)\n", r.ignored)
+	}
+}
@@ -0,0 +1,464 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"trackmytime/config"
+	"trackmytime/internal/rules"
+	"trackmytime/internal/storage"
+	"trackmytime/internal/tracker"
+)
+
+const rulesUsage = `Usage: trackmytime rules <commande> [arguments]
+
+Commandes:
+  list                    Lister les règles dans leur ordre d'évaluation
+  show <id>               Afficher une règle en JSON
+  add [options]           Ajouter une règle (voir trackmytime rules add -h)
+  rm <id>                 Supprimer une règle
+  enable <id>             Activer une règle
+  disable <id>            Désactiver une règle
+  move <id> <position>    Changer la position d'une règle
+  export [fichier]        Exporter les règles en JSON (sortie standard par défaut)
+  import <fichier>        Remplacer toutes les règles par celles du fichier JSON
+  reset                   Revenir aux règles par défaut
+  test [options]          Évaluer les règles sur une fenêtre fictive
+  coverage [options]      Part du temps nommée par les règles et règles suggérées
+
+Les modifications sont prises en compte par l'agent en cours d'exécution
+dans les 30 secondes.
+`
+
+// runRules exécute la commande "trackmytime rules" et retourne le code de sortie
+func runRules(args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "help" {
+		fmt.Fprint(os.Stderr, rulesUsage)
+		return 2
+	}
+
+	cfg := config.DefaultConfig()
+	db, err := storage.NewDB(cfg.DBPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Erreur connexion DB: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	if err := ensureDefaults(db); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Erreur installation des règles par défaut: %v\n", err)
+		return 1
+	}
+
+	cmd, args := args[0], args[1:]
+	switch cmd {
+	case "list":
+		err = listRules(db, os.Stdout)
+	case "show":
+		err = showRule(db, args)
+	case "add":
+		err = addRule(db, args)
+	case "rm":
+		err = withRuleID(args, db.DeleteRule)
+	case "enable", "disable":
+		err = withRuleID(args, func(id int64) error {
+			return setRuleEnabled(db, id, cmd == "enable")
+		})
+	case "move":
+		err = moveRule(db, args)
+	case "export":
+		err = exportRules(db, args)
+	case "import":
+		err = importRules(db, args)
+	case "reset":
+		err = db.ReplaceRules(rules.Defaults())
+	case "test":
+		err = testRules(db, args)
+	case "coverage":
+		err = ruleCoverage(db, args)
+	default:
+		fmt.Fprintf(os.Stderr, "❌ Commande inconnue: %s\n\n%s", cmd, rulesUsage)
+		return 2
+	}
+
+	if errors.Is(err, flag.ErrHelp) {
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// listRules affiche les règles sous forme de tableau
+func listRules(db *storage.DB, out io.Writer) error {
+	rs, err := db.ListRules()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tPOS\tACTIVE\tNOM\tCONDITIONS\tACTION")
+	for _, r := range rs {
+		active := "oui"
+		if !r.Enabled {
+			active = "non"
+		}
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\t%s\n", r.ID, r.Position, active, r.Name,
+			formatConditions(r.Conditions), formatAction(r.Action))
+	}
+	return w.Flush()
+}
+
+// formatConditions résume les conditions d'une règle sur une ligne
+func formatConditions(conditions []rules.Condition) string {
+	parts := make([]string, 0, len(conditions))
+	for _, c := range conditions {
+		negate := ""
+		if c.Negate {
+			negate = "!"
+		}
+		parts = append(parts, fmt.Sprintf("%s %s%s %q", c.Field, negate, c.Match, c.Pattern))
+	}
+	return strings.Join(parts, " ET ")
+}
+
+// formatAction résume l'action d'une règle sur une ligne
+func formatAction(a rules.Action) string {
+	var parts []string
+	if a.EnrichedName != "" {
+		parts = append(parts, "nom="+a.EnrichedName)
+	}
+	if a.Category != "" {
+		parts = append(parts, "catégorie="+a.Category)
+	}
+	if a.Project != "" {
+		parts = append(parts, "projet="+a.Project)
+	}
+	if len(a.Tags) > 0 {
+		parts = append(parts, "tags="+strings.Join(a.Tags, ","))
+	}
+	if a.Idle {
+		parts = append(parts, "inactif")
+	}
+	if a.Ignore {
+		parts = append(parts, "ignorée")
+	}
+	if a.InferSite {
+		parts = append(parts, "site-déduit")
+	}
+	return strings.Join(parts, " ")
+}
+
+// showRule affiche une règle en JSON
+func showRule(db *storage.DB, args []string) error {
+	return withRuleID(args, func(id int64) error {
+		r, err := db.GetRule(id)
+		if err != nil {
+			return err
+		}
+		return writeRulesJSON(os.Stdout, r)
+	})
+}
+
+// conditionFlag ajoute une condition sur field à chaque occurrence du flag.
+// La valeur est de la forme [!][contains|equals|glob|regex:]motif.
+func conditionFlag(fs *flag.FlagSet, conditions *[]rules.Condition, field rules.Field, usage string) {
+	fs.Func(string(field), usage, func(value string) error {
+		c := rules.Condition{Field: field, Match: rules.MatchContains}
+		if strings.HasPrefix(value, "!") {
+			c.Negate = true
+			value = value[1:]
+		}
+		if prefix, pattern, ok := strings.Cut(value, ":"); ok {
+			switch m := rules.MatchType(prefix); m {
+			case rules.MatchContains, rules.MatchEquals, rules.MatchGlob, rules.MatchRegex:
+				c.Match = m
+				value = pattern
+			}
+		}
+		c.Pattern = value
+		*conditions = append(*conditions, c)
+		return nil
+	})
+}
+
+// addRule crée une règle à partir des options de la ligne de commande
+func addRule(db *storage.DB, args []string) error {
+	var r rules.Rule
+	r.Enabled = true
+
+	fs := flag.NewFlagSet("rules add", flag.ContinueOnError)
+	fs.StringVar(&r.Name, "name", "", "Nom de la règle (requis)")
+	fs.IntVar(&r.Position, "position", 0, "Position d'évaluation (défaut: en dernier)")
+	disabled := fs.Bool("disabled", false, "Créer la règle désactivée")
+	conditionFlag(fs, &r.Conditions, rules.FieldApp, "Condition sur le nom de l'application")
+	conditionFlag(fs, &r.Conditions, rules.FieldTitle, "Condition sur le titre de la fenêtre")
+	conditionFlag(fs, &r.Conditions, rules.FieldPath, "Condition sur le chemin de l'exécutable")
+	conditionFlag(fs, &r.Conditions, rules.FieldURL, "Condition sur l'URL de l'onglet")
+	conditionFlag(fs, &r.Conditions, rules.FieldEnriched, "Condition sur le nom enrichi fixé par les règles précédentes")
+	conditionFlag(fs, &r.Conditions, rules.FieldCommand, "Condition sur la commande au premier plan du terminal")
+	conditionFlag(fs, &r.Conditions, rules.FieldCwd, "Condition sur le dossier courant du terminal")
+	conditionFlag(fs, &r.Conditions, rules.FieldSSHHost, "Condition sur l'hôte distant de la session ssh")
+	fs.StringVar(&r.Action.EnrichedName, "set-name", "", "Nom enrichi ($1 = premier groupe de la dernière condition regex)")
+	fs.StringVar(&r.Action.Category, "category", "", "Catégorie")
+	fs.StringVar(&r.Action.Project, "project", "", "Projet")
+	fs.Func("tag", "Tag (répétable)", func(tag string) error {
+		r.Action.Tags = append(r.Action.Tags, tag)
+		return nil
+	})
+	fs.BoolVar(&r.Action.Idle, "idle", false, "Compter l'activité comme de l'inactivité")
+	fs.BoolVar(&r.Action.Ignore, "ignore", false, "Ne pas enregistrer l'activité")
+	fs.BoolVar(&r.Action.InferSite, "infer-site", false, "Déduire le site du titre (-set-name sert de repli)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: trackmytime rules add -name NOM [conditions] [actions]\n\n")
+		fmt.Fprintf(fs.Output(), "Les conditions s'écrivent [!][contains|equals|glob|regex:]motif (contains par défaut).\n\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), "\nExemple:\n  trackmytime rules add -name \"Jira\" -app Chrome -title \"regex:(?i)jira\" -set-name Jira -category Travail\n")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	r.Enabled = !*disabled
+
+	if err := rules.Validate(r); err != nil {
+		return err
+	}
+	if err := db.CreateRule(&r); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Règle %d ajoutée en position %d\n", r.ID, r.Position)
+	return nil
+}
+
+// setRuleEnabled active ou désactive une règle
+func setRuleEnabled(db *storage.DB, id int64, enabled bool) error {
+	r, err := db.GetRule(id)
+	if err != nil {
+		return err
+	}
+	r.Enabled = enabled
+	return db.UpdateRule(r)
+}
+
+// moveRule change la position d'une règle
+func moveRule(db *storage.DB, args []string) error {
+	if len(args) != 2 {
+		return errors.New("usage: trackmytime rules move <id> <position>")
+	}
+	position, err := strconv.Atoi(args[1])
+	if err != nil || position <= 0 {
+		return fmt.Errorf("position invalide: %s", args[1])
+	}
+	return withRuleID(args[:1], func(id int64) error {
+		r, err := db.GetRule(id)
+		if err != nil {
+			return err
+		}
+		r.Position = position
+		return db.UpdateRule(r)
+	})
+}
+
+// exportRules écrit toutes les règles en JSON
+func exportRules(db *storage.DB, args []string) error {
+	rs, err := db.ListRules()
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return writeRulesJSON(os.Stdout, rs)
+	}
+
+	f, err := os.Create(args[0])
+	if err != nil {
+		return err
+	}
+	if err := writeRulesJSON(f, rs); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "✅ %d règles exportées dans %s\n", len(rs), args[0])
+	return nil
+}
+
+// importRules remplace les règles par celles d'un fichier produit par export
+func importRules(db *storage.DB, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: trackmytime rules import <fichier>")
+	}
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	var rs []rules.Rule
+	if err := json.Unmarshal(data, &rs); err != nil {
+		return fmt.Errorf("fichier de règles invalide: %w", err)
+	}
+	for _, r := range rs {
+		if err := rules.Validate(r); err != nil {
+			return err
+		}
+	}
+
+	if err := db.ReplaceRules(rs); err != nil {
+		return err
+	}
+	fmt.Printf("✅ %d règles importées\n", len(rs))
+	return nil
+}
+
+// testRules affiche le résultat des règles stockées pour une fenêtre donnée
+func testRules(db *storage.DB, args []string) error {
+	var w tracker.WindowInfo
+	fs := flag.NewFlagSet("rules test", flag.ContinueOnError)
+	fs.StringVar(&w.AppName, "app", "", "Nom de l'application")
+	fs.StringVar(&w.WindowTitle, "title", "", "Titre de la fenêtre")
+	fs.StringVar(&w.ProcessPath, "path", "", "Chemin de l'exécutable")
+	fs.StringVar(&w.URL, "url", "", "URL de l'onglet")
+	fs.StringVar(&w.Command, "command", "", "Commande au premier plan du terminal")
+	fs.StringVar(&w.WorkingDir, "cwd", "", "Dossier courant de la commande")
+	fs.StringVar(&w.SSHHost, "ssh_host", "", "Hôte distant de la session ssh")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := loadStoredRules(db); err != nil {
+		return err
+	}
+	return writeRulesJSON(os.Stdout, w.Enrich())
+}
+
+// loadStoredRules applique au tracker les règles stockées, les motifs
+// d'entités, les règles de confidentialité et les sites appris
+func loadStoredRules(db *storage.DB) error {
+	rs, err := db.ListRules()
+	if err != nil {
+		return err
+	}
+	if err := tracker.SetRules(rs); err != nil {
+		return err
+	}
+	patterns, err := db.EntityPatterns()
+	if err != nil {
+		return err
+	}
+	if err := tracker.SetEntityPatterns(patterns); err != nil {
+		return err
+	}
+	privacyRules, err := db.PrivacyRules()
+	if err != nil {
+		return err
+	}
+	if err := tracker.SetPrivacyRules(privacyRules); err != nil {
+		return err
+	}
+	sites, err := db.ConfirmedSites(rules.SiteConfirmations)
+	if err != nil {
+		return err
+	}
+	tracker.ConfirmSites(sites...)
+	return nil
+}
+
+// withRuleID appelle fn avec l'identifiant passé en unique argument
+func withRuleID(args []string, fn func(id int64) error) error {
+	if len(args) != 1 {
+		return errors.New("un identifiant de règle est requis")
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("identifiant de règle invalide: %s", args[0])
+	}
+	return fn(id)
+}
+
+// writeRulesJSON écrit v en JSON indenté
+func writeRulesJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// ruleCoverage affiche la part du temps actif nommée par les règles, les
+// titres non classés les plus longs et des règles candidates
+func ruleCoverage(db *storage.DB, args []string) error {
+	fs := flag.NewFlagSet("rules coverage", flag.ContinueOnError)
+	days := fs.Int("days", 30, "Nombre de jours analysés, aujourd'hui compris")
+	limit := fs.Int("limit", 15, "Nombre de titres et de suggestions affichés")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *days <= 0 || *limit <= 0 {
+		return errors.New("-days et -limit doivent être positifs")
+	}
+
+	now := time.Now()
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+	start := end.AddDate(0, 0, -*days)
+
+	coverage, err := db.GetCoverage(start, end)
+	if err != nil {
+		return err
+	}
+	titles, err := db.GetUnclassifiedTitles(start, end, max(*limit, 500))
+	if err != nil {
+		return err
+	}
+
+	percent := func(seconds int64) float64 {
+		if coverage.TotalSeconds == 0 {
+			return 0
+		}
+		return 100 * float64(seconds) / float64(coverage.TotalSeconds)
+	}
+	duration := func(seconds int64) time.Duration { return time.Duration(seconds) * time.Second }
+	classified := coverage.TotalSeconds - coverage.UnclassifiedSeconds()
+	fmt.Printf("📊 %d derniers jours: %s de temps actif\n", *days, duration(coverage.TotalSeconds))
+	fmt.Printf("   Nommé par les règles:  %5.1f%% (%s)\n", percent(classified), duration(classified))
+	fmt.Printf("   Nom de l'application:  %5.1f%% (%s)\n", percent(coverage.FallbackSeconds), duration(coverage.FallbackSeconds))
+	fmt.Printf("   %-22s %5.1f%% (%s)\n", rules.OtherSites+":", percent(coverage.OtherSeconds), duration(coverage.OtherSeconds))
+
+	samples := make([]rules.TitleSample, 0, len(titles))
+	for _, t := range titles {
+		samples = append(samples, rules.TitleSample{AppName: t.AppName, WindowTitle: t.WindowTitle, Seconds: t.Seconds})
+	}
+	if len(titles) > *limit {
+		titles = titles[:*limit]
+	}
+
+	if len(titles) > 0 {
+		fmt.Println()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "DURÉE\tAPPLICATION\tTITRE NON CLASSÉ")
+		for _, t := range titles {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", duration(t.Seconds), t.AppName, t.WindowTitle)
+		}
+		w.Flush()
+	}
+
+	if suggestions := rules.Suggest(samples, *limit); len(suggestions) > 0 {
+		fmt.Println()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "DURÉE\tTITRES\tRÈGLE SUGGÉRÉE")
+		for _, sg := range suggestions {
+			fmt.Fprintf(w, "%s\t%d\ttrackmytime rules add -name %q -position 1 -app %q -title %q -set-name %q\n",
+				duration(sg.Seconds), sg.Titles, sg.Rule.Name, "equals:"+sg.AppName, sg.Token, sg.Token)
+		}
+		w.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"trackmytime/client"
+	"trackmytime/config"
+	"trackmytime/internal/auth"
+	"trackmytime/internal/shell"
+	"trackmytime/internal/storage"
+)
+
+const shellInitUsage = `Usage: trackmytime shell-init bash|zsh|fish
+
+Affiche les hooks qui signalent à l'agent chaque commande lancée dans le
+shell (début, fin, code de sortie, dossier courant et durée). À ajouter à la
+configuration du shell :
+
+  bash  ~/.bashrc                   eval "$(trackmytime shell-init bash)"
+  zsh   ~/.zshrc                    eval "$(trackmytime shell-init zsh)"
+  fish  ~/.config/fish/config.fish  trackmytime shell-init fish | source
+
+Les commandes sont envoyées à l'agent local avec le jeton shell ; elles sont
+perdues si l'agent ne tourne pas. Voir "trackmytime commands".
+`
+
+// runShellInit exécute la commande "trackmytime shell-init" et retourne le code de sortie
+func runShellInit(args []string) int {
+	if len(args) != 1 || args[0] == "-h" || args[0] == "help" {
+		fmt.Fprint(os.Stderr, shellInitUsage)
+		return 2
+	}
+
+	binary, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	if resolved, err := filepath.EvalSymlinks(binary); err == nil {
+		binary = resolved
+	}
+	script, err := shell.Script(args[0], binary)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n\n%s", err, shellInitUsage)
+		return 2
+	}
+	fmt.Print(script)
+	return 0
+}
+
+// runShellEvent exécute "trackmytime shell-event", appelée par les hooks de
+// shell-init, et retourne le code de sortie
+func runShellEvent(args []string) int {
+	if len(args) == 0 || (args[0] != "start" && args[0] != "end") {
+		fmt.Fprintln(os.Stderr, "Usage: trackmytime shell-event start|end -session id -seq n [options] [-- commande]")
+		return 2
+	}
+
+	event := client.ShellEvent{Event: args[0], Timestamp: time.Now()}
+	fs := flag.NewFlagSet("shell-event "+args[0], flag.ContinueOnError)
+	fs.StringVar(&event.Shell, "shell", "", "Shell (bash, zsh, fish)")
+	fs.StringVar(&event.Session, "session", "", "Identifiant du shell")
+	fs.Int64Var(&event.Seq, "seq", 0, "Numéro de la commande dans la session")
+	fs.StringVar(&event.WorkingDir, "cwd", "", "Dossier courant")
+	fs.IntVar(&event.ExitCode, "status", 0, "Code de sortie (end)")
+	fs.Int64Var(&event.DurationMs, "duration", 0, "Durée en millisecondes (end)")
+	fs.Func("time", "Heure de l'événement en secondes Unix ($EPOCHREALTIME)", func(value string) error {
+		if value == "" {
+			return nil // bash < 5 : heure de réception
+		}
+		seconds, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+		if err != nil {
+			return err
+		}
+		event.Timestamp = time.UnixMicro(int64(seconds * 1e6))
+		return nil
+	})
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if event.Session == "" || event.Seq <= 0 {
+		fmt.Fprintln(os.Stderr, "❌ -session et -seq sont requis")
+		return 2
+	}
+	if event.Event == "start" {
+		if fs.NArg() != 1 {
+			fmt.Fprintln(os.Stderr, "❌ La commande est requise après --")
+			return 2
+		}
+		event.Command = fs.Arg(0)
+	}
+
+	// Lecture seule : un hook ne doit pas créer le fichier de jetons, que
+	// l'agent génère à son premier lancement
+	cfg := config.DefaultConfig()
+	tokens, err := auth.LoadTokens(cfg.TokenPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	token := tokens.Allowing(auth.ScopeShell)
+	if token == nil {
+		fmt.Fprintf(os.Stderr, "❌ Aucun jeton shell dans %s\n", cfg.TokenPath)
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	c := client.New("http://"+net.JoinHostPort(cfg.APIHost, cfg.APIPort), client.WithToken(token.Value))
+	if _, err := c.SendShellEvent(ctx, event); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+const commandsUsage = `Usage: trackmytime commands <commande> [options]
+
+Commandes:
+  list [-days 1] [-program nom] [-failed] [-limit 50]
+                          Dernières commandes lancées dans les shells
+  top [-days 7] [-limit 20]
+                          Temps passé par programme (go test, make build...)
+
+Les commandes sont signalées par les hooks de "trackmytime shell-init".
+`
+
+// runCommands exécute la commande "trackmytime commands" et retourne le code de sortie
+func runCommands(args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "help" {
+		fmt.Fprint(os.Stderr, commandsUsage)
+		return 2
+	}
+
+	cfg := config.DefaultConfig()
+	db, err := storage.NewDB(cfg.DBPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Erreur connexion DB: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	cmd, args := args[0], args[1:]
+	switch cmd {
+	case "list":
+		err = listCommands(db, args)
+	case "top":
+		err = topCommands(db, args)
+	default:
+		fmt.Fprintf(os.Stderr, "❌ Commande inconnue: %s\n\n%s", cmd, commandsUsage)
+		return 2
+	}
+
+	if errors.Is(err, flag.ErrHelp) {
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// lastDays retourne la période des days derniers jours, aujourd'hui compris
+func lastDays(days int) (start, end time.Time) {
+	now := time.Now()
+	end = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+	return end.AddDate(0, 0, -days), end
+}
+
+// listCommands affiche les dernières commandes de shell
+func listCommands(db *storage.DB, args []string) error {
+	fs := flag.NewFlagSet("commands list", flag.ContinueOnError)
+	days := fs.Int("days", 1, "Nombre de jours analysés, aujourd'hui compris")
+	filter := storage.ShellCommandFilter{}
+	fs.StringVar(&filter.Program, "program", "", "Uniquement ce programme")
+	fs.BoolVar(&filter.Failed, "failed", false, "Uniquement les commandes en échec")
+	fs.IntVar(&filter.Limit, "limit", 50, "Nombre de commandes affichées")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *days <= 0 || filter.Limit <= 0 {
+		return errors.New("-days et -limit doivent être positifs")
+	}
+	filter.Start, filter.End = lastDays(*days)
+
+	commands, err := db.ListShellCommands(filter)
+	if err != nil {
+		return err
+	}
+	if len(commands) == 0 {
+		fmt.Printf("Aucune commande sur les %d derniers jours\n", *days)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DÉBUT\tDURÉE\tCODE\tFOCUS\tDOSSIER\tCOMMANDE")
+	for _, c := range commands {
+		duration, code := "en cours", ""
+		if !c.EndedAt.IsZero() {
+			duration = c.Duration.Round(time.Second).String()
+		}
+		if c.ExitCode != nil {
+			code = strconv.Itoa(*c.ExitCode)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", c.StartedAt.Local().Format("2006-01-02 15:04:05"),
+			duration, code, time.Duration(c.FocusSeconds)*time.Second, c.WorkingDir, c.Command)
+	}
+	return w.Flush()
+}
+
+// topCommands affiche le temps passé par programme des derniers jours
+func topCommands(db *storage.DB, args []string) error {
+	fs := flag.NewFlagSet("commands top", flag.ContinueOnError)
+	days := fs.Int("days", 7, "Nombre de jours analysés, aujourd'hui compris")
+	limit := fs.Int("limit", 20, "Nombre de programmes affichés")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *days <= 0 || *limit <= 0 {
+		return errors.New("-days et -limit doivent être positifs")
+	}
+	start, end := lastDays(*days)
+
+	stats, err := db.GetShellStats(start, end, *limit)
+	if err != nil {
+		return err
+	}
+	if len(stats) == 0 {
+		fmt.Printf("Aucune commande sur les %d derniers jours\n", *days)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROGRAMME\tLANCEMENTS\tÉCHECS\tDURÉE TOTALE\tPLUS LONGUE\tDERNIÈRE FOIS")
+	for _, s := range stats {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\t%s\n", s.Program, s.Runs, s.Failures,
+			s.Duration.Round(time.Second), s.Longest.Round(time.Second), s.LastRun.Local().Format("2006-01-02 15:04"))
+	}
+	return w.Flush()
+}
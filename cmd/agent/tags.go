@@ -0,0 +1,167 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"trackmytime/config"
+	"trackmytime/internal/storage"
+)
+
+const tagsUsage = `Usage: trackmytime tags <commande> [arguments]
+
+Commandes:
+  list                                  Lister les tags et leur utilisation
+  add <id-activité> <tag>...            Poser des tags sur une activité
+  remove <id-activité> <tag>...         Retirer des tags d'une activité
+  range [-remove] <début> <fin> <tag>...
+                                        Poser (ou retirer) des tags sur les activités de la plage
+  rm <tag>                              Supprimer un tag de toutes les activités
+
+Les dates de range sont au format RFC 3339 ou "2006-01-02 15:04" (heure locale).
+Exemple :
+  trackmytime tags range "2024-05-13 14:00" "2024-05-13 16:30" client-call
+`
+
+// runTags exécute la commande "trackmytime tags" et retourne le code de sortie
+func runTags(args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "help" {
+		fmt.Fprint(os.Stderr, tagsUsage)
+		return 2
+	}
+
+	cfg := config.DefaultConfig()
+	db, err := storage.NewDB(cfg.DBPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Erreur connexion DB: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	cmd, args := args[0], args[1:]
+	switch cmd {
+	case "list":
+		err = listTags(db)
+	case "add", "remove":
+		err = tagActivity(db, cmd == "remove", args)
+	case "range":
+		err = tagRange(db, args)
+	case "rm":
+		if len(args) != 1 {
+			err = errors.New("usage: trackmytime tags rm <tag>")
+		} else if err = db.DeleteTag(args[0]); err == nil {
+			fmt.Printf("✅ Tag %s supprimé\n", args[0])
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "❌ Commande inconnue: %s\n\n%s", cmd, tagsUsage)
+		return 2
+	}
+
+	if errors.Is(err, flag.ErrHelp) {
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// listTags affiche les tags sous forme de tableau
+func listTags(db *storage.DB) error {
+	tags, err := db.ListTags()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TAG\tACTIVITÉS\tTEMPS ACTIF")
+	for _, t := range tags {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", t.Name, t.Activities, time.Duration(t.Seconds)*time.Second)
+	}
+	return w.Flush()
+}
+
+// tagActivity pose ou retire des tags sur une activité
+func tagActivity(db *storage.DB, remove bool, args []string) error {
+	if len(args) < 2 {
+		return errors.New("usage: trackmytime tags add|remove <id-activité> <tag>...")
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || id <= 0 {
+		return fmt.Errorf("identifiant d'activité invalide: %s", args[0])
+	}
+
+	if remove {
+		err = db.RemoveActivityTags(id, args[1:])
+	} else {
+		err = db.AddActivityTags(id, args[1:])
+	}
+	if err != nil {
+		return err
+	}
+
+	tags, err := db.ActivityTags(id)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ Activité %d: %v\n", id, tags)
+	return nil
+}
+
+// tagRange pose ou retire des tags sur les activités actives d'une plage horaire
+func tagRange(db *storage.DB, args []string) error {
+	fs := flag.NewFlagSet("tags range", flag.ContinueOnError)
+	remove := fs.Bool("remove", false, "Retirer les tags au lieu de les poser")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	args = fs.Args()
+	if len(args) < 3 {
+		return errors.New("usage: trackmytime tags range [-remove] <début> <fin> <tag>...")
+	}
+
+	start, err := parseTagTime(args[0])
+	if err != nil {
+		return err
+	}
+	end, err := parseTagTime(args[1])
+	if err != nil {
+		return err
+	}
+	if !end.After(start) {
+		return errors.New("la fin de la plage doit être après le début")
+	}
+
+	if *remove {
+		n, err := db.UntagRange(start, end, args[2:])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ %d tag(s) retiré(s)\n", n)
+		return nil
+	}
+	n, err := db.TagRange(start, end, args[2:])
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ %d activité(s) taguée(s)\n", n)
+	return nil
+}
+
+// parseTagTime lit une date RFC 3339 ou "2006-01-02 15:04" en heure locale
+func parseTagTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02 15:04", s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("date invalide %q (RFC 3339 ou \"2006-01-02 15:04\")", s)
+	}
+	return t, nil
+}
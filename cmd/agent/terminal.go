@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"trackmytime/config"
+	"trackmytime/internal/storage"
+)
+
+const terminalUsage = `Usage: trackmytime terminal [-by command|ssh_host|cwd] [-days 7] [-limit 20]
+
+Temps passé dans les terminaux, regroupé par commande au premier plan, par
+hôte des sessions ssh ou par dossier courant.
+
+La commande est lue dans /proc pour les émulateurs de terminal Linux
+reconnus (GNOME Terminal, Konsole, Alacritty, kitty, WezTerm...).
+`
+
+// runTerminal exécute la commande "trackmytime terminal" et retourne le code de sortie
+func runTerminal(args []string) int {
+	fs := flag.NewFlagSet("terminal", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, terminalUsage) }
+	by := fs.String("by", string(storage.TerminalByCommand), "Regroupement: command, ssh_host ou cwd")
+	days := fs.Int("days", 7, "Nombre de jours analysés, aujourd'hui compris")
+	limit := fs.Int("limit", 20, "Nombre de valeurs affichées")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *days <= 0 || *limit <= 0 {
+		fmt.Fprintln(os.Stderr, "❌ -days et -limit doivent être positifs")
+		return 2
+	}
+
+	var header string
+	switch dimension := storage.TerminalDimension(*by); dimension {
+	case storage.TerminalByCommand:
+		header = "COMMANDE"
+	case storage.TerminalByHost:
+		header = "HÔTE"
+	case storage.TerminalByCwd:
+		header = "DOSSIER"
+	default:
+		fmt.Fprintf(os.Stderr, "❌ Regroupement inconnu: %s (command, ssh_host, cwd)\n", *by)
+		return 2
+	}
+
+	cfg := config.DefaultConfig()
+	db, err := storage.NewDB(cfg.DBPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Erreur connexion DB: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	now := time.Now()
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+	start := end.AddDate(0, 0, -*days)
+	stats, err := db.GetTerminalStats(start, end, storage.TerminalDimension(*by), *limit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	if len(stats) == 0 {
+		fmt.Printf("Aucune activité de terminal sur les %d derniers jours\n", *days)
+		return 0
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\tACTIVITÉS\tTEMPS ACTIF\tDERNIÈRE FOIS\n", header)
+	for _, s := range stats {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", s.Value, s.Activities,
+			time.Duration(s.Seconds)*time.Second, s.LastSeen.Local().Format("2006-01-02 15:04"))
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	return 0
+}
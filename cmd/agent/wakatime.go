@@ -0,0 +1,136 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"trackmytime/config"
+	"trackmytime/internal/api"
+	"trackmytime/internal/auth"
+	"trackmytime/internal/storage"
+)
+
+const wakaTimeUsage = `Usage: trackmytime wakatime <commande> [options]
+
+Commandes:
+  config                  Affiche la section [settings] de ~/.wakatime.cfg qui
+                          envoie les heartbeats des plugins d'éditeur à l'agent
+  summary [-days 1]       Temps passé par projet, langage et éditeur d'après
+                          les heartbeats
+
+Les plugins WakaTime (VS Code, JetBrains, Vim, Emacs...) passent par
+wakatime-cli, qui lit api_url et api_key dans ~/.wakatime.cfg. Leurs
+heartbeats précisent le fichier, le langage, le projet et la branche des
+activités d'éditeur.
+`
+
+// runWakaTime exécute la commande "trackmytime wakatime" et retourne le code de sortie
+func runWakaTime(args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "help" {
+		fmt.Fprint(os.Stderr, wakaTimeUsage)
+		return 2
+	}
+
+	var err error
+	cmd, args := args[0], args[1:]
+	switch cmd {
+	case "config":
+		err = wakaTimeConfig(args)
+	case "summary":
+		err = wakaTimeSummary(args)
+	default:
+		fmt.Fprintf(os.Stderr, "❌ Commande inconnue: %s\n\n%s", cmd, wakaTimeUsage)
+		return 2
+	}
+
+	if errors.Is(err, flag.ErrHelp) {
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// wakaTimeConfig affiche la configuration de wakatime-cli pointant sur l'agent
+func wakaTimeConfig(args []string) error {
+	fs := flag.NewFlagSet("wakatime config", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg := config.DefaultConfig()
+	tokens, err := auth.LoadOrCreateTokens(cfg.TokenPath)
+	if err != nil {
+		return err
+	}
+	token := tokens.Allowing(auth.ScopeWakaTime)
+	if token == nil {
+		return fmt.Errorf("aucun jeton wakatime dans %s", cfg.TokenPath)
+	}
+
+	fmt.Println("[settings]")
+	fmt.Printf("api_url = http://%s%s\n", net.JoinHostPort(cfg.APIHost, cfg.APIPort), api.APIVersionPrefix)
+	fmt.Printf("api_key = %s\n", token.Value)
+	return nil
+}
+
+// wakaTimeSummary affiche le temps compté à partir des heartbeats
+func wakaTimeSummary(args []string) error {
+	fs := flag.NewFlagSet("wakatime summary", flag.ContinueOnError)
+	days := fs.Int("days", 1, "Nombre de jours analysés, aujourd'hui compris")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *days <= 0 {
+		return errors.New("-days doit être positif")
+	}
+
+	db, err := storage.NewDB(config.DefaultConfig().DBPath)
+	if err != nil {
+		return fmt.Errorf("erreur connexion DB: %w", err)
+	}
+	defer db.Close()
+
+	start, end := lastDays(*days)
+	totals, err := db.GetHeartbeatTotals(start, end)
+	if err != nil {
+		return err
+	}
+	if totals.Total == 0 {
+		fmt.Printf("Aucun heartbeat sur les %d derniers jours\n", *days)
+		return nil
+	}
+
+	fmt.Printf("⌨️  %s dans les éditeurs\n\n", totals.Total.Round(time.Second))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tNOM\tTEMPS\tPART")
+	for _, group := range []struct {
+		label string
+		stats []storage.HeartbeatStat
+	}{
+		{"projet", totals.Projects},
+		{"langage", totals.Languages},
+		{"éditeur", totals.Editors},
+		{"branche", totals.Branches},
+	} {
+		for _, s := range group.stats {
+			if s.Duration == 0 {
+				continue
+			}
+			name := s.Name
+			if name == "" {
+				name = "(inconnu)"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%.0f%%\n", group.label, name, s.Duration.Round(time.Second),
+				float64(s.Duration)*100/float64(totals.Total))
+		}
+	}
+	return w.Flush()
+}
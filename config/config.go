@@ -17,26 +17,58 @@ type Config struct {
 	// Chemin de la base de données SQLite
 	DBPath string
 
+	// Adresse d'écoute du serveur HTTP local (127.0.0.1 = accessible uniquement en local)
+	APIHost string
+
 	// Port du serveur HTTP local
 	APIPort string
 
+	// Port de l'API compatible ActivityWatch pour les watchers aw-* (vide =
+	// désactivée ; 5600 est celui d'aw-server). N'écoute qu'en local.
+	ActivityWatchPort string
+
+	// Endpoint DevTools d'un navigateur Chromium lancé avec
+	// --remote-debugging-port, interrogé pour l'URL de l'onglet actif
+	// (vide = désactivé), ex: http://127.0.0.1:9222
+	DevToolsURL string
+
+	// Fichier des jetons d'accès à l'API (créé en 0600 au premier lancement)
+	TokenPath string
+
+	// Origines autorisées en CORS, ex: chrome-extension://<id>
+	AllowedOrigins []string
+
 	// Activer ou non l'API HTTP
 	EnableAPI bool
+
+	// Dossier du dashboard servi depuis le disque (vide = fichiers embarqués)
+	WebDir string
+
+	// Intervalle de vérification des modifications de règles faites hors de l'agent (CLI)
+	RulesReloadInterval time.Duration
+
+	// Délai maximal accordé à l'arrêt propre (drainage HTTP, écriture finale)
+	ShutdownTimeout time.Duration
 }
 
 // DefaultConfig retourne la configuration par défaut
 func DefaultConfig() *Config {
 	homeDir, _ := os.UserHomeDir()
-	dbPath := filepath.Join(homeDir, ".trackmytime", "activities.db")
+	dataDir := filepath.Join(homeDir, ".trackmytime")
+	dbPath := filepath.Join(dataDir, "activities.db")
 
 	// Créer le dossier s'il n'existe pas
-	os.MkdirAll(filepath.Dir(dbPath), 0755)
+	os.MkdirAll(dataDir, 0755)
 
 	return &Config{
-		CheckInterval: 2 * time.Second,
-		IdleThreshold: 60 * time.Second,
-		DBPath:        dbPath,
-		APIPort:       "8787",
-		EnableAPI:     true,
+		CheckInterval:       2 * time.Second,
+		IdleThreshold:       60 * time.Second,
+		DBPath:              dbPath,
+		APIHost:             "127.0.0.1",
+		APIPort:             "8787",
+		TokenPath:           filepath.Join(dataDir, "tokens.json"),
+		EnableAPI:           true,
+		RulesReloadInterval: 30 * time.Second,
+		ShutdownTimeout:     10 * time.Second,
 	}
 }
@@ -0,0 +1,674 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"trackmytime/internal/gitinfo"
+	"trackmytime/internal/privacy"
+	"trackmytime/internal/storage"
+	"trackmytime/internal/tracker"
+)
+
+// ActivityWatchPrefix est le préfixe de l'API aw-server
+const ActivityWatchPrefix = "/api/0"
+
+// AWInfo est la réponse de GET /api/0/info
+type AWInfo struct {
+	Hostname string `json:"hostname"`
+	Version  string `json:"version"`
+	Testing  bool   `json:"testing"`
+	DeviceID string `json:"device_id"`
+}
+
+// AWBucket est un bucket au format aw-server
+type AWBucket struct {
+	ID          string          `json:"id"`
+	Name        string          `json:"name,omitempty"`
+	Type        string          `json:"type"`
+	Client      string          `json:"client"`
+	Hostname    string          `json:"hostname"`
+	Created     time.Time       `json:"created"`
+	LastUpdated time.Time       `json:"last_updated,omitzero"`
+	Data        json.RawMessage `json:"data,omitempty"`
+}
+
+// AWEvent est un événement au format aw-server
+type AWEvent struct {
+	ID        int64           `json:"id,omitempty"`
+	Timestamp time.Time       `json:"timestamp"`
+	Duration  float64         `json:"duration" doc:"En secondes"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// InputHour est l'activité du clavier et de la souris sur une heure
+type InputHour struct {
+	Hour    int   `json:"hour" doc:"Heure locale, de 0 à 23"`
+	Presses int64 `json:"presses"`
+	Clicks  int64 `json:"clicks"`
+}
+
+// InputStatsResponse est la réponse de GET /api/v1/stats/input
+type InputStatsResponse struct {
+	Period        PeriodInfo  `json:"period"`
+	Presses       int64       `json:"presses" doc:"Touches pressées"`
+	Clicks        int64       `json:"clicks"`
+	MouseMoved    float64     `json:"mouse_moved" doc:"Déplacement de la souris, en pixels"`
+	Scrolled      float64     `json:"scrolled" doc:"Défilement, en pixels"`
+	ActiveSeconds int64       `json:"active_seconds" doc:"Durée des événements avec au moins une touche, un clic ou un mouvement"`
+	Hours         []InputHour `json:"hours" doc:"Les 24 heures de la journée, même vides"`
+}
+
+// EnableActivityWatch sert aussi l'API d'aw-server sur 127.0.0.1:port pour
+// les watchers ActivityWatch. Comme aw-server, elle n'exige pas de jeton :
+// elle n'écoute qu'en local et refuse les origines non autorisées. Doit être
+// appelé avant Start.
+func (s *Server) EnableActivityWatch(port string) {
+	s.awServer = &http.Server{
+		Addr:    net.JoinHostPort("127.0.0.1", port),
+		Handler: s.secure(true, "GET, POST, DELETE", s.activityWatchRoutes()),
+	}
+}
+
+// activityWatchRoutes construit le routeur de l'API aw-server
+func (s *Server) activityWatchRoutes() http.Handler {
+	mux := http.NewServeMux()
+	p := ActivityWatchPrefix
+	mux.HandleFunc("GET "+p+"/info", s.handleAWInfo)
+	mux.HandleFunc("GET "+p+"/buckets/{$}", s.handleAWBuckets)
+	mux.HandleFunc("GET "+p+"/buckets", s.handleAWBuckets)
+	mux.HandleFunc("GET "+p+"/buckets/{bucket}", s.handleAWBucket)
+	mux.HandleFunc("POST "+p+"/buckets/{bucket}", s.handleAWCreateBucket)
+	mux.HandleFunc("DELETE "+p+"/buckets/{bucket}", s.handleAWDeleteBucket)
+	mux.HandleFunc("GET "+p+"/buckets/{bucket}/events", s.handleAWEvents)
+	mux.HandleFunc("POST "+p+"/buckets/{bucket}/events", s.handleAWInsertEvents)
+	mux.HandleFunc("GET "+p+"/buckets/{bucket}/events/count", s.handleAWCountEvents)
+	mux.HandleFunc("GET "+p+"/buckets/{bucket}/events/{id}", s.handleAWEvent)
+	mux.HandleFunc("DELETE "+p+"/buckets/{bucket}/events/{id}", s.handleAWDeleteEvent)
+	mux.HandleFunc("POST "+p+"/buckets/{bucket}/heartbeat", s.handleAWHeartbeat)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "route inconnue: "+r.Method+" "+r.URL.Path)
+	})
+	return mux
+}
+
+// handleAWInfo décrit le serveur aux watchers
+func (s *Server) handleAWInfo(w http.ResponseWriter, r *http.Request) {
+	hostname, _ := os.Hostname()
+	writeJSON(w, http.StatusOK, AWInfo{Hostname: hostname, Version: "trackmytime", DeviceID: hostname})
+}
+
+// handleAWBuckets retourne les buckets indexés par identifiant
+func (s *Server) handleAWBuckets(w http.ResponseWriter, r *http.Request) {
+	buckets, err := s.db.ListAWBuckets()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	response := make(map[string]AWBucket, len(buckets))
+	for _, b := range buckets {
+		response[b.ID] = awBucketItem(b)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleAWBucket retourne un bucket
+func (s *Server) handleAWBucket(w http.ResponseWriter, r *http.Request) {
+	bucket, ok := s.awBucket(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, awBucketItem(*bucket))
+}
+
+// handleAWCreateBucket crée un bucket ; 304 s'il existe déjà, comme aw-server
+func (s *Server) handleAWCreateBucket(w http.ResponseWriter, r *http.Request) {
+	var body AWBucket
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "JSON invalide: "+err.Error())
+		return
+	}
+	if body.Type == "" || body.Client == "" || body.Hostname == "" {
+		writeError(w, http.StatusBadRequest, "type, client et hostname sont requis")
+		return
+	}
+	data, err := awData(body.Data, true)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	created, err := s.db.CreateAWBucket(storage.AWBucket{
+		ID:       r.PathValue("bucket"),
+		Type:     body.Type,
+		Client:   body.Client,
+		Hostname: body.Hostname,
+		Data:     data,
+		Created:  time.Now(),
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !created {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	log.Printf("👁️  Bucket ActivityWatch créé: %s (%s)", r.PathValue("bucket"), body.Type)
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleAWDeleteBucket supprime un bucket et ses événements (?force=1 requis)
+func (s *Server) handleAWDeleteBucket(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("force") != "1" {
+		writeError(w, http.StatusBadRequest, "suppression de bucket sans ?force=1")
+		return
+	}
+	deleted, err := s.db.DeleteAWBucket(r.PathValue("bucket"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !deleted {
+		writeError(w, http.StatusNotFound, "bucket inconnu: "+r.PathValue("bucket"))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleAWEvents retourne les événements d'un bucket, les plus récents d'abord
+func (s *Server) handleAWEvents(w http.ResponseWriter, r *http.Request) {
+	bucket, ok := s.awBucket(w, r)
+	if !ok {
+		return
+	}
+	start, end, ok := awRange(w, r)
+	if !ok {
+		return
+	}
+	limit := -1
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "limit invalide")
+			return
+		}
+		limit = n
+	}
+
+	events, err := s.db.ListAWEvents(bucket.ID, start, end, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	response := make([]AWEvent, 0, len(events))
+	for _, e := range events {
+		response = append(response, awEventItem(e))
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleAWCountEvents compte les événements d'un bucket
+func (s *Server) handleAWCountEvents(w http.ResponseWriter, r *http.Request) {
+	bucket, ok := s.awBucket(w, r)
+	if !ok {
+		return
+	}
+	start, end, ok := awRange(w, r)
+	if !ok {
+		return
+	}
+	count, err := s.db.CountAWEvents(bucket.ID, start, end)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, count)
+}
+
+// handleAWEvent retourne un événement
+func (s *Server) handleAWEvent(w http.ResponseWriter, r *http.Request) {
+	bucket, ok := s.awBucket(w, r)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "identifiant d'événement invalide")
+		return
+	}
+	event, err := s.db.GetAWEvent(bucket.ID, id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if event == nil {
+		writeError(w, http.StatusNotFound, "événement inconnu: "+r.PathValue("id"))
+		return
+	}
+	writeJSON(w, http.StatusOK, awEventItem(*event))
+}
+
+// handleAWDeleteEvent supprime un événement
+func (s *Server) handleAWDeleteEvent(w http.ResponseWriter, r *http.Request) {
+	bucket, ok := s.awBucket(w, r)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "identifiant d'événement invalide")
+		return
+	}
+	deleted, err := s.db.DeleteAWEvent(bucket.ID, id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !deleted {
+		writeError(w, http.StatusNotFound, "événement inconnu: "+r.PathValue("id"))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleAWInsertEvents ajoute un événement ou une liste d'événements
+func (s *Server) handleAWInsertEvents(w http.ResponseWriter, r *http.Request) {
+	bucket, ok := s.awBucket(w, r)
+	if !ok {
+		return
+	}
+	var body json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "JSON invalide: "+err.Error())
+		return
+	}
+	var items []AWEvent
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(body, &items); err != nil {
+			writeError(w, http.StatusBadRequest, "JSON invalide: "+err.Error())
+			return
+		}
+	} else {
+		var item AWEvent
+		if err := json.Unmarshal(body, &item); err != nil {
+			writeError(w, http.StatusBadRequest, "JSON invalide: "+err.Error())
+			return
+		}
+		items = []AWEvent{item}
+	}
+
+	events := make([]storage.AWEvent, 0, len(items))
+	for _, item := range items {
+		event, err := awEventRecord(item)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		// Les événements exclus par les règles de confidentialité ne sont
+		// pas enregistrés
+		if event.Protect(*bucket, tracker.Protect) {
+			events = append(events, event)
+		}
+	}
+	inserted, err := s.db.InsertAWEvents(bucket.ID, events)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := make([]AWEvent, 0, len(inserted))
+	for _, e := range inserted {
+		s.forwardAWEvent(*bucket, e)
+		response = append(response, awEventItem(e))
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleAWHeartbeat fusionne un heartbeat avec le dernier événement du bucket
+// (?pulsetime= en secondes)
+func (s *Server) handleAWHeartbeat(w http.ResponseWriter, r *http.Request) {
+	bucket, ok := s.awBucket(w, r)
+	if !ok {
+		return
+	}
+	var pulsetime time.Duration
+	if value := r.URL.Query().Get("pulsetime"); value != "" {
+		seconds, err := strconv.ParseFloat(value, 64)
+		if err != nil || seconds < 0 {
+			writeError(w, http.StatusBadRequest, "pulsetime invalide")
+			return
+		}
+		pulsetime = time.Duration(seconds * float64(time.Second))
+	}
+	var item AWEvent
+	if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+		writeError(w, http.StatusBadRequest, "JSON invalide: "+err.Error())
+		return
+	}
+	heartbeat, err := awEventRecord(item)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !heartbeat.Protect(*bucket, tracker.Protect) {
+		// Exclu par les règles de confidentialité : rien n'est enregistré
+		writeJSON(w, http.StatusOK, awEventItem(heartbeat))
+		return
+	}
+
+	event, _, err := s.db.AWHeartbeat(bucket.ID, heartbeat, pulsetime)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	switch bucket.Type {
+	case storage.AWTypeWindow, storage.AWTypeAFK:
+		// L'événement fusionné prolonge l'activité qui en est déduite
+		s.forwardAWEvent(*bucket, event)
+	default:
+		// Tous les heartbeats sont transmis : le temps se compte entre heartbeats
+		heartbeat.ID = event.ID
+		s.forwardAWEvent(*bucket, heartbeat)
+	}
+	writeJSON(w, http.StatusOK, awEventItem(event))
+}
+
+// forwardAWEvent transmet à TrackMyTime un événement des watchers qu'il sait
+// exploiter : les fichiers des éditeurs deviennent des heartbeats, reportés
+// sur les activités comme ceux des plugins WakaTime, les onglets des
+// signaux de l'extension navigateur, et les fenêtres et l'inactivité des
+// activités là où l'agent n'a rien enregistré. Les événements
+// d'aw-watcher-input restent dans aw_events, comptés par /stats/input.
+func (s *Server) forwardAWEvent(bucket storage.AWBucket, event storage.AWEvent) {
+	switch bucket.Type {
+	case storage.AWTypeWindow, storage.AWTypeAFK:
+		activity := awActivity(bucket.Type, event)
+		if activity == nil {
+			return
+		}
+		// L'activité en cours de l'agent n'est pas encore en base
+		if _, current := s.tracker.Current(); !current.IsZero() {
+			if !activity.StartTime.Before(current) {
+				return
+			}
+			if activity.EndTime.After(current) {
+				activity.EndTime = current
+			}
+		}
+		if _, err := s.db.RecordAWActivity(event.ID, activity); err != nil {
+			log.Printf("⚠️  Erreur activité ActivityWatch: %v", err)
+		}
+
+	case storage.AWTypeEditor:
+		var data struct {
+			File     string `json:"file"`
+			Project  string `json:"project"`
+			Language string `json:"language"`
+			Branch   string `json:"branch"`
+		}
+		if json.Unmarshal([]byte(event.Data), &data) != nil || data.File == "" {
+			return
+		}
+		project := data.Project
+		if filepath.IsAbs(project) {
+			// aw-watcher-vim envoie le dossier courant
+			project = filepath.Base(project)
+		}
+		record, err := heartbeatRecord(Heartbeat{
+			Entity:  data.File,
+			Time:    float64(event.Timestamp.UnixNano()) / 1e9,
+			Project: project,
+			Branch:  data.Branch,
+		}, "", map[string]gitinfo.Repo{})
+		if err != nil {
+			return
+		}
+		if record.Document.Language == "" && data.Language != "" {
+			record.Document.Language = strings.ToUpper(data.Language[:1]) + data.Language[1:]
+		}
+		record.Editor = awEditor(bucket.Client)
+		record.UserAgent = bucket.Client
+		if err := s.recordHeartbeats([]storage.Heartbeat{record}); err != nil {
+			log.Printf("⚠️  Erreur heartbeat ActivityWatch: %v", err)
+		}
+
+	case storage.AWTypeWebTab:
+		var data struct {
+			URL   string `json:"url"`
+			Title string `json:"title"`
+		}
+		if json.Unmarshal([]byte(event.Data), &data) != nil || data.URL == "" {
+			return
+		}
+		_, err := s.recordBrowserSignals([]storage.BrowserSignal{{
+			Type:     storage.BrowserHeartbeat,
+			URL:      data.URL,
+			TabTitle: data.Title,
+			Browser:  bucket.App(),
+			Time:     event.Timestamp,
+			Duration: event.Duration,
+		}})
+		if err != nil {
+			log.Printf("⚠️  Erreur onglet ActivityWatch: %v", err)
+		}
+	}
+}
+
+// handleV1InputStats additionne les événements d'aw-watcher-input de la période
+func (s *Server) handleV1InputStats(w http.ResponseWriter, r *http.Request) {
+	period, ok := s.v1Period(w, r)
+	if !ok {
+		return
+	}
+	totals, err := s.db.GetAWInputTotals(period.Start, period.End)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := InputStatsResponse{
+		Period:        period,
+		Presses:       totals.Presses,
+		Clicks:        totals.Clicks,
+		MouseMoved:    totals.MouseMoved,
+		Scrolled:      totals.Scrolled,
+		ActiveSeconds: int64(totals.Active.Seconds()),
+		Hours:         make([]InputHour, 0, len(totals.Hours)),
+	}
+	for _, hour := range totals.Hours {
+		response.Hours = append(response.Hours, InputHour{Hour: hour.Hour, Presses: hour.Presses, Clicks: hour.Clicks})
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// awActivity déduit l'activité d'un événement d'aw-watcher-window
+// ({app, title, url}) ou d'aw-watcher-afk ({status}), comme l'agent le fait
+// de la fenêtre active ; nil si l'événement n'a pas de durée, si les règles
+// de confidentialité l'excluent ou si les règles l'ignorent
+func awActivity(bucketType string, event storage.AWEvent) *storage.Activity {
+	if event.Duration <= 0 {
+		return nil
+	}
+	start := event.Timestamp.Local()
+	end := start.Add(event.Duration)
+
+	if bucketType == storage.AWTypeAFK {
+		var data struct {
+			Status string `json:"status"`
+		}
+		if json.Unmarshal([]byte(event.Data), &data) != nil || data.Status != "afk" {
+			return nil
+		}
+		return &storage.Activity{
+			AppName:      "IDLE",
+			WindowTitle:  "Inactif",
+			StartTime:    start,
+			EndTime:      end,
+			DurationSecs: int64(event.Duration.Seconds()),
+			IsIdle:       true,
+		}
+	}
+
+	var data struct {
+		App   string `json:"app"`
+		Title string `json:"title"`
+		URL   string `json:"url"`
+	}
+	if json.Unmarshal([]byte(event.Data), &data) != nil || data.App == "" {
+		return nil
+	}
+	w := tracker.WindowInfo{AppName: data.App, WindowTitle: data.Title, URL: data.URL}
+	if w.Protect().Action == privacy.ActionDrop {
+		return nil
+	}
+	enriched := w.Enrich()
+	if enriched.Ignored {
+		return nil
+	}
+	activity := &storage.Activity{
+		AppName:      w.AppName,
+		EnrichedName: enriched.EnrichedName,
+		Category:     enriched.Category,
+		Project:      enriched.Project,
+		Tags:         enriched.Tags,
+		Entities:     w.Entities(),
+		Document:     w.Document(),
+		URL:          w.URL,
+		WindowTitle:  w.WindowTitle,
+		StartTime:    start,
+		EndTime:      end,
+		DurationSecs: int64(event.Duration.Seconds()),
+		IsIdle:       enriched.Idle,
+	}
+	if site := enriched.Site; site != nil {
+		activity.InferredSite = site.Name
+		activity.Confidence = &site.Confidence
+	}
+	return activity
+}
+
+// awEditor déduit l'éditeur du client d'un bucket : "aw-watcher-vim" → "Vim"
+func awEditor(client string) string {
+	name := strings.TrimPrefix(strings.ToLower(client), "aw-watcher-")
+	if display, ok := wakaTimeEditors[name]; ok {
+		return display
+	}
+	return name
+}
+
+// awBucket lit le bucket du chemin ; écrit une 404 et retourne false s'il
+// n'existe pas
+func (s *Server) awBucket(w http.ResponseWriter, r *http.Request) (*storage.AWBucket, bool) {
+	bucket, err := s.db.GetAWBucket(r.PathValue("bucket"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	if bucket == nil {
+		writeError(w, http.StatusNotFound, "bucket inconnu: "+r.PathValue("bucket"))
+		return nil, false
+	}
+	return bucket, true
+}
+
+// awRange lit les bornes ?start= et ?end= (ISO 8601) d'une requête
+// d'événements ; une borne absente n'est pas appliquée
+func awRange(w http.ResponseWriter, r *http.Request) (start, end time.Time, ok bool) {
+	for _, bound := range []struct {
+		name string
+		dest *time.Time
+	}{{"start", &start}, {"end", &end}} {
+		value := r.URL.Query().Get(bound.name)
+		if value == "" {
+			continue
+		}
+		t, err := parseAWTime(value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, bound.name+" invalide (ISO 8601)")
+			return start, end, false
+		}
+		*bound.dest = t
+	}
+	return start, end, true
+}
+
+// parseAWTime lit une date ISO 8601 ; sans fuseau, elle est en UTC comme
+// dans aw-server
+func parseAWTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t.Local(), nil
+	}
+	t, err := time.Parse("2006-01-02T15:04:05.999999999", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.Local(), nil
+}
+
+// awEventRecord valide un événement reçu et le convertit pour le stockage
+func awEventRecord(item AWEvent) (storage.AWEvent, error) {
+	if item.Timestamp.IsZero() {
+		return storage.AWEvent{}, fmt.Errorf("timestamp est requis")
+	}
+	if item.Duration < 0 {
+		return storage.AWEvent{}, fmt.Errorf("duration ne peut pas être négative")
+	}
+	data, err := awData(item.Data, false)
+	if err != nil {
+		return storage.AWEvent{}, err
+	}
+	return storage.AWEvent{
+		// Heure locale comme les autres tables, pour comparer les dates stockées
+		Timestamp: item.Timestamp.Local(),
+		Duration:  time.Duration(item.Duration * float64(time.Second)),
+		Data:      data,
+	}, nil
+}
+
+// awData vérifie que data est un objet JSON et le met sous forme canonique
+// (clés triées) pour comparer les heartbeats ; vide si optional et absent
+func awData(data json.RawMessage, optional bool) (string, error) {
+	if len(data) == 0 && optional {
+		return "{}", nil
+	}
+	var object map[string]any
+	if err := json.Unmarshal(data, &object); err != nil || object == nil {
+		return "", fmt.Errorf("data doit être un objet JSON")
+	}
+	canonical, err := json.Marshal(object)
+	return string(canonical), err
+}
+
+// awBucketItem convertit un bucket stocké au format aw-server
+func awBucketItem(b storage.AWBucket) AWBucket {
+	return AWBucket{
+		ID:          b.ID,
+		Name:        b.ID,
+		Type:        b.Type,
+		Client:      b.Client,
+		Hostname:    b.Hostname,
+		Created:     b.Created,
+		LastUpdated: b.LastUpdated,
+		Data:        json.RawMessage(b.Data),
+	}
+}
+
+// awEventItem convertit un événement stocké au format aw-server
+func awEventItem(e storage.AWEvent) AWEvent {
+	return AWEvent{
+		ID:        e.ID,
+		Timestamp: e.Timestamp.UTC(),
+		Duration:  e.Duration.Seconds(),
+		Data:      json.RawMessage(e.Data),
+	}
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"trackmytime/web"
+)
+
+// assetServer sert les fichiers du dashboard avec ETag et en-têtes de cache
+type assetServer struct {
+	fsys fs.FS
+	dev  bool
+
+	mu    sync.Mutex
+	etags map[string]string
+}
+
+// newEmbeddedAssets sert les fichiers embarqués dans le binaire
+func newEmbeddedAssets() *assetServer {
+	return &assetServer{
+		fsys:  web.Assets,
+		etags: make(map[string]string),
+	}
+}
+
+// newDiskAssets sert les fichiers depuis un dossier local, sans cache,
+// pour pouvoir modifier le frontend sans recompiler
+func newDiskAssets(dir string) *assetServer {
+	return &assetServer{
+		fsys:  os.DirFS(dir),
+		dev:   true,
+		etags: make(map[string]string),
+	}
+}
+
+// serve écrit le fichier name dans la réponse
+func (a *assetServer) serve(w http.ResponseWriter, r *http.Request, name string) {
+	if !fs.ValidPath(name) {
+		http.NotFound(w, r)
+		return
+	}
+
+	f, err := a.fsys.Open(name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		http.Error(w, "fichier illisible", http.StatusInternalServerError)
+		return
+	}
+
+	if a.dev {
+		w.Header().Set("Cache-Control", "no-store")
+	} else {
+		etag, err := a.etag(name, content)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		// Les URL ne changent pas d'une version à l'autre : tout est revalidé
+		// (304 grâce à l'ETag) pour que le nouveau JS soit pris dès la mise à jour
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+
+	// ServeContent gère If-None-Match (304) à partir de l'en-tête ETag
+	http.ServeContent(w, r, name, info.ModTime(), content)
+}
+
+// etag calcule (une seule fois par fichier) l'ETag à partir du contenu
+func (a *assetServer) etag(name string, content io.ReadSeeker) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if etag, ok := a.etags[name]; ok {
+		return etag, nil
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, content); err != nil {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	etag := `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+	a.etags[name] = etag
+	return etag, nil
+}
+
+// handleStatic sert les fichiers sous /static/
+func (s *Server) handleStatic(w http.ResponseWriter, r *http.Request) {
+	name := path.Join("static", strings.TrimPrefix(r.URL.Path, "/static/"))
+	s.assets.serve(w, r, name)
+}
@@ -0,0 +1,271 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"trackmytime/internal/privacy"
+	"trackmytime/internal/storage"
+	"trackmytime/internal/tracker"
+)
+
+// BrowserEvent est un signal de l'extension navigateur sur l'onglet actif.
+// POST /api/v1/browser/events accepte un signal ou un tableau de signaux.
+type BrowserEvent struct {
+	Type        string  `json:"type,omitempty" doc:"focus, heartbeat (défaut) ou blur"`
+	URL         string  `json:"url" doc:"URL de l'onglet, facultative pour blur"`
+	TabTitle    string  `json:"tab_title"`
+	BrowserName string  `json:"browser_name"`
+	Timestamp   string  `json:"timestamp" doc:"RFC 3339 (heure de réception par défaut)"`
+	Duration    float64 `json:"duration,omitempty" doc:"Secondes déjà passées sur la page à timestamp, pour les lots envoyés a posteriori"`
+}
+
+// DomainStat est le temps passé sur un domaine d'après l'extension navigateur
+type DomainStat struct {
+	Domain       string    `json:"domain" doc:"Hôte sans www."`
+	Visits       int       `json:"visits"`
+	TotalSeconds int64     `json:"total_seconds"`
+	LastSeen     time.Time `json:"last_seen,omitzero"`
+}
+
+// DomainStatsResponse est la réponse de GET /api/v1/stats/domains
+type DomainStatsResponse struct {
+	Period  PeriodInfo   `json:"period"`
+	Domains []DomainStat `json:"domains" doc:"Triés par durée décroissante"`
+}
+
+// PathStat est le temps passé sur une page (domaine et chemin, sans paramètres)
+type PathStat struct {
+	Domain       string    `json:"domain"`
+	Path         string    `json:"path"`
+	Visits       int       `json:"visits"`
+	TotalSeconds int64     `json:"total_seconds"`
+	LastSeen     time.Time `json:"last_seen,omitzero"`
+}
+
+// PathStatsResponse est la réponse de GET /api/v1/stats/paths
+type PathStatsResponse struct {
+	Period PeriodInfo `json:"period"`
+	Paths  []PathStat `json:"paths" doc:"Triés par durée décroissante"`
+}
+
+// handleV1BrowserEvent enregistre un signal ou un lot de signaux de
+// l'extension navigateur
+func (s *Server) handleV1BrowserEvent(w http.ResponseWriter, r *http.Request) {
+	events, err := decodeBrowserEvents(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "JSON invalide: "+err.Error())
+		return
+	}
+	signals, err := browserSignals(events, time.Now())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	visits, err := s.recordBrowserSignals(signals)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, BrowserEventResponse{Status: "received", Received: len(signals), Visits: visits})
+}
+
+// decodeBrowserEvents lit un signal seul ou un tableau de signaux
+func decodeBrowserEvents(body io.Reader) ([]BrowserEvent, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(body).Decode(&raw); err != nil {
+		return nil, err
+	}
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '[' {
+		var events []BrowserEvent
+		err := json.Unmarshal(trimmed, &events)
+		return events, err
+	}
+	var event BrowserEvent
+	if err := json.Unmarshal(raw, &event); err != nil {
+		return nil, err
+	}
+	return []BrowserEvent{event}, nil
+}
+
+// browserSignals valide les signaux reçus et les trie par heure ; now
+// remplace les heures absentes
+func browserSignals(events []BrowserEvent, now time.Time) ([]storage.BrowserSignal, error) {
+	signals := make([]storage.BrowserSignal, 0, len(events))
+	for i, e := range events {
+		signal := storage.BrowserSignal{
+			Type:     e.Type,
+			URL:      e.URL,
+			TabTitle: e.TabTitle,
+			Browser:  e.BrowserName,
+			Time:     now,
+			Duration: time.Duration(e.Duration * float64(time.Second)),
+		}
+		if signal.Type == "" {
+			signal.Type = storage.BrowserHeartbeat
+		}
+		if !slices.Contains([]string{storage.BrowserFocus, storage.BrowserHeartbeat, storage.BrowserBlur}, signal.Type) {
+			return nil, fmt.Errorf("événement %d: type inconnu %q (focus, heartbeat ou blur)", i, e.Type)
+		}
+		if signal.URL == "" && signal.Type != storage.BrowserBlur {
+			return nil, fmt.Errorf("événement %d: url requise", i)
+		}
+		if e.Duration < 0 {
+			return nil, fmt.Errorf("événement %d: duration négative", i)
+		}
+		if e.Timestamp != "" {
+			t, err := time.Parse(time.RFC3339Nano, e.Timestamp)
+			if err != nil {
+				return nil, fmt.Errorf("événement %d: timestamp invalide (RFC 3339 attendu): %s", i, e.Timestamp)
+			}
+			signal.Time = t.Local()
+		}
+		signals = append(signals, signal)
+	}
+	sort.SliceStable(signals, func(i, j int) bool { return signals[i].Time.Before(signals[j].Time) })
+	return signals, nil
+}
+
+// recordBrowserSignals enregistre des signaux de l'extension puis corrige
+// l'enrichissement des activités de navigateur déjà enregistrées qu'ils
+// couvrent ; retourne le nombre de nouvelles visites
+func (s *Server) recordBrowserSignals(signals []storage.BrowserSignal) (int, error) {
+	if len(signals) == 0 {
+		return 0, nil
+	}
+	for i := range signals {
+		signals[i].Protect(tracker.Protect)
+	}
+	visits, err := s.db.RecordBrowserSignals(signals)
+	if err != nil {
+		return 0, err
+	}
+
+	// Un signal peut terminer la visite précédente, jusqu'à
+	// BrowserEventTimeout plus tôt
+	start := signals[0].Time.Add(-storage.BrowserEventTimeout)
+	end := signals[0].Time
+	for _, signal := range signals {
+		if signalEnd := signal.Time.Add(signal.Duration); signalEnd.After(end) {
+			end = signalEnd
+		}
+	}
+	if err := s.reconcileBrowserActivities(start, end.Add(time.Second)); err != nil {
+		log.Printf("⚠️  Erreur rapprochement des onglets: %v", err)
+	}
+	return visits, nil
+}
+
+// reconcileBrowserActivities réenrichit les activités de navigateur de
+// [start, end) avec l'URL réelle de la page affichée, à la place du site
+// deviné d'après le titre de la fenêtre. Les règles de confidentialité
+// s'appliquent à nouveau avec cette URL : le titre protégé est réécrit, et
+// les activités exclues sont supprimées, comme par "privacy scrub".
+func (s *Server) reconcileBrowserActivities(start, end time.Time) error {
+	activities, err := s.db.BrowserURLChanges(start, end)
+	if err != nil {
+		return err
+	}
+
+	var updates []storage.Activity
+	var deleted []int64
+	for _, a := range activities {
+		// Une activité réduite à l'application par la confidentialité
+		// (navigation privée, app_only) ne reprend pas d'URL
+		if a.WindowTitle == "" {
+			continue
+		}
+		after, decision, ignored := tracker.Reprotect(a)
+		if decision.Action == privacy.ActionDrop {
+			deleted = append(deleted, a.ID)
+			continue
+		}
+		if ignored {
+			continue
+		}
+		updates = append(updates, after)
+	}
+	return s.db.ScrubActivities(updates, deleted)
+}
+
+// handleV1DomainStats retourne le temps passé par domaine sur la période
+func (s *Server) handleV1DomainStats(w http.ResponseWriter, r *http.Request) {
+	period, ok := s.v1Period(w, r)
+	if !ok {
+		return
+	}
+	limit, ok := browserStatsLimit(w, r)
+	if !ok {
+		return
+	}
+
+	stats, err := s.db.GetDomainStats(period.Start, period.End, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := DomainStatsResponse{Period: period, Domains: make([]DomainStat, 0, len(stats))}
+	for _, stat := range stats {
+		response.Domains = append(response.Domains, DomainStat{
+			Domain:       stat.Domain,
+			Visits:       stat.Visits,
+			TotalSeconds: stat.Seconds,
+			LastSeen:     stat.LastSeen,
+		})
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleV1PathStats retourne le temps passé par page sur la période
+func (s *Server) handleV1PathStats(w http.ResponseWriter, r *http.Request) {
+	period, ok := s.v1Period(w, r)
+	if !ok {
+		return
+	}
+	limit, ok := browserStatsLimit(w, r)
+	if !ok {
+		return
+	}
+
+	stats, err := s.db.GetPathStats(period.Start, period.End, r.URL.Query().Get("domain"), limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := PathStatsResponse{Period: period, Paths: make([]PathStat, 0, len(stats))}
+	for _, stat := range stats {
+		response.Paths = append(response.Paths, PathStat{
+			Domain:       stat.Domain,
+			Path:         stat.Path,
+			Visits:       stat.Visits,
+			TotalSeconds: stat.Seconds,
+			LastSeen:     stat.LastSeen,
+		})
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// browserStatsLimit lit le paramètre limit (20 par défaut)
+func browserStatsLimit(w http.ResponseWriter, r *http.Request) (int, bool) {
+	limit := 20
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "limit invalide")
+			return 0, false
+		}
+		limit = n
+	}
+	return limit, true
+}
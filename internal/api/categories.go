@@ -0,0 +1,186 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"trackmytime/internal/rules"
+	"trackmytime/internal/storage"
+)
+
+// CategoriesResponse est la réponse de GET /api/v1/categories
+type CategoriesResponse struct {
+	Categories []rules.Category `json:"categories" doc:"Triées par nom"`
+}
+
+// CategoryUpdate est le corps de PUT /api/v1/categories/{name}
+type CategoryUpdate struct {
+	Productivity rules.Productivity `json:"productivity" doc:"productive, neutral ou distracting"`
+}
+
+// CategoryStat est le temps actif d'une catégorie
+type CategoryStat struct {
+	Category     string             `json:"category" doc:"Non classé pour les activités sans catégorie"`
+	Productivity rules.Productivity `json:"productivity"`
+	TotalSeconds int64              `json:"total_seconds"`
+}
+
+// ProductivitySummary est la répartition du temps actif par productivité
+type ProductivitySummary struct {
+	ProductiveSeconds  int64   `json:"productive_seconds"`
+	NeutralSeconds     int64   `json:"neutral_seconds"`
+	DistractingSeconds int64   `json:"distracting_seconds"`
+	Score              float64 `json:"score" doc:"De 0 (distrayant) à 100 (productif), 0 sans activité"`
+}
+
+// CategoryStatsResponse est la réponse de GET /api/v1/stats/categories
+type CategoryStatsResponse struct {
+	Period     PeriodInfo          `json:"period"`
+	Categories []CategoryStat      `json:"categories" doc:"Triées par durée décroissante"`
+	Summary    ProductivitySummary `json:"summary"`
+}
+
+// DayProductivity est la productivité d'une journée
+type DayProductivity struct {
+	Date               string  `json:"date" doc:"YYYY-MM-DD"`
+	ProductiveSeconds  int64   `json:"productive_seconds"`
+	NeutralSeconds     int64   `json:"neutral_seconds"`
+	DistractingSeconds int64   `json:"distracting_seconds"`
+	Score              float64 `json:"score" doc:"De 0 (distrayant) à 100 (productif)"`
+}
+
+// ProductivityResponse est la réponse de GET /api/v1/stats/productivity
+type ProductivityResponse struct {
+	Period  PeriodInfo          `json:"period"`
+	Days    []DayProductivity   `json:"days" doc:"Jours ayant de l'activité, dans l'ordre"`
+	Summary ProductivitySummary `json:"summary"`
+}
+
+// handleV1Categories liste les catégories et leur productivité
+func (s *Server) handleV1Categories(w http.ResponseWriter, r *http.Request) {
+	categories, err := s.db.ListCategories()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if categories == nil {
+		categories = []rules.Category{}
+	}
+	writeJSON(w, http.StatusOK, CategoriesResponse{Categories: categories})
+}
+
+// handleV1SetCategory crée une catégorie ou change sa productivité
+func (s *Server) handleV1SetCategory(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimSpace(r.PathValue("name"))
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "nom de catégorie requis")
+		return
+	}
+
+	var update CategoryUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeError(w, http.StatusBadRequest, "JSON invalide: "+err.Error())
+		return
+	}
+	productivity, err := rules.ParseProductivity(string(update.Productivity))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	category := rules.Category{Name: name, Productivity: productivity}
+	if err := s.db.SetCategory(category); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, category)
+}
+
+// handleV1DeleteCategory supprime une catégorie
+func (s *Server) handleV1DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	if err := s.db.DeleteCategory(r.PathValue("name")); err != nil {
+		if errors.Is(err, storage.ErrCategoryNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleV1CategoryStats retourne le temps actif par catégorie
+func (s *Server) handleV1CategoryStats(w http.ResponseWriter, r *http.Request) {
+	period, ok := s.v1Period(w, r)
+	if !ok {
+		return
+	}
+
+	stats, err := s.db.GetStatsByCategory(period.Start, period.End)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := CategoryStatsResponse{Period: period, Categories: make([]CategoryStat, 0, len(stats))}
+	var total storage.DailyProductivity
+	for _, stat := range stats {
+		name := stat.Category
+		if name == "" {
+			name = rules.Uncategorized
+		}
+		response.Categories = append(response.Categories, CategoryStat{
+			Category:     name,
+			Productivity: stat.Productivity,
+			TotalSeconds: stat.Seconds,
+		})
+		total.Add(stat.Productivity, stat.Seconds)
+	}
+	response.Summary = productivitySummary(total)
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleV1Productivity retourne le score de productivité par jour
+func (s *Server) handleV1Productivity(w http.ResponseWriter, r *http.Request) {
+	period, ok := s.v1Period(w, r)
+	if !ok {
+		return
+	}
+
+	days, err := s.db.GetDailyProductivity(period.Start, period.End)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := ProductivityResponse{Period: period, Days: make([]DayProductivity, 0, len(days))}
+	var total storage.DailyProductivity
+	for _, day := range days {
+		response.Days = append(response.Days, DayProductivity{
+			Date:               day.Date.Format("2006-01-02"),
+			ProductiveSeconds:  day.Productive,
+			NeutralSeconds:     day.Neutral,
+			DistractingSeconds: day.Distracting,
+			Score:              day.Score(),
+		})
+		total.Productive += day.Productive
+		total.Neutral += day.Neutral
+		total.Distracting += day.Distracting
+	}
+	response.Summary = productivitySummary(total)
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// productivitySummary convertit un cumul de productivité en réponse
+func productivitySummary(d storage.DailyProductivity) ProductivitySummary {
+	return ProductivitySummary{
+		ProductiveSeconds:  d.Productive,
+		NeutralSeconds:     d.Neutral,
+		DistractingSeconds: d.Distracting,
+		Score:              d.Score(),
+	}
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"trackmytime/internal/rules"
+)
+
+// suggestionSamples est le nombre de titres non classés analysés pour
+// proposer des règles
+const suggestionSamples = 500
+
+// UnclassifiedTitle est un titre de fenêtre qu'aucune règle n'a nommé
+type UnclassifiedTitle struct {
+	AppName      string `json:"app_name"`
+	EnrichedName string `json:"enriched_name" doc:"Nom de l'application ou Autres"`
+	WindowTitle  string `json:"window_title"`
+	Activities   int    `json:"activities"`
+	TotalSeconds int64  `json:"total_seconds"`
+}
+
+// CoverageResponse est la réponse de GET /api/v1/stats/coverage
+type CoverageResponse struct {
+	Period            PeriodInfo          `json:"period"`
+	TotalSeconds      int64               `json:"total_seconds" doc:"Temps actif"`
+	ClassifiedSeconds int64               `json:"classified_seconds" doc:"Temps nommé par une règle"`
+	FallbackSeconds   int64               `json:"fallback_seconds" doc:"Temps dont le nom enrichi est le nom de l'application"`
+	OtherSeconds      int64               `json:"other_seconds" doc:"Onglets regroupés sous Autres"`
+	Coverage          float64             `json:"coverage" doc:"Part du temps actif nommée par une règle, entre 0 et 1"`
+	Titles            []UnclassifiedTitle `json:"titles" doc:"Titres non classés, triés par durée décroissante"`
+	Suggestions       []rules.Suggestion  `json:"suggestions" doc:"Règles proposées à partir des mots fréquents des titres non classés"`
+}
+
+// handleV1Coverage mesure la part du temps nommée par les règles et
+// propose des règles pour le temps non classé
+func (s *Server) handleV1Coverage(w http.ResponseWriter, r *http.Request) {
+	period, ok := s.v1Period(w, r)
+	if !ok {
+		return
+	}
+	limit := 20
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "limit invalide")
+			return
+		}
+		limit = n
+	}
+
+	coverage, err := s.db.GetCoverage(period.Start, period.End)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	titles, err := s.db.GetUnclassifiedTitles(period.Start, period.End, max(limit, suggestionSamples))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := CoverageResponse{
+		Period:            period,
+		TotalSeconds:      coverage.TotalSeconds,
+		ClassifiedSeconds: coverage.TotalSeconds - coverage.UnclassifiedSeconds(),
+		FallbackSeconds:   coverage.FallbackSeconds,
+		OtherSeconds:      coverage.OtherSeconds,
+		Titles:            []UnclassifiedTitle{},
+		Suggestions:       []rules.Suggestion{},
+	}
+	if coverage.TotalSeconds > 0 {
+		response.Coverage = float64(response.ClassifiedSeconds) / float64(coverage.TotalSeconds)
+	}
+
+	samples := make([]rules.TitleSample, 0, len(titles))
+	for i, t := range titles {
+		samples = append(samples, rules.TitleSample{AppName: t.AppName, WindowTitle: t.WindowTitle, Seconds: t.Seconds})
+		if i < limit {
+			response.Titles = append(response.Titles, UnclassifiedTitle{
+				AppName:      t.AppName,
+				EnrichedName: t.EnrichedName,
+				WindowTitle:  t.WindowTitle,
+				Activities:   t.Activities,
+				TotalSeconds: t.Seconds,
+			})
+		}
+	}
+	response.Suggestions = append(response.Suggestions, rules.Suggest(samples, limit)...)
+
+	writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// LanguageStat est le temps actif passé sur les fichiers d'un langage
+type LanguageStat struct {
+	Language     string `json:"language" doc:"Vide pour les fichiers de langage inconnu"`
+	Files        int    `json:"files" doc:"Fichiers distincts"`
+	Activities   int    `json:"activities"`
+	TotalSeconds int64  `json:"total_seconds"`
+}
+
+// LanguageStatsResponse est la réponse de GET /api/v1/stats/languages
+type LanguageStatsResponse struct {
+	Period       PeriodInfo     `json:"period"`
+	Languages    []LanguageStat `json:"languages" doc:"Triés par durée décroissante"`
+	TotalSeconds int64          `json:"total_seconds" doc:"Temps actif passé sur des fichiers dans un éditeur"`
+}
+
+// FileStat est le temps actif passé sur un fichier dans un éditeur
+type FileStat struct {
+	FileName     string    `json:"file_name"`
+	Extension    string    `json:"extension,omitempty"`
+	Language     string    `json:"language,omitempty"`
+	Project      string    `json:"project,omitempty"`
+	Activities   int       `json:"activities"`
+	TotalSeconds int64     `json:"total_seconds"`
+	LastSeen     time.Time `json:"last_seen,omitzero"`
+}
+
+// FileStatsResponse est la réponse de GET /api/v1/stats/files
+type FileStatsResponse struct {
+	Period PeriodInfo `json:"period"`
+	Files  []FileStat `json:"files" doc:"Triés par durée décroissante ; un même nom compte séparément dans chaque projet"`
+}
+
+// handleV1LanguageStats retourne le temps passé par langage sur la période
+func (s *Server) handleV1LanguageStats(w http.ResponseWriter, r *http.Request) {
+	period, ok := s.v1Period(w, r)
+	if !ok {
+		return
+	}
+
+	stats, err := s.db.GetStatsByLanguage(period.Start, period.End, r.URL.Query().Get("project"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := LanguageStatsResponse{Period: period, Languages: make([]LanguageStat, 0, len(stats))}
+	for _, stat := range stats {
+		response.Languages = append(response.Languages, LanguageStat{
+			Language:     stat.Language,
+			Files:        stat.Files,
+			Activities:   stat.Activities,
+			TotalSeconds: stat.Seconds,
+		})
+		response.TotalSeconds += stat.Seconds
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleV1FileStats retourne les fichiers les plus travaillés de la période
+func (s *Server) handleV1FileStats(w http.ResponseWriter, r *http.Request) {
+	period, ok := s.v1Period(w, r)
+	if !ok {
+		return
+	}
+	limit := 20
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "limit invalide")
+			return
+		}
+		limit = n
+	}
+
+	stats, err := s.db.GetTopFiles(period.Start, period.End, r.URL.Query().Get("project"), limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := FileStatsResponse{Period: period, Files: make([]FileStat, 0, len(stats))}
+	for _, stat := range stats {
+		response.Files = append(response.Files, FileStat{
+			FileName:     stat.FileName,
+			Extension:    stat.Extension,
+			Language:     stat.Language,
+			Project:      stat.Project,
+			Activities:   stat.Activities,
+			TotalSeconds: stat.Seconds,
+			LastSeen:     stat.LastSeen,
+		})
+	}
+	writeJSON(w, http.StatusOK, response)
+}
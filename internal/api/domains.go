@@ -0,0 +1,185 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"trackmytime/internal/domains"
+	"trackmytime/internal/storage"
+)
+
+// SiteStat est le temps actif passé sur un site ou un de ses détails
+type SiteStat struct {
+	Name         string    `json:"name" doc:"Site (google.com, github.com/golang), hôte ou page selon la liste"`
+	Domain       string    `json:"domain" doc:"Domaine enregistrable, d'après la liste des suffixes publics"`
+	Hosts        []string  `json:"hosts,omitempty" doc:"Hôtes visités, sans www."`
+	Activities   int       `json:"activities"`
+	TotalSeconds int64     `json:"total_seconds"`
+	TotalHours   float64   `json:"total_hours"`
+	LastSeen     time.Time `json:"last_seen,omitzero"`
+}
+
+// SiteStatsResponse est la réponse de GET /api/v1/stats/sites
+type SiteStatsResponse struct {
+	Period PeriodInfo `json:"period"`
+	Sites  []SiteStat `json:"sites" doc:"Triés par durée décroissante"`
+}
+
+// SiteDetailResponse est la réponse de GET /api/v1/stats/site
+type SiteDetailResponse struct {
+	Period PeriodInfo `json:"period"`
+	Site   SiteStat   `json:"site"`
+	Hosts  []SiteStat `json:"hosts" doc:"Par hôte (docs.google.com, mail.google.com)"`
+	Sites  []SiteStat `json:"sites" doc:"Par site, quand une règle regroupe les chemins (github.com/golang)"`
+	Pages  []SiteStat `json:"pages" doc:"Par page (hôte et chemin, sans paramètres), limitées à limit"`
+}
+
+// SiteRulesResponse est la réponse des routes /api/v1/sites/rules
+type SiteRulesResponse struct {
+	Rules []domains.SiteRule `json:"rules" doc:"Domaines dont les sites sont distingués par les premiers segments du chemin"`
+}
+
+// handleV1SiteStats retourne le temps actif par site sur la période
+func (s *Server) handleV1SiteStats(w http.ResponseWriter, r *http.Request) {
+	period, ok := s.v1Period(w, r)
+	if !ok {
+		return
+	}
+	limit, ok := browserStatsLimit(w, r)
+	if !ok {
+		return
+	}
+	grouper, ok := s.siteGrouper(w)
+	if !ok {
+		return
+	}
+
+	stats, err := s.db.GetStatsBySite(period.Start, period.End, grouper, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, SiteStatsResponse{Period: period, Sites: siteStats(stats, 0)})
+}
+
+// handleV1SiteDetail détaille le temps passé sur un site ou un domaine
+func (s *Server) handleV1SiteDetail(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "name requis")
+		return
+	}
+	period, ok := s.v1Period(w, r)
+	if !ok {
+		return
+	}
+	limit, ok := browserStatsLimit(w, r)
+	if !ok {
+		return
+	}
+	grouper, ok := s.siteGrouper(w)
+	if !ok {
+		return
+	}
+
+	report, err := s.db.GetSiteReport(period.Start, period.End, grouper, name)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if report.Total.Activities == 0 {
+		writeError(w, http.StatusNotFound, "aucune visite de "+name+" sur la période")
+		return
+	}
+	writeJSON(w, http.StatusOK, SiteDetailResponse{
+		Period: period,
+		Site:   siteStat(report.Total),
+		Hosts:  siteStats(report.Hosts, 0),
+		Sites:  siteStats(report.Sites, 0),
+		Pages:  siteStats(report.Pages, limit),
+	})
+}
+
+// siteGrouper compile les règles de regroupement enregistrées
+func (s *Server) siteGrouper(w http.ResponseWriter) (*domains.Grouper, bool) {
+	rules, err := s.db.SiteRules()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	grouper, err := domains.Compile(rules)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	return grouper, true
+}
+
+func siteStat(stat storage.SiteStat) SiteStat {
+	return SiteStat{
+		Name:         stat.Site,
+		Domain:       stat.Domain,
+		Hosts:        stat.Hosts,
+		Activities:   stat.Activities,
+		TotalSeconds: stat.Seconds,
+		TotalHours:   float64(stat.Seconds) / 3600,
+		LastSeen:     stat.LastSeen,
+	}
+}
+
+// siteStats convertit au plus limit statistiques (toutes si limit vaut 0)
+func siteStats(stats []storage.SiteStat, limit int) []SiteStat {
+	if limit > 0 && len(stats) > limit {
+		stats = stats[:limit]
+	}
+	out := make([]SiteStat, 0, len(stats))
+	for _, stat := range stats {
+		out = append(out, siteStat(stat))
+	}
+	return out
+}
+
+// handleV1SiteRules retourne les règles de regroupement des sites
+func (s *Server) handleV1SiteRules(w http.ResponseWriter, r *http.Request) {
+	s.writeSiteRules(w)
+}
+
+// handleV1SetSiteRules remplace les règles de regroupement des sites
+func (s *Server) handleV1SetSiteRules(w http.ResponseWriter, r *http.Request) {
+	var in SiteRulesResponse
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, http.StatusBadRequest, "JSON invalide: "+err.Error())
+		return
+	}
+	if in.Rules == nil {
+		in.Rules = []domains.SiteRule{}
+	}
+	if _, err := domains.Compile(in.Rules); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.db.SetSiteRules(in.Rules); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.writeSiteRules(w)
+}
+
+// handleV1ResetSiteRules revient aux règles de regroupement par défaut
+func (s *Server) handleV1ResetSiteRules(w http.ResponseWriter, r *http.Request) {
+	if err := s.db.SetSiteRules(nil); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.writeSiteRules(w)
+}
+
+func (s *Server) writeSiteRules(w http.ResponseWriter) {
+	rules, err := s.db.SiteRules()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, SiteRulesResponse{Rules: rules})
+}
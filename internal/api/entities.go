@@ -0,0 +1,201 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"trackmytime/internal/entities"
+	"trackmytime/internal/storage"
+	"trackmytime/internal/tracker"
+)
+
+// EntityStat est le temps actif passé sur un ticket, un dépôt, une PR ou une MR
+type EntityStat struct {
+	Kind         entities.Kind `json:"kind" doc:"ticket, repo, pr ou mr"`
+	Value        string        `json:"value" doc:"Forme normalisée, ex. PROJ-1234, acme/web, acme/web#567"`
+	Activities   int           `json:"activities"`
+	TotalSeconds int64         `json:"total_seconds"`
+	FirstSeen    time.Time     `json:"first_seen,omitzero"`
+	LastSeen     time.Time     `json:"last_seen,omitzero"`
+}
+
+// EntityStatsResponse est la réponse de GET /api/v1/stats/entities
+type EntityStatsResponse struct {
+	Period   PeriodInfo   `json:"period"`
+	Entities []EntityStat `json:"entities" doc:"Triées par durée décroissante ; une activité compte pour chacune de ses entités"`
+}
+
+// EntityReportResponse est la réponse de GET /api/v1/stats/entity
+type EntityReportResponse struct {
+	Entity EntityStat   `json:"entity" doc:"Totaux sur tout l'historique"`
+	Days   []DaySeconds `json:"days" doc:"Jours où l'entité apparaît, dans l'ordre"`
+	Apps   []AppGroup   `json:"apps" doc:"Temps par application puis par nom enrichi"`
+}
+
+// EntityPatternsResponse est la réponse des routes /api/v1/entities/patterns
+type EntityPatternsResponse struct {
+	Patterns []entities.Pattern `json:"patterns" doc:"Appliqués dans l'ordre à chaque nouvelle activité"`
+}
+
+// handleV1EntityStats retourne le temps passé par entité sur la période
+func (s *Server) handleV1EntityStats(w http.ResponseWriter, r *http.Request) {
+	period, ok := s.v1Period(w, r)
+	if !ok {
+		return
+	}
+	kind, ok := entityKind(w, r, false)
+	if !ok {
+		return
+	}
+	limit := 50
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "limit invalide")
+			return
+		}
+		limit = n
+	}
+
+	stats, err := s.db.GetStatsByEntity(period.Start, period.End, kind, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := EntityStatsResponse{Period: period, Entities: make([]EntityStat, 0, len(stats))}
+	for _, stat := range stats {
+		response.Entities = append(response.Entities, entityStat(stat))
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleV1EntityReport détaille le temps passé sur une entité, par jour et
+// par application, sur tout l'historique
+func (s *Server) handleV1EntityReport(w http.ResponseWriter, r *http.Request) {
+	kind, ok := entityKind(w, r, true)
+	if !ok {
+		return
+	}
+	value := r.URL.Query().Get("value")
+	if value == "" {
+		writeError(w, http.StatusBadRequest, "value est requis")
+		return
+	}
+
+	stat, err := s.db.GetEntityStat(kind, value)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if stat.Activities == 0 {
+		writeError(w, http.StatusNotFound, "entité introuvable")
+		return
+	}
+	daily, err := s.db.GetEntityDailyStats(kind, value)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	grouped, err := s.db.GetEntityGroupedStats(kind, value)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := EntityReportResponse{
+		Entity: entityStat(stat),
+		Days:   make([]DaySeconds, 0, len(daily)),
+		Apps:   buildAppGroups(grouped),
+	}
+	for _, d := range daily {
+		response.Days = append(response.Days, DaySeconds{Date: d.Date.Format("2006-01-02"), Seconds: d.Seconds})
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleV1EntityPatterns retourne les motifs d'extraction d'entités
+func (s *Server) handleV1EntityPatterns(w http.ResponseWriter, r *http.Request) {
+	s.writeEntityPatterns(w)
+}
+
+// handleV1SetEntityPatterns remplace les motifs d'extraction d'entités
+func (s *Server) handleV1SetEntityPatterns(w http.ResponseWriter, r *http.Request) {
+	var in EntityPatternsResponse
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, http.StatusBadRequest, "JSON invalide: "+err.Error())
+		return
+	}
+	if in.Patterns == nil {
+		in.Patterns = []entities.Pattern{}
+	}
+	if err := tracker.SetEntityPatterns(in.Patterns); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.db.SetEntityPatterns(in.Patterns); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.writeEntityPatterns(w)
+}
+
+// handleV1ResetEntityPatterns revient aux motifs d'extraction par défaut
+func (s *Server) handleV1ResetEntityPatterns(w http.ResponseWriter, r *http.Request) {
+	if err := s.db.SetEntityPatterns(nil); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err := tracker.SetEntityPatterns(entities.Defaults()); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.writeEntityPatterns(w)
+}
+
+func (s *Server) writeEntityPatterns(w http.ResponseWriter) {
+	patterns, err := s.db.EntityPatterns()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, EntityPatternsResponse{Patterns: patterns})
+}
+
+// entityKind lit le paramètre kind ; écrit l'erreur et retourne false s'il
+// est invalide, ou absent alors qu'il est requis
+func entityKind(w http.ResponseWriter, r *http.Request, required bool) (entities.Kind, bool) {
+	kind := entities.Kind(r.URL.Query().Get("kind"))
+	if kind == "" && !required {
+		return "", true
+	}
+	for _, k := range entities.Kinds {
+		if kind == k {
+			return kind, true
+		}
+	}
+	writeError(w, http.StatusBadRequest, "kind invalide (ticket, repo, pr, mr)")
+	return "", false
+}
+
+func entityStat(s storage.EntityStat) EntityStat {
+	return EntityStat{
+		Kind:         s.Kind,
+		Value:        s.Value,
+		Activities:   s.Activities,
+		TotalSeconds: s.Seconds,
+		FirstSeen:    s.FirstSeen,
+		LastSeen:     s.LastSeen,
+	}
+}
+
+// entityKinds liste les types d'entités pour la spec OpenAPI
+func entityKinds() []string {
+	kinds := make([]string, 0, len(entities.Kinds))
+	for _, k := range entities.Kinds {
+		kinds = append(kinds, string(k))
+	}
+	return kinds
+}
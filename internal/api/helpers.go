@@ -1,18 +1,19 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
-
-	"trackmytime/internal/storage"
 )
 
 // parseCustomPeriod parses custom start and end dates from query parameters
 func (s *Server) parseCustomPeriod(r *http.Request) (start, end time.Time, err error) {
-	startStr := r.URL.Query().Get("start")
-	endStr := r.URL.Query().Get("end")
+	return parseCustomDates(r.URL.Query().Get("start"), r.URL.Query().Get("end"))
+}
 
+// parseCustomDates parses YYYY-MM-DD start and end dates, end date included
+func parseCustomDates(startStr, endStr string) (start, end time.Time, err error) {
 	if startStr == "" || endStr == "" {
 		return time.Time{}, time.Time{}, fmt.Errorf("start and end dates required")
 	}
@@ -40,6 +41,12 @@ func (s *Server) parseCustomPeriod(r *http.Request) (start, end time.Time, err e
 
 // getPeriodBounds calculates start and end time for a given period
 func (s *Server) getPeriodBounds(period string, r *http.Request) (start, end time.Time, err error) {
+	return periodBounds(period, r.URL.Query().Get("start"), r.URL.Query().Get("end"))
+}
+
+// periodBounds calculates start and end time for a given period; startStr
+// and endStr are only used by the custom period
+func periodBounds(period, startStr, endStr string) (start, end time.Time, err error) {
 	now := time.Now()
 
 	switch period {
@@ -60,7 +67,7 @@ func (s *Server) getPeriodBounds(period string, r *http.Request) (start, end tim
 		end = start.AddDate(0, 1, 0)
 
 	case "custom":
-		return s.parseCustomPeriod(r)
+		return parseCustomDates(startStr, endStr)
 
 	default:
 		return time.Time{}, time.Time{}, fmt.Errorf("invalid period (today, week, month, custom)")
@@ -69,13 +76,55 @@ func (s *Server) getPeriodBounds(period string, r *http.Request) (start, end tim
 	return start, end, nil
 }
 
-// calculateIdleTime calculates total idle seconds from activities
-func calculateIdleTime(activities []storage.Activity) int64 {
-	var totalIdleSeconds int64
-	for _, activity := range activities {
-		if activity.IsIdle {
-			totalIdleSeconds += activity.DurationSecs
-		}
+// ErrorResponse est l'enveloppe JSON de toutes les erreurs de l'API
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// ErrorBody décrit une erreur de l'API
+type ErrorBody struct {
+	Status  int    `json:"status" doc:"Code HTTP"`
+	Code    string `json:"code" doc:"Identifiant stable de l'erreur (bad_request, unauthorized, ...)"`
+	Message string `json:"message" doc:"Message lisible"`
+}
+
+// errorCodes associe un identifiant stable à chaque code HTTP d'erreur
+var errorCodes = map[int]string{
+	http.StatusBadRequest:          "bad_request",
+	http.StatusUnauthorized:        "unauthorized",
+	http.StatusForbidden:           "forbidden",
+	http.StatusNotFound:            "not_found",
+	http.StatusMethodNotAllowed:    "method_not_allowed",
+	http.StatusConflict:            "conflict",
+	http.StatusInternalServerError: "internal_error",
+}
+
+// writeJSON écrit v en JSON avec le code HTTP donné
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError écrit une erreur dans l'enveloppe JSON standard
+func writeError(w http.ResponseWriter, status int, message string) {
+	code, ok := errorCodes[status]
+	if !ok {
+		code = "error"
+	}
+	writeJSON(w, status, ErrorResponse{Error: ErrorBody{
+		Status:  status,
+		Code:    code,
+		Message: message,
+	}})
+}
+
+// periodFromRequest lit les paramètres period/start/end (period=today par défaut)
+func (s *Server) periodFromRequest(r *http.Request) (period string, start, end time.Time, err error) {
+	period = r.URL.Query().Get("period")
+	if period == "" {
+		period = "today"
 	}
-	return totalIdleSeconds
+	start, end, err = s.getPeriodBounds(period, r)
+	return period, start, end, err
 }
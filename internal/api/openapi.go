@@ -0,0 +1,294 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// openAPISpec construit la spécification OpenAPI 3 à partir de la table des
+// routes v1 et des structs de réponse (tags json et doc)
+func (s *Server) openAPISpec() map[string]any {
+	schemas := map[string]any{}
+	gen := &schemaGenerator{schemas: schemas, names: map[reflect.Type]string{}}
+
+	errorRef := gen.schemaFor(reflect.TypeOf(ErrorResponse{}))
+	paths := map[string]any{}
+
+	for _, e := range s.v1Endpoints() {
+		op := map[string]any{
+			"summary":     e.Summary,
+			"operationId": operationID(e),
+		}
+
+		params := pathParams(e.Path)
+		if len(e.Params) > 0 {
+			for _, p := range e.Params {
+				paramType := p.Type
+				if paramType == "" {
+					paramType = "string"
+				}
+				schema := map[string]any{"type": paramType}
+				if len(p.Enum) > 0 {
+					schema["enum"] = p.Enum
+				}
+				params = append(params, map[string]any{
+					"name":        p.Name,
+					"in":          "query",
+					"description": p.Description,
+					"required":    p.Required,
+					"schema":      schema,
+				})
+			}
+		}
+		if len(params) > 0 {
+			op["parameters"] = params
+		}
+
+		if e.Request != nil {
+			op["requestBody"] = map[string]any{
+				"required": true,
+				"content": map[string]any{
+					"application/json": map[string]any{
+						"schema": gen.schemaFor(reflect.TypeOf(e.Request)),
+					},
+				},
+			}
+		}
+
+		status := e.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := map[string]any{"description": "Succès"}
+		switch {
+		case status == http.StatusNoContent:
+		case e.Response != nil:
+			success["content"] = map[string]any{
+				"application/json": map[string]any{
+					"schema": gen.schemaFor(reflect.TypeOf(e.Response)),
+				},
+			}
+		case e.ContentType != "":
+			success["content"] = map[string]any{
+				e.ContentType: map[string]any{
+					"schema": map[string]any{"type": "string"},
+				},
+			}
+		default:
+			success["content"] = map[string]any{
+				"application/json": map[string]any{
+					"schema": map[string]any{"type": "object"},
+				},
+			}
+		}
+
+		op["responses"] = map[string]any{
+			strconv.Itoa(status): success,
+			"default": map[string]any{
+				"description": "Erreur",
+				"content": map[string]any{
+					"application/json": map[string]any{"schema": errorRef},
+				},
+			},
+		}
+
+		if e.Scope != "" {
+			op["security"] = []any{map[string]any{"bearerAuth": []string{}}}
+			op["x-scope"] = string(e.Scope)
+		}
+
+		path := APIVersionPrefix + e.Path
+		item, ok := paths[path].(map[string]any)
+		if !ok {
+			item = map[string]any{}
+			paths[path] = item
+		}
+		item[strings.ToLower(e.Method)] = op
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "TrackMyTime API",
+			"version": "1.0.0",
+		},
+		"servers": []any{map[string]any{"url": "/"}},
+		"paths":   paths,
+		"components": map[string]any{
+			"schemas": schemas,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{
+					"type":         "http",
+					"scheme":       "bearer",
+					"bearerFormat": "tmt_<hex>",
+				},
+			},
+		},
+	}
+}
+
+// handleOpenAPI sert la spécification OpenAPI de l'API v1
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.openAPISpec())
+}
+
+// pathParams décrit les segments {nom} du chemin ; {id} est un entier
+func pathParams(path string) []any {
+	var params []any
+	for _, segment := range strings.Split(path, "/") {
+		if !strings.HasPrefix(segment, "{") || !strings.HasSuffix(segment, "}") {
+			continue
+		}
+		name := strings.Trim(segment, "{}")
+		paramType := "string"
+		if name == "id" {
+			paramType = "integer"
+		}
+		params = append(params, map[string]any{
+			"name":     name,
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]any{"type": paramType},
+		})
+	}
+	return params
+}
+
+// operationID dérive un identifiant d'opération stable depuis la méthode et le chemin
+func operationID(e endpoint) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(e.Method))
+	for _, part := range strings.FieldsFunc(e.Path, func(r rune) bool {
+		return r == '/' || r == '.' || r == '_' || r == '{' || r == '}'
+	}) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// schemaGenerator convertit des types Go en schémas JSON, en enregistrant
+// les structs nommées dans components/schemas
+type schemaGenerator struct {
+	schemas map[string]any
+	names   map[reflect.Type]string // nom de schéma de chaque struct rencontrée
+}
+
+// schemaName retourne le nom de schéma d'une struct : son nom Go, préfixé
+// par son paquet si une autre struct porte déjà ce nom (rules.Rule puis
+// privacy.Rule donnent Rule et PrivacyRule)
+func (g *schemaGenerator) schemaName(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	for _, used := range g.names {
+		if used == name {
+			pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+			name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+			break
+		}
+	}
+	g.names[t] = name
+	return name
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaFor retourne le schéma (ou la référence) correspondant à t
+func (g *schemaGenerator) schemaFor(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]any{"type": "integer", "format": "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name := g.schemaName(t)
+		if _, ok := g.schemas[name]; !ok {
+			// Réserver le nom avant de descendre pour supporter les types récursifs
+			g.schemas[name] = map[string]any{}
+			g.schemas[name] = g.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	default:
+		return map[string]any{}
+	}
+}
+
+// structSchema décrit les champs exportés d'une struct selon leurs tags json
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Name
+		optional := false
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			if tag == "-" {
+				continue
+			}
+			parts := strings.Split(tag, ",")
+			if parts[0] != "" {
+				name = parts[0]
+			}
+			for _, opt := range parts[1:] {
+				if opt == "omitempty" || opt == "omitzero" {
+					optional = true
+				}
+			}
+		}
+
+		schema := g.schemaFor(field.Type)
+		if doc := field.Tag.Get("doc"); doc != "" {
+			if _, isRef := schema["$ref"]; isRef {
+				// OpenAPI 3.0 ignore les voisins de $ref : on passe par allOf
+				schema = map[string]any{"allOf": []any{schema}, "description": doc}
+			} else {
+				schema["description"] = doc
+			}
+		}
+
+		properties[name] = schema
+		if !optional {
+			required = append(required, name)
+		}
+	}
+
+	result := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		result["required"] = required
+	}
+	return result
+}
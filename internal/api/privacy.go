@@ -0,0 +1,59 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"trackmytime/internal/privacy"
+	"trackmytime/internal/tracker"
+)
+
+// PrivacyRulesResponse est la réponse des routes /api/v1/privacy/rules
+type PrivacyRulesResponse struct {
+	Rules []privacy.Rule `json:"rules" doc:"Évaluées dans l'ordre avant l'enregistrement de chaque activité ; la première qui correspond s'applique"`
+}
+
+// handleV1PrivacyRules retourne les règles de confidentialité
+func (s *Server) handleV1PrivacyRules(w http.ResponseWriter, r *http.Request) {
+	s.writePrivacyRules(w)
+}
+
+// handleV1SetPrivacyRules remplace les règles de confidentialité
+func (s *Server) handleV1SetPrivacyRules(w http.ResponseWriter, r *http.Request) {
+	var in PrivacyRulesResponse
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, http.StatusBadRequest, "JSON invalide: "+err.Error())
+		return
+	}
+	if err := tracker.SetPrivacyRules(in.Rules); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.db.SetPrivacyRules(in.Rules); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.writePrivacyRules(w)
+}
+
+// handleV1ResetPrivacyRules supprime toutes les règles de confidentialité
+func (s *Server) handleV1ResetPrivacyRules(w http.ResponseWriter, r *http.Request) {
+	if err := s.db.SetPrivacyRules(nil); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err := tracker.SetPrivacyRules(nil); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.writePrivacyRules(w)
+}
+
+func (s *Server) writePrivacyRules(w http.ResponseWriter) {
+	rs, err := s.db.PrivacyRules()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, PrivacyRulesResponse{Rules: rs})
+}
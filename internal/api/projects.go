@@ -0,0 +1,341 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"trackmytime/internal/storage"
+)
+
+// ProjectItem est un projet auquel le temps est attribué
+type ProjectItem struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Client    string    `json:"client,omitempty"`
+	Color     string    `json:"color" doc:"#rrggbb"`
+	Archived  bool      `json:"archived"`
+	CreatedAt time.Time `json:"created_at,omitzero"`
+}
+
+// ProjectInput est le corps de POST /api/v1/projects et PUT /api/v1/projects/{id}
+type ProjectInput struct {
+	Name     string `json:"name"`
+	Client   string `json:"client,omitempty"`
+	Color    string `json:"color,omitempty" doc:"#rrggbb ; choisie automatiquement (création) ou conservée (modification) si vide"`
+	Archived bool   `json:"archived,omitempty"`
+}
+
+// ProjectsResponse est la réponse de GET /api/v1/projects
+type ProjectsResponse struct {
+	Projects []ProjectItem `json:"projects" doc:"Triés par client puis par nom"`
+}
+
+// ProjectStat est le temps actif d'un projet
+type ProjectStat struct {
+	ProjectID    int64  `json:"project_id"`
+	Project      string `json:"project"`
+	Client       string `json:"client,omitempty"`
+	Color        string `json:"color"`
+	Archived     bool   `json:"archived"`
+	TotalSeconds int64  `json:"total_seconds"`
+}
+
+// ClientStat est le temps actif cumulé des projets d'un client
+type ClientStat struct {
+	Client       string `json:"client" doc:"Vide pour les projets sans client"`
+	TotalSeconds int64  `json:"total_seconds"`
+}
+
+// ProjectStatsResponse est la réponse de GET /api/v1/stats/projects
+type ProjectStatsResponse struct {
+	Period            PeriodInfo    `json:"period"`
+	Projects          []ProjectStat `json:"projects" doc:"Triés par durée décroissante"`
+	Clients           []ClientStat  `json:"clients" doc:"Triés par durée décroissante"`
+	UnassignedSeconds int64         `json:"unassigned_seconds" doc:"Temps actif sans projet"`
+	TotalSeconds      int64         `json:"total_seconds"`
+}
+
+// DaySeconds est le temps actif d'une journée
+type DaySeconds struct {
+	Date    string `json:"date" doc:"YYYY-MM-DD"`
+	Seconds int64  `json:"seconds"`
+}
+
+// ProjectReportResponse est la réponse de GET /api/v1/projects/{id}/stats
+type ProjectReportResponse struct {
+	Period       PeriodInfo   `json:"period"`
+	Project      ProjectItem  `json:"project"`
+	TotalSeconds int64        `json:"total_seconds"`
+	Days         []DaySeconds `json:"days" doc:"Jours ayant de l'activité, dans l'ordre"`
+	Apps         []AppGroup   `json:"apps" doc:"Temps par application puis par nom enrichi"`
+}
+
+// UnassignedResponse est la réponse de GET /api/v1/stats/unassigned
+type UnassignedResponse struct {
+	Period       PeriodInfo   `json:"period"`
+	TotalSeconds int64        `json:"total_seconds"`
+	Days         []DaySeconds `json:"days" doc:"Jours ayant de l'activité, dans l'ordre"`
+	Apps         []AppGroup   `json:"apps" doc:"Temps non attribué par application puis par nom enrichi"`
+}
+
+// handleV1Projects liste les projets
+func (s *Server) handleV1Projects(w http.ResponseWriter, r *http.Request) {
+	projects, err := s.db.ListProjects(r.URL.Query().Get("archived") == "true")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	items := make([]ProjectItem, 0, len(projects))
+	for _, p := range projects {
+		items = append(items, projectItem(p))
+	}
+	writeJSON(w, http.StatusOK, ProjectsResponse{Projects: items})
+}
+
+// handleV1Project retourne un projet
+func (s *Server) handleV1Project(w http.ResponseWriter, r *http.Request) {
+	p, ok := s.pathProject(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, projectItem(p))
+}
+
+// handleV1CreateProject crée un projet
+func (s *Server) handleV1CreateProject(w http.ResponseWriter, r *http.Request) {
+	p, ok := decodeProject(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.db.CreateProject(&p); err != nil {
+		writeProjectError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, projectItem(p))
+}
+
+// handleV1UpdateProject modifie un projet (un renommage est reporté sur
+// l'historique et les règles)
+func (s *Server) handleV1UpdateProject(w http.ResponseWriter, r *http.Request) {
+	id, ok := projectID(w, r)
+	if !ok {
+		return
+	}
+	p, ok := decodeProject(w, r)
+	if !ok {
+		return
+	}
+
+	p.ID = id
+	if err := s.db.UpdateProject(p); err != nil {
+		writeProjectError(w, err)
+		return
+	}
+	s.reloadRules()
+
+	updated, err := s.db.GetProject(id)
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, projectItem(updated))
+}
+
+// handleV1DeleteProject supprime un projet ; son temps redevient non attribué
+func (s *Server) handleV1DeleteProject(w http.ResponseWriter, r *http.Request) {
+	id, ok := projectID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.db.DeleteProject(id); err != nil {
+		writeProjectError(w, err)
+		return
+	}
+	s.reloadRules()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleV1ProjectStats retourne le temps d'un projet sur la période
+func (s *Server) handleV1ProjectStats(w http.ResponseWriter, r *http.Request) {
+	p, ok := s.pathProject(w, r)
+	if !ok {
+		return
+	}
+	period, ok := s.v1Period(w, r)
+	if !ok {
+		return
+	}
+
+	days, apps, total, err := s.projectBreakdown(p.Name, period)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ProjectReportResponse{
+		Period:       period,
+		Project:      projectItem(p),
+		TotalSeconds: total,
+		Days:         days,
+		Apps:         apps,
+	})
+}
+
+// handleV1UnassignedStats retourne le temps qui n'est attribué à aucun projet
+func (s *Server) handleV1UnassignedStats(w http.ResponseWriter, r *http.Request) {
+	period, ok := s.v1Period(w, r)
+	if !ok {
+		return
+	}
+
+	days, apps, total, err := s.projectBreakdown("", period)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, UnassignedResponse{
+		Period:       period,
+		TotalSeconds: total,
+		Days:         days,
+		Apps:         apps,
+	})
+}
+
+// handleV1StatsByProject retourne le temps par projet et par client
+func (s *Server) handleV1StatsByProject(w http.ResponseWriter, r *http.Request) {
+	period, ok := s.v1Period(w, r)
+	if !ok {
+		return
+	}
+
+	stats, err := s.db.GetStatsByProject(period.Start, period.End)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := ProjectStatsResponse{Period: period, Projects: []ProjectStat{}, Clients: []ClientStat{}}
+	clients := map[string]int64{}
+	for _, stat := range stats {
+		response.TotalSeconds += stat.Seconds
+		if stat.ProjectID == 0 {
+			response.UnassignedSeconds += stat.Seconds
+			continue
+		}
+		response.Projects = append(response.Projects, ProjectStat{
+			ProjectID:    stat.ProjectID,
+			Project:      stat.Project,
+			Client:       stat.Client,
+			Color:        stat.Color,
+			Archived:     stat.Archived,
+			TotalSeconds: stat.Seconds,
+		})
+		clients[stat.Client] += stat.Seconds
+	}
+	for client, seconds := range clients {
+		response.Clients = append(response.Clients, ClientStat{Client: client, TotalSeconds: seconds})
+	}
+	sort.Slice(response.Clients, func(i, j int) bool {
+		return response.Clients[i].TotalSeconds > response.Clients[j].TotalSeconds
+	})
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// projectBreakdown détaille le temps d'un projet (non attribué si project
+// est vide) par jour et par application
+func (s *Server) projectBreakdown(project string, period PeriodInfo) ([]DaySeconds, []AppGroup, int64, error) {
+	daily, err := s.db.GetProjectDailyStats(project, period.Start, period.End)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	grouped, err := s.db.GetProjectGroupedStats(project, period.Start, period.End)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	var total int64
+	days := make([]DaySeconds, 0, len(daily))
+	for _, d := range daily {
+		days = append(days, DaySeconds{Date: d.Date.Format("2006-01-02"), Seconds: d.Seconds})
+		total += d.Seconds
+	}
+	return days, buildAppGroups(grouped), total, nil
+}
+
+// pathProject charge le projet désigné par le chemin ; écrit l'erreur et retourne false sinon
+func (s *Server) pathProject(w http.ResponseWriter, r *http.Request) (storage.Project, bool) {
+	id, ok := projectID(w, r)
+	if !ok {
+		return storage.Project{}, false
+	}
+	p, err := s.db.GetProject(id)
+	if err != nil {
+		writeProjectError(w, err)
+		return storage.Project{}, false
+	}
+	return p, true
+}
+
+// projectID lit l'identifiant de projet du chemin ; écrit l'erreur et retourne false si invalide
+func projectID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, "identifiant de projet invalide")
+		return 0, false
+	}
+	return id, true
+}
+
+// decodeProject lit et valide le projet du corps de la requête
+func decodeProject(w http.ResponseWriter, r *http.Request) (storage.Project, bool) {
+	var in ProjectInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, http.StatusBadRequest, "JSON invalide: "+err.Error())
+		return storage.Project{}, false
+	}
+
+	p := storage.Project{
+		Name:     strings.TrimSpace(in.Name),
+		Client:   strings.TrimSpace(in.Client),
+		Color:    in.Color,
+		Archived: in.Archived,
+	}
+	if err := storage.ValidateProject(p); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return storage.Project{}, false
+	}
+	return p, true
+}
+
+// writeProjectError traduit les erreurs de stockage des projets en réponse HTTP
+func writeProjectError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, storage.ErrProjectNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, storage.ErrProjectExists):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func projectItem(p storage.Project) ProjectItem {
+	return ProjectItem{
+		ID:        p.ID,
+		Name:      p.Name,
+		Client:    p.Client,
+		Color:     p.Color,
+		Archived:  p.Archived,
+		CreatedAt: p.CreatedAt,
+	}
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RepoStat est le temps actif passé dans un dépôt git
+type RepoStat struct {
+	Repo         string    `json:"repo" doc:"Dépôt distant (github.com/acme/api), sinon racine locale"`
+	Root         string    `json:"root" doc:"Dernière racine locale utilisée"`
+	Branches     int       `json:"branches" doc:"Branches distinctes"`
+	Activities   int       `json:"activities"`
+	TotalSeconds int64     `json:"total_seconds"`
+	LastSeen     time.Time `json:"last_seen,omitzero"`
+}
+
+// RepoStatsResponse est la réponse de GET /api/v1/stats/repos
+type RepoStatsResponse struct {
+	Period PeriodInfo `json:"period"`
+	Repos  []RepoStat `json:"repos" doc:"Triés par durée décroissante"`
+}
+
+// BranchStat est le temps actif passé sur une branche d'un dépôt git
+type BranchStat struct {
+	Repo         string    `json:"repo"`
+	Branch       string    `json:"branch" doc:"Commit abrégé si HEAD était détaché"`
+	Activities   int       `json:"activities"`
+	TotalSeconds int64     `json:"total_seconds"`
+	LastSeen     time.Time `json:"last_seen,omitzero"`
+}
+
+// BranchStatsResponse est la réponse de GET /api/v1/stats/branches
+type BranchStatsResponse struct {
+	Period   PeriodInfo   `json:"period"`
+	Branches []BranchStat `json:"branches" doc:"Triées par durée décroissante"`
+}
+
+// handleV1RepoStats retourne le temps passé par dépôt git sur la période
+func (s *Server) handleV1RepoStats(w http.ResponseWriter, r *http.Request) {
+	period, ok := s.v1Period(w, r)
+	if !ok {
+		return
+	}
+	limit := 20
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "limit invalide")
+			return
+		}
+		limit = n
+	}
+
+	stats, err := s.db.GetRepoStats(period.Start, period.End, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := RepoStatsResponse{Period: period, Repos: make([]RepoStat, 0, len(stats))}
+	for _, stat := range stats {
+		response.Repos = append(response.Repos, RepoStat{
+			Repo:         stat.Repo,
+			Root:         stat.Root,
+			Branches:     stat.Branches,
+			Activities:   stat.Activities,
+			TotalSeconds: stat.Seconds,
+			LastSeen:     stat.LastSeen,
+		})
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleV1BranchStats retourne le temps passé par branche sur la période
+func (s *Server) handleV1BranchStats(w http.ResponseWriter, r *http.Request) {
+	period, ok := s.v1Period(w, r)
+	if !ok {
+		return
+	}
+	limit := 20
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "limit invalide")
+			return
+		}
+		limit = n
+	}
+
+	stats, err := s.db.GetBranchStats(period.Start, period.End, r.URL.Query().Get("repo"), limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := BranchStatsResponse{Period: period, Branches: make([]BranchStat, 0, len(stats))}
+	for _, stat := range stats {
+		response.Branches = append(response.Branches, BranchStat{
+			Repo:         stat.Repo,
+			Branch:       stat.Branch,
+			Activities:   stat.Activities,
+			TotalSeconds: stat.Seconds,
+			LastSeen:     stat.LastSeen,
+		})
+	}
+	writeJSON(w, http.StatusOK, response)
+}
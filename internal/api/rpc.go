@@ -0,0 +1,281 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"trackmytime/internal/auth"
+	"trackmytime/internal/storage"
+	trackmytimev1 "trackmytime/rpc/trackmytime/v1"
+	"trackmytime/rpc/trackmytime/v1/trackmytimev1connect"
+)
+
+// rpcService implémente TrackMyTimeService (gRPC, gRPC-Web et Connect) en
+// s'appuyant sur les mêmes calculs que l'API REST v1
+type rpcService struct {
+	s *Server
+}
+
+// registerRPC monte le service RPC sur mux. Les clients gRPC utilisent HTTP/2
+// en clair (h2c), activé sur le serveur HTTP dans NewServer.
+func (s *Server) registerRPC(mux *http.ServeMux) {
+	path, handler := trackmytimev1connect.NewTrackMyTimeServiceHandler(
+		&rpcService{s: s},
+		connect.WithInterceptors(&rpcAuthInterceptor{s: s}),
+	)
+	mux.Handle(path, handler)
+}
+
+// GetCurrentActivity retourne l'activité en cours
+func (r *rpcService) GetCurrentActivity(ctx context.Context, req *connect.Request[trackmytimev1.GetCurrentActivityRequest]) (*connect.Response[trackmytimev1.GetCurrentActivityResponse], error) {
+	return connect.NewResponse(&trackmytimev1.GetCurrentActivityResponse{
+		Activity: currentActivityToProto(r.s.currentActivity()),
+	}), nil
+}
+
+// GetStats retourne le temps actif par application
+func (r *rpcService) GetStats(ctx context.Context, req *connect.Request[trackmytimev1.GetStatsRequest]) (*connect.Response[trackmytimev1.GetStatsResponse], error) {
+	period, err := periodFromProto(req.Msg.GetPeriod())
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := r.s.buildStats(period, "")
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	apps := make([]*trackmytimev1.AppStat, 0, len(stats.Apps))
+	for _, app := range stats.Apps {
+		apps = append(apps, &trackmytimev1.AppStat{AppName: app.AppName, TotalSeconds: app.TotalSeconds})
+	}
+
+	return connect.NewResponse(&trackmytimev1.GetStatsResponse{
+		Period:             periodInfoToProto(period),
+		TotalActivities:    int32(stats.TotalActivities),
+		TotalActiveSeconds: stats.TotalActiveSeconds,
+		TotalIdleSeconds:   stats.TotalIdleSeconds,
+		Apps:               apps,
+	}), nil
+}
+
+// GetGroupedStats retourne le temps par application puis par nom enrichi
+func (r *rpcService) GetGroupedStats(ctx context.Context, req *connect.Request[trackmytimev1.GetGroupedStatsRequest]) (*connect.Response[trackmytimev1.GetGroupedStatsResponse], error) {
+	period, err := periodFromProto(req.Msg.GetPeriod())
+	if err != nil {
+		return nil, err
+	}
+
+	grouped, err := r.s.db.GetGroupedStats(period.Start, period.End)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	var groups []*trackmytimev1.AppGroup
+	for _, group := range buildAppGroups(grouped) {
+		children := make([]*trackmytimev1.EnrichedStat, 0, len(group.Children))
+		for _, child := range group.Children {
+			children = append(children, &trackmytimev1.EnrichedStat{Name: child.Name, TotalSeconds: child.Duration})
+		}
+		groups = append(groups, &trackmytimev1.AppGroup{
+			AppName:      group.AppName,
+			TotalSeconds: group.TotalSeconds,
+			Children:     children,
+		})
+	}
+
+	return connect.NewResponse(&trackmytimev1.GetGroupedStatsResponse{
+		Period: periodInfoToProto(period),
+		Groups: groups,
+	}), nil
+}
+
+// GetTimeline retourne le temps actif par heure ou par jour
+func (r *rpcService) GetTimeline(ctx context.Context, req *connect.Request[trackmytimev1.GetTimelineRequest]) (*connect.Response[trackmytimev1.GetTimelineResponse], error) {
+	period, err := periodFromProto(req.Msg.GetPeriod())
+	if err != nil {
+		return nil, err
+	}
+
+	timeline, err := r.s.buildTimelineResponse(period)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	granularity := trackmytimev1.Granularity_GRANULARITY_DAY
+	if timeline.Granularity == "hour" {
+		granularity = trackmytimev1.Granularity_GRANULARITY_HOUR
+	}
+
+	return connect.NewResponse(&trackmytimev1.GetTimelineResponse{
+		Period:      periodInfoToProto(period),
+		Granularity: granularity,
+		Labels:      timeline.Labels,
+		Seconds:     timeline.Seconds,
+	}), nil
+}
+
+// ListActivities retourne les activités brutes filtrées
+func (r *rpcService) ListActivities(ctx context.Context, req *connect.Request[trackmytimev1.ListActivitiesRequest]) (*connect.Response[trackmytimev1.ListActivitiesResponse], error) {
+	period, err := periodFromProto(req.Msg.GetPeriod())
+	if err != nil {
+		return nil, err
+	}
+	if req.Msg.GetLimit() < 0 {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("limit invalide"))
+	}
+
+	activities, err := r.s.db.QueryActivities(storage.ActivityFilter{
+		Start:        period.Start,
+		End:          period.End,
+		AppName:      req.Msg.GetAppName(),
+		EnrichedName: req.Msg.GetEnrichedName(),
+		ExcludeIdle:  !req.Msg.GetIncludeIdle(),
+		Limit:        int(req.Msg.GetLimit()),
+	})
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	items := make([]*trackmytimev1.Activity, 0, len(activities))
+	for _, a := range activities {
+		items = append(items, &trackmytimev1.Activity{
+			Id:              a.ID,
+			AppName:         a.AppName,
+			EnrichedName:    a.EnrichedName,
+			WindowTitle:     a.WindowTitle,
+			ProcessPath:     a.ProcessPath,
+			StartTime:       timestamppb.New(a.StartTime),
+			EndTime:         timestamppb.New(a.EndTime),
+			DurationSeconds: a.DurationSecs,
+			IsIdle:          a.IsIdle,
+		})
+	}
+
+	return connect.NewResponse(&trackmytimev1.ListActivitiesResponse{
+		Period:     periodInfoToProto(period),
+		Activities: items,
+	}), nil
+}
+
+// StreamActivity envoie l'activité courante puis chacun de ses changements
+func (r *rpcService) StreamActivity(ctx context.Context, req *connect.Request[trackmytimev1.StreamActivityRequest], stream *connect.ServerStream[trackmytimev1.StreamActivityResponse]) error {
+	updates := r.s.tracker.subscribe()
+	defer r.s.tracker.unsubscribe(updates)
+
+	for {
+		err := stream.Send(&trackmytimev1.StreamActivityResponse{
+			Activity: currentActivityToProto(r.s.currentActivity()),
+		})
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-updates:
+		case <-ctx.Done():
+			return nil
+		case <-r.s.streams:
+			return nil
+		}
+	}
+}
+
+// periodFromProto convertit la période demandée en bornes de temps
+func periodFromProto(p *trackmytimev1.Period) (PeriodInfo, error) {
+	name := "today"
+	switch p.GetKind() {
+	case trackmytimev1.PeriodKind_PERIOD_KIND_WEEK:
+		name = "week"
+	case trackmytimev1.PeriodKind_PERIOD_KIND_MONTH:
+		name = "month"
+	case trackmytimev1.PeriodKind_PERIOD_KIND_CUSTOM:
+		name = "custom"
+	}
+
+	start, end, err := periodBounds(name, p.GetStartDate(), p.GetEndDate())
+	if err != nil {
+		return PeriodInfo{}, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	return PeriodInfo{Name: name, Start: start, End: end}, nil
+}
+
+// periodInfoToProto convertit la période effective en message protobuf
+func periodInfoToProto(p PeriodInfo) *trackmytimev1.PeriodInfo {
+	return &trackmytimev1.PeriodInfo{
+		Name:  p.Name,
+		Start: timestamppb.New(p.Start),
+		End:   timestamppb.New(p.End),
+	}
+}
+
+// currentActivityToProto convertit l'activité courante en message protobuf
+func currentActivityToProto(a CurrentActivityResponse) *trackmytimev1.CurrentActivity {
+	activity := &trackmytimev1.CurrentActivity{
+		Active:          a.Active,
+		AppName:         a.AppName,
+		EnrichedName:    a.EnrichedName,
+		WindowTitle:     a.WindowTitle,
+		ProcessPath:     a.ProcessPath,
+		DurationSeconds: a.DurationSeconds,
+	}
+	if a.Active {
+		activity.StartTime = timestamppb.New(a.StartTime)
+	}
+	return activity
+}
+
+// rpcAuthInterceptor vérifie le jeton Bearer et retourne les erreurs sous
+// forme de codes gRPC (Unauthenticated, PermissionDenied)
+type rpcAuthInterceptor struct {
+	s *Server
+}
+
+// authorize vérifie que le jeton de la requête possède le scope read
+func (i *rpcAuthInterceptor) authorize(header http.Header) error {
+	if i.s.tokens == nil {
+		return nil
+	}
+
+	value := ""
+	scheme, token, found := strings.Cut(header.Get("Authorization"), " ")
+	if found && strings.EqualFold(scheme, "Bearer") {
+		value = strings.TrimSpace(token)
+	}
+
+	t := i.s.tokens.Lookup(value)
+	if t == nil {
+		return connect.NewError(connect.CodeUnauthenticated, errors.New("jeton d'accès manquant ou invalide"))
+	}
+	if !t.Allows(auth.ScopeRead) {
+		return connect.NewError(connect.CodePermissionDenied, errors.New("jeton sans le droit read"))
+	}
+	return nil
+}
+
+func (i *rpcAuthInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if err := i.authorize(req.Header()); err != nil {
+			return nil, err
+		}
+		return next(ctx, req)
+	}
+}
+
+func (i *rpcAuthInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (i *rpcAuthInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		if err := i.authorize(conn.RequestHeader()); err != nil {
+			return err
+		}
+		return next(ctx, conn)
+	}
+}
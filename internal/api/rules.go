@@ -0,0 +1,251 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"trackmytime/internal/rules"
+	"trackmytime/internal/storage"
+	"trackmytime/internal/tracker"
+)
+
+// RulesResponse est la réponse de GET /api/v1/rules
+type RulesResponse struct {
+	Rules []rules.Rule `json:"rules" doc:"Dans l'ordre d'évaluation"`
+}
+
+// handleV1Rules liste les règles d'enrichissement
+func (s *Server) handleV1Rules(w http.ResponseWriter, r *http.Request) {
+	rs, err := s.db.ListRules()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if rs == nil {
+		rs = []rules.Rule{}
+	}
+	writeJSON(w, http.StatusOK, RulesResponse{Rules: rs})
+}
+
+// handleV1Rule retourne une règle
+func (s *Server) handleV1Rule(w http.ResponseWriter, r *http.Request) {
+	id, ok := ruleID(w, r)
+	if !ok {
+		return
+	}
+
+	rule, err := s.db.GetRule(id)
+	if err != nil {
+		writeRuleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, rule)
+}
+
+// handleV1CreateRule ajoute une règle (en dernière position par défaut)
+func (s *Server) handleV1CreateRule(w http.ResponseWriter, r *http.Request) {
+	rule, ok := decodeRule(w, r)
+	if !ok {
+		return
+	}
+
+	rule.ID = 0
+	if err := s.db.CreateRule(&rule); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.reloadRules()
+	writeJSON(w, http.StatusCreated, rule)
+}
+
+// handleV1UpdateRule remplace une règle
+func (s *Server) handleV1UpdateRule(w http.ResponseWriter, r *http.Request) {
+	id, ok := ruleID(w, r)
+	if !ok {
+		return
+	}
+	rule, ok := decodeRule(w, r)
+	if !ok {
+		return
+	}
+
+	// Sans position, la règle garde sa place
+	if rule.Position <= 0 {
+		existing, err := s.db.GetRule(id)
+		if err != nil {
+			writeRuleError(w, err)
+			return
+		}
+		rule.Position = existing.Position
+	}
+
+	rule.ID = id
+	if err := s.db.UpdateRule(rule); err != nil {
+		writeRuleError(w, err)
+		return
+	}
+	s.reloadRules()
+	writeJSON(w, http.StatusOK, rule)
+}
+
+// handleV1DeleteRule supprime une règle
+func (s *Server) handleV1DeleteRule(w http.ResponseWriter, r *http.Request) {
+	id, ok := ruleID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.db.DeleteRule(id); err != nil {
+		writeRuleError(w, err)
+		return
+	}
+	s.reloadRules()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleV1ResetRules remplace toutes les règles par les règles par défaut
+func (s *Server) handleV1ResetRules(w http.ResponseWriter, r *http.Request) {
+	rs := rules.Defaults()
+	if err := s.db.ReplaceRules(rs); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.reloadRules()
+	writeJSON(w, http.StatusOK, RulesResponse{Rules: rs})
+}
+
+// RuleTestInput est le corps de POST /api/v1/rules/test
+type RuleTestInput struct {
+	AppName     string       `json:"app_name"`
+	WindowTitle string       `json:"window_title,omitempty"`
+	ProcessPath string       `json:"process_path,omitempty"`
+	URL         string       `json:"url,omitempty"`
+	Command     string       `json:"command,omitempty" doc:"Commande au premier plan d'un terminal"`
+	WorkingDir  string       `json:"cwd,omitempty"`
+	SSHHost     string       `json:"ssh_host,omitempty"`
+	Rules       []rules.Rule `json:"rules,omitempty" doc:"Règles à essayer, dans l'ordre donné, à la place des règles enregistrées"`
+}
+
+// FiredRule est une règle qui a modifié le résultat
+type FiredRule struct {
+	ID       int64  `json:"id" doc:"0 pour une règle non enregistrée"`
+	Name     string `json:"name"`
+	Position int    `json:"position"`
+}
+
+// RuleTestResponse est la réponse de POST /api/v1/rules/test
+type RuleTestResponse struct {
+	Result       rules.Result `json:"result"`
+	Fired        []FiredRule  `json:"fired" doc:"Règles appliquées, dans l'ordre d'évaluation"`
+	Unclassified bool         `json:"unclassified" doc:"Aucune règle n'a nommé l'activité (nom de l'application ou Autres)"`
+}
+
+// handleV1TestRules évalue les règles sur une fenêtre fictive
+func (s *Server) handleV1TestRules(w http.ResponseWriter, r *http.Request) {
+	var in RuleTestInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, http.StatusBadRequest, "JSON invalide: "+err.Error())
+		return
+	}
+	if in.AppName == "" {
+		writeError(w, http.StatusBadRequest, "app_name est requis")
+		return
+	}
+
+	rs := in.Rules
+	if rs == nil {
+		var err error
+		if rs, err = s.db.ListRules(); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	for _, rule := range rs {
+		if err := rules.Validate(rule); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	window := tracker.WindowInfo{
+		AppName:     in.AppName,
+		WindowTitle: in.WindowTitle,
+		ProcessPath: in.ProcessPath,
+		URL:         in.URL,
+		Command:     in.Command,
+		WorkingDir:  in.WorkingDir,
+		SSHHost:     in.SSHHost,
+	}
+	result, err := window.EnrichWith(rs)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Les règles appliquées sont retrouvées par identifiant, ou par nom pour
+	// les règles non enregistrées
+	response := RuleTestResponse{
+		Result:       result,
+		Fired:        []FiredRule{},
+		Unclassified: result.EnrichedName == in.AppName || result.EnrichedName == rules.OtherSites,
+	}
+	for i, name := range result.Matched {
+		id := result.MatchedIDs[i]
+		fired := FiredRule{ID: id, Name: name}
+		for _, rule := range rs {
+			if rule.Enabled && ((id != 0 && rule.ID == id) || (id == 0 && rule.Name == name)) {
+				fired.Position = rule.Position
+				break
+			}
+		}
+		response.Fired = append(response.Fired, fired)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// reloadRules applique immédiatement les règles stockées au tracking
+func (s *Server) reloadRules() {
+	rs, err := s.db.ListRules()
+	if err == nil {
+		err = tracker.SetRules(rs)
+	}
+	if err != nil {
+		log.Printf("⚠️  Erreur rechargement des règles: %v", err)
+	}
+}
+
+// ruleID lit l'identifiant de règle du chemin ; écrit l'erreur et retourne false si invalide
+func ruleID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, "identifiant de règle invalide")
+		return 0, false
+	}
+	return id, true
+}
+
+// decodeRule lit et valide la règle du corps de la requête
+func decodeRule(w http.ResponseWriter, r *http.Request) (rules.Rule, bool) {
+	var rule rules.Rule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		writeError(w, http.StatusBadRequest, "JSON invalide: "+err.Error())
+		return rules.Rule{}, false
+	}
+	if err := rules.Validate(rule); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return rules.Rule{}, false
+	}
+	return rule, true
+}
+
+// writeRuleError traduit les erreurs de stockage des règles en réponse HTTP
+func writeRuleError(w http.ResponseWriter, err error) {
+	if errors.Is(err, storage.ErrRuleNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err.Error())
+}
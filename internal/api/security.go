@@ -0,0 +1,155 @@
+package api
+
+import (
+	"encoding/base64"
+	"net"
+	"net/http"
+	"strings"
+
+	"trackmytime/internal/auth"
+)
+
+// SetTokens active l'authentification par jeton Bearer.
+// Doit être appelé avant Start.
+func (s *Server) SetTokens(tokens *auth.TokenStore) {
+	s.tokens = tokens
+}
+
+// SetAllowedOrigins définit les origines (ex: chrome-extension://<id>)
+// autorisées à appeler l'API en cross-origin. Doit être appelé avant Start.
+func (s *Server) SetAllowedOrigins(origins []string) {
+	s.allowedOrigins = origins
+}
+
+// requireScope protège un handler par un jeton possédant le scope demandé
+func (s *Server) requireScope(scope auth.Scope, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.tokens == nil {
+			next(w, r)
+			return
+		}
+
+		token := s.tokens.Lookup(requestToken(r))
+		if token == nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="trackmytime"`)
+			writeError(w, http.StatusUnauthorized, "jeton d'accès manquant ou invalide")
+			return
+		}
+		if !token.Allows(scope) {
+			writeError(w, http.StatusForbidden, "jeton sans le droit "+string(scope))
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// requestToken extrait le jeton de l'en-tête Authorization, ou du paramètre
+// ?token= pour les liens de téléchargement du dashboard. Les plugins WakaTime
+// envoient leur clé en Basic (base64 de la clé) ou dans ?api_key=.
+func requestToken(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); header != "" {
+		scheme, value, found := strings.Cut(header, " ")
+		if !found {
+			return ""
+		}
+		switch {
+		case strings.EqualFold(scheme, "Bearer"):
+			return strings.TrimSpace(value)
+		case strings.EqualFold(scheme, "Basic"):
+			decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+			if err != nil {
+				return ""
+			}
+			// "clé" ou "utilisateur:clé" selon les clients
+			credentials := string(decoded)
+			if user, password, ok := strings.Cut(credentials, ":"); ok {
+				if password != "" {
+					return password
+				}
+				return user
+			}
+			return credentials
+		}
+		return ""
+	}
+	if key := r.URL.Query().Get("api_key"); key != "" {
+		return key
+	}
+	return r.URL.Query().Get("token")
+}
+
+// withSecurity applique la vérification du Host et la politique CORS
+func (s *Server) withSecurity(next http.Handler) http.Handler {
+	return s.secure(isLoopbackAddr(s.httpServer.Addr), "GET, POST", next)
+}
+
+// secure applique la vérification du Host (si loopbackOnly) et la politique
+// CORS ; methods sont les méthodes annoncées aux requêtes preflight
+func (s *Server) secure(loopbackOnly bool, methods string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Protection contre le DNS rebinding : un serveur local n'accepte que
+		// les noms d'hôte locaux
+		if loopbackOnly && !isLoopbackHost(r.Host) {
+			writeError(w, http.StatusForbidden, "hôte non autorisé")
+			return
+		}
+
+		origin := r.Header.Get("Origin")
+		if origin == "" || origin == "http://"+r.Host {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !s.isAllowedOrigin(origin) {
+			writeError(w, http.StatusForbidden, "origine non autorisée")
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Add("Vary", "Origin")
+
+		// Requête preflight
+		if r.Method == http.MethodOptions {
+			w.Header().Set("Access-Control-Allow-Methods", methods)
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+			w.Header().Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isAllowedOrigin vérifie que origin figure exactement dans la liste autorisée
+func (s *Server) isAllowedOrigin(origin string) bool {
+	for _, allowed := range s.allowedOrigins {
+		if origin == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// isLoopbackAddr indique si l'adresse d'écoute n'est joignable qu'en local
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	return isLoopbackHost(host)
+}
+
+// isLoopbackHost indique si host (avec ou sans port) désigne la machine locale
+func isLoopbackHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
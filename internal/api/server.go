@@ -1,71 +1,163 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"sort"
+	"sync"
 	"time"
 
+	"trackmytime/internal/auth"
 	"trackmytime/internal/storage"
 	"trackmytime/internal/tracker"
 )
 
 // Server représente le serveur HTTP de l'API
 type Server struct {
-	db      *storage.DB
-	port    string
-	tracker *ActivityTracker
+	db             *storage.DB
+	tracker        *ActivityTracker
+	assets         *assetServer
+	tokens         *auth.TokenStore
+	allowedOrigins []string
+	httpServer     *http.Server
+	awServer       *http.Server  // API ActivityWatch, nil si désactivée
+	streams        chan struct{} // fermé à l'arrêt du serveur
+	closeStreams   sync.Once     // Shutdown peut être appelé plusieurs fois
 }
 
 // ActivityTracker contient l'état actuel du tracking
 type ActivityTracker struct {
 	CurrentWindow *tracker.WindowInfo
 	StartTime     time.Time
+
+	mu          sync.RWMutex
+	subscribers map[chan struct{}]struct{}
+}
+
+// Current retourne la fenêtre en cours (nil si aucune) et son heure de début
+func (t *ActivityTracker) Current() (*tracker.WindowInfo, time.Time) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.CurrentWindow, t.StartTime
 }
 
-// NewServer crée un nouveau serveur API
-func NewServer(db *storage.DB, port string) *Server {
-	return &Server{
+// NewServer crée un nouveau serveur API écoutant sur addr (host:port)
+func NewServer(db *storage.DB, addr string) *Server {
+	s := &Server{
 		db:      db,
-		port:    port,
-		tracker: &ActivityTracker{},
-	}
+		tracker: &ActivityTracker{subscribers: make(map[chan struct{}]struct{})},
+		assets:  newEmbeddedAssets(),
+		streams: make(chan struct{}),
+	}
+	// HTTP/2 en clair (h2c) pour les clients gRPC, en plus de HTTP/1.1
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+	s.httpServer = &http.Server{Addr: addr, Protocols: protocols}
+	s.httpServer.Handler = s.withSecurity(s.routes())
+	// Les flux SSE ne deviennent jamais inactifs : on les ferme explicitement
+	// pour que Shutdown puisse drainer les connexions
+	s.httpServer.RegisterOnShutdown(func() {
+		s.closeStreams.Do(func() { close(s.streams) })
+	})
+	return s
+}
+
+// UseWebDir sert le dashboard depuis dir au lieu des fichiers embarqués.
+// Doit être appelé avant Start.
+func (s *Server) UseWebDir(dir string) {
+	s.assets = newDiskAssets(dir)
 }
 
 // SetCurrentActivity met à jour l'activité courante
+// et notifie les clients abonnés au flux d'activité. window vaut nil quand
+// l'utilisateur est inactif.
 func (s *Server) SetCurrentActivity(window *tracker.WindowInfo, startTime time.Time) {
+	s.tracker.mu.Lock()
 	s.tracker.CurrentWindow = window
 	s.tracker.StartTime = startTime
+	s.tracker.mu.Unlock()
+
+	s.tracker.notify()
 }
 
-// Start démarre le serveur HTTP
-func (s *Server) Start() error {
+// routes construit le routeur HTTP de l'API
+func (s *Server) routes() http.Handler {
 	mux := http.NewServeMux()
 
 	// Web UI routes
 	mux.HandleFunc("/", s.handleWebUI)
-	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("web/static"))))
-
-	// API routes
-	mux.HandleFunc("/stats/today", s.handleStatsToday)
-	mux.HandleFunc("/stats/week", s.handleStatsWeek)
-	mux.HandleFunc("/stats/month", s.handleStatsMonth)
-	mux.HandleFunc("/stats/custom", s.handleStatsCustom)
-	mux.HandleFunc("/export/csv", s.handleExportCSV)
-	mux.HandleFunc("/activity/current", s.handleCurrentActivity)
-	mux.HandleFunc("/browser/event", s.handleBrowserEvent)
+	mux.HandleFunc("/static/", s.handleStatic)
+
+	// API v1
+	s.registerV1(mux)
+
+	// API RPC (gRPC, gRPC-Web, Connect) : /trackmytime.v1.TrackMyTimeService/
+	s.registerRPC(mux)
+
+	// Anciennes routes, conservées comme alias dépréciés de /api/v1
+	mux.HandleFunc("/stats/today", s.requireScope(auth.ScopeRead, deprecated("/stats?period=today", s.handleStatsToday)))
+	mux.HandleFunc("/stats/week", s.requireScope(auth.ScopeRead, deprecated("/stats?period=week", s.handleStatsWeek)))
+	mux.HandleFunc("/stats/month", s.requireScope(auth.ScopeRead, deprecated("/stats?period=month", s.handleStatsMonth)))
+	mux.HandleFunc("/stats/custom", s.requireScope(auth.ScopeRead, deprecated("/stats?period=custom", s.handleStatsCustom)))
+	mux.HandleFunc("/export/csv", s.requireScope(auth.ScopeRead, deprecated("/export?format=csv", s.handleExportCSV)))
+	mux.HandleFunc("/activity/current", s.requireScope(auth.ScopeRead, deprecated("/activity/current", s.handleCurrentActivity)))
+	mux.HandleFunc("/browser/event", s.requireScope(auth.ScopeBrowser, deprecated("/browser/events", s.handleBrowserEvent)))
 	mux.HandleFunc("/health", s.handleHealth)
-	mux.HandleFunc("/export/aggregated", s.handleExportAggregated)
-	mux.HandleFunc("/api/stats/hourly", s.handleStatsHourly)
-	mux.HandleFunc("/api/stats/grouped", s.handleStatsGrouped)
+	mux.HandleFunc("/export/aggregated", s.requireScope(auth.ScopeRead, deprecated("/export?aggregated=true", s.handleExportAggregated)))
+	mux.HandleFunc("/api/stats/hourly", s.requireScope(auth.ScopeRead, deprecated("/stats/timeline", s.handleStatsHourly)))
+	mux.HandleFunc("/api/stats/grouped", s.requireScope(auth.ScopeRead, deprecated("/stats/grouped", s.handleStatsGrouped)))
+
+	return mux
+}
+
+// Handler retourne le handler HTTP complet (routes et contrôles de sécurité),
+// par exemple pour le servir via httptest
+func (s *Server) Handler() http.Handler {
+	return s.httpServer.Handler
+}
 
-	addr := fmt.Sprintf(":%s", s.port)
-	log.Printf("API HTTP démarrée sur http://localhost%s", addr)
-	log.Printf("Dashboard web disponible sur http://localhost%s/", addr)
+// Start démarre le serveur HTTP et bloque jusqu'à son arrêt.
+// Retourne nil lorsque le serveur a été arrêté via Shutdown.
+func (s *Server) Start() error {
+	addr := s.httpServer.Addr
+	log.Printf("API HTTP démarrée sur http://%s", addr)
+
+	if s.awServer != nil {
+		go func() {
+			log.Printf("👁️  API ActivityWatch démarrée sur http://%s", s.awServer.Addr)
+			if err := s.awServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Printf("⚠️  Erreur API ActivityWatch: %v", err)
+			}
+		}()
+	}
 
-	return http.ListenAndServe(addr, mux)
+	if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown arrête le serveur HTTP en laissant les requêtes en cours se terminer.
+// Si ctx expire avant la fin du drainage, les connexions restantes sont fermées.
+func (s *Server) Shutdown(ctx context.Context) error {
+	var awErr error
+	if s.awServer != nil {
+		if err := s.awServer.Shutdown(ctx); err != nil {
+			s.awServer.Close()
+			awErr = fmt.Errorf("arrêt de l'API ActivityWatch interrompu: %w", err)
+		}
+	}
+	err := s.httpServer.Shutdown(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		s.httpServer.Close()
+		err = fmt.Errorf("arrêt du serveur API interrompu: %w", err)
+	}
+	return errors.Join(awErr, err)
 }
 
 // handleHealth vérifie l'état du serveur
@@ -79,29 +171,29 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 
 // handleStatsToday retourne les statistiques du jour
 func (s *Server) handleStatsToday(w http.ResponseWriter, r *http.Request) {
-	activities, err := s.db.GetTodayActivities()
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
 	now := time.Now()
 	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 	endOfDay := startOfDay.Add(24 * time.Hour)
 
+	totals, err := s.db.GetActivityTotals(startOfDay, endOfDay, "")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
 	stats, err := s.db.GetStatsByApp(startOfDay, endOfDay)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	response := map[string]any{
 		"date":                 now.Format("2006-01-02"),
-		"total_activities":     len(activities),
+		"total_activities":     totals.Activities,
 		"stats_by_app":         stats,
 		"total_active_seconds": sumStats(stats),
 		"total_active_hours":   float64(sumStats(stats)) / 3600.0,
-		"total_idle_seconds":   calculateIdleTime(activities),
+		"total_idle_seconds":   totals.IdleSeconds,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -110,12 +202,6 @@ func (s *Server) handleStatsToday(w http.ResponseWriter, r *http.Request) {
 
 // handleStatsWeek retourne les statistiques de la semaine
 func (s *Server) handleStatsWeek(w http.ResponseWriter, r *http.Request) {
-	activities, err := s.db.GetWeekActivities()
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
 	now := time.Now()
 	weekday := int(now.Weekday())
 	if weekday == 0 {
@@ -124,20 +210,26 @@ func (s *Server) handleStatsWeek(w http.ResponseWriter, r *http.Request) {
 	startOfWeek := time.Date(now.Year(), now.Month(), now.Day()-weekday+1, 0, 0, 0, 0, now.Location())
 	endOfWeek := startOfWeek.Add(7 * 24 * time.Hour)
 
+	totals, err := s.db.GetActivityTotals(startOfWeek, endOfWeek, "")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
 	stats, err := s.db.GetStatsByApp(startOfWeek, endOfWeek)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	response := map[string]any{
 		"week_start":           startOfWeek.Format("2006-01-02"),
 		"week_end":             endOfWeek.Format("2006-01-02"),
-		"total_activities":     len(activities),
+		"total_activities":     totals.Activities,
 		"stats_by_app":         stats,
 		"total_active_seconds": sumStats(stats),
 		"total_active_hours":   float64(sumStats(stats)) / 3600.0,
-		"total_idle_seconds":   calculateIdleTime(activities),
+		"total_idle_seconds":   totals.IdleSeconds,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -146,30 +238,30 @@ func (s *Server) handleStatsWeek(w http.ResponseWriter, r *http.Request) {
 
 // handleStatsMonth retourne les statistiques du mois
 func (s *Server) handleStatsMonth(w http.ResponseWriter, r *http.Request) {
-	activities, err := s.db.GetMonthActivities()
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
 	now := time.Now()
 	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
 	endOfMonth := startOfMonth.AddDate(0, 1, 0)
 
+	totals, err := s.db.GetActivityTotals(startOfMonth, endOfMonth, "")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
 	stats, err := s.db.GetStatsByApp(startOfMonth, endOfMonth)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	response := map[string]any{
 		"month_start":          startOfMonth.Format("2006-01-02"),
 		"month_end":            endOfMonth.Format("2006-01-02"),
-		"total_activities":     len(activities),
+		"total_activities":     totals.Activities,
 		"stats_by_app":         stats,
 		"total_active_seconds": sumStats(stats),
 		"total_active_hours":   float64(sumStats(stats)) / 3600.0,
-		"total_idle_seconds":   calculateIdleTime(activities),
+		"total_idle_seconds":   totals.IdleSeconds,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -183,31 +275,30 @@ func (s *Server) handleStatsCustom(w http.ResponseWriter, r *http.Request) {
 
 	start, end, err := s.parseCustomPeriod(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	// Récupérer les activités
-	activities, err := s.db.GetActivitiesByDateRange(start, end)
+	totals, err := s.db.GetActivityTotals(start, end, "")
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	stats, err := s.db.GetStatsByApp(start, end)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	response := map[string]any{
 		"start_date":           startStr,
 		"end_date":             endStr,
-		"total_activities":     len(activities),
+		"total_activities":     totals.Activities,
 		"stats_by_app":         stats,
 		"total_active_seconds": sumStats(stats),
 		"total_active_hours":   float64(sumStats(stats)) / 3600.0,
-		"total_idle_seconds":   calculateIdleTime(activities),
+		"total_idle_seconds":   totals.IdleSeconds,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -235,17 +326,17 @@ func (s *Server) handleExportCSV(w http.ResponseWriter, r *http.Request) {
 		var start, end time.Time
 		start, end, err = s.parseCustomPeriod(r)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeError(w, http.StatusBadRequest, err.Error())
 			return
 		}
 		activities, err = s.db.GetActivitiesByDateRange(start, end)
 	default:
-		http.Error(w, "period invalide (today, week, month, custom)", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, "period invalide (today, week, month, custom)")
 		return
 	}
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
@@ -271,7 +362,8 @@ func (s *Server) handleExportCSV(w http.ResponseWriter, r *http.Request) {
 
 // handleCurrentActivity retourne l'activité en cours
 func (s *Server) handleCurrentActivity(w http.ResponseWriter, r *http.Request) {
-	if s.tracker.CurrentWindow == nil {
+	window, startTime := s.tracker.Current()
+	if window == nil {
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]string{
 			"status": "no activity",
@@ -279,13 +371,13 @@ func (s *Server) handleCurrentActivity(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	duration := time.Since(s.tracker.StartTime)
+	duration := time.Since(startTime)
 
 	response := map[string]any{
-		"app_name":         s.tracker.CurrentWindow.AppName,
-		"window_title":     s.tracker.CurrentWindow.WindowTitle,
-		"process_path":     s.tracker.CurrentWindow.ProcessPath,
-		"start_time":       s.tracker.StartTime.Format(time.RFC3339),
+		"app_name":         window.AppName,
+		"window_title":     window.WindowTitle,
+		"process_path":     window.ProcessPath,
+		"start_time":       startTime.Format(time.RFC3339),
 		"current_duration": int64(duration.Seconds()),
 	}
 
@@ -293,30 +385,28 @@ func (s *Server) handleCurrentActivity(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
-// BrowserEvent représente un événement du navigateur
-type BrowserEvent struct {
-	URL         string `json:"url"`
-	TabTitle    string `json:"tab_title"`
-	BrowserName string `json:"browser_name"`
-	Timestamp   string `json:"timestamp"`
-}
-
-// handleBrowserEvent gère les événements du navigateur (pour future extension)
+// handleBrowserEvent enregistre un signal ou un lot de signaux de
+// l'extension navigateur (route historique)
 func (s *Server) handleBrowserEvent(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Méthode non autorisée", http.StatusMethodNotAllowed)
+		writeError(w, http.StatusMethodNotAllowed, "Méthode non autorisée")
 		return
 	}
 
-	var event BrowserEvent
-	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+	events, err := decodeBrowserEvents(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	signals, err := browserSignals(events, time.Now())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, err := s.recordBrowserSignals(signals); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-
-	// Pour l'instant, on log juste l'événement
-	// Dans le futur, on pourra le stocker dans browser_events
-	log.Printf("Browser event received: %s - %s (%s)", event.BrowserName, event.TabTitle, event.URL)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{
@@ -348,13 +438,13 @@ func (s *Server) handleExportAggregated(w http.ResponseWriter, r *http.Request)
 
 	start, end, err := s.getPeriodBounds(period, r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	stats, err := s.db.GetStatsByApp(start, end)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
@@ -457,7 +547,7 @@ func (s *Server) handleExportAggregated(w http.ResponseWriter, r *http.Request)
 
 // handleWebUI sert l'interface web
 func (s *Server) handleWebUI(w http.ResponseWriter, r *http.Request) {
-	http.ServeFile(w, r, "web/index.html")
+	s.assets.serve(w, r, "index.html")
 }
 
 // handleStatsHourly retourne les statistiques par heure
@@ -469,47 +559,14 @@ func (s *Server) handleStatsHourly(w http.ResponseWriter, r *http.Request) {
 
 	start, end, err := s.getPeriodBounds(period, r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	// Pour today, utiliser les stats horaires, sinon les stats journalières
-	var timelineData []int64
-	var labels []string
-
-	// Utiliser les stats horaires pour une seule journée, sinon par jour
-	durationDays := int(end.Sub(start).Hours() / 24)
-
-	if period == "today" || (period == "custom" && durationDays == 1) {
-		timelineData, err = s.db.GetHourlyStats(start, end)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		// Labels pour les heures
-		labels = make([]string, 24)
-		for i := range labels {
-			labels[i] = fmt.Sprintf("%02d:00", i)
-		}
-	} else {
-		timelineData, err = s.db.GetDailyStats(start, end)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		// Labels pour les jours
-		labels = make([]string, len(timelineData))
-		for i := range labels {
-			day := start.AddDate(0, 0, i)
-			if period == "week" {
-				// Pour la semaine, afficher Lun, Mar, Mer, etc.
-				weekdays := []string{"Dim", "Lun", "Mar", "Mer", "Jeu", "Ven", "Sam"}
-				labels[i] = weekdays[day.Weekday()]
-			} else {
-				// Pour le mois, afficher le numéro du jour
-				labels[i] = fmt.Sprintf("%d", day.Day())
-			}
-		}
+	timelineData, labels, err := s.buildTimeline(period, start, end)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
 	}
 
 	response := map[string]any{
@@ -531,29 +588,68 @@ func (s *Server) handleStatsGrouped(w http.ResponseWriter, r *http.Request) {
 
 	start, end, err := s.getPeriodBounds(period, r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	grouped, err := s.db.GetGroupedStats(start, end)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	// Formatter la réponse
-	type EnrichedApp struct {
-		Name     string `json:"name"`
-		Duration int64  `json:"duration"`
+	result := buildAppGroups(grouped)
+
+	response := map[string]any{
+		"period": period,
+		"groups": result,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// buildTimeline retourne la série temporelle de la période : par heure pour
+// une seule journée, par jour sinon
+func (s *Server) buildTimeline(period string, start, end time.Time) ([]int64, []string, error) {
+	durationDays := int(end.Sub(start).Hours() / 24)
+
+	if period == "today" || (period == "custom" && durationDays == 1) {
+		timelineData, err := s.db.GetHourlyStats(start, end)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Labels pour les heures
+		labels := make([]string, 24)
+		for i := range labels {
+			labels[i] = fmt.Sprintf("%02d:00", i)
+		}
+		return timelineData, labels, nil
 	}
 
-	type AppGroup struct {
-		AppName      string        `json:"app_name"`
-		TotalSeconds int64         `json:"total_seconds"`
-		Children     []EnrichedApp `json:"children"`
+	timelineData, err := s.db.GetDailyStats(start, end)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Labels pour les jours
+	labels := make([]string, len(timelineData))
+	for i := range labels {
+		day := start.AddDate(0, 0, i)
+		if period == "week" {
+			// Pour la semaine, afficher Lun, Mar, Mer, etc.
+			weekdays := []string{"Dim", "Lun", "Mar", "Mer", "Jeu", "Ven", "Sam"}
+			labels[i] = weekdays[day.Weekday()]
+		} else {
+			// Pour le mois, afficher le numéro du jour
+			labels[i] = fmt.Sprintf("%d", day.Day())
+		}
 	}
+	return timelineData, labels, nil
+}
 
-	var result []AppGroup
+// buildAppGroups convertit les stats groupées en liste triée par durée décroissante
+func buildAppGroups(grouped map[string]map[string]int64) []AppGroup {
+	result := []AppGroup{}
 
 	for appName, children := range grouped {
 		var totalSeconds int64
@@ -584,11 +680,5 @@ func (s *Server) handleStatsGrouped(w http.ResponseWriter, r *http.Request) {
 		return result[i].TotalSeconds > result[j].TotalSeconds
 	})
 
-	response := map[string]any{
-		"period": period,
-		"groups": result,
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	return result
 }
@@ -0,0 +1,214 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"trackmytime/internal/shell"
+	"trackmytime/internal/storage"
+	"trackmytime/internal/tracker"
+)
+
+// ShellEvent est le corps de POST /api/v1/shell/events, envoyé par les hooks
+// de shell au début et à la fin de chaque commande
+type ShellEvent struct {
+	Event      string    `json:"event" doc:"start ou end"`
+	Session    string    `json:"session" doc:"Identifiant du shell, unique pour chaque shell lancé"`
+	Seq        int64     `json:"seq" doc:"Numéro de la commande dans la session, identique au début et à la fin"`
+	Shell      string    `json:"shell,omitempty" doc:"bash, zsh ou fish"`
+	Command    string    `json:"command,omitempty" doc:"Ligne de commande (start)"`
+	WorkingDir string    `json:"cwd,omitempty" doc:"Dossier courant (start)"`
+	ExitCode   int       `json:"exit_code,omitempty" doc:"Code de sortie (end)"`
+	DurationMs int64     `json:"duration_ms,omitempty" doc:"Durée mesurée par le shell (end) ; à défaut, écart entre les deux événements"`
+	Timestamp  time.Time `json:"timestamp,omitzero" doc:"Heure de l'événement (heure de réception par défaut)"`
+}
+
+// ShellEventResponse est la réponse de POST /api/v1/shell/events
+type ShellEventResponse struct {
+	Status string `json:"status" doc:"recorded"`
+	ID     int64  `json:"id,omitempty" doc:"Identifiant de la commande"`
+}
+
+// ShellCommand est une commande exécutée dans un shell équipé des hooks
+type ShellCommand struct {
+	ID           int64     `json:"id"`
+	Shell        string    `json:"shell"`
+	Session      string    `json:"session"`
+	Command      string    `json:"command"`
+	Program      string    `json:"program" doc:"Programme et sous-commande (go test, make build...)"`
+	WorkingDir   string    `json:"cwd,omitempty"`
+	StartedAt    time.Time `json:"started_at"`
+	EndedAt      time.Time `json:"ended_at,omitzero" doc:"Absent tant que la commande tourne"`
+	DurationMs   int64     `json:"duration_ms"`
+	ExitCode     *int      `json:"exit_code,omitempty" doc:"Absent tant que la commande tourne ou si sa fin a été perdue"`
+	ActivityID   int64     `json:"activity_id,omitempty" doc:"Activité du terminal au démarrage de la commande"`
+	FocusSeconds int64     `json:"focus_seconds" doc:"Temps actif passé dans ce terminal pendant la commande"`
+}
+
+// ShellCommandsResponse est la réponse de GET /api/v1/shell/commands
+type ShellCommandsResponse struct {
+	Period   PeriodInfo     `json:"period"`
+	Commands []ShellCommand `json:"commands" doc:"Les plus récentes d'abord"`
+}
+
+// ShellStat est le temps passé dans les commandes d'un programme
+type ShellStat struct {
+	Program      string    `json:"program"`
+	Runs         int       `json:"runs"`
+	Failures     int       `json:"failures" doc:"Commandes au code de sortie non nul"`
+	TotalSeconds int64     `json:"total_seconds" doc:"Durée cumulée des commandes terminées"`
+	LongestMs    int64     `json:"longest_ms"`
+	LastRun      time.Time `json:"last_run,omitzero"`
+}
+
+// ShellStatsResponse est la réponse de GET /api/v1/stats/commands
+type ShellStatsResponse struct {
+	Period   PeriodInfo  `json:"period"`
+	Programs []ShellStat `json:"programs" doc:"Triés par durée cumulée décroissante"`
+}
+
+// handleV1ShellEvent enregistre le début ou la fin d'une commande de shell
+func (s *Server) handleV1ShellEvent(w http.ResponseWriter, r *http.Request) {
+	var event ShellEvent
+	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+		writeError(w, http.StatusBadRequest, "JSON invalide: "+err.Error())
+		return
+	}
+	if event.Session == "" || event.Seq <= 0 {
+		writeError(w, http.StatusBadRequest, "session et seq sont requis")
+		return
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	switch event.Event {
+	case "start":
+		command := strings.TrimSpace(event.Command)
+		if command == "" {
+			writeError(w, http.StatusBadRequest, "command est requis")
+			return
+		}
+		if event.Shell != "" && !slices.Contains(shell.Shells, event.Shell) {
+			writeError(w, http.StatusBadRequest, "shell invalide ("+strings.Join(shell.Shells, ", ")+")")
+			return
+		}
+		record := storage.ShellCommand{
+			Session:    event.Session,
+			Seq:        event.Seq,
+			Shell:      event.Shell,
+			Command:    command,
+			Program:    shell.Program(command),
+			WorkingDir: event.WorkingDir,
+			StartedAt:  event.Timestamp,
+		}
+		record.Protect(tracker.Protect)
+		id, err := s.db.RecordShellStart(record)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, ShellEventResponse{Status: "recorded", ID: id})
+
+	case "end":
+		duration := time.Duration(event.DurationMs) * time.Millisecond
+		id, err := s.db.RecordShellEnd(event.Session, event.Seq, event.Timestamp, event.ExitCode, duration)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, ShellEventResponse{Status: "recorded", ID: id})
+
+	default:
+		writeError(w, http.StatusBadRequest, "event invalide (start, end)")
+	}
+}
+
+// handleV1ShellCommands retourne les commandes lancées sur la période
+func (s *Server) handleV1ShellCommands(w http.ResponseWriter, r *http.Request) {
+	period, ok := s.v1Period(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	filter := storage.ShellCommandFilter{
+		Start:   period.Start,
+		End:     period.End,
+		Program: query.Get("program"),
+		Failed:  query.Get("failed") == "true",
+		Limit:   100,
+	}
+	if value := query.Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "limit invalide")
+			return
+		}
+		filter.Limit = n
+	}
+
+	commands, err := s.db.ListShellCommands(filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := ShellCommandsResponse{Period: period, Commands: make([]ShellCommand, 0, len(commands))}
+	for _, c := range commands {
+		response.Commands = append(response.Commands, ShellCommand{
+			ID:           c.ID,
+			Shell:        c.Shell,
+			Session:      c.Session,
+			Command:      c.Command,
+			Program:      c.Program,
+			WorkingDir:   c.WorkingDir,
+			StartedAt:    c.StartedAt,
+			EndedAt:      c.EndedAt,
+			DurationMs:   c.Duration.Milliseconds(),
+			ExitCode:     c.ExitCode,
+			ActivityID:   c.ActivityID,
+			FocusSeconds: c.FocusSeconds,
+		})
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleV1ShellStats retourne le temps passé par programme sur la période
+func (s *Server) handleV1ShellStats(w http.ResponseWriter, r *http.Request) {
+	period, ok := s.v1Period(w, r)
+	if !ok {
+		return
+	}
+	limit := 20
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "limit invalide")
+			return
+		}
+		limit = n
+	}
+
+	stats, err := s.db.GetShellStats(period.Start, period.End, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := ShellStatsResponse{Period: period, Programs: make([]ShellStat, 0, len(stats))}
+	for _, stat := range stats {
+		response.Programs = append(response.Programs, ShellStat{
+			Program:      stat.Program,
+			Runs:         stat.Runs,
+			Failures:     stat.Failures,
+			TotalSeconds: int64(stat.Duration.Seconds()),
+			LongestMs:    stat.Longest.Milliseconds(),
+			LastRun:      stat.LastRun,
+		})
+	}
+	writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// streamKeepAlive est l'intervalle des commentaires SSE gardant la connexion ouverte
+const streamKeepAlive = 30 * time.Second
+
+// subscribe enregistre un abonné aux changements d'activité
+func (t *ActivityTracker) subscribe() chan struct{} {
+	ch := make(chan struct{}, 1)
+	t.mu.Lock()
+	t.subscribers[ch] = struct{}{}
+	t.mu.Unlock()
+	return ch
+}
+
+// unsubscribe retire un abonné
+func (t *ActivityTracker) unsubscribe(ch chan struct{}) {
+	t.mu.Lock()
+	delete(t.subscribers, ch)
+	t.mu.Unlock()
+}
+
+// notify prévient les abonnés sans jamais bloquer le tracking : un abonné
+// lent ne reçoit qu'une notification pour plusieurs changements
+func (t *ActivityTracker) notify() {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for ch := range t.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// handleV1ActivityStream envoie l'activité courante puis chacun de ses
+// changements au format Server-Sent Events
+func (s *Server) handleV1ActivityStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming non supporté")
+		return
+	}
+
+	updates := s.tracker.subscribe()
+	defer s.tracker.unsubscribe(updates)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	// Envoyer l'état initial
+	if err := writeEvent(w, "activity", s.currentActivity()); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case <-updates:
+			if err := writeEvent(w, "activity", s.currentActivity()); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		case <-s.streams:
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// writeEvent écrit un événement SSE dont les données sont v encodé en JSON
+func writeEvent(w http.ResponseWriter, event string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	return err
+}
@@ -0,0 +1,218 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"trackmytime/internal/storage"
+)
+
+// TagItem est un tag et son utilisation
+type TagItem struct {
+	Name         string `json:"name"`
+	Activities   int    `json:"activities"`
+	TotalSeconds int64  `json:"total_seconds" doc:"Temps actif tagué, toutes périodes"`
+}
+
+// TagsResponse est la réponse de GET /api/v1/tags
+type TagsResponse struct {
+	Tags []TagItem `json:"tags" doc:"Triés par nom"`
+}
+
+// TagsInput est le corps de POST /api/v1/activities/{id}/tags
+type TagsInput struct {
+	Tags []string `json:"tags" doc:"Le # initial est facultatif ; les tags sont mis en minuscules"`
+}
+
+// ActivityTagsResponse est la réponse des routes /api/v1/activities/{id}/tags
+type ActivityTagsResponse struct {
+	ActivityID int64    `json:"activity_id"`
+	Tags       []string `json:"tags"`
+}
+
+// TagRangeInput est le corps de POST /api/v1/tags/range
+type TagRangeInput struct {
+	Start  time.Time `json:"start" doc:"Début de la plage (RFC 3339)"`
+	End    time.Time `json:"end" doc:"Fin de la plage (RFC 3339, exclue)"`
+	Tags   []string  `json:"tags"`
+	Remove bool      `json:"remove,omitempty" doc:"Retirer les tags au lieu de les poser"`
+}
+
+// TagRangeResponse est la réponse de POST /api/v1/tags/range
+type TagRangeResponse struct {
+	Updated int64 `json:"updated" doc:"Activités taguées, ou tags retirés si remove"`
+}
+
+// TagStat est le temps actif portant un tag
+type TagStat struct {
+	Tag          string `json:"tag"`
+	Activities   int    `json:"activities"`
+	TotalSeconds int64  `json:"total_seconds"`
+}
+
+// TagStatsResponse est la réponse de GET /api/v1/stats/tags
+type TagStatsResponse struct {
+	Period          PeriodInfo `json:"period"`
+	Tags            []TagStat  `json:"tags" doc:"Triés par durée décroissante ; une activité compte pour chacun de ses tags"`
+	UntaggedSeconds int64      `json:"untagged_seconds"`
+}
+
+// handleV1Tags liste les tags
+func (s *Server) handleV1Tags(w http.ResponseWriter, r *http.Request) {
+	tags, err := s.db.ListTags()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	items := make([]TagItem, 0, len(tags))
+	for _, t := range tags {
+		items = append(items, TagItem{Name: t.Name, Activities: t.Activities, TotalSeconds: t.Seconds})
+	}
+	writeJSON(w, http.StatusOK, TagsResponse{Tags: items})
+}
+
+// handleV1DeleteTag retire un tag de toutes les activités
+func (s *Server) handleV1DeleteTag(w http.ResponseWriter, r *http.Request) {
+	if err := s.db.DeleteTag(r.PathValue("name")); err != nil {
+		writeTagError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleV1ActivityTags retourne les tags d'une activité
+func (s *Server) handleV1ActivityTags(w http.ResponseWriter, r *http.Request) {
+	id, ok := activityID(w, r)
+	if !ok {
+		return
+	}
+	s.writeActivityTags(w, id)
+}
+
+// handleV1AddActivityTags pose des tags sur une activité
+func (s *Server) handleV1AddActivityTags(w http.ResponseWriter, r *http.Request) {
+	id, ok := activityID(w, r)
+	if !ok {
+		return
+	}
+
+	var in TagsInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, http.StatusBadRequest, "JSON invalide: "+err.Error())
+		return
+	}
+	if len(in.Tags) == 0 {
+		writeError(w, http.StatusBadRequest, "au moins un tag est requis")
+		return
+	}
+
+	if err := s.db.AddActivityTags(id, in.Tags); err != nil {
+		writeTagError(w, err)
+		return
+	}
+	s.writeActivityTags(w, id)
+}
+
+// handleV1RemoveActivityTag retire un tag d'une activité
+func (s *Server) handleV1RemoveActivityTag(w http.ResponseWriter, r *http.Request) {
+	id, ok := activityID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.db.RemoveActivityTags(id, []string{r.PathValue("tag")}); err != nil {
+		writeTagError(w, err)
+		return
+	}
+	s.writeActivityTags(w, id)
+}
+
+// handleV1TagRange pose ou retire des tags sur une plage horaire
+func (s *Server) handleV1TagRange(w http.ResponseWriter, r *http.Request) {
+	var in TagRangeInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, http.StatusBadRequest, "JSON invalide: "+err.Error())
+		return
+	}
+	if len(in.Tags) == 0 {
+		writeError(w, http.StatusBadRequest, "au moins un tag est requis")
+		return
+	}
+	if in.Start.IsZero() || !in.End.After(in.Start) {
+		writeError(w, http.StatusBadRequest, "plage invalide : start et end requis, end après start")
+		return
+	}
+
+	var updated int64
+	var err error
+	if in.Remove {
+		updated, err = s.db.UntagRange(in.Start, in.End, in.Tags)
+	} else {
+		updated, err = s.db.TagRange(in.Start, in.End, in.Tags)
+	}
+	if err != nil {
+		writeTagError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, TagRangeResponse{Updated: updated})
+}
+
+// handleV1TagStats retourne le temps actif par tag
+func (s *Server) handleV1TagStats(w http.ResponseWriter, r *http.Request) {
+	period, ok := s.v1Period(w, r)
+	if !ok {
+		return
+	}
+
+	stats, untagged, err := s.db.GetStatsByTag(period.Start, period.End)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := TagStatsResponse{Period: period, Tags: make([]TagStat, 0, len(stats)), UntaggedSeconds: untagged}
+	for _, stat := range stats {
+		response.Tags = append(response.Tags, TagStat{
+			Tag:          stat.Tag,
+			Activities:   stat.Activities,
+			TotalSeconds: stat.Seconds,
+		})
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// writeActivityTags répond avec les tags courants d'une activité
+func (s *Server) writeActivityTags(w http.ResponseWriter, id int64) {
+	tags, err := s.db.ActivityTags(id)
+	if err != nil {
+		writeTagError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, ActivityTagsResponse{ActivityID: id, Tags: tags})
+}
+
+// activityID lit l'identifiant d'activité du chemin ; écrit l'erreur et retourne false si invalide
+func activityID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, "identifiant d'activité invalide")
+		return 0, false
+	}
+	return id, true
+}
+
+// writeTagError traduit les erreurs de stockage des tags en réponse HTTP
+func writeTagError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, storage.ErrActivityNotFound), errors.Is(err, storage.ErrTagNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, storage.ErrInvalidTag):
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"trackmytime/internal/storage"
+)
+
+// TerminalStat est le temps actif passé dans les terminaux pour une valeur
+type TerminalStat struct {
+	Value        string    `json:"value" doc:"Commande, hôte ssh ou dossier selon group_by"`
+	Activities   int       `json:"activities"`
+	TotalSeconds int64     `json:"total_seconds"`
+	LastSeen     time.Time `json:"last_seen,omitzero"`
+}
+
+// TerminalStatsResponse est la réponse de GET /api/v1/stats/terminal
+type TerminalStatsResponse struct {
+	Period  PeriodInfo     `json:"period"`
+	GroupBy string         `json:"group_by"`
+	Stats   []TerminalStat `json:"stats" doc:"Triées par durée décroissante"`
+}
+
+// terminalDimensions retourne les valeurs acceptées par group_by
+func terminalDimensions() []string {
+	return []string{string(storage.TerminalByCommand), string(storage.TerminalByHost), string(storage.TerminalByCwd)}
+}
+
+// handleV1TerminalStats retourne le temps passé dans les terminaux sur la
+// période, regroupé par commande, hôte ssh ou dossier courant
+func (s *Server) handleV1TerminalStats(w http.ResponseWriter, r *http.Request) {
+	period, ok := s.v1Period(w, r)
+	if !ok {
+		return
+	}
+	by := storage.TerminalByCommand
+	if value := r.URL.Query().Get("group_by"); value != "" {
+		by = storage.TerminalDimension(value)
+	}
+	switch by {
+	case storage.TerminalByCommand, storage.TerminalByHost, storage.TerminalByCwd:
+	default:
+		writeError(w, http.StatusBadRequest, "group_by invalide (command, ssh_host, cwd)")
+		return
+	}
+	limit := 20
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "limit invalide")
+			return
+		}
+		limit = n
+	}
+
+	stats, err := s.db.GetTerminalStats(period.Start, period.End, by, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := TerminalStatsResponse{Period: period, GroupBy: string(by), Stats: make([]TerminalStat, 0, len(stats))}
+	for _, stat := range stats {
+		response.Stats = append(response.Stats, TerminalStat{
+			Value:        stat.Value,
+			Activities:   stat.Activities,
+			TotalSeconds: stat.Seconds,
+			LastSeen:     stat.LastSeen,
+		})
+	}
+	writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,1141 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"trackmytime/internal/auth"
+	"trackmytime/internal/domains"
+	"trackmytime/internal/export"
+	"trackmytime/internal/rules"
+	"trackmytime/internal/storage"
+)
+
+// APIVersionPrefix est le préfixe de toutes les routes versionnées
+const APIVersionPrefix = "/api/v1"
+
+// PeriodInfo décrit la période couverte par une réponse
+type PeriodInfo struct {
+	Name  string    `json:"name" doc:"today, week, month ou custom"`
+	Start time.Time `json:"start" doc:"Début de la période (inclus)"`
+	End   time.Time `json:"end" doc:"Fin de la période (exclue)"`
+}
+
+// HealthResponse est la réponse de GET /api/v1/health
+type HealthResponse struct {
+	Status string    `json:"status"`
+	Time   time.Time `json:"time"`
+}
+
+// CurrentActivityResponse est la réponse de GET /api/v1/activity/current
+type CurrentActivityResponse struct {
+	Active          bool      `json:"active" doc:"false si aucune activité n'est en cours"`
+	AppName         string    `json:"app_name,omitempty"`
+	EnrichedName    string    `json:"enriched_name,omitempty"`
+	WindowTitle     string    `json:"window_title,omitempty"`
+	ProcessPath     string    `json:"process_path,omitempty"`
+	URL             string    `json:"url,omitempty" doc:"URL de l'onglet actif (navigateurs, via DevTools)"`
+	Domain          string    `json:"domain,omitempty" doc:"Hôte de url sans www."`
+	StartTime       time.Time `json:"start_time,omitzero"`
+	DurationSeconds int64     `json:"duration_seconds"`
+}
+
+// AppStat est le temps passé sur une application
+type AppStat struct {
+	AppName      string `json:"app_name"`
+	TotalSeconds int64  `json:"total_seconds"`
+}
+
+// StatsResponse est la réponse de GET /api/v1/stats
+type StatsResponse struct {
+	Period             PeriodInfo `json:"period"`
+	TotalActivities    int        `json:"total_activities"`
+	TotalActiveSeconds int64      `json:"total_active_seconds"`
+	TotalIdleSeconds   int64      `json:"total_idle_seconds"`
+	Apps               []AppStat  `json:"apps" doc:"Triées par durée décroissante"`
+}
+
+// TimelineResponse est la réponse de GET /api/v1/stats/timeline
+type TimelineResponse struct {
+	Period      PeriodInfo `json:"period"`
+	Granularity string     `json:"granularity" doc:"hour pour une journée, day sinon"`
+	Labels      []string   `json:"labels"`
+	Seconds     []int64    `json:"seconds" doc:"Temps actif par intervalle, aligné sur labels"`
+}
+
+// EnrichedApp est le temps passé sur un nom enrichi (site, projet...)
+type EnrichedApp struct {
+	Name     string `json:"name"`
+	Duration int64  `json:"duration"`
+}
+
+// AppGroup regroupe les noms enrichis d'une application
+type AppGroup struct {
+	AppName      string        `json:"app_name"`
+	TotalSeconds int64         `json:"total_seconds"`
+	Children     []EnrichedApp `json:"children"`
+}
+
+// GroupedStatsResponse est la réponse de GET /api/v1/stats/grouped
+type GroupedStatsResponse struct {
+	Period PeriodInfo `json:"period"`
+	Groups []AppGroup `json:"groups"`
+}
+
+// ActivityItem est une activité brute
+type ActivityItem struct {
+	ID              int64     `json:"id"`
+	AppName         string    `json:"app_name"`
+	EnrichedName    string    `json:"enriched_name"`
+	Category        string    `json:"category,omitempty"`
+	Productivity    string    `json:"productivity,omitempty" doc:"Déduite de la catégorie : productive, neutral ou distracting"`
+	Project         string    `json:"project,omitempty"`
+	Client          string    `json:"client,omitempty" doc:"Client du projet"`
+	Tags            []string  `json:"tags,omitempty"`
+	InferredSite    string    `json:"inferred_site,omitempty" doc:"Site déduit du titre, même s'il a été rejeté"`
+	SiteConfidence  *float64  `json:"site_confidence,omitempty" doc:"Confiance de inferred_site (0 à 1)"`
+	FileName        string    `json:"file_name,omitempty" doc:"Fichier ouvert dans un éditeur"`
+	Language        string    `json:"language,omitempty" doc:"Langage déduit de l'extension du fichier"`
+	Command         string    `json:"command,omitempty" doc:"Commande au premier plan d'un terminal"`
+	WorkingDir      string    `json:"cwd,omitempty" doc:"Dossier courant de la commande"`
+	SSHHost         string    `json:"ssh_host,omitempty" doc:"Hôte distant si la commande est ssh"`
+	Repo            string    `json:"repo,omitempty" doc:"Dépôt git (distant, sinon racine locale)"`
+	RepoRoot        string    `json:"repo_root,omitempty" doc:"Racine locale du dépôt git"`
+	Branch          string    `json:"branch,omitempty" doc:"Branche git courante"`
+	URL             string    `json:"url,omitempty" doc:"URL de l'onglet signalée par l'extension navigateur"`
+	WindowTitle     string    `json:"window_title"`
+	ProcessPath     string    `json:"process_path"`
+	StartTime       time.Time `json:"start_time"`
+	EndTime         time.Time `json:"end_time"`
+	DurationSeconds int64     `json:"duration_seconds"`
+	IsIdle          bool      `json:"is_idle"`
+}
+
+// ActivitiesResponse est la réponse de GET /api/v1/activities
+type ActivitiesResponse struct {
+	Period     PeriodInfo     `json:"period"`
+	Activities []ActivityItem `json:"activities"`
+}
+
+// BrowserEventResponse est la réponse de POST /api/v1/browser/events
+type BrowserEventResponse struct {
+	Status   string `json:"status"`
+	Received int    `json:"received" doc:"Signaux enregistrés"`
+	Visits   int    `json:"visits" doc:"Nouvelles visites de page ; les autres signaux prolongent ou terminent la visite en cours"`
+}
+
+// queryParam décrit un paramètre de requête pour la spec OpenAPI
+type queryParam struct {
+	Name        string
+	Description string
+	Enum        []string
+	Type        string // string (défaut), integer, boolean
+	Required    bool
+}
+
+// endpoint décrit une route /api/v1 : sert à la fois au routage et à la spec OpenAPI
+type endpoint struct {
+	Method      string
+	Path        string
+	Summary     string
+	Scope       auth.Scope // vide = route publique
+	Params      []queryParam
+	Request     any    // type du corps JSON attendu (nil = aucun)
+	Response    any    // type de la réponse JSON
+	ContentType string // type de la réponse si différent de application/json
+	Status      int    // code de succès (200 par défaut)
+	Handler     http.HandlerFunc
+}
+
+// periodParams sont les paramètres communs à toutes les routes par période
+var periodParams = []queryParam{
+	{Name: "period", Description: "Période (today par défaut)", Enum: []string{"today", "week", "month", "custom"}},
+	{Name: "start", Description: "Date de début YYYY-MM-DD (period=custom)"},
+	{Name: "end", Description: "Date de fin incluse YYYY-MM-DD (period=custom)"},
+}
+
+// withTagParam ajoute le filtre par tag aux paramètres donnés
+func withTagParam(params []queryParam) []queryParam {
+	return append(append([]queryParam{}, params...),
+		queryParam{Name: "tag", Description: "Uniquement les activités portant ce tag"})
+}
+
+// v1Endpoints retourne la liste des routes de l'API v1
+func (s *Server) v1Endpoints() []endpoint {
+	return []endpoint{
+		{
+			Method:   http.MethodGet,
+			Path:     "/health",
+			Summary:  "État du serveur",
+			Response: HealthResponse{},
+			Handler:  s.handleV1Health,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/activity/current",
+			Summary:  "Activité en cours",
+			Scope:    auth.ScopeRead,
+			Response: CurrentActivityResponse{},
+			Handler:  s.handleV1CurrentActivity,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/activity/stream",
+			Summary:     "Flux Server-Sent Events des changements d'activité (événement activity)",
+			Scope:       auth.ScopeRead,
+			ContentType: "text/event-stream",
+			Handler:     s.handleV1ActivityStream,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/stats",
+			Summary:  "Temps actif par application",
+			Scope:    auth.ScopeRead,
+			Params:   withTagParam(periodParams),
+			Response: StatsResponse{},
+			Handler:  s.handleV1Stats,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/stats/timeline",
+			Summary:  "Temps actif par heure (une journée) ou par jour",
+			Scope:    auth.ScopeRead,
+			Params:   periodParams,
+			Response: TimelineResponse{},
+			Handler:  s.handleV1Timeline,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/stats/grouped",
+			Summary:  "Temps actif par application puis par nom enrichi",
+			Scope:    auth.ScopeRead,
+			Params:   withTagParam(periodParams),
+			Response: GroupedStatsResponse{},
+			Handler:  s.handleV1Grouped,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/stats/sites",
+			Summary: "Temps actif par site : domaine enregistrable, ou sous-chemin selon les règles de regroupement",
+			Scope:   auth.ScopeRead,
+			Params: append(append([]queryParam{}, periodParams...),
+				queryParam{Name: "limit", Description: "Nombre de sites (20 par défaut)", Type: "integer"}),
+			Response: SiteStatsResponse{},
+			Handler:  s.handleV1SiteStats,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/stats/site",
+			Summary: "Détail d'un site ou d'un domaine par hôte, sous-chemin et page",
+			Scope:   auth.ScopeRead,
+			Params: append([]queryParam{
+				{Name: "name", Description: "Site (github.com/golang) ou domaine enregistrable (google.com)", Required: true},
+				{Name: "limit", Description: "Nombre de pages (20 par défaut)", Type: "integer"},
+			}, periodParams...),
+			Response: SiteDetailResponse{},
+			Handler:  s.handleV1SiteDetail,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/stats/input",
+			Summary:  "Touches, clics et mouvements de souris signalés par aw-watcher-input, par heure",
+			Scope:    auth.ScopeRead,
+			Params:   periodParams,
+			Response: InputStatsResponse{},
+			Handler:  s.handleV1InputStats,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/stats/categories",
+			Summary:  "Temps actif par catégorie et score de productivité",
+			Scope:    auth.ScopeRead,
+			Params:   periodParams,
+			Response: CategoryStatsResponse{},
+			Handler:  s.handleV1CategoryStats,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/stats/productivity",
+			Summary:  "Temps productif, neutre et distrayant par jour",
+			Scope:    auth.ScopeRead,
+			Params:   periodParams,
+			Response: ProductivityResponse{},
+			Handler:  s.handleV1Productivity,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/stats/projects",
+			Summary:  "Temps actif par projet et par client, et temps non attribué",
+			Scope:    auth.ScopeRead,
+			Params:   periodParams,
+			Response: ProjectStatsResponse{},
+			Handler:  s.handleV1StatsByProject,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/stats/unassigned",
+			Summary:  "Temps actif attribué à aucun projet, par jour et par application",
+			Scope:    auth.ScopeRead,
+			Params:   periodParams,
+			Response: UnassignedResponse{},
+			Handler:  s.handleV1UnassignedStats,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/stats/tags",
+			Summary:  "Temps actif par tag et temps sans tag",
+			Scope:    auth.ScopeRead,
+			Params:   periodParams,
+			Response: TagStatsResponse{},
+			Handler:  s.handleV1TagStats,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/stats/coverage",
+			Summary: "Part du temps nommée par les règles, titres non classés et règles suggérées",
+			Scope:   auth.ScopeRead,
+			Params: append(append([]queryParam{}, periodParams...),
+				queryParam{Name: "limit", Description: "Nombre de titres et de suggestions (20 par défaut)", Type: "integer"}),
+			Response: CoverageResponse{},
+			Handler:  s.handleV1Coverage,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/stats/entities",
+			Summary: "Temps actif par ticket, dépôt, pull request et merge request",
+			Scope:   auth.ScopeRead,
+			Params: append(append([]queryParam{}, periodParams...),
+				queryParam{Name: "kind", Description: "Un seul type d'entité", Enum: entityKinds()},
+				queryParam{Name: "limit", Description: "Nombre maximal d'entités (50 par défaut)", Type: "integer"}),
+			Response: EntityStatsResponse{},
+			Handler:  s.handleV1EntityStats,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/stats/entity",
+			Summary: "Temps passé sur une entité par jour et par application, sur tout l'historique",
+			Scope:   auth.ScopeRead,
+			Params: []queryParam{
+				{Name: "kind", Description: "Type de l'entité", Enum: entityKinds(), Required: true},
+				{Name: "value", Description: "Valeur normalisée, ex. PROJ-1234 ou acme/web#567", Required: true},
+			},
+			Response: EntityReportResponse{},
+			Handler:  s.handleV1EntityReport,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/stats/languages",
+			Summary: "Temps passé par langage de programmation dans les éditeurs",
+			Scope:   auth.ScopeRead,
+			Params: append(append([]queryParam{}, periodParams...),
+				queryParam{Name: "project", Description: "Uniquement les fichiers de ce projet"}),
+			Response: LanguageStatsResponse{},
+			Handler:  s.handleV1LanguageStats,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/stats/files",
+			Summary: "Fichiers les plus travaillés dans les éditeurs",
+			Scope:   auth.ScopeRead,
+			Params: append(append([]queryParam{}, periodParams...),
+				queryParam{Name: "project", Description: "Uniquement les fichiers de ce projet"},
+				queryParam{Name: "limit", Description: "Nombre maximal de fichiers (20 par défaut)", Type: "integer"}),
+			Response: FileStatsResponse{},
+			Handler:  s.handleV1FileStats,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/stats/terminal",
+			Summary: "Temps passé dans les terminaux par commande, hôte ssh ou dossier",
+			Scope:   auth.ScopeRead,
+			Params: append(append([]queryParam{}, periodParams...),
+				queryParam{Name: "group_by", Description: "Regroupement (command par défaut)", Enum: terminalDimensions()},
+				queryParam{Name: "limit", Description: "Nombre maximal de valeurs (20 par défaut)", Type: "integer"}),
+			Response: TerminalStatsResponse{},
+			Handler:  s.handleV1TerminalStats,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/stats/repos",
+			Summary: "Temps passé par dépôt git (terminaux et éditeurs)",
+			Scope:   auth.ScopeRead,
+			Params: append(append([]queryParam{}, periodParams...),
+				queryParam{Name: "limit", Description: "Nombre maximal de dépôts (20 par défaut)", Type: "integer"}),
+			Response: RepoStatsResponse{},
+			Handler:  s.handleV1RepoStats,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/stats/branches",
+			Summary: "Temps passé par branche git",
+			Scope:   auth.ScopeRead,
+			Params: append(append([]queryParam{}, periodParams...),
+				queryParam{Name: "repo", Description: "Uniquement les branches de ce dépôt (distant ou racine)"},
+				queryParam{Name: "limit", Description: "Nombre maximal de branches (20 par défaut)", Type: "integer"}),
+			Response: BranchStatsResponse{},
+			Handler:  s.handleV1BranchStats,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/stats/commands",
+			Summary: "Temps passé par programme dans les commandes de shell",
+			Scope:   auth.ScopeRead,
+			Params: append(append([]queryParam{}, periodParams...),
+				queryParam{Name: "limit", Description: "Nombre maximal de programmes (20 par défaut)", Type: "integer"}),
+			Response: ShellStatsResponse{},
+			Handler:  s.handleV1ShellStats,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/stats/domains",
+			Summary: "Temps passé par domaine d'après l'extension navigateur",
+			Scope:   auth.ScopeRead,
+			Params: append(append([]queryParam{}, periodParams...),
+				queryParam{Name: "limit", Description: "Nombre maximal de domaines (20 par défaut)", Type: "integer"}),
+			Response: DomainStatsResponse{},
+			Handler:  s.handleV1DomainStats,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/stats/paths",
+			Summary: "Temps passé par page (domaine et chemin) d'après l'extension navigateur",
+			Scope:   auth.ScopeRead,
+			Params: append(append([]queryParam{}, periodParams...),
+				queryParam{Name: "domain", Description: "Uniquement les pages de ce domaine"},
+				queryParam{Name: "limit", Description: "Nombre maximal de pages (20 par défaut)", Type: "integer"}),
+			Response: PathStatsResponse{},
+			Handler:  s.handleV1PathStats,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/activities",
+			Summary: "Activités brutes, les plus récentes d'abord",
+			Scope:   auth.ScopeRead,
+			Params: append(append([]queryParam{}, periodParams...),
+				queryParam{Name: "app", Description: "Filtrer sur une application"},
+				queryParam{Name: "enriched", Description: "Filtrer sur un nom enrichi"},
+				queryParam{Name: "project", Description: "Filtrer sur un projet (par nom)"},
+				queryParam{Name: "unassigned", Description: "Uniquement les activités sans projet", Type: "boolean"},
+				queryParam{Name: "tag", Description: "Uniquement les activités portant ce tag"},
+				queryParam{Name: "include_idle", Description: "Inclure les périodes d'inactivité", Type: "boolean"},
+				queryParam{Name: "limit", Description: "Nombre maximal d'activités", Type: "integer"},
+			),
+			Response: ActivitiesResponse{},
+			Handler:  s.handleV1Activities,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/export",
+			Summary: "Export des activités (détaillé ou agrégé par application)",
+			Scope:   auth.ScopeRead,
+			Params: append(append([]queryParam{}, periodParams...),
+				queryParam{Name: "format", Description: "Format du fichier (csv par défaut)", Enum: []string{"csv", "json"}},
+				queryParam{Name: "aggregated", Description: "Agréger le temps (par application par défaut)", Type: "boolean"},
+				queryParam{Name: "group_by", Description: "Regroupement de l'export agrégé", Enum: []string{"app", "tag", "site"}},
+				queryParam{Name: "tag", Description: "Uniquement les activités portant ce tag (pas avec group_by tag ou site)"},
+			),
+			ContentType: "text/csv",
+			Handler:     s.handleV1Export,
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/browser/events",
+			Summary:  "Signal de l'onglet actif (focus, heartbeat ou blur) envoyé par l'extension navigateur, seul ou par lot",
+			Scope:    auth.ScopeBrowser,
+			Request:  BrowserEvent{},
+			Response: BrowserEventResponse{},
+			Handler:  s.handleV1BrowserEvent,
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/shell/events",
+			Summary:  "Début ou fin d'une commande, envoyé par les hooks de shell",
+			Scope:    auth.ScopeShell,
+			Request:  ShellEvent{},
+			Response: ShellEventResponse{},
+			Handler:  s.handleV1ShellEvent,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/shell/commands",
+			Summary: "Commandes lancées dans les shells, les plus récentes d'abord",
+			Scope:   auth.ScopeRead,
+			Params: append(append([]queryParam{}, periodParams...),
+				queryParam{Name: "program", Description: "Uniquement ce programme (go test, make build...)"},
+				queryParam{Name: "failed", Description: "Uniquement les commandes en échec", Type: "boolean"},
+				queryParam{Name: "limit", Description: "Nombre maximal de commandes (100 par défaut)", Type: "integer"}),
+			Response: ShellCommandsResponse{},
+			Handler:  s.handleV1ShellCommands,
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/users/current/heartbeats",
+			Summary:  "Heartbeat d'un plugin d'éditeur WakaTime (clé wakatime en Basic ou ?api_key=)",
+			Scope:    auth.ScopeWakaTime,
+			Request:  Heartbeat{},
+			Response: HeartbeatResponse{},
+			Status:   http.StatusCreated,
+			Handler:  s.handleV1Heartbeat,
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/users/current/heartbeats.bulk",
+			Summary:  "Lot de heartbeats WakaTime (file d'attente de wakatime-cli)",
+			Scope:    auth.ScopeWakaTime,
+			Request:  []Heartbeat{},
+			Response: HeartbeatBulkResponse{},
+			Status:   http.StatusCreated,
+			Handler:  s.handleV1HeartbeatsBulk,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/users/current/statusbar/today",
+			Summary:  "Temps du jour dans les éditeurs, pour la barre d'état des plugins WakaTime",
+			Scope:    auth.ScopeWakaTime,
+			Response: StatusBarResponse{},
+			Handler:  s.handleV1StatusBar,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/users/current/summaries",
+			Summary: "Résumés WakaTime par jour, calculés à partir des heartbeats",
+			Scope:   auth.ScopeWakaTime,
+			Params: []queryParam{
+				{Name: "range", Description: "Période prédéfinie, à la place de start et end", Enum: []string{"Today", "Yesterday", "Last 7 Days", "Last 30 Days"}},
+				{Name: "start", Description: "Premier jour (YYYY-MM-DD)"},
+				{Name: "end", Description: "Dernier jour inclus (YYYY-MM-DD)"},
+			},
+			Response: SummariesResponse{},
+			Handler:  s.handleV1Summaries,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/activities/{id}/tags",
+			Summary:  "Tags d'une activité",
+			Scope:    auth.ScopeRead,
+			Response: ActivityTagsResponse{},
+			Handler:  s.handleV1ActivityTags,
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/activities/{id}/tags",
+			Summary:  "Ajout manuel de tags sur une activité",
+			Scope:    auth.ScopeWrite,
+			Request:  TagsInput{},
+			Response: ActivityTagsResponse{},
+			Handler:  s.handleV1AddActivityTags,
+		},
+		{
+			Method:   http.MethodDelete,
+			Path:     "/activities/{id}/tags/{tag}",
+			Summary:  "Retrait d'un tag d'une activité",
+			Scope:    auth.ScopeWrite,
+			Response: ActivityTagsResponse{},
+			Handler:  s.handleV1RemoveActivityTag,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/tags",
+			Summary:  "Tags et leur utilisation",
+			Scope:    auth.ScopeRead,
+			Response: TagsResponse{},
+			Handler:  s.handleV1Tags,
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/tags/range",
+			Summary:  "Ajout (ou retrait) manuel de tags sur les activités actives d'une plage horaire",
+			Scope:    auth.ScopeWrite,
+			Request:  TagRangeInput{},
+			Response: TagRangeResponse{},
+			Handler:  s.handleV1TagRange,
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/tags/{name}",
+			Summary: "Suppression d'un tag de toutes les activités",
+			Scope:   auth.ScopeWrite,
+			Status:  http.StatusNoContent,
+			Handler: s.handleV1DeleteTag,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/entities/patterns",
+			Summary:  "Motifs d'extraction des tickets, dépôts, pull requests et merge requests",
+			Scope:    auth.ScopeRead,
+			Response: EntityPatternsResponse{},
+			Handler:  s.handleV1EntityPatterns,
+		},
+		{
+			Method:   http.MethodPut,
+			Path:     "/entities/patterns",
+			Summary:  "Remplacement des motifs d'extraction d'entités",
+			Scope:    auth.ScopeWrite,
+			Request:  EntityPatternsResponse{},
+			Response: EntityPatternsResponse{},
+			Handler:  s.handleV1SetEntityPatterns,
+		},
+		{
+			Method:   http.MethodDelete,
+			Path:     "/entities/patterns",
+			Summary:  "Retour aux motifs d'extraction par défaut",
+			Scope:    auth.ScopeWrite,
+			Response: EntityPatternsResponse{},
+			Handler:  s.handleV1ResetEntityPatterns,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/sites/rules",
+			Summary:  "Règles de regroupement des sites par segments de chemin",
+			Scope:    auth.ScopeRead,
+			Response: SiteRulesResponse{},
+			Handler:  s.handleV1SiteRules,
+		},
+		{
+			Method:   http.MethodPut,
+			Path:     "/sites/rules",
+			Summary:  "Remplacement des règles de regroupement des sites",
+			Scope:    auth.ScopeWrite,
+			Request:  SiteRulesResponse{},
+			Response: SiteRulesResponse{},
+			Handler:  s.handleV1SetSiteRules,
+		},
+		{
+			Method:   http.MethodDelete,
+			Path:     "/sites/rules",
+			Summary:  "Retour aux règles de regroupement par défaut",
+			Scope:    auth.ScopeWrite,
+			Response: SiteRulesResponse{},
+			Handler:  s.handleV1ResetSiteRules,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/privacy/rules",
+			Summary:  "Règles de confidentialité appliquées avant l'enregistrement des activités",
+			Scope:    auth.ScopeRead,
+			Response: PrivacyRulesResponse{},
+			Handler:  s.handleV1PrivacyRules,
+		},
+		{
+			Method:   http.MethodPut,
+			Path:     "/privacy/rules",
+			Summary:  "Remplacement des règles de confidentialité",
+			Scope:    auth.ScopeWrite,
+			Request:  PrivacyRulesResponse{},
+			Response: PrivacyRulesResponse{},
+			Handler:  s.handleV1SetPrivacyRules,
+		},
+		{
+			Method:   http.MethodDelete,
+			Path:     "/privacy/rules",
+			Summary:  "Suppression de toutes les règles de confidentialité",
+			Scope:    auth.ScopeWrite,
+			Response: PrivacyRulesResponse{},
+			Handler:  s.handleV1ResetPrivacyRules,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/rules",
+			Summary:  "Règles d'enrichissement, dans l'ordre d'évaluation",
+			Scope:    auth.ScopeRead,
+			Response: RulesResponse{},
+			Handler:  s.handleV1Rules,
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/rules",
+			Summary:  "Ajout d'une règle (en dernière position si position vaut 0)",
+			Scope:    auth.ScopeWrite,
+			Request:  rules.Rule{},
+			Response: rules.Rule{},
+			Status:   http.StatusCreated,
+			Handler:  s.handleV1CreateRule,
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/rules/test",
+			Summary:  "Évaluation des règles (enregistrées ou fournies) sur une fenêtre fictive",
+			Scope:    auth.ScopeRead,
+			Request:  RuleTestInput{},
+			Response: RuleTestResponse{},
+			Handler:  s.handleV1TestRules,
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/rules/reset",
+			Summary:  "Remplacement de toutes les règles par les règles par défaut",
+			Scope:    auth.ScopeWrite,
+			Response: RulesResponse{},
+			Handler:  s.handleV1ResetRules,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/rules/{id}",
+			Summary:  "Détail d'une règle",
+			Scope:    auth.ScopeRead,
+			Response: rules.Rule{},
+			Handler:  s.handleV1Rule,
+		},
+		{
+			Method:   http.MethodPut,
+			Path:     "/rules/{id}",
+			Summary:  "Modification d'une règle",
+			Scope:    auth.ScopeWrite,
+			Request:  rules.Rule{},
+			Response: rules.Rule{},
+			Handler:  s.handleV1UpdateRule,
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/rules/{id}",
+			Summary: "Suppression d'une règle",
+			Scope:   auth.ScopeWrite,
+			Status:  http.StatusNoContent,
+			Handler: s.handleV1DeleteRule,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/categories",
+			Summary:  "Catégories et leur niveau de productivité",
+			Scope:    auth.ScopeRead,
+			Response: CategoriesResponse{},
+			Handler:  s.handleV1Categories,
+		},
+		{
+			Method:   http.MethodPut,
+			Path:     "/categories/{name}",
+			Summary:  "Création d'une catégorie ou changement de sa productivité (rétroactif)",
+			Scope:    auth.ScopeWrite,
+			Request:  CategoryUpdate{},
+			Response: rules.Category{},
+			Handler:  s.handleV1SetCategory,
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/categories/{name}",
+			Summary: "Suppression d'une catégorie (ses activités deviennent neutres)",
+			Scope:   auth.ScopeWrite,
+			Status:  http.StatusNoContent,
+			Handler: s.handleV1DeleteCategory,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/projects",
+			Summary: "Projets, triés par client puis par nom",
+			Scope:   auth.ScopeRead,
+			Params: []queryParam{
+				{Name: "archived", Description: "Inclure les projets archivés", Type: "boolean"},
+			},
+			Response: ProjectsResponse{},
+			Handler:  s.handleV1Projects,
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/projects",
+			Summary:  "Création d'un projet",
+			Scope:    auth.ScopeWrite,
+			Request:  ProjectInput{},
+			Response: ProjectItem{},
+			Status:   http.StatusCreated,
+			Handler:  s.handleV1CreateProject,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/projects/{id}",
+			Summary:  "Détail d'un projet",
+			Scope:    auth.ScopeRead,
+			Response: ProjectItem{},
+			Handler:  s.handleV1Project,
+		},
+		{
+			Method:   http.MethodPut,
+			Path:     "/projects/{id}",
+			Summary:  "Modification d'un projet (un renommage est reporté sur l'historique et les règles)",
+			Scope:    auth.ScopeWrite,
+			Request:  ProjectInput{},
+			Response: ProjectItem{},
+			Handler:  s.handleV1UpdateProject,
+		},
+		{
+			Method:  http.MethodDelete,
+			Path:    "/projects/{id}",
+			Summary: "Suppression d'un projet (son temps redevient non attribué)",
+			Scope:   auth.ScopeWrite,
+			Status:  http.StatusNoContent,
+			Handler: s.handleV1DeleteProject,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/projects/{id}/stats",
+			Summary:  "Temps d'un projet par jour et par application",
+			Scope:    auth.ScopeRead,
+			Params:   periodParams,
+			Response: ProjectReportResponse{},
+			Handler:  s.handleV1ProjectStats,
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/openapi.json",
+			Summary: "Spécification OpenAPI 3 de l'API v1",
+			Handler: s.handleOpenAPI,
+		},
+	}
+}
+
+// registerV1 enregistre les routes /api/v1 sur mux
+func (s *Server) registerV1(mux *http.ServeMux) {
+	for _, e := range s.v1Endpoints() {
+		handler := e.Handler
+		if e.Scope != "" {
+			handler = s.requireScope(e.Scope, handler)
+		}
+		mux.HandleFunc(e.Method+" "+APIVersionPrefix+e.Path, handler)
+	}
+
+	// Toute autre route sous /api/v1 retourne une erreur JSON : 405 si le
+	// chemin existe avec d'autres méthodes, 404 sinon
+	catchAll := APIVersionPrefix + "/"
+	mux.HandleFunc(catchAll, func(w http.ResponseWriter, r *http.Request) {
+		var allowed []string
+		for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete} {
+			probe := r.Clone(r.Context())
+			probe.Method = method
+			if _, pattern := mux.Handler(probe); pattern != catchAll {
+				allowed = append(allowed, method)
+			}
+		}
+		if len(allowed) > 0 {
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+			writeError(w, http.StatusMethodNotAllowed, "méthode non autorisée: "+r.Method)
+			return
+		}
+		writeError(w, http.StatusNotFound, "route inconnue: "+r.Method+" "+r.URL.Path)
+	})
+}
+
+// deprecated marque une ancienne route comme dépréciée au profit de successor
+func deprecated(successor string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", fmt.Sprintf("<%s%s>; rel=\"successor-version\"", APIVersionPrefix, successor))
+		next(w, r)
+	}
+}
+
+// v1Period lit la période de la requête ; écrit l'erreur et retourne false si invalide
+func (s *Server) v1Period(w http.ResponseWriter, r *http.Request) (PeriodInfo, bool) {
+	period, start, end, err := s.periodFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return PeriodInfo{}, false
+	}
+	return PeriodInfo{Name: period, Start: start, End: end}, true
+}
+
+// handleV1Health vérifie l'état du serveur
+func (s *Server) handleV1Health(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, HealthResponse{Status: "ok", Time: time.Now()})
+}
+
+// handleV1CurrentActivity retourne l'activité en cours
+func (s *Server) handleV1CurrentActivity(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.currentActivity())
+}
+
+// currentActivity construit la réponse décrivant l'activité en cours
+func (s *Server) currentActivity() CurrentActivityResponse {
+	window, startTime := s.tracker.Current()
+	if window == nil {
+		return CurrentActivityResponse{Active: false}
+	}
+
+	return CurrentActivityResponse{
+		Active:          true,
+		AppName:         window.AppName,
+		EnrichedName:    window.GetEnrichedName(),
+		WindowTitle:     window.WindowTitle,
+		ProcessPath:     window.ProcessPath,
+		URL:             window.URL,
+		Domain:          window.Domain,
+		StartTime:       startTime,
+		DurationSeconds: int64(time.Since(startTime).Seconds()),
+	}
+}
+
+// handleV1Stats retourne le temps actif par application
+func (s *Server) handleV1Stats(w http.ResponseWriter, r *http.Request) {
+	period, ok := s.v1Period(w, r)
+	if !ok {
+		return
+	}
+
+	response, err := s.buildStats(period, r.URL.Query().Get("tag"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// buildStats calcule le temps actif par application sur la période,
+// restreint aux activités portant le tag s'il n'est pas vide
+func (s *Server) buildStats(period PeriodInfo, tag string) (StatsResponse, error) {
+	totals, err := s.db.GetActivityTotals(period.Start, period.End, tag)
+	if err != nil {
+		return StatsResponse{}, err
+	}
+
+	stats, err := s.db.GetTaggedStatsByApp(period.Start, period.End, tag)
+	if err != nil {
+		return StatsResponse{}, err
+	}
+
+	apps := make([]AppStat, 0, len(stats))
+	for appName, seconds := range stats {
+		apps = append(apps, AppStat{AppName: appName, TotalSeconds: seconds})
+	}
+	sort.Slice(apps, func(i, j int) bool {
+		return apps[i].TotalSeconds > apps[j].TotalSeconds
+	})
+
+	return StatsResponse{
+		Period:             period,
+		TotalActivities:    totals.Activities,
+		TotalActiveSeconds: sumStats(stats),
+		TotalIdleSeconds:   totals.IdleSeconds,
+		Apps:               apps,
+	}, nil
+}
+
+// handleV1Timeline retourne la série temporelle de la période
+func (s *Server) handleV1Timeline(w http.ResponseWriter, r *http.Request) {
+	period, ok := s.v1Period(w, r)
+	if !ok {
+		return
+	}
+
+	response, err := s.buildTimelineResponse(period)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// buildTimelineResponse calcule la série temporelle et sa granularité
+func (s *Server) buildTimelineResponse(period PeriodInfo) (TimelineResponse, error) {
+	seconds, labels, err := s.buildTimeline(period.Name, period.Start, period.End)
+	if err != nil {
+		return TimelineResponse{}, err
+	}
+
+	granularity := "day"
+	if len(seconds) == 24 && labels[0] == "00:00" {
+		granularity = "hour"
+	}
+
+	return TimelineResponse{
+		Period:      period,
+		Granularity: granularity,
+		Labels:      labels,
+		Seconds:     seconds,
+	}, nil
+}
+
+// handleV1Grouped retourne les stats groupées par app et enriched_name
+func (s *Server) handleV1Grouped(w http.ResponseWriter, r *http.Request) {
+	period, ok := s.v1Period(w, r)
+	if !ok {
+		return
+	}
+
+	grouped, err := s.db.GetTaggedGroupedStats(period.Start, period.End, r.URL.Query().Get("tag"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, GroupedStatsResponse{
+		Period: period,
+		Groups: buildAppGroups(grouped),
+	})
+}
+
+// handleV1Activities retourne les activités brutes filtrées
+func (s *Server) handleV1Activities(w http.ResponseWriter, r *http.Request) {
+	period, ok := s.v1Period(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	filter := storage.ActivityFilter{
+		Start:        period.Start,
+		End:          period.End,
+		AppName:      query.Get("app"),
+		EnrichedName: query.Get("enriched"),
+		Project:      query.Get("project"),
+		Unassigned:   query.Get("unassigned") == "true",
+		Tag:          query.Get("tag"),
+		ExcludeIdle:  query.Get("include_idle") != "true",
+	}
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "limit invalide")
+			return
+		}
+		filter.Limit = n
+	}
+
+	activities, err := s.db.QueryActivities(filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	items := make([]ActivityItem, 0, len(activities))
+	for _, a := range activities {
+		items = append(items, activityItem(a))
+	}
+
+	writeJSON(w, http.StatusOK, ActivitiesResponse{Period: period, Activities: items})
+}
+
+// activityItem convertit une activité stockée en élément de réponse
+func activityItem(a storage.Activity) ActivityItem {
+	return ActivityItem{
+		ID:              a.ID,
+		AppName:         a.AppName,
+		EnrichedName:    a.EnrichedName,
+		Category:        a.Category,
+		Productivity:    string(a.Productivity),
+		Project:         a.Project,
+		Client:          a.Client,
+		Tags:            a.Tags,
+		InferredSite:    a.InferredSite,
+		SiteConfidence:  a.Confidence,
+		FileName:        a.Document.FileName,
+		Language:        a.Document.Language,
+		Command:         a.Command,
+		WorkingDir:      a.WorkingDir,
+		SSHHost:         a.SSHHost,
+		Repo:            a.Repo.Name(),
+		RepoRoot:        a.Repo.Root,
+		Branch:          a.Repo.Branch,
+		URL:             a.URL,
+		WindowTitle:     a.WindowTitle,
+		ProcessPath:     a.ProcessPath,
+		StartTime:       a.StartTime,
+		EndTime:         a.EndTime,
+		DurationSeconds: a.DurationSecs,
+		IsIdle:          a.IsIdle,
+	}
+}
+
+// handleV1Export exporte les activités de la période en CSV ou JSON
+func (s *Server) handleV1Export(w http.ResponseWriter, r *http.Request) {
+	period, ok := s.v1Period(w, r)
+	if !ok {
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "json" {
+		writeError(w, http.StatusBadRequest, "format invalide (csv, json)")
+		return
+	}
+	aggregated := r.URL.Query().Get("aggregated") == "true"
+	tag := r.URL.Query().Get("tag")
+	groupBy := r.URL.Query().Get("group_by")
+	if groupBy == "" {
+		groupBy = "app"
+	}
+	if groupBy != "app" && groupBy != "tag" && groupBy != "site" {
+		writeError(w, http.StatusBadRequest, "group_by invalide (app, tag, site)")
+		return
+	}
+	// Les exports par tag et par site portent sur toutes les activités
+	if aggregated && groupBy != "app" && tag != "" {
+		writeError(w, http.StatusBadRequest, "tag n'est pas compatible avec group_by="+groupBy)
+		return
+	}
+	var grouper *domains.Grouper
+	if aggregated && groupBy == "site" {
+		if grouper, ok = s.siteGrouper(w); !ok {
+			return
+		}
+	}
+
+	// Les données sont lues avant l'envoi des en-têtes de téléchargement :
+	// une erreur de lecture reste une erreur JSON
+	kind := "activities"
+	var write func(io.Writer) error
+	if aggregated && groupBy == "tag" {
+		kind = "tags"
+		stats, _, err := s.db.GetStatsByTag(period.Start, period.End)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		write = func(out io.Writer) error { return export.WriteTagAggregatedCSV(out, stats) }
+		if format == "json" {
+			write = func(out io.Writer) error { return export.WriteTagAggregatedJSON(out, stats) }
+		}
+	} else if aggregated && groupBy == "site" {
+		kind = "sites"
+		stats, err := s.db.GetStatsBySite(period.Start, period.End, grouper, 0)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		write = func(out io.Writer) error { return export.WriteSiteAggregatedCSV(out, stats) }
+		if format == "json" {
+			write = func(out io.Writer) error { return export.WriteSiteAggregatedJSON(out, stats) }
+		}
+	} else if aggregated {
+		kind = "aggregated"
+		stats, err := s.db.GetTaggedStatsByApp(period.Start, period.End, tag)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		write = func(out io.Writer) error { return export.WriteAggregatedCSV(out, stats) }
+		if format == "json" {
+			write = func(out io.Writer) error { return export.WriteAggregatedJSON(out, stats) }
+		}
+	} else {
+		activities, err := s.db.QueryActivities(storage.ActivityFilter{Start: period.Start, End: period.End, Tag: tag})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		write = func(out io.Writer) error { return export.WriteCSV(out, activities) }
+		if format == "json" {
+			write = func(out io.Writer) error { return export.WriteJSON(out, activities) }
+		}
+	}
+
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s_%s.%s", kind, period.Name, format))
+
+	// Les en-têtes sont déjà envoyés : on ne peut plus que journaliser
+	if err := write(w); err != nil {
+		log.Printf("⚠️  Erreur export: %v", err)
+	}
+}
@@ -0,0 +1,433 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"trackmytime/internal/documents"
+	"trackmytime/internal/gitinfo"
+	"trackmytime/internal/storage"
+	"trackmytime/internal/tracker"
+)
+
+// Heartbeat est un heartbeat au format de l'API WakaTime, tel qu'envoyé par
+// les plugins d'éditeur via wakatime-cli
+type Heartbeat struct {
+	Entity    string  `json:"entity" doc:"Chemin du fichier, domaine ou application"`
+	Type      string  `json:"type,omitempty" doc:"file (défaut), app, domain ou url"`
+	Category  string  `json:"category,omitempty" doc:"coding (défaut), debugging, building..."`
+	Time      float64 `json:"time" doc:"Heure Unix en secondes, avec fraction"`
+	Project   string  `json:"project,omitempty"`
+	Branch    string  `json:"branch,omitempty"`
+	Language  string  `json:"language,omitempty" doc:"Déduit de l'extension si absent"`
+	IsWrite   bool    `json:"is_write,omitempty" doc:"Le fichier vient d'être enregistré"`
+	Lines     int     `json:"lines,omitempty"`
+	LineNo    int     `json:"lineno,omitempty"`
+	UserAgent string  `json:"user_agent,omitempty" doc:"User agent de wakatime-cli (en-tête User-Agent à défaut)"`
+}
+
+// HeartbeatResponse est la réponse de POST /api/v1/users/current/heartbeats
+type HeartbeatResponse struct {
+	Data Heartbeat `json:"data"`
+}
+
+// HeartbeatBulkResponse est la réponse de POST
+// /api/v1/users/current/heartbeats.bulk
+type HeartbeatBulkResponse struct {
+	Responses [][]any `json:"responses" doc:"Une paire [{\"data\": heartbeat} ou {\"error\": message}, code HTTP] par heartbeat, dans l'ordre reçu"`
+}
+
+// WakaTimeDuration est un temps au format des résumés WakaTime
+type WakaTimeDuration struct {
+	Name         string  `json:"name,omitempty"`
+	TotalSeconds float64 `json:"total_seconds"`
+	Percent      float64 `json:"percent,omitempty"`
+	Digital      string  `json:"digital" doc:"h:mm"`
+	Decimal      string  `json:"decimal" doc:"Heures avec deux décimales"`
+	Text         string  `json:"text" doc:"1 hr 2 mins"`
+	Hours        int     `json:"hours"`
+	Minutes      int     `json:"minutes"`
+	Seconds      int     `json:"seconds"`
+}
+
+// WakaTimeRange est la journée couverte par un résumé WakaTime
+type WakaTimeRange struct {
+	Date     string    `json:"date" doc:"YYYY-MM-DD"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Text     string    `json:"text"`
+	Timezone string    `json:"timezone"`
+}
+
+// WakaTimeSummary est le résumé WakaTime d'une journée, calculé à partir des
+// heartbeats
+type WakaTimeSummary struct {
+	GrandTotal WakaTimeDuration   `json:"grand_total"`
+	Categories []WakaTimeDuration `json:"categories"`
+	Editors    []WakaTimeDuration `json:"editors"`
+	Languages  []WakaTimeDuration `json:"languages"`
+	Projects   []WakaTimeDuration `json:"projects"`
+	Branches   []WakaTimeDuration `json:"branches"`
+	Range      WakaTimeRange      `json:"range"`
+}
+
+// StatusBarResponse est la réponse de GET /api/v1/users/current/statusbar/today
+type StatusBarResponse struct {
+	CachedAt time.Time       `json:"cached_at"`
+	Data     WakaTimeSummary `json:"data"`
+}
+
+// SummariesResponse est la réponse de GET /api/v1/users/current/summaries
+type SummariesResponse struct {
+	Data            []WakaTimeSummary `json:"data" doc:"Un résumé par jour"`
+	CumulativeTotal WakaTimeDuration  `json:"cumulative_total"`
+	Start           time.Time         `json:"start"`
+	End             time.Time         `json:"end"`
+}
+
+// wakaTimeEditors donne le nom affiché des éditeurs, par nom de user agent
+var wakaTimeEditors = map[string]string{
+	"vscode":       "VS Code",
+	"vscodium":     "VSCodium",
+	"cursor":       "Cursor",
+	"windsurf":     "Windsurf",
+	"vim":          "Vim",
+	"neovim":       "Neovim",
+	"emacs":        "Emacs",
+	"sublime":      "Sublime Text",
+	"sublime_text": "Sublime Text",
+	"zed":          "Zed",
+	"helix":        "Helix",
+	"xcode":        "Xcode",
+}
+
+// handleV1Heartbeat enregistre un heartbeat WakaTime
+func (s *Server) handleV1Heartbeat(w http.ResponseWriter, r *http.Request) {
+	var heartbeat Heartbeat
+	if err := json.NewDecoder(r.Body).Decode(&heartbeat); err != nil {
+		writeError(w, http.StatusBadRequest, "JSON invalide: "+err.Error())
+		return
+	}
+	record, err := heartbeatRecord(heartbeat, r.Header.Get("User-Agent"), map[string]gitinfo.Repo{})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.recordHeartbeats([]storage.Heartbeat{record}); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, HeartbeatResponse{Data: heartbeatItem(heartbeat, record)})
+}
+
+// handleV1HeartbeatsBulk enregistre un lot de heartbeats WakaTime (file
+// d'attente de wakatime-cli) ; les heartbeats invalides sont rejetés un à un
+func (s *Server) handleV1HeartbeatsBulk(w http.ResponseWriter, r *http.Request) {
+	var heartbeats []Heartbeat
+	if err := json.NewDecoder(r.Body).Decode(&heartbeats); err != nil {
+		writeError(w, http.StatusBadRequest, "JSON invalide: "+err.Error())
+		return
+	}
+
+	response := HeartbeatBulkResponse{Responses: make([][]any, len(heartbeats))}
+	records := make([]storage.Heartbeat, 0, len(heartbeats))
+	repos := map[string]gitinfo.Repo{}
+	for i, heartbeat := range heartbeats {
+		record, err := heartbeatRecord(heartbeat, r.Header.Get("User-Agent"), repos)
+		if err != nil {
+			response.Responses[i] = []any{map[string]string{"error": err.Error()}, http.StatusBadRequest}
+			continue
+		}
+		records = append(records, record)
+		response.Responses[i] = []any{HeartbeatResponse{Data: heartbeatItem(heartbeat, record)}, http.StatusCreated}
+	}
+	if err := s.recordHeartbeats(records); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, response)
+}
+
+// recordHeartbeats enregistre les heartbeats que les règles de
+// confidentialité n'excluent pas, puis les reporte sur les activités déjà
+// enregistrées qu'ils chevauchent (heartbeats en retard)
+func (s *Server) recordHeartbeats(records []storage.Heartbeat) error {
+	kept := make([]storage.Heartbeat, 0, len(records))
+	for _, h := range records {
+		if h.Protect(tracker.Protect) {
+			kept = append(kept, h)
+		}
+	}
+	records = kept
+	if len(records) == 0 {
+		return nil
+	}
+	if _, err := s.db.RecordHeartbeats(records); err != nil {
+		return err
+	}
+
+	start, end := records[0].Time, records[0].Time
+	for _, h := range records[1:] {
+		if h.Time.Before(start) {
+			start = h.Time
+		}
+		if h.Time.After(end) {
+			end = h.Time
+		}
+	}
+	if _, err := s.db.MergeHeartbeats(start, end.Add(time.Millisecond)); err != nil {
+		log.Printf("⚠️  Erreur fusion des heartbeats: %v", err)
+	}
+	return nil
+}
+
+// heartbeatRecord valide un heartbeat WakaTime et le convertit pour le
+// stockage ; repos mémorise le dépôt git des dossiers déjà résolus
+func heartbeatRecord(h Heartbeat, userAgent string, repos map[string]gitinfo.Repo) (storage.Heartbeat, error) {
+	entity := strings.TrimSpace(h.Entity)
+	if entity == "" {
+		return storage.Heartbeat{}, fmt.Errorf("entity est requis")
+	}
+	if h.Time <= 0 || math.IsInf(h.Time, 0) || math.IsNaN(h.Time) {
+		return storage.Heartbeat{}, fmt.Errorf("time invalide")
+	}
+	if h.UserAgent != "" {
+		userAgent = h.UserAgent
+	}
+
+	seconds, fraction := math.Modf(h.Time)
+	record := storage.Heartbeat{
+		Time:      time.Unix(int64(seconds), int64(fraction*1e9)).Round(time.Millisecond),
+		Entity:    entity,
+		Type:      strings.ToLower(h.Type),
+		Category:  strings.ToLower(h.Category),
+		Project:   strings.TrimSpace(h.Project),
+		IsWrite:   h.IsWrite,
+		Lines:     h.Lines,
+		LineNo:    h.LineNo,
+		Editor:    heartbeatEditor(userAgent),
+		UserAgent: userAgent,
+	}
+	if record.Type == "" {
+		record.Type = "file"
+	}
+	if record.Category == "" {
+		record.Category = "coding"
+	}
+
+	if record.Type == "file" {
+		record.Document = documents.ForFile(entity)
+		if filepath.IsAbs(entity) {
+			dir := filepath.Dir(entity)
+			repo, ok := repos[dir]
+			if !ok {
+				repo, _ = gitinfo.Lookup(dir)
+				repos[dir] = repo
+			}
+			record.Repo = repo
+		}
+	}
+	if h.Language != "" {
+		record.Document.Language = h.Language
+	}
+	if h.Branch != "" {
+		// La branche du plugin est celle au moment du heartbeat, peut-être
+		// envoyé en différé
+		record.Repo.Branch = h.Branch
+	}
+	return record, nil
+}
+
+// heartbeatItem retourne le heartbeat tel qu'enregistré, au format WakaTime
+func heartbeatItem(h Heartbeat, record storage.Heartbeat) Heartbeat {
+	h.Entity = record.Entity
+	h.Type = record.Type
+	h.Category = record.Category
+	h.Language = record.Document.Language
+	h.Branch = record.Repo.Branch
+	h.UserAgent = record.UserAgent
+	return h
+}
+
+// heartbeatEditor déduit l'éditeur du user agent de wakatime-cli :
+// "wakatime/v1.90.0 (linux-6.5) go1.21 vscode/1.85.1 vscode-wakatime/24.4.0"
+// → "VS Code". L'éditeur précède le plugin ("neovim/0.9.4 vim-wakatime/11.1").
+func heartbeatEditor(userAgent string) string {
+	fields := strings.Fields(userAgent)
+	for i := len(fields) - 1; i >= 0; i-- {
+		name, _, _ := strings.Cut(fields[i], "/")
+		plugin, ok := strings.CutSuffix(strings.ToLower(name), "-wakatime")
+		if !ok {
+			continue
+		}
+		if i > 0 && !strings.HasPrefix(fields[i-1], "go") && strings.Contains(fields[i-1], "/") {
+			name, _, _ = strings.Cut(fields[i-1], "/")
+		} else {
+			name = plugin
+		}
+		if display, ok := wakaTimeEditors[strings.ToLower(name)]; ok {
+			return display
+		}
+		return name
+	}
+	return ""
+}
+
+// handleV1StatusBar retourne le résumé du jour affiché dans la barre d'état
+// des éditeurs
+func (s *Server) handleV1StatusBar(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	summary, err := s.wakaTimeSummary(start, "Today")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, StatusBarResponse{CachedAt: now, Data: summary})
+}
+
+// handleV1Summaries retourne un résumé WakaTime par jour de la période
+func (s *Server) handleV1Summaries(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var start, end time.Time
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch strings.ToLower(query.Get("range")) {
+	case "":
+		var err error
+		start, end, err = parseCustomDates(query.Get("start"), query.Get("end"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	case "today":
+		start, end = today, today.AddDate(0, 0, 1)
+	case "yesterday":
+		start, end = today.AddDate(0, 0, -1), today
+	case "last 7 days":
+		start, end = today.AddDate(0, 0, -6), today.AddDate(0, 0, 1)
+	case "last 30 days":
+		start, end = today.AddDate(0, 0, -29), today.AddDate(0, 0, 1)
+	default:
+		writeError(w, http.StatusBadRequest, "range invalide (Today, Yesterday, Last 7 Days, Last 30 Days)")
+		return
+	}
+	if end.Sub(start) > 366*24*time.Hour {
+		writeError(w, http.StatusBadRequest, "période limitée à un an")
+		return
+	}
+
+	response := SummariesResponse{Start: start, End: end}
+	var total float64
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		summary, err := s.wakaTimeSummary(day, day.Format("Mon Jan 2 2006"))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		total += summary.GrandTotal.TotalSeconds
+		response.Data = append(response.Data, summary)
+	}
+	response.CumulativeTotal = wakaTimeDuration("", time.Duration(total*float64(time.Second)), 0)
+	writeJSON(w, http.StatusOK, response)
+}
+
+// wakaTimeSummary calcule le résumé WakaTime de la journée commençant à day
+func (s *Server) wakaTimeSummary(day time.Time, text string) (WakaTimeSummary, error) {
+	end := day.AddDate(0, 0, 1)
+	totals, err := s.db.GetHeartbeatTotals(day, end)
+	if err != nil {
+		return WakaTimeSummary{}, err
+	}
+
+	// Les catégories sont en minuscules dans les heartbeats ("coding") et
+	// capitalisées dans les résumés ("Coding")
+	for i, category := range totals.Categories {
+		if category.Name != "" {
+			totals.Categories[i].Name = strings.ToUpper(category.Name[:1]) + category.Name[1:]
+		}
+	}
+
+	zone, _ := day.Zone()
+	if name := day.Location().String(); name != "Local" {
+		zone = name
+	}
+	return WakaTimeSummary{
+		GrandTotal: wakaTimeDuration("", totals.Total, 0),
+		Categories: wakaTimeDurations(totals.Categories, totals.Total, "Coding"),
+		Editors:    wakaTimeDurations(totals.Editors, totals.Total, "Other"),
+		Languages:  wakaTimeDurations(totals.Languages, totals.Total, "Other"),
+		Projects:   wakaTimeDurations(totals.Projects, totals.Total, "Unknown Project"),
+		Branches:   wakaTimeDurations(totals.Branches, totals.Total, ""),
+		Range: WakaTimeRange{
+			Date:     day.Format("2006-01-02"),
+			Start:    day,
+			End:      end,
+			Text:     text,
+			Timezone: zone,
+		},
+	}, nil
+}
+
+// wakaTimeDurations convertit des temps par valeur ; les heartbeats sans
+// valeur sont regroupés sous unknown, ou omis si unknown est vide
+func wakaTimeDurations(stats []storage.HeartbeatStat, total time.Duration, unknown string) []WakaTimeDuration {
+	durations := make([]WakaTimeDuration, 0, len(stats))
+	for _, stat := range stats {
+		name := stat.Name
+		if name == "" {
+			if unknown == "" {
+				continue
+			}
+			name = unknown
+		}
+		durations = append(durations, wakaTimeDuration(name, stat.Duration, total))
+	}
+	return durations
+}
+
+// wakaTimeDuration met un temps au format WakaTime ; total sert au pourcentage
+func wakaTimeDuration(name string, d, total time.Duration) WakaTimeDuration {
+	seconds := int(d.Seconds())
+	hours, minutes := seconds/3600, seconds%3600/60
+	duration := WakaTimeDuration{
+		Name:         name,
+		TotalSeconds: d.Seconds(),
+		Digital:      fmt.Sprintf("%d:%02d", hours, minutes),
+		Decimal:      fmt.Sprintf("%.2f", d.Hours()),
+		Hours:        hours,
+		Minutes:      minutes,
+		Seconds:      seconds % 60,
+	}
+	if total > 0 {
+		duration.Percent = math.Round(float64(d)*10000/float64(total)) / 100
+	}
+
+	var parts []string
+	if hours > 0 {
+		parts = append(parts, plural(hours, "hr"))
+	}
+	if minutes > 0 || hours == 0 {
+		parts = append(parts, plural(minutes, "min"))
+	}
+	if hours == 0 && minutes == 0 {
+		parts = []string{plural(seconds, "sec")}
+	}
+	duration.Text = strings.Join(parts, " ")
+	return duration
+}
+
+// plural retourne "1 hr", "2 hrs"...
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return strconv.Itoa(n) + " " + unit + "s"
+}
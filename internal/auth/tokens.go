@@ -0,0 +1,212 @@
+// Package auth gère les jetons d'accès à l'API locale.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// Scope définit ce qu'un jeton a le droit de faire
+type Scope string
+
+const (
+	// ScopeRead autorise la lecture des stats, activités et exports
+	ScopeRead Scope = "read"
+	// ScopeWrite autorise les modifications (règles, tags, ...) ; implique ScopeRead
+	ScopeWrite Scope = "write"
+	// ScopeBrowser autorise uniquement l'envoi d'événements par l'extension navigateur
+	ScopeBrowser Scope = "browser"
+	// ScopeShell autorise uniquement l'envoi des commandes par les hooks de shell
+	ScopeShell Scope = "shell"
+	// ScopeWakaTime autorise uniquement l'API compatible WakaTime des plugins
+	// d'éditeur (heartbeats et résumés de la barre d'état)
+	ScopeWakaTime Scope = "wakatime"
+)
+
+// defaultScopes reçoivent chacun un jeton à la création du fichier de jetons
+var defaultScopes = []Scope{ScopeRead, ScopeWrite, ScopeBrowser, ScopeShell, ScopeWakaTime}
+
+// addedScopes sont apparus après les premières versions : un jeton leur est
+// ajouté au chargement des fichiers qui n'en ont aucun
+var addedScopes = []Scope{ScopeShell, ScopeWakaTime}
+
+// Token est un jeton d'accès et ses droits
+type Token struct {
+	Name   string  `json:"name"`
+	Value  string  `json:"token"`
+	Scopes []Scope `json:"scopes"`
+}
+
+// Allows indique si le jeton donne accès au scope demandé
+func (t *Token) Allows(scope Scope) bool {
+	for _, s := range t.Scopes {
+		if s == scope || (s == ScopeWrite && scope == ScopeRead) {
+			return true
+		}
+	}
+	return false
+}
+
+// TokenStore contient les jetons chargés depuis le fichier de jetons
+type TokenStore struct {
+	Tokens []Token `json:"tokens"`
+}
+
+// LoadTokens lit le fichier de jetons sans jamais le créer ni le modifier,
+// pour les commandes lancées hors de l'agent (hooks de shell)
+func LoadTokens(path string) (*TokenStore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erreur lecture jetons: %w", err)
+	}
+	var store TokenStore
+	if err := json.Unmarshal(data, &store); err != nil {
+		return nil, fmt.Errorf("fichier de jetons invalide (%s): %w", path, err)
+	}
+	return &store, nil
+}
+
+// LoadOrCreateTokens lit le fichier de jetons, ou le crée avec un jeton par
+// scope lors du premier lancement. Le fichier est toujours en 0600.
+func LoadOrCreateTokens(path string) (*TokenStore, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return createTokens(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erreur lecture jetons: %w", err)
+	}
+
+	// Resserrer les permissions si le fichier a été modifié à la main
+	if info, err := os.Stat(path); err == nil && info.Mode().Perm() != 0600 {
+		log.Printf("⚠️  Permissions %v sur %s, correction en 0600", info.Mode().Perm(), path)
+		if err := os.Chmod(path, 0600); err != nil {
+			return nil, fmt.Errorf("erreur permissions jetons: %w", err)
+		}
+	}
+
+	var store TokenStore
+	if err := json.Unmarshal(data, &store); err != nil {
+		return nil, fmt.Errorf("fichier de jetons invalide (%s): %w", path, err)
+	}
+
+	added := false
+	for _, scope := range addedScopes {
+		if store.Allowing(scope) != nil {
+			continue
+		}
+		value, err := generateToken(scope)
+		if err != nil {
+			return nil, err
+		}
+		store.Tokens = append(store.Tokens, Token{Name: string(scope), Value: value, Scopes: []Scope{scope}})
+		log.Printf("🔑 Jeton %s ajouté dans %s", scope, path)
+		added = true
+	}
+	if added {
+		data, err := json.MarshalIndent(&store, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			return nil, fmt.Errorf("erreur écriture jetons: %w", err)
+		}
+	}
+	return &store, nil
+}
+
+// createTokens génère les jetons par défaut et les écrit dans path
+func createTokens(path string) (*TokenStore, error) {
+	store := &TokenStore{}
+	for _, scope := range defaultScopes {
+		value, err := generateToken(scope)
+		if err != nil {
+			return nil, err
+		}
+		store.Tokens = append(store.Tokens, Token{
+			Name:   string(scope),
+			Value:  value,
+			Scopes: []Scope{scope},
+		})
+	}
+
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("erreur création dossier jetons: %w", err)
+	}
+	// O_EXCL : ne jamais écraser des jetons existants
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("erreur création jetons: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		return nil, fmt.Errorf("erreur écriture jetons: %w", err)
+	}
+
+	log.Printf("🔑 Jetons d'accès API générés dans %s", path)
+	return store, nil
+}
+
+// generateToken retourne un jeton aléatoire de 256 bits. Le jeton wakatime a
+// le format des clés WakaTime ("waka_" suivi d'un UUID, 122 bits aléatoires),
+// seul accepté par wakatime-cli.
+func generateToken(scope Scope) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("erreur génération jeton: %w", err)
+	}
+	if scope == ScopeWakaTime {
+		buf[6] = buf[6]&0x0f | 0x40 // UUID version 4
+		buf[8] = buf[8]&0x3f | 0x80 // variante RFC 4122
+		h := hex.EncodeToString(buf[:16])
+		return "waka_" + h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:], nil
+	}
+	return "tmt_" + hex.EncodeToString(buf), nil
+}
+
+// Lookup retourne le jeton correspondant à value, ou nil s'il est inconnu
+func (s *TokenStore) Lookup(value string) *Token {
+	if value == "" {
+		return nil
+	}
+	for i := range s.Tokens {
+		if subtle.ConstantTimeCompare([]byte(s.Tokens[i].Value), []byte(value)) == 1 {
+			return &s.Tokens[i]
+		}
+	}
+	return nil
+}
+
+// Allowing retourne le premier jeton donnant accès à scope, ou nil
+func (s *TokenStore) Allowing(scope Scope) *Token {
+	for i := range s.Tokens {
+		if s.Tokens[i].Allows(scope) {
+			return &s.Tokens[i]
+		}
+	}
+	return nil
+}
+
+// Find retourne le premier jeton portant ce nom, ou nil
+func (s *TokenStore) Find(name string) *Token {
+	for i := range s.Tokens {
+		if s.Tokens[i].Name == name {
+			return &s.Tokens[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+// Package documents reconnaît le fichier ouvert dans les titres de fenêtre
+// des éditeurs (VSCode, Cursor, JetBrains, Vim, Emacs) et en déduit le
+// langage de programmation.
+package documents
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Document est le fichier ouvert dans une fenêtre d'éditeur
+type Document struct {
+	FileName  string // nom du fichier, sans dossier
+	Extension string // extension en minuscules, sans le point ("" pour Makefile)
+	Language  string // langage déduit, "" s'il n'est pas reconnu
+}
+
+// editor reconnaît les titres de fenêtre d'un éditeur
+type editor struct {
+	name  string
+	app   *regexp.Regexp // nil = toute application (éditeurs en terminal)
+	title *regexp.Regexp // le groupe file désigne le fichier
+	last  bool           // le fichier est le dernier segment " – " du groupe file
+}
+
+var editors = []editor{
+	// "● main.go — trackmytime — Perso", "main.go - trackmytime - Visual Studio Code"
+	{
+		name:  "VSCode",
+		app:   regexp.MustCompile(`Code|Visual Studio Code|Cursor|VSCodium|Windsurf`),
+		title: regexp.MustCompile(`^(?:● )?(?P<file>.+?)(?: \([^)]*\))?(?: [—-] .*)?$`),
+	},
+	// "trackmytime – main.go", "trackmytime [~/code/trackmytime] – .../storage/db.go [storage]"
+	{
+		name:  "JetBrains",
+		app:   regexp.MustCompile(`IntelliJ IDEA|GoLand|PyCharm|WebStorm|PhpStorm|RubyMine|CLion|Rider|DataGrip|RustRover|Android Studio|AppCode|Aqua|Fleet`),
+		title: regexp.MustCompile(`^(?P<file>.+? – .+?)(?: \[[^\]]*\])?$`),
+		last:  true,
+	},
+	// "main.go + (~/code/trackmytime) - NVIM", "main.go (~/code) - VIM", "main.go - GVIM1"
+	{
+		name:  "Vim",
+		title: regexp.MustCompile(`^(?P<file>.+?)(?: [-+=]+)?(?: \([^)]*\))? - (?i:n?vim|gvim|macvim)\d*$`),
+	},
+	// "main.go - GNU Emacs at host", "main.go<2>"
+	{
+		name:  "Emacs",
+		title: regexp.MustCompile(`^(?P<file>.+?)(?:<[^>]*>)? - GNU Emacs(?: at .*)?$`),
+	},
+	{
+		name:  "Emacs",
+		app:   regexp.MustCompile(`Emacs`),
+		title: regexp.MustCompile(`^(?P<file>.+?)(?:<[^>]*>)?$`),
+	},
+}
+
+// fileName reconnaît un nom de fichier avec extension ; les noms sans
+// extension (Makefile, Dockerfile...) sont ceux de fileLanguages
+var fileName = regexp.MustCompile(`^[^\s*/\\][^/\\]*\.[A-Za-z0-9_+-]{1,12}$`)
+
+// Detect retourne le fichier ouvert dans une fenêtre d'éditeur ; false si
+// l'application n'est pas un éditeur reconnu ou si le titre ne désigne pas
+// un fichier (accueil, paramètres, espace de travail seul...)
+func Detect(appName, title string) (Document, bool) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return Document{}, false
+	}
+
+	for _, e := range editors {
+		if e.app != nil && !e.app.MatchString(appName) {
+			continue
+		}
+		m := e.title.FindStringSubmatch(title)
+		if m == nil {
+			continue
+		}
+		file := m[e.title.SubexpIndex("file")]
+		if e.last {
+			segments := strings.Split(file, " – ")
+			file = segments[len(segments)-1]
+		}
+		if doc, ok := document(file); ok {
+			return doc, true
+		}
+		if e.app != nil {
+			// Éditeur identifié mais sans fichier ouvert
+			return Document{}, false
+		}
+	}
+	return Document{}, false
+}
+
+// document construit le Document d'un chemin ou nom de fichier
+func document(file string) (Document, bool) {
+	file = path.Base(strings.ReplaceAll(strings.TrimSpace(file), `\`, "/"))
+	if file == "." || file == "/" {
+		return Document{}, false
+	}
+	if _, known := fileLanguages[file]; !known && !fileName.MatchString(file) {
+		return Document{}, false
+	}
+	return ForFile(file), true
+}
+
+// ForFile retourne le Document d'un chemin désignant à coup sûr un fichier
+// (heartbeats des plugins d'éditeur) : le nom n'a pas besoin d'extension
+func ForFile(file string) Document {
+	file = path.Base(strings.ReplaceAll(file, `\`, "/"))
+	doc := Document{FileName: file}
+	if ext := path.Ext(file); ext != "" && ext != file {
+		doc.Extension = strings.ToLower(ext[1:])
+	}
+	doc.Language = Language(file)
+	return doc
+}
+
+// editorApps reconnaît les éditeurs sans règle de titre ci-dessus
+var editorApps = regexp.MustCompile(`(?i)^(sublime[ _]text|zed|xcode|gvim|macvim|neovide|kate|gedit|helix)$`)
+
+// editorCommands reconnaît les éditeurs lancés dans un terminal
+var editorCommands = regexp.MustCompile(`^(n?vim?|hx|helix|emacs|nano|micro|kak)$`)
+
+// IsEditor indique si appName est un éditeur reconnu
+func IsEditor(appName string) bool {
+	for _, e := range editors {
+		if e.app != nil && e.app.MatchString(appName) {
+			return true
+		}
+	}
+	return editorApps.MatchString(appName)
+}
+
+// IsEditorCommand indique si la commande au premier plan d'un terminal est
+// un éditeur (vim, nvim, hx, emacs -nw...)
+func IsEditorCommand(command string) bool {
+	return editorCommands.MatchString(command)
+}
@@ -0,0 +1,128 @@
+package documents
+
+import (
+	"path"
+	"strings"
+)
+
+// fileLanguages associe des noms de fichiers sans extension parlante à leur langage
+var fileLanguages = map[string]string{
+	"Makefile":       "Makefile",
+	"GNUmakefile":    "Makefile",
+	"Dockerfile":     "Dockerfile",
+	"Containerfile":  "Dockerfile",
+	"CMakeLists.txt": "CMake",
+	"Gemfile":        "Ruby",
+	"Rakefile":       "Ruby",
+	"Jenkinsfile":    "Groovy",
+	"Vagrantfile":    "Ruby",
+	"go.mod":         "Go Module",
+	"go.sum":         "Go Module",
+	".bashrc":        "Shell",
+	".zshrc":         "Shell",
+	".vimrc":         "Vim Script",
+}
+
+// extensionLanguages associe les extensions (minuscules, sans point) à leur langage
+var extensionLanguages = map[string]string{
+	"go":         "Go",
+	"py":         "Python",
+	"pyi":        "Python",
+	"ipynb":      "Jupyter Notebook",
+	"js":         "JavaScript",
+	"mjs":        "JavaScript",
+	"cjs":        "JavaScript",
+	"jsx":        "JavaScript",
+	"ts":         "TypeScript",
+	"mts":        "TypeScript",
+	"cts":        "TypeScript",
+	"tsx":        "TypeScript",
+	"rs":         "Rust",
+	"java":       "Java",
+	"kt":         "Kotlin",
+	"kts":        "Kotlin",
+	"scala":      "Scala",
+	"groovy":     "Groovy",
+	"gradle":     "Groovy",
+	"swift":      "Swift",
+	"m":          "Objective-C",
+	"mm":         "Objective-C",
+	"c":          "C",
+	"h":          "C",
+	"cpp":        "C++",
+	"cc":         "C++",
+	"cxx":        "C++",
+	"hpp":        "C++",
+	"hh":         "C++",
+	"cs":         "C#",
+	"fs":         "F#",
+	"vb":         "Visual Basic",
+	"rb":         "Ruby",
+	"php":        "PHP",
+	"pl":         "Perl",
+	"lua":        "Lua",
+	"dart":       "Dart",
+	"ex":         "Elixir",
+	"exs":        "Elixir",
+	"erl":        "Erlang",
+	"hs":         "Haskell",
+	"ml":         "OCaml",
+	"clj":        "Clojure",
+	"cljs":       "Clojure",
+	"r":          "R",
+	"jl":         "Julia",
+	"zig":        "Zig",
+	"nim":        "Nim",
+	"sh":         "Shell",
+	"bash":       "Shell",
+	"zsh":        "Shell",
+	"fish":       "Fish",
+	"ps1":        "PowerShell",
+	"bat":        "Batch",
+	"sql":        "SQL",
+	"html":       "HTML",
+	"htm":        "HTML",
+	"css":        "CSS",
+	"scss":       "SCSS",
+	"sass":       "SCSS",
+	"less":       "Less",
+	"vue":        "Vue",
+	"svelte":     "Svelte",
+	"astro":      "Astro",
+	"json":       "JSON",
+	"jsonc":      "JSON",
+	"yaml":       "YAML",
+	"yml":        "YAML",
+	"toml":       "TOML",
+	"ini":        "INI",
+	"xml":        "XML",
+	"md":         "Markdown",
+	"markdown":   "Markdown",
+	"mdx":        "Markdown",
+	"rst":        "reStructuredText",
+	"tex":        "TeX",
+	"txt":        "Text",
+	"csv":        "CSV",
+	"proto":      "Protocol Buffers",
+	"graphql":    "GraphQL",
+	"gql":        "GraphQL",
+	"tf":         "HCL",
+	"hcl":        "HCL",
+	"nix":        "Nix",
+	"vim":        "Vim Script",
+	"el":         "Emacs Lisp",
+	"org":        "Org",
+	"dockerfile": "Dockerfile",
+}
+
+// Language retourne le langage d'un nom de fichier, "" s'il n'est pas reconnu
+func Language(file string) string {
+	if lang, ok := fileLanguages[file]; ok {
+		return lang
+	}
+	ext := path.Ext(file)
+	if ext == "" || ext == file {
+		return ""
+	}
+	return extensionLanguages[strings.ToLower(ext[1:])]
+}
@@ -0,0 +1,124 @@
+// Package domains regroupe les URL par site : leur domaine enregistrable
+// (google.com pour docs.google.com, bbc.co.uk pour www.bbc.co.uk) d'après la
+// liste des suffixes publics, suivi des premiers segments du chemin pour les
+// domaines qui hébergent les sites de plusieurs organisations
+// (github.com/golang).
+package domains
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// MaxSegments est le nombre maximal de segments de chemin d'une règle
+const MaxSegments = 3
+
+// SiteRule regroupe les URL d'un domaine par les premiers segments de leur chemin
+type SiteRule struct {
+	Domain   string `json:"domain" doc:"Domaine enregistrable (github.com)"`
+	Segments int    `json:"segments" doc:"Segments de chemin ajoutés au site, de 1 à 3 (1 = github.com/<org>)"`
+}
+
+// Defaults retourne les règles de regroupement par défaut : les forges,
+// où chaque organisation est un site
+func Defaults() []SiteRule {
+	return []SiteRule{
+		{Domain: "github.com", Segments: 1},
+		{Domain: "gitlab.com", Segments: 1},
+		{Domain: "bitbucket.org", Segments: 1},
+		{Domain: "codeberg.org", Segments: 1},
+		{Domain: "huggingface.co", Segments: 1},
+	}
+}
+
+// Site est le regroupement d'une URL
+type Site struct {
+	Name   string // domaine enregistrable, suivi des segments d'une règle (github.com/golang)
+	Domain string // domaine enregistrable (google.com)
+	Host   string // hôte sans www. (docs.google.com)
+	Path   string // chemin, sans paramètres ("/" par défaut)
+}
+
+// Grouper applique des règles de regroupement compilées
+type Grouper struct {
+	segments map[string]int
+}
+
+// Compile valide les règles ; une règle par domaine au plus
+func Compile(rules []SiteRule) (*Grouper, error) {
+	g := &Grouper{segments: make(map[string]int, len(rules))}
+	for _, r := range rules {
+		domain := strings.ToLower(strings.TrimSpace(r.Domain))
+		if domain == "" {
+			return nil, fmt.Errorf("règle de site sans domaine")
+		}
+		if r.Segments < 1 || r.Segments > MaxSegments {
+			return nil, fmt.Errorf("règle %s: segments doit être entre 1 et %d", domain, MaxSegments)
+		}
+		if _, ok := g.segments[domain]; ok {
+			return nil, fmt.Errorf("règle %s: domaine en double", domain)
+		}
+		g.segments[domain] = r.Segments
+	}
+	return g, nil
+}
+
+// MustCompile compile des règles sûres, comme celles de Defaults
+func MustCompile(rules []SiteRule) *Grouper {
+	g, err := Compile(rules)
+	if err != nil {
+		panic(err)
+	}
+	return g
+}
+
+// Parse retourne le site d'une URL http(s) ; false pour les autres pages
+// (nouvel onglet, about:, fichiers locaux)
+func (g *Grouper) Parse(rawURL string) (Site, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return Site{}, false
+	}
+
+	site := Site{
+		Host: strings.TrimPrefix(strings.ToLower(u.Hostname()), "www."),
+		Path: u.EscapedPath(),
+	}
+	if site.Path == "" {
+		site.Path = "/"
+	}
+	site.Domain = Registrable(site.Host)
+	site.Name = site.Domain
+
+	if n := g.segments[site.Domain]; n > 0 {
+		var segments []string
+		for _, s := range strings.Split(strings.Trim(site.Path, "/"), "/") {
+			if s != "" && len(segments) < n {
+				segments = append(segments, s)
+			}
+		}
+		if len(segments) > 0 {
+			site.Name += "/" + strings.ToLower(strings.Join(segments, "/"))
+		}
+	}
+	return site, true
+}
+
+// Registrable retourne le domaine enregistrable d'un hôte (eTLD+1). Les
+// adresses IP, localhost et les hôtes qui sont eux-mêmes un suffixe public
+// sont retournés tels quels.
+func Registrable(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if net.ParseIP(host) != nil {
+		return host
+	}
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host
+	}
+	return domain
+}
@@ -0,0 +1,29 @@
+package entities
+
+// repoPaths sont les premiers segments d'URL GitHub / GitLab qui ne
+// désignent pas un propriétaire de dépôt
+const repoPaths = `^(orgs|settings|notifications|pulls|issues|marketplace|explore|topics|users|dashboard|login|new|search|sponsors|features|codespaces|-)/`
+
+// Defaults retourne les motifs d'extraction par défaut
+func Defaults() []Pattern {
+	return []Pattern{
+		// Tickets Jira, Linear, YouTrack... : "PROJ-1234" (sauf UTF-8, ISO-8859, GPT-4...)
+		{Kind: KindTicket, Field: "title", Pattern: `\b([A-Z][A-Z0-9]{1,9}-[1-9][0-9]{0,6})\b`,
+			Exclude: `^(UTF|ISO|SHA|RFC|CVE|WIN|GPT|COVID|MP|X|ES)-`},
+		{Kind: KindTicket, Field: "url", Pattern: `(?:/browse/|selectedIssue=|/issue/)([A-Za-z][A-Za-z0-9]{1,9}-[1-9][0-9]{0,6})\b`},
+
+		// Dépôts : URL GitHub / GitLab, titres "owner/repo: ...", "Issues · owner/repo",
+		// "Merge requests · owner / repo · GitLab"
+		{Kind: KindRepo, Field: "url", Pattern: `^https?://(?:www\.)?(?:github|gitlab)\.com/([\w.-]+/[\w.-]+)`, Exclude: repoPaths},
+		{Kind: KindRepo, Field: "title", Pattern: `(?:^|· )([A-Za-z0-9][\w.-]*(?: / |/)[A-Za-z0-9][\w.-]*)(?:$|: | · )`},
+
+		// Pull requests : "Pull Request #567", "PR #567", URL .../pull/567
+		{Kind: KindPR, Field: "title", Pattern: `(?i)\b(?:pull request|PR)\s*#(\d+)`},
+		{Kind: KindPR, Field: "url", Pattern: `github\.com/([\w.-]+/[\w.-]+)/pull/(\d+)`, Format: "$1#$2"},
+
+		// Merge requests : "MR !89", "Titre (!89) · Merge requests", URL .../-/merge_requests/89
+		{Kind: KindMR, Field: "title", Pattern: `(?i)\b(?:merge request|MR)\s*!(\d+)`},
+		{Kind: KindMR, Field: "title", Pattern: `\(!(\d+)\) · Merge requests`},
+		{Kind: KindMR, Field: "url", Pattern: `gitlab\.com/(.+?)/-/merge_requests/(\d+)`, Format: "$1!$2"},
+	}
+}
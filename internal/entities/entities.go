@@ -0,0 +1,204 @@
+// Package entities extrait des titres de fenêtre et des URL les références
+// de travail qu'ils contiennent : tickets (PROJ-1234), dépôts (owner/repo),
+// pull requests (#567) et merge requests (!89).
+package entities
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Kind est le type d'une entité
+type Kind string
+
+const (
+	KindTicket Kind = "ticket" // clé de ticket, ex. PROJ-1234
+	KindRepo   Kind = "repo"   // dépôt, ex. acme/web
+	KindPR     Kind = "pr"     // pull request, ex. acme/web#567 ou #567
+	KindMR     Kind = "mr"     // merge request, ex. acme/web!89 ou !89
+)
+
+// Kinds sont les types d'entités reconnus
+var Kinds = []Kind{KindTicket, KindRepo, KindPR, KindMR}
+
+// Entity est une référence extraite d'une fenêtre, sous forme normalisée
+type Entity struct {
+	Kind  Kind   `json:"kind"`
+	Value string `json:"value"`
+}
+
+// Pattern décrit comment extraire un type d'entité
+type Pattern struct {
+	Kind    Kind   `json:"kind" doc:"ticket, repo, pr ou mr"`
+	Field   string `json:"field" doc:"title ou url"`
+	Pattern string `json:"pattern" doc:"Expression régulière Go (RE2)"`
+	Format  string `json:"format,omitempty" doc:"Valeur extraite ; $1 ou ${nom} reprennent les groupes (défaut: $1)"`
+	Exclude string `json:"exclude,omitempty" doc:"Expression régulière : les valeurs correspondantes sont écartées"`
+}
+
+type compiledPattern struct {
+	Pattern
+	re      *regexp.Regexp
+	exclude *regexp.Regexp
+}
+
+// Extractor extrait les entités selon une liste de motifs compilés
+type Extractor struct {
+	patterns []compiledPattern
+}
+
+// Validate vérifie un motif d'extraction
+func Validate(p Pattern) error {
+	_, err := compile(p)
+	return err
+}
+
+func compile(p Pattern) (compiledPattern, error) {
+	c := compiledPattern{Pattern: p}
+	if !validKind(p.Kind) {
+		return c, fmt.Errorf("type d'entité inconnu %q (ticket, repo, pr, mr)", p.Kind)
+	}
+	if p.Field != "title" && p.Field != "url" {
+		return c, fmt.Errorf("champ inconnu %q (title, url)", p.Field)
+	}
+	var err error
+	if c.re, err = regexp.Compile(p.Pattern); err != nil {
+		return c, fmt.Errorf("motif %q invalide: %w", p.Pattern, err)
+	}
+	if c.re.NumSubexp() == 0 && p.Format == "" {
+		return c, fmt.Errorf("motif %q: un groupe capturant ou un format est requis", p.Pattern)
+	}
+	if p.Exclude != "" {
+		if c.exclude, err = regexp.Compile(p.Exclude); err != nil {
+			return c, fmt.Errorf("exclusion %q invalide: %w", p.Exclude, err)
+		}
+	}
+	return c, nil
+}
+
+func validKind(k Kind) bool {
+	for _, kind := range Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Compile prépare une liste de motifs d'extraction
+func Compile(ps []Pattern) (*Extractor, error) {
+	e := &Extractor{}
+	for _, p := range ps {
+		c, err := compile(p)
+		if err != nil {
+			return nil, err
+		}
+		e.patterns = append(e.patterns, c)
+	}
+	return e, nil
+}
+
+// MustCompile est Compile pour les motifs par défaut
+func MustCompile(ps []Pattern) *Extractor {
+	e, err := Compile(ps)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// Extract retourne les entités du titre et de l'URL, sans doublon. Les
+// numéros de pull et merge requests sont préfixés par le dépôt quand la
+// fenêtre n'en désigne qu'un.
+func (e *Extractor) Extract(title, url string) []Entity {
+	var found []Entity
+	seen := map[Entity]bool{}
+	for _, p := range e.patterns {
+		text := title
+		if p.Field == "url" {
+			text = url
+		}
+		if text == "" {
+			continue
+		}
+
+		format := p.Format
+		if format == "" {
+			format = "$1"
+		}
+		for _, m := range p.re.FindAllStringSubmatchIndex(text, -1) {
+			value := normalize(p.Kind, string(p.re.ExpandString(nil, format, text, m)))
+			if value == "" || (p.exclude != nil && p.exclude.MatchString(value)) {
+				continue
+			}
+			entity := Entity{Kind: p.Kind, Value: value}
+			if !seen[entity] {
+				seen[entity] = true
+				found = append(found, entity)
+			}
+		}
+	}
+
+	var repos []string
+	for _, entity := range found {
+		if entity.Kind == KindRepo {
+			repos = append(repos, entity.Value)
+		}
+	}
+	if len(repos) != 1 {
+		return found
+	}
+
+	// "#567" → "acme/web#567", sans créer de doublon
+	var entities []Entity
+	qualified := map[Entity]bool{}
+	for _, entity := range found {
+		if (entity.Kind == KindPR || entity.Kind == KindMR) && !strings.Contains(entity.Value, "/") {
+			entity.Value = repos[0] + entity.Value
+		}
+		if !qualified[entity] {
+			qualified[entity] = true
+			entities = append(entities, entity)
+		}
+	}
+	return entities
+}
+
+// normalize met une valeur extraite sous sa forme canonique : tickets en
+// majuscules, dépôts en minuscules, numéros préfixés par # (pr) ou ! (mr)
+func normalize(kind Kind, value string) string {
+	value = strings.TrimSpace(value)
+	switch kind {
+	case KindTicket:
+		return strings.ToUpper(value)
+	case KindRepo:
+		return strings.ToLower(strings.Join(strings.Fields(value), ""))
+	case KindPR, KindMR:
+		marker := "#"
+		if kind == KindMR {
+			marker = "!"
+		}
+		repo, number, ok := strings.Cut(value, marker)
+		if !ok {
+			repo, number = "", value
+		}
+		number = strings.TrimLeft(number, "#!")
+		if number == "" {
+			return ""
+		}
+		return strings.ToLower(strings.Join(strings.Fields(repo), "")) + marker + number
+	}
+	return value
+}
+
+// Sort trie des entités par type puis par valeur
+func Sort(list []Entity) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Kind != list[j].Kind {
+			return list[i].Kind < list[j].Kind
+		}
+		return list[i].Value < list[j].Value
+	})
+}
@@ -4,7 +4,9 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"time"
 
 	"trackmytime/internal/storage"
@@ -18,11 +20,16 @@ func ExportCSV(activities []storage.Activity, filename string) error {
 	}
 	defer file.Close()
 
-	writer := csv.NewWriter(file)
+	return WriteCSV(file, activities)
+}
+
+// WriteCSV écrit les activités au format CSV dans w
+func WriteCSV(w io.Writer, activities []storage.Activity) error {
+	writer := csv.NewWriter(w)
 	defer writer.Flush()
 
 	// Header
-	header := []string{"ID", "App Name", "Window Title", "Process Path", "Start Time", "End Time", "Duration (seconds)", "Is Idle"}
+	header := []string{"ID", "App Name", "Window Title", "Process Path", "Start Time", "End Time", "Duration (seconds)", "Is Idle", "Category", "Productivity", "Project", "Client", "Tags", "URL"}
 	if err := writer.Write(header); err != nil {
 		return fmt.Errorf("erreur écriture header: %w", err)
 	}
@@ -38,6 +45,12 @@ func ExportCSV(activities []storage.Activity, filename string) error {
 			activity.EndTime.Format(time.RFC3339),
 			fmt.Sprintf("%d", activity.DurationSecs),
 			fmt.Sprintf("%t", activity.IsIdle),
+			activity.Category,
+			string(activity.Productivity),
+			activity.Project,
+			activity.Client,
+			strings.Join(activity.Tags, ","),
+			activity.URL,
 		}
 		if err := writer.Write(record); err != nil {
 			return fmt.Errorf("erreur écriture ligne: %w", err)
@@ -55,7 +68,12 @@ func ExportJSON(activities []storage.Activity, filename string) error {
 	}
 	defer file.Close()
 
-	encoder := json.NewEncoder(file)
+	return WriteJSON(file, activities)
+}
+
+// WriteJSON écrit les activités au format JSON dans w
+func WriteJSON(w io.Writer, activities []storage.Activity) error {
+	encoder := json.NewEncoder(w)
 	encoder.SetIndent("", "  ")
 
 	if err := encoder.Encode(activities); err != nil {
@@ -207,7 +225,12 @@ func ExportAggregatedCSV(stats map[string]int64, filename string) error {
 	}
 	defer file.Close()
 
-	writer := csv.NewWriter(file)
+	return WriteAggregatedCSV(file, stats)
+}
+
+// WriteAggregatedCSV écrit les stats agrégées par app au format CSV dans w
+func WriteAggregatedCSV(w io.Writer, stats map[string]int64) error {
+	writer := csv.NewWriter(w)
 	defer writer.Flush()
 
 	// Header
@@ -276,6 +299,11 @@ func ExportAggregatedJSON(stats map[string]int64, filename string) error {
 	}
 	defer file.Close()
 
+	return WriteAggregatedJSON(file, stats)
+}
+
+// WriteAggregatedJSON écrit les stats agrégées au format JSON dans w
+func WriteAggregatedJSON(w io.Writer, stats map[string]int64) error {
 	// Convertir en slice
 	var statsList []AggregatedStat
 	var totalSeconds int64
@@ -310,7 +338,7 @@ func ExportAggregatedJSON(stats map[string]int64, filename string) error {
 		},
 	}
 
-	encoder := json.NewEncoder(file)
+	encoder := json.NewEncoder(w)
 	encoder.SetIndent("", "  ")
 
 	if err := encoder.Encode(result); err != nil {
@@ -319,3 +347,124 @@ func ExportAggregatedJSON(stats map[string]int64, filename string) error {
 
 	return nil
 }
+
+// TagStat est le temps actif d'un tag dans l'export agrégé par tag
+type TagStat struct {
+	Tag          string  `json:"tag"`
+	Activities   int     `json:"activities"`
+	TotalSeconds int64   `json:"total_seconds"`
+	Duration     string  `json:"duration"` // Format HH:MM:SS
+	TotalHours   float64 `json:"total_hours"`
+}
+
+// WriteTagAggregatedCSV écrit le temps actif par tag au format CSV dans w.
+// Pas de ligne de total : une activité portant plusieurs tags compte pour chacun.
+func WriteTagAggregatedCSV(w io.Writer, stats []storage.TagStat) error {
+	writer := csv.NewWriter(w)
+	defer writer.Flush()
+
+	header := []string{"Tag", "Activities", "Duration (HH:MM:SS)", "Total Hours", "Total Seconds"}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("erreur écriture header: %w", err)
+	}
+
+	for _, stat := range stats {
+		record := []string{
+			stat.Tag,
+			fmt.Sprintf("%d", stat.Activities),
+			formatDuration(stat.Seconds),
+			fmt.Sprintf("%.2f", float64(stat.Seconds)/3600.0),
+			fmt.Sprintf("%d", stat.Seconds),
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("erreur écriture ligne: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// WriteTagAggregatedJSON écrit le temps actif par tag au format JSON dans w
+func WriteTagAggregatedJSON(w io.Writer, stats []storage.TagStat) error {
+	tags := make([]TagStat, 0, len(stats))
+	for _, stat := range stats {
+		tags = append(tags, TagStat{
+			Tag:          stat.Tag,
+			Activities:   stat.Activities,
+			TotalSeconds: stat.Seconds,
+			Duration:     formatDuration(stat.Seconds),
+			TotalHours:   float64(stat.Seconds) / 3600.0,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(map[string]interface{}{"tags": tags}); err != nil {
+		return fmt.Errorf("erreur encodage JSON: %w", err)
+	}
+
+	return nil
+}
+
+// SiteStat est le temps actif d'un site dans l'export agrégé par site
+type SiteStat struct {
+	Site         string   `json:"site"`
+	Domain       string   `json:"domain"`
+	Hosts        []string `json:"hosts"`
+	Activities   int      `json:"activities"`
+	TotalSeconds int64    `json:"total_seconds"`
+	Duration     string   `json:"duration"` // Format HH:MM:SS
+	TotalHours   float64  `json:"total_hours"`
+}
+
+// WriteSiteAggregatedCSV écrit le temps actif par site au format CSV dans w
+func WriteSiteAggregatedCSV(w io.Writer, stats []storage.SiteStat) error {
+	writer := csv.NewWriter(w)
+	defer writer.Flush()
+
+	header := []string{"Site", "Domain", "Hosts", "Activities", "Duration (HH:MM:SS)", "Total Hours", "Total Seconds"}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("erreur écriture header: %w", err)
+	}
+
+	for _, stat := range stats {
+		record := []string{
+			stat.Site,
+			stat.Domain,
+			strings.Join(stat.Hosts, ","),
+			fmt.Sprintf("%d", stat.Activities),
+			formatDuration(stat.Seconds),
+			fmt.Sprintf("%.2f", float64(stat.Seconds)/3600.0),
+			fmt.Sprintf("%d", stat.Seconds),
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("erreur écriture ligne: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// WriteSiteAggregatedJSON écrit le temps actif par site au format JSON dans w
+func WriteSiteAggregatedJSON(w io.Writer, stats []storage.SiteStat) error {
+	sites := make([]SiteStat, 0, len(stats))
+	for _, stat := range stats {
+		sites = append(sites, SiteStat{
+			Site:         stat.Site,
+			Domain:       stat.Domain,
+			Hosts:        stat.Hosts,
+			Activities:   stat.Activities,
+			TotalSeconds: stat.Seconds,
+			Duration:     formatDuration(stat.Seconds),
+			TotalHours:   float64(stat.Seconds) / 3600.0,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(map[string]interface{}{"sites": sites}); err != nil {
+		return fmt.Errorf("erreur encodage JSON: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,205 @@
+// Package gitinfo retrouve le dépôt git contenant un dossier : racine, nom
+// du dépôt distant et branche courante. Tout est lu localement dans .git,
+// sans lancer git ni accéder au réseau.
+package gitinfo
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Repo est le dépôt git d'un dossier
+type Repo struct {
+	Root   string // dossier racine du dépôt (celui qui contient .git)
+	Remote string // dépôt distant sans schéma ni identifiants ("github.com/acme/api"), vide sans remote
+	Branch string // branche courante, ou commit abrégé si HEAD est détaché
+}
+
+// Name retourne le nom du dépôt : le distant s'il est connu, sinon la racine
+func (r Repo) Name() string {
+	if r.Remote != "" {
+		return r.Remote
+	}
+	return r.Root
+}
+
+// Lookup retourne le dépôt contenant dir ; false si dir n'est dans aucun dépôt
+func Lookup(dir string) (Repo, bool) {
+	if dir == "" {
+		return Repo{}, false
+	}
+	root, gitDir, ok := findGitDir(filepath.Clean(dir))
+	if !ok {
+		return Repo{}, false
+	}
+
+	repo := Repo{Root: root, Branch: readHead(gitDir)}
+	// Les worktrees partagent la configuration du dépôt principal
+	commonDir := gitDir
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = resolve(gitDir, strings.TrimSpace(string(data)))
+	}
+	if url := readRemoteURL(filepath.Join(commonDir, "config")); url != "" {
+		repo.Remote = RemoteName(url)
+	}
+	return repo, true
+}
+
+// findGitDir remonte depuis dir jusqu'au dossier contenant .git et retourne
+// ce dossier et le répertoire git (différent pour les worktrees et sous-modules)
+func findGitDir(dir string) (root, gitDir string, ok bool) {
+	for {
+		path := filepath.Join(dir, ".git")
+		if info, err := os.Stat(path); err == nil {
+			if info.IsDir() {
+				return dir, path, true
+			}
+			// Fichier "gitdir: <chemin>" des worktrees et sous-modules
+			if data, err := os.ReadFile(path); err == nil {
+				if target, found := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: "); found {
+					return dir, resolve(dir, target), true
+				}
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", false
+		}
+		dir = parent
+	}
+}
+
+// resolve rend path absolu par rapport à base
+func resolve(base, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(base, path)
+}
+
+// readHead retourne la branche pointée par HEAD, ou les 7 premiers
+// caractères du commit si HEAD est détaché
+func readHead(gitDir string) string {
+	data, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	head := strings.TrimSpace(string(data))
+	if ref, found := strings.CutPrefix(head, "ref: "); found {
+		return strings.TrimPrefix(ref, "refs/heads/")
+	}
+	if len(head) > 7 {
+		head = head[:7]
+	}
+	return head
+}
+
+// readRemoteURL retourne l'URL du remote origin du fichier de configuration,
+// à défaut celle du premier remote déclaré
+func readRemoteURL(configPath string) string {
+	f, err := os.Open(configPath)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	var section, first string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			section = line
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || !strings.HasPrefix(section, `[remote "`) || !strings.EqualFold(strings.TrimSpace(key), "url") {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		if section == `[remote "origin"]` {
+			return value
+		}
+		if first == "" {
+			first = value
+		}
+	}
+	return first
+}
+
+// RemoteName normalise l'URL d'un remote en retirant schéma, identifiants,
+// port et suffixe .git : "git@github.com:acme/api.git" et
+// "https://user@github.com/acme/api" donnent tous deux "github.com/acme/api"
+func RemoteName(url string) string {
+	name := strings.TrimSpace(url)
+	if scheme, rest, ok := strings.Cut(name, "://"); ok {
+		if scheme == "file" {
+			name = rest
+		} else {
+			host, path, _ := strings.Cut(rest, "/")
+			host = host[strings.LastIndexByte(host, '@')+1:]
+			if h, _, err := net.SplitHostPort(host); err == nil {
+				host = h
+			}
+			name = host + "/" + path
+		}
+	} else if i := strings.IndexByte(name, ':'); i > 1 && !strings.ContainsAny(name[:i], `/\`) {
+		// Syntaxe scp : [utilisateur@]hôte:chemin
+		host := name[strings.LastIndexByte(name[:i], '@')+1 : i]
+		name = host + "/" + strings.TrimPrefix(name[i+1:], "/")
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(name, "/"), ".git")
+}
+
+// Cache mémorise le dépôt de chaque dossier pendant une durée donnée, pour
+// ne relire .git qu'une fois par intervalle (la branche peut changer)
+type Cache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	repo    Repo
+	ok      bool
+	expires time.Time
+}
+
+// maxCacheEntries déclenche la purge des entrées expirées
+const maxCacheEntries = 256
+
+// NewCache crée un cache dont les entrées expirent après ttl
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{ttl: ttl, entries: make(map[string]cacheEntry)}
+}
+
+// Lookup retourne le dépôt contenant dir, depuis le cache s'il est récent
+func (c *Cache) Lookup(dir string) (Repo, bool) {
+	now := time.Now()
+	c.mu.Lock()
+	entry, found := c.entries[dir]
+	c.mu.Unlock()
+	if found && now.Before(entry.expires) {
+		return entry.repo, entry.ok
+	}
+
+	repo, ok := Lookup(dir)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxCacheEntries {
+		for key, e := range c.entries {
+			if !now.Before(e.expires) {
+				delete(c.entries, key)
+			}
+		}
+		if len(c.entries) >= maxCacheEntries {
+			clear(c.entries)
+		}
+	}
+	c.entries[dir] = cacheEntry{repo: repo, ok: ok, expires: now.Add(c.ttl)}
+	return repo, ok
+}
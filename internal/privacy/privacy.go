@@ -0,0 +1,168 @@
+// Package privacy protège les données des fenêtres avant leur enregistrement.
+// Des règles, évaluées dans l'ordre, excluent l'activité, ne gardent que
+// l'application, masquent des passages du titre ou remplacent le titre par
+// son empreinte. Les fenêtres de navigation privée ne gardent jamais leur
+// titre.
+package privacy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"trackmytime/internal/rules"
+)
+
+// Action est le traitement appliqué à une fenêtre par une règle
+type Action string
+
+const (
+	ActionDrop    Action = "drop"     // ne pas enregistrer l'activité
+	ActionAppOnly Action = "app_only" // ne garder que l'application
+	ActionRedact  Action = "redact"   // masquer les passages du titre correspondant à Pattern
+	ActionHash    Action = "hash"     // remplacer le titre par son empreinte
+)
+
+// Actions sont les actions reconnues
+var Actions = []Action{ActionDrop, ActionAppOnly, ActionRedact, ActionHash}
+
+// Redacted remplace les passages masqués du titre
+const Redacted = "[masqué]"
+
+// PrivateRule est le nom de la décision appliquée aux fenêtres de
+// navigation privée
+const PrivateRule = "navigation privée"
+
+// Rule est une règle de confidentialité ; toutes ses conditions doivent être
+// vraies
+type Rule struct {
+	Name       string            `json:"name"`
+	Conditions []rules.Condition `json:"conditions" doc:"Mêmes conditions que les règles d'enrichissement, sauf enriched ; aucune = toutes les fenêtres"`
+	Action     Action            `json:"action" doc:"drop, app_only, redact ou hash"`
+	Pattern    string            `json:"pattern,omitempty" doc:"Expression régulière des passages du titre à masquer (redact)"`
+}
+
+// Decision est le traitement appliqué à une fenêtre
+type Decision struct {
+	Action Action // vide si aucune règle ne s'applique
+	Rule   string // nom de la règle appliquée, ou PrivateRule
+}
+
+// Policy évalue une liste ordonnée de règles compilées
+type Policy struct {
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	Rule
+	matcher *rules.Matcher
+	redact  *regexp.Regexp
+}
+
+// Compile valide les règles et prépare leur évaluation
+func Compile(rs []Rule) (*Policy, error) {
+	p := &Policy{}
+	for i, r := range rs {
+		name := r.Name
+		if strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("règle de confidentialité %d: nom requis", i+1)
+		}
+		for _, cond := range r.Conditions {
+			if cond.Field == rules.FieldEnriched {
+				return nil, fmt.Errorf("règle %s: le champ enriched n'est pas encore calculé", name)
+			}
+		}
+		matcher, err := rules.CompileConditions(name, r.Conditions)
+		if err != nil {
+			return nil, err
+		}
+
+		c := compiledRule{Rule: r, matcher: matcher}
+		switch r.Action {
+		case ActionDrop, ActionAppOnly, ActionHash:
+		case ActionRedact:
+			if r.Pattern == "" {
+				return nil, fmt.Errorf("règle %s: pattern requis pour redact", name)
+			}
+			if c.redact, err = regexp.Compile(r.Pattern); err != nil {
+				return nil, fmt.Errorf("règle %s: pattern %q invalide: %w", name, r.Pattern, err)
+			}
+		default:
+			return nil, fmt.Errorf("règle %s: action inconnue %q (drop, app_only, redact, hash)", name, r.Action)
+		}
+		p.rules = append(p.rules, c)
+	}
+	return p, nil
+}
+
+// MustCompile compile des règles connues pour être valides
+func MustCompile(rs []Rule) *Policy {
+	p, err := Compile(rs)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Apply protège la fenêtre décrite par in. Une fenêtre de navigation privée
+// ne garde que l'application ; sinon la première règle dont les conditions
+// sont vraies s'applique. in est modifié en place, sauf pour ActionDrop :
+// l'activité ne doit alors pas être enregistrée.
+func (p *Policy) Apply(in *rules.Input) Decision {
+	if IsPrivateWindow(in.WindowTitle) {
+		appOnly(in)
+		return Decision{Action: ActionAppOnly, Rule: PrivateRule}
+	}
+
+	for _, r := range p.rules {
+		if !r.matcher.Match(*in) {
+			continue
+		}
+		switch r.Action {
+		case ActionAppOnly:
+			appOnly(in)
+		case ActionRedact:
+			in.WindowTitle = r.redact.ReplaceAllString(in.WindowTitle, Redacted)
+		case ActionHash:
+			in.WindowTitle = HashTitle(in.WindowTitle)
+		}
+		return Decision{Action: r.Action, Rule: r.Name}
+	}
+	return Decision{}
+}
+
+// appOnly efface tout ce qui décrit le contenu de la fenêtre
+func appOnly(in *rules.Input) {
+	in.WindowTitle = ""
+	in.URL = ""
+	in.Command = ""
+	in.WorkingDir = ""
+	in.SSHHost = ""
+}
+
+// HashTitle retourne l'empreinte d'un titre : des titres identiques restent
+// regroupables sans être lisibles. Un titre vide ou déjà haché est inchangé.
+func HashTitle(title string) string {
+	if title == "" || strings.HasPrefix(title, hashPrefix) {
+		return title
+	}
+	sum := sha256.Sum256([]byte(title))
+	return hashPrefix + hex.EncodeToString(sum[:8])
+}
+
+const hashPrefix = "sha256:"
+
+// privateTitle reconnaît les titres des fenêtres de navigation privée :
+// Chrome, Chromium et Brave (Incognito, Private), Edge (InPrivate), Firefox
+// (Private Browsing), en anglais, français et allemand
+var privateTitle = regexp.MustCompile(`(?i)\((incognito|private|navigation privée|inkognito)\)|\binprivate\b|private browsing|navigation privée|privater modus`)
+
+// IsPrivateWindow indique si le titre est celui d'une fenêtre de navigation
+// privée. L'application n'est pas vérifiée : sous Linux, le nom du processus
+// (firefox, chrome) ne désigne pas toujours un navigateur connu, et un faux
+// positif ne coûte que le titre.
+func IsPrivateWindow(title string) bool {
+	return privateTitle.MatchString(title)
+}
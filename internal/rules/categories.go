@@ -0,0 +1,112 @@
+package rules
+
+import "fmt"
+
+// Productivity classe le temps passé dans une catégorie
+type Productivity string
+
+const (
+	Productive  Productivity = "productive"
+	Neutral     Productivity = "neutral"
+	Distracting Productivity = "distracting"
+)
+
+// Weight est la contribution au score de productivité (0 à 1)
+func (p Productivity) Weight() float64 {
+	switch p {
+	case Productive:
+		return 1
+	case Distracting:
+		return 0
+	default:
+		return 0.5
+	}
+}
+
+// ParseProductivity valide un niveau de productivité
+func ParseProductivity(s string) (Productivity, error) {
+	switch p := Productivity(s); p {
+	case Productive, Neutral, Distracting:
+		return p, nil
+	}
+	return "", fmt.Errorf("productivité invalide %q (productive, neutral, distracting)", s)
+}
+
+// Category associe une catégorie d'activité à son niveau de productivité
+type Category struct {
+	Name         string       `json:"name"`
+	Productivity Productivity `json:"productivity" doc:"productive, neutral ou distracting"`
+}
+
+// Uncategorized est le nom affiché pour les activités sans catégorie (neutres)
+const Uncategorized = "Non classé"
+
+// Catégories par défaut, attribuées par les règles par défaut
+const (
+	CategoryDevelopment   = "Développement"
+	CategoryCommunication = "Communication"
+	CategoryMeetings      = "Réunions"
+	CategoryDocs          = "Documentation"
+	CategoryPlanning      = "Gestion de projet"
+	CategoryAI            = "IA"
+	CategoryMusic         = "Musique"
+	CategoryEntertainment = "Divertissement"
+	CategorySocial        = "Réseaux sociaux"
+)
+
+// DefaultCategories retourne les catégories installées au premier démarrage
+func DefaultCategories() []Category {
+	return []Category{
+		{CategoryDevelopment, Productive},
+		{CategoryDocs, Productive},
+		{CategoryPlanning, Productive},
+		{CategoryAI, Productive},
+		{CategoryMeetings, Productive},
+		{CategoryCommunication, Neutral},
+		{CategoryMusic, Neutral},
+		{CategoryEntertainment, Distracting},
+		{CategorySocial, Distracting},
+	}
+}
+
+// categoryRules classent les applications et les sites reconnus par les
+// règles de nom. Réunions passe avant Communication pour Teams.
+func categoryRules() []Rule {
+	var rs ruleList
+
+	byApp := func(category, pattern string) {
+		rs.add("Catégorie "+category+" (applications)", Action{Category: category},
+			Condition{Field: FieldApp, Match: MatchRegex, Pattern: pattern})
+	}
+	bySite := func(category, pattern string) {
+		rs.add("Catégorie "+category+" (sites)", Action{Category: category},
+			Condition{Field: FieldApp, Match: MatchRegex, Pattern: browserApps},
+			Condition{Field: FieldEnriched, Match: MatchRegex, Pattern: pattern})
+	}
+
+	byApp(CategoryMeetings, `(?i)zoom|facetime|webex|microsoft teams|^teams`)
+	bySite(CategoryMeetings, `^(Google Meet|Zoom|Microsoft Teams)$`)
+
+	byApp(CategoryDevelopment, `(?i)^(code|cursor|vscodium|electron|goland|intellij|pycharm|webstorm|phpstorm|rider|clion|android studio|xcode|sublime|zed|vim|nvim|gvim|emacs|terminal|iterm|warp|alacritty|kitty|wezterm|ghostty|gnome-terminal|konsole|xterm|windowsterminal|powershell|cmd|docker|postman|insomnia|dbeaver|tableplus|github desktop|sourcetree|fork)`)
+	bySite(CategoryDevelopment, `^(GitHub|GitLab|Bitbucket|Stack Overflow|MDN|Go Packages|Docker Hub|Vercel|Netlify|Sentry|Grafana|Datadog|Postman|Google Cloud|AWS)$`)
+
+	byApp(CategoryCommunication, `(?i)slack|discord|mail|outlook|thunderbird|telegram|whatsapp|signal|messages|messenger|mattermost|element`)
+	bySite(CategoryCommunication, `^(Gmail|Slack|Discord|Outlook|WhatsApp|Messenger)$`)
+
+	byApp(CategoryDocs, `(?i)notion|obsidian|logseq|word|pages|libreoffice|excel|numbers|keynote|powerpoint|preview|acrobat|evince|okular`)
+	bySite(CategoryDocs, `^(Notion|Google Drive|Confluence|Wikipedia|Medium|DEV Community|Figma|Miro)$`)
+
+	byApp(CategoryPlanning, `(?i)jira|linear|trello|asana|todoist|things|omnifocus`)
+	bySite(CategoryPlanning, `^(Jira|Linear|Trello|Asana|Google Calendar)$`)
+
+	byApp(CategoryAI, `(?i)chatgpt|claude`)
+	bySite(CategoryAI, `^(ChatGPT|Claude)$`)
+
+	byApp(CategoryMusic, `(?i)spotify|deezer|music|rhythmbox`)
+	bySite(CategoryMusic, `^(Spotify)$`)
+
+	bySite(CategoryEntertainment, `^(YouTube|Twitch|Netflix)$`)
+	bySite(CategorySocial, `^(X|TikTok|Instagram|Facebook|LinkedIn|Reddit|Hacker News)$`)
+
+	return rs
+}
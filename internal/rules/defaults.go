@@ -0,0 +1,150 @@
+package rules
+
+import "regexp"
+
+// Applications reconnues par les règles par défaut (correspondance sur une
+// partie du nom, sensible à la casse comme le suivi historique)
+const (
+	browserApps  = `Brave Browser|Google Chrome|Safari|Firefox|Microsoft Edge|Arc|Zen Browser|Zen|Opera|Vivaldi|Chromium|Waterfox|LibreWolf`
+	electronApps = `Electron|Code|Visual Studio Code|Cursor|VSCodium`
+)
+
+// browserApp reconnaît les navigateurs comme les règles par défaut
+var browserApp = regexp.MustCompile(browserApps)
+
+// IsBrowser indique si une application est un navigateur
+func IsBrowser(app string) bool {
+	return browserApp.MatchString(app)
+}
+
+// OtherSites est le nom enrichi de repli des onglets dont le site n'a pas
+// pu être déduit avec assez de confiance
+const OtherSites = "Autres"
+
+// site décrit un site reconnu dans le titre d'un onglet
+type site struct {
+	name     string
+	patterns []string // sous-chaînes du titre, insensibles à la casse
+}
+
+// defaultSites est la liste historique des sites reconnus, dans l'ordre de test
+var defaultSites = []site{
+	{"YouTube", []string{"youtube"}},
+	{"Twitch", []string{"twitch"}},
+	{"TikTok", []string{"tiktok"}},
+	{"Gmail", []string{"gmail", "inbox"}},
+	{"GitHub", []string{"github"}},
+	{"LinkedIn", []string{"linkedin"}},
+	{"Reddit", []string{"reddit"}},
+	{"Instagram", []string{"instagram"}},
+	{"Facebook", []string{"facebook"}},
+	{"Discord", []string{"discord"}},
+	{"Slack", []string{"slack"}},
+	{"Notion", []string{"notion"}},
+	{"Google Drive", []string{"google drive", "google docs", "google sheets"}},
+	{"Stack Overflow", []string{"stack overflow"}},
+	{"ChatGPT", []string{"chatgpt"}},
+	{"Claude", []string{"claude"}},
+	{"Netflix", []string{"netflix"}},
+	{"Spotify", []string{"spotify"}},
+}
+
+// DefaultsVersion est la version courante des règles par défaut. Les règles
+// apparues depuis la version installée dans une base sont ajoutées à la suite.
+const DefaultsVersion = 3
+
+// Defaults retourne les règles installées au premier démarrage
+func Defaults() []Rule {
+	return DefaultsSince(0)
+}
+
+// DefaultsSince retourne les règles par défaut apparues après la version donnée
+func DefaultsSince(version int) []Rule {
+	var rs []Rule
+	if version < 1 {
+		rs = append(rs, siteAndProjectRules()...)
+	}
+	if version < 2 {
+		rs = append(rs, categoryRules()...)
+	}
+	if version < 3 {
+		rs = append(rs, projectRules()...)
+	}
+	for i := range rs {
+		rs[i].Position = i + 1
+	}
+	return rs
+}
+
+// ruleList aide à construire une liste de règles actives
+type ruleList []Rule
+
+func (l *ruleList) add(name string, action Action, conditions ...Condition) {
+	*l = append(*l, Rule{
+		Name:       name,
+		Enabled:    true,
+		Conditions: conditions,
+		Action:     action,
+	})
+}
+
+// editorProjects extraient le nom du projet ouvert des titres d'éditeur
+var editorProjects = []struct{ name, pattern string }{
+	// "fichier.md — TrackMyTime — Perso" → "TrackMyTime"
+	{"fichier — projet — espace", `^.*? — (.*?) — `},
+	// "TrackMyTime — Perso" → "TrackMyTime"
+	{"projet — espace générique", `^(.*?) — \s*(?i:Perso|Workspace|Visual Studio Code)\s*$`},
+	// "fichier.py — my-project" → "my-project"
+	{"fichier — projet", `^.*? — (.+)$`},
+	// "[TrackMyTime] fichier.md" → "TrackMyTime"
+	{"[projet]", `^\[([^\]]+)\]`},
+}
+
+// siteAndProjectRules reproduisent la détection historique : site pour les
+// navigateurs (déduit du titre, sinon "Autres") et projet pour les éditeurs
+// (titres "fichier — projet — espace")
+func siteAndProjectRules() []Rule {
+	var rs ruleList
+	add := rs.add
+	browser := Condition{Field: FieldApp, Match: MatchRegex, Pattern: browserApps}
+	editor := Condition{Field: FieldApp, Match: MatchRegex, Pattern: electronApps}
+
+	// X (Twitter) : "Nom sur X : ...", "Accueil / X", "(3) X"
+	add("Site X", Action{EnrichedName: "X"}, browser,
+		Condition{Field: FieldTitle, Match: MatchRegex, Pattern: `(?i)( sur x |accueil / x| / x$| / x [-–] |\) x)`})
+
+	for _, s := range defaultSites {
+		pattern := "(?i)"
+		for i, p := range s.patterns {
+			if i > 0 {
+				pattern += "|"
+			}
+			pattern += regexp.QuoteMeta(p)
+		}
+		add("Site "+s.name, Action{EnrichedName: s.name}, browser,
+			Condition{Field: FieldTitle, Match: MatchRegex, Pattern: pattern})
+	}
+
+	// Autres sites : déduits du titre, sinon regroupés sous "Autres"
+	add("Autres sites", Action{EnrichedName: OtherSites, InferSite: true}, browser,
+		Condition{Field: FieldTitle, Match: MatchRegex, Pattern: `(?s).`})
+
+	for _, p := range editorProjects {
+		add("Projet éditeur ("+p.name+")", Action{EnrichedName: "$1"}, editor,
+			Condition{Field: FieldTitle, Match: MatchRegex, Pattern: p.pattern})
+	}
+
+	return rs
+}
+
+// projectRules attribuent au projet le temps passé dans l'espace de travail
+// d'un éditeur ; le projet est créé automatiquement à la première activité
+func projectRules() []Rule {
+	var rs ruleList
+	editor := Condition{Field: FieldApp, Match: MatchRegex, Pattern: electronApps}
+	for _, p := range editorProjects {
+		rs.add("Attribution projet éditeur ("+p.name+")", Action{Project: "$1"}, editor,
+			Condition{Field: FieldTitle, Match: MatchRegex, Pattern: p.pattern})
+	}
+	return rs
+}
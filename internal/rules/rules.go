@@ -0,0 +1,375 @@
+// Package rules évalue les règles d'enrichissement des activités. Chaque règle
+// associe des conditions (application, titre, chemin du processus, URL,
+// commande d'un terminal) à une action (nom enrichi, catégorie, projet, tags,
+// inactivité ou exclusion).
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Field est la donnée de la fenêtre testée par une condition
+type Field string
+
+const (
+	FieldApp      Field = "app"      // nom de l'application
+	FieldTitle    Field = "title"    // titre de la fenêtre
+	FieldPath     Field = "path"     // chemin de l'exécutable
+	FieldURL      Field = "url"      // URL de l'onglet actif (navigateurs)
+	FieldEnriched Field = "enriched" // nom enrichi fixé par les règles précédentes
+	FieldCommand  Field = "command"  // commande au premier plan d'un terminal
+	FieldCwd      Field = "cwd"      // dossier courant de la commande
+	FieldSSHHost  Field = "ssh_host" // hôte distant si la commande est ssh
+)
+
+// MatchType est la façon de comparer le champ au motif
+type MatchType string
+
+const (
+	MatchContains MatchType = "contains" // sous-chaîne, insensible à la casse
+	MatchEquals   MatchType = "equals"   // égalité, insensible à la casse
+	MatchGlob     MatchType = "glob"     // * et ?, insensible à la casse
+	MatchRegex    MatchType = "regex"    // expression régulière Go (RE2)
+)
+
+// Condition teste un champ de la fenêtre
+type Condition struct {
+	Field   Field     `json:"field" doc:"app, title, path, url, enriched, command, cwd ou ssh_host"`
+	Match   MatchType `json:"match" doc:"contains, equals, glob ou regex"`
+	Pattern string    `json:"pattern"`
+	Negate  bool      `json:"negate,omitempty" doc:"La condition est vraie si le champ ne correspond pas"`
+}
+
+// Action décrit ce qu'une règle applique à l'activité. Chaque champ n'est
+// fixé que par la première règle qui le renseigne ; les tags s'accumulent.
+type Action struct {
+	EnrichedName string   `json:"enriched_name,omitempty" doc:"Nom enrichi ; $1 ou ${nom} reprennent les groupes de la dernière condition regex"`
+	Category     string   `json:"category,omitempty"`
+	Project      string   `json:"project,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	Idle         bool     `json:"idle,omitempty" doc:"Compter l'activité comme de l'inactivité"`
+	Ignore       bool     `json:"ignore,omitempty" doc:"Ne pas enregistrer l'activité et arrêter l'évaluation"`
+	InferSite    bool     `json:"infer_site,omitempty" doc:"Déduire le site du titre ou de l'URL ; enriched_name sert de repli si la confiance est faible"`
+}
+
+// Rule est une règle d'enrichissement ; toutes ses conditions doivent être vraies
+type Rule struct {
+	ID         int64       `json:"id"`
+	Name       string      `json:"name"`
+	Position   int         `json:"position" doc:"Ordre d'évaluation croissant"`
+	Enabled    bool        `json:"enabled"`
+	Conditions []Condition `json:"conditions"`
+	Action     Action      `json:"action"`
+}
+
+// Input contient les données de la fenêtre soumises aux règles
+type Input struct {
+	AppName     string
+	WindowTitle string
+	ProcessPath string
+	URL         string
+	Command     string
+	WorkingDir  string
+	SSHHost     string
+}
+
+// Result est le résultat de l'évaluation des règles sur une fenêtre
+type Result struct {
+	EnrichedName string     `json:"enriched_name" doc:"Nom de l'application si aucune règle ne le fixe"`
+	Category     string     `json:"category,omitempty"`
+	Project      string     `json:"project,omitempty"`
+	Tags         []string   `json:"tags,omitempty"`
+	Idle         bool       `json:"idle,omitempty"`
+	Ignored      bool       `json:"ignored,omitempty"`
+	Site         *SiteGuess `json:"site,omitempty" doc:"Site déduit par une règle infer_site, même sous le seuil de confiance"`
+	Matched      []string   `json:"matched,omitempty" doc:"Noms des règles appliquées, dans l'ordre"`
+	MatchedIDs   []int64    `json:"matched_ids,omitempty" doc:"Identifiants des règles appliquées (0 pour une règle non enregistrée)"`
+}
+
+// Engine évalue une liste ordonnée de règles compilées
+type Engine struct {
+	rules []compiledRule
+	sites *SiteMemory
+}
+
+type compiledRule struct {
+	rule       Rule
+	conditions []compiledCondition
+}
+
+type compiledCondition struct {
+	Condition
+	re *regexp.Regexp
+}
+
+// Validate vérifie qu'une règle est complète et que ses motifs compilent
+func Validate(r Rule) error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("règle #%d: nom requis", r.ID)
+	}
+	_, err := compile(r)
+	return err
+}
+
+// Compile prépare les règles actives pour l'évaluation, dans l'ordre donné
+func Compile(rs []Rule) (*Engine, error) {
+	e := &Engine{}
+	for _, r := range rs {
+		if !r.Enabled {
+			continue
+		}
+		c, err := compile(r)
+		if err != nil {
+			return nil, err
+		}
+		e.rules = append(e.rules, c)
+	}
+	return e, nil
+}
+
+// compile valide une règle et compile ses conditions
+func compile(r Rule) (compiledRule, error) {
+	name := r.Name
+	if name == "" {
+		name = fmt.Sprintf("#%d", r.ID)
+	}
+	if len(r.Conditions) == 0 {
+		return compiledRule{}, fmt.Errorf("règle %s: au moins une condition est requise", name)
+	}
+	a := r.Action
+	if a.EnrichedName == "" && a.Category == "" && a.Project == "" && len(a.Tags) == 0 && !a.Idle && !a.Ignore && !a.InferSite {
+		return compiledRule{}, fmt.Errorf("règle %s: action vide", name)
+	}
+
+	c := compiledRule{rule: r}
+	for _, cond := range r.Conditions {
+		cc, err := compileCondition(name, cond)
+		if err != nil {
+			return compiledRule{}, err
+		}
+		c.conditions = append(c.conditions, cc)
+	}
+	return c, nil
+}
+
+// compileCondition valide une condition de la règle name et compile son motif
+func compileCondition(name string, cond Condition) (compiledCondition, error) {
+	switch cond.Field {
+	case FieldApp, FieldTitle, FieldPath, FieldURL, FieldEnriched, FieldCommand, FieldCwd, FieldSSHHost:
+	default:
+		return compiledCondition{}, fmt.Errorf("règle %s: champ inconnu %q (app, title, path, url, enriched, command, cwd, ssh_host)", name, cond.Field)
+	}
+	if cond.Pattern == "" {
+		return compiledCondition{}, fmt.Errorf("règle %s: motif vide", name)
+	}
+
+	cc := compiledCondition{Condition: cond}
+	var err error
+	switch cond.Match {
+	case MatchContains, MatchEquals:
+	case MatchGlob:
+		cc.re, err = regexp.Compile(globToRegexp(cond.Pattern))
+	case MatchRegex:
+		cc.re, err = regexp.Compile(cond.Pattern)
+	default:
+		return compiledCondition{}, fmt.Errorf("règle %s: comparaison inconnue %q (contains, equals, glob, regex)", name, cond.Match)
+	}
+	if err != nil {
+		return compiledCondition{}, fmt.Errorf("règle %s: motif %q invalide: %w", name, cond.Pattern, err)
+	}
+	return cc, nil
+}
+
+// Matcher teste des conditions hors du moteur d'enrichissement, par exemple
+// celles des règles de confidentialité
+type Matcher struct {
+	rule compiledRule
+}
+
+// CompileConditions compile des conditions ; name désigne leur règle dans
+// les erreurs. Sans condition, le Matcher accepte toutes les fenêtres.
+func CompileConditions(name string, conditions []Condition) (*Matcher, error) {
+	m := &Matcher{}
+	for _, cond := range conditions {
+		cc, err := compileCondition(name, cond)
+		if err != nil {
+			return nil, err
+		}
+		m.rule.conditions = append(m.rule.conditions, cc)
+	}
+	return m, nil
+}
+
+// Match indique si la fenêtre vérifie toutes les conditions ; le champ
+// enriched vaut le nom de l'application
+func (m *Matcher) Match(in Input) bool {
+	_, ok := m.rule.match(in, &Result{})
+	return ok
+}
+
+// globToRegexp convertit un motif glob (* et ?) en expression régulière ancrée
+func globToRegexp(pattern string) string {
+	var b strings.Builder
+	b.WriteString("(?is)^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// UseSiteMemory branche la mémoire des sites confirmés utilisée par infer_site
+func (e *Engine) UseSiteMemory(m *SiteMemory) {
+	e.sites = m
+}
+
+// Evaluate applique les règles à la fenêtre décrite par in
+func (e *Engine) Evaluate(in Input) Result {
+	var result Result
+	for _, r := range e.rules {
+		expand, ok := r.match(in, &result)
+		if !ok {
+			continue
+		}
+
+		a := r.rule.Action
+		applied := false
+		if a.InferSite && result.EnrichedName == "" {
+			guess := InferSite(in.WindowTitle, in.URL, e.sites)
+			result.Site = &guess
+			if guess.Name != "" && guess.Confidence >= MinSiteConfidence {
+				result.EnrichedName = guess.Name
+			}
+			applied = true
+		}
+		if a.EnrichedName != "" && result.EnrichedName == "" {
+			if name := strings.TrimSpace(expand(a.EnrichedName)); name != "" {
+				result.EnrichedName = name
+				applied = true
+			}
+		}
+		if a.Category != "" && result.Category == "" {
+			result.Category = a.Category
+			applied = true
+		}
+		if a.Project != "" && result.Project == "" {
+			result.Project = strings.TrimSpace(expand(a.Project))
+			applied = true
+		}
+		for _, tag := range a.Tags {
+			if !containsFold(result.Tags, tag) {
+				result.Tags = append(result.Tags, tag)
+				applied = true
+			}
+		}
+		if a.Idle && !result.Idle {
+			result.Idle = true
+			applied = true
+		}
+		if a.Ignore {
+			result.Ignored = true
+			applied = true
+		}
+
+		if applied {
+			result.Matched = append(result.Matched, r.rule.Name)
+			result.MatchedIDs = append(result.MatchedIDs, r.rule.ID)
+		}
+		if result.Ignored {
+			break
+		}
+	}
+
+	if result.EnrichedName == "" {
+		result.EnrichedName = in.AppName
+	}
+	return result
+}
+
+// match teste les conditions de la règle ; la fonction retournée développe
+// les références aux groupes capturés par la dernière condition regex
+func (r compiledRule) match(in Input, result *Result) (func(string) string, bool) {
+	expand := func(template string) string { return template }
+
+	for _, c := range r.conditions {
+		value := c.value(in, result)
+		matched := false
+
+		switch c.Match {
+		case MatchContains:
+			matched = strings.Contains(strings.ToLower(value), strings.ToLower(c.Pattern))
+		case MatchEquals:
+			matched = strings.EqualFold(value, c.Pattern)
+		case MatchGlob:
+			matched = c.re.MatchString(value)
+		case MatchRegex:
+			if m := c.re.FindStringSubmatchIndex(value); m != nil {
+				matched = true
+				if !c.Negate && c.re.NumSubexp() > 0 {
+					re := c.re
+					expand = func(template string) string {
+						return string(re.ExpandString(nil, template, value, m))
+					}
+				}
+			}
+		}
+
+		if matched == c.Negate {
+			return nil, false
+		}
+	}
+	return expand, true
+}
+
+// value retourne le champ de la fenêtre testé par la condition
+func (c compiledCondition) value(in Input, result *Result) string {
+	switch c.Field {
+	case FieldApp:
+		return in.AppName
+	case FieldTitle:
+		return in.WindowTitle
+	case FieldPath:
+		return in.ProcessPath
+	case FieldURL:
+		return in.URL
+	case FieldCommand:
+		return in.Command
+	case FieldCwd:
+		return in.WorkingDir
+	case FieldSSHHost:
+		return in.SSHHost
+	case FieldEnriched:
+		if result.EnrichedName != "" {
+			return result.EnrichedName
+		}
+		return in.AppName
+	}
+	return ""
+}
+
+func containsFold(values []string, v string) bool {
+	for _, existing := range values {
+		if strings.EqualFold(existing, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// MustCompile compile des règles connues pour être valides (règles par défaut)
+func MustCompile(rs []Rule) *Engine {
+	e, err := Compile(rs)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
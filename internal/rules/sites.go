@@ -0,0 +1,246 @@
+package rules
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	// MinSiteConfidence est la confiance minimale pour retenir un site déduit
+	// plutôt que le nom de repli de la règle ("Autres")
+	MinSiteConfidence = 0.7
+
+	// SiteConfirmations est le nombre de titres différents à partir duquel un
+	// site candidat est confirmé par l'apprentissage
+	SiteConfirmations = 3
+)
+
+// SiteGuess est le site déduit d'un titre d'onglet ou d'une URL
+type SiteGuess struct {
+	Name       string  `json:"name" doc:"Site candidat (vide si aucun)"`
+	Confidence float64 `json:"confidence" doc:"Entre 0 et 1"`
+}
+
+// SiteMemory retient les sites candidats confirmés par leur fréquence
+type SiteMemory struct {
+	mu        sync.RWMutex
+	confirmed map[string]bool
+}
+
+// NewSiteMemory crée une mémoire de sites vide
+func NewSiteMemory() *SiteMemory {
+	return &SiteMemory{confirmed: make(map[string]bool)}
+}
+
+// Confirm marque des sites candidats comme confirmés
+func (m *SiteMemory) Confirm(names ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, name := range names {
+		m.confirmed[strings.ToLower(name)] = true
+	}
+}
+
+// Confirmed indique si un site candidat a été confirmé
+func (m *SiteMemory) Confirmed(name string) bool {
+	if m == nil {
+		return false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.confirmed[strings.ToLower(name)]
+}
+
+var (
+	// Séparateurs usuels entre la page et le site : " - ", " | ", " · "...
+	titleSeparator = regexp.MustCompile(`\s+[-–—|·•]\s+`)
+
+	// Nom d'hôte écrit dans le titre, limité aux extensions courantes pour
+	// ne pas confondre avec un nom de fichier (main.go, README.md)
+	hostInTitle = regexp.MustCompile(`(?i)(?:^|[\s(\[])((?:[a-z0-9](?:[a-z0-9-]*[a-z0-9])?\.)+(?:com|org|net|io|dev|app|ai|co|fr|de|uk|eu|be|ch|ca|us|me|info|tech|cloud|sh|so|gg|tv|xyz|gov|edu))(?:$|[\s/:)\]])`)
+)
+
+// browserNames sont les suffixes ajoutés par les navigateurs aux titres
+var browserNames = []string{
+	"Google Chrome", "Chrome", "Brave", "Brave Browser", "Mozilla Firefox", "Firefox",
+	"Safari", "Microsoft Edge", "Microsoft\u200b Edge", "Edge", "Arc", "Zen", "Zen Browser",
+	"Opera", "Vivaldi", "Chromium", "Waterfox", "LibreWolf",
+}
+
+// genericTitles sont des pages sans site identifiable
+var genericTitles = []string{
+	"nouvel onglet", "new tab", "untitled", "sans titre", "start page",
+	"page de démarrage", "about:blank", "nouvelle fenêtre privée", "new private tab",
+}
+
+// brands associe les segments de titre de marques connues au nom du site
+var brands = map[string]string{
+	"confluence":                       "Confluence",
+	"jira":                             "Jira",
+	"figma":                            "Figma",
+	"wikipedia":                        "Wikipedia",
+	"wikipédia":                        "Wikipedia",
+	"wikipedia, the free encyclopedia": "Wikipedia",
+	"mdn":                              "MDN",
+	"mdn web docs":                     "MDN",
+	"medium":                           "Medium",
+	"dev community":                    "DEV Community",
+	"hacker news":                      "Hacker News",
+	"gitlab":                           "GitLab",
+	"bitbucket":                        "Bitbucket",
+	"trello":                           "Trello",
+	"asana":                            "Asana",
+	"linear":                           "Linear",
+	"miro":                             "Miro",
+	"outlook":                          "Outlook",
+	"microsoft outlook":                "Outlook",
+	"google calendar":                  "Google Calendar",
+	"google agenda":                    "Google Calendar",
+	"google meet":                      "Google Meet",
+	"google search":                    "Google Search",
+	"recherche google":                 "Google Search",
+	"zoom":                             "Zoom",
+	"microsoft teams":                  "Microsoft Teams",
+	"vercel":                           "Vercel",
+	"netlify":                          "Netlify",
+	"amazon.com":                       "Amazon",
+	"amazon.fr":                        "Amazon",
+	"go packages":                      "Go Packages",
+	"docker hub":                       "Docker Hub",
+	"postman":                          "Postman",
+	"sentry":                           "Sentry",
+	"grafana":                          "Grafana",
+	"datadog":                          "Datadog",
+	"google cloud console":             "Google Cloud",
+	"aws management console":           "AWS",
+	"le monde.fr":                      "Le Monde",
+	"whatsapp":                         "WhatsApp",
+	"messenger":                        "Messenger",
+}
+
+// InferSite déduit le site d'une page à partir de son URL si elle est
+// connue, sinon des conventions de titre : marque connue, nom d'hôte, ou
+// dernier segment après " - ", " | " ou " · ". Un dernier segment seul reste
+// sous MinSiteConfidence tant que memory ne l'a pas confirmé.
+func InferSite(title, rawURL string, memory *SiteMemory) SiteGuess {
+	if host := URLHost(rawURL); host != "" {
+		return SiteGuess{Name: host, Confidence: 0.9}
+	}
+
+	title = stripBrowserSuffix(strings.TrimSpace(title))
+	if title == "" || isGenericTitle(title) {
+		return SiteGuess{}
+	}
+
+	segments := splitTitle(title)
+	for i := len(segments) - 1; i >= 0; i-- {
+		if brand, ok := brands[strings.ToLower(segments[i])]; ok {
+			return SiteGuess{Name: brand, Confidence: 0.95}
+		}
+	}
+
+	if m := hostInTitle.FindStringSubmatch(title); m != nil {
+		return SiteGuess{Name: strings.TrimPrefix(strings.ToLower(m[1]), "www."), Confidence: 0.8}
+	}
+
+	if len(segments) < 2 {
+		return SiteGuess{}
+	}
+
+	candidate := segments[len(segments)-1]
+	confidence := segmentConfidence(candidate, len(segments))
+	if memory.Confirmed(candidate) && confidence < 0.85 {
+		confidence = 0.85
+	}
+	return SiteGuess{Name: candidate, Confidence: confidence}
+}
+
+// segmentConfidence estime la probabilité qu'un dernier segment soit un nom de site
+func segmentConfidence(segment string, segments int) float64 {
+	words := strings.Fields(segment)
+	switch {
+	case len(words) > 5 || len(segment) > 40:
+		// Phrase plutôt que nom de site
+		return 0.2
+	case hasManyDigits(segment):
+		return 0.3
+	}
+
+	confidence := 0.5
+	if len(words) <= 3 && len(segment) <= 30 {
+		confidence += 0.1
+	}
+	if segments >= 3 {
+		confidence += 0.05
+	}
+	return confidence
+}
+
+// hasManyDigits détecte les segments numériques (dates, numéros, compteurs)
+func hasManyDigits(s string) bool {
+	digits := 0
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			digits++
+		}
+	}
+	return digits*3 >= len(s)
+}
+
+// splitTitle découpe un titre selon les séparateurs usuels
+func splitTitle(title string) []string {
+	var segments []string
+	for _, part := range titleSeparator.Split(title, -1) {
+		if part = strings.TrimSpace(part); part != "" {
+			segments = append(segments, part)
+		}
+	}
+	return segments
+}
+
+// stripBrowserSuffix retire les noms de navigateur en fin de titre
+func stripBrowserSuffix(title string) string {
+	for {
+		loc := titleSeparator.FindAllStringIndex(title, -1)
+		if len(loc) == 0 {
+			return title
+		}
+		last := loc[len(loc)-1]
+		if !isBrowserName(strings.TrimSpace(title[last[1]:])) {
+			return title
+		}
+		title = strings.TrimSpace(title[:last[0]])
+	}
+}
+
+func isBrowserName(s string) bool {
+	for _, name := range browserNames {
+		if strings.EqualFold(s, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func isGenericTitle(title string) bool {
+	for _, generic := range genericTitles {
+		if strings.EqualFold(title, generic) {
+			return true
+		}
+	}
+	return false
+}
+
+// URLHost retourne l'hôte d'une URL http(s) sans le préfixe www.
+func URLHost(rawURL string) string {
+	if rawURL == "" {
+		return ""
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
@@ -0,0 +1,120 @@
+package rules
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// TitleSample est un titre de fenêtre non classé et le temps passé dessus
+type TitleSample struct {
+	AppName     string
+	WindowTitle string
+	Seconds     int64
+}
+
+// Suggestion est une règle candidate construite à partir d'un mot fréquent
+// des titres non classés d'une application
+type Suggestion struct {
+	Token   string `json:"token"`
+	AppName string `json:"app_name"`
+	Titles  int    `json:"titles" doc:"Titres distincts contenant le mot"`
+	Seconds int64  `json:"seconds" doc:"Temps passé sur ces titres"`
+	Rule    Rule   `json:"rule" doc:"Règle proposée, à ajuster avant de l'enregistrer"`
+}
+
+// suggestStopWords sont des mots trop génériques pour nommer une activité
+var suggestStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "from": true, "your": true, "you": true,
+	"new": true, "tab": true, "page": true, "home": true, "untitled": true, "window": true,
+	"les": true, "des": true, "pour": true, "dans": true, "sur": true, "avec": true, "une": true,
+	"par": true, "est": true, "pas": true, "nouvel": true, "onglet": true, "accueil": true, "sans": true,
+	"titre": true, "http": true, "https": true, "www": true, "com": true, "org": true, "net": true,
+}
+
+// titleTokens découpe un titre en mots d'au moins 3 caractères, sans les
+// nombres ni les mots du nom de l'application ; la clé est en minuscules
+func titleTokens(title string, skip map[string]bool) map[string]string {
+	tokens := map[string]string{}
+	words := strings.FieldsFunc(title, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		key := strings.ToLower(word)
+		if len([]rune(key)) < 3 || suggestStopWords[key] || skip[key] || strings.IndexFunc(key, unicode.IsLetter) < 0 {
+			continue
+		}
+		if _, ok := tokens[key]; !ok {
+			tokens[key] = word
+		}
+	}
+	return tokens
+}
+
+// Suggest propose au plus limit règles à partir des mots qui reviennent
+// dans au moins deux titres non classés d'une même application, le temps
+// le plus long d'abord. Chaque règle teste l'application et la présence du
+// mot dans le titre, fixe le mot comme nom enrichi et se place en tête.
+func Suggest(samples []TitleSample, limit int) []Suggestion {
+	type key struct{ app, token string }
+	type usage struct {
+		display string
+		titles  int
+		seconds int64
+	}
+	usages := map[key]*usage{}
+
+	for _, sample := range samples {
+		skip := map[string]bool{}
+		for _, word := range strings.Fields(strings.ToLower(sample.AppName)) {
+			skip[word] = true
+		}
+		for token, display := range titleTokens(sample.WindowTitle, skip) {
+			k := key{sample.AppName, token}
+			u := usages[k]
+			if u == nil {
+				u = &usage{display: display}
+				usages[k] = u
+			}
+			u.titles++
+			u.seconds += sample.Seconds
+		}
+	}
+
+	var suggestions []Suggestion
+	for k, u := range usages {
+		if u.titles < 2 {
+			continue
+		}
+		suggestions = append(suggestions, Suggestion{
+			Token:   u.display,
+			AppName: k.app,
+			Titles:  u.titles,
+			Seconds: u.seconds,
+			Rule: Rule{
+				Name:     "Suggestion " + u.display,
+				Position: 1, // avant les règles de repli comme "Autres sites"
+				Enabled:  true,
+				Conditions: []Condition{
+					{Field: FieldApp, Match: MatchEquals, Pattern: k.app},
+					{Field: FieldTitle, Match: MatchContains, Pattern: u.display},
+				},
+				Action: Action{EnrichedName: u.display},
+			},
+		})
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Seconds != suggestions[j].Seconds {
+			return suggestions[i].Seconds > suggestions[j].Seconds
+		}
+		if suggestions[i].AppName != suggestions[j].AppName {
+			return suggestions[i].AppName < suggestions[j].AppName
+		}
+		return suggestions[i].Token < suggestions[j].Token
+	})
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
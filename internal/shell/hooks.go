@@ -0,0 +1,114 @@
+package shell
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Shells sont les shells pris en charge par Script
+var Shells = []string{"bash", "zsh", "fish"}
+
+// Script retourne les hooks à évaluer au démarrage du shell donné. Ils
+// appellent binary en arrière-plan au début ("shell-event start") et à la
+// fin ("shell-event end") de chaque commande interactive, avec l'heure
+// relevée par le shell (bash 5+, zsh) ou la durée qu'il a mesurée (fish).
+func Script(shell, binary string) (string, error) {
+	var script string
+	switch shell {
+	case "bash":
+		script = bashHooks
+	case "zsh":
+		script = zshHooks
+	case "fish":
+		script = fishHooks
+	default:
+		return "", fmt.Errorf("shell non pris en charge: %q (%s)", shell, strings.Join(Shells, ", "))
+	}
+	return strings.ReplaceAll(script, "__TMT_BIN__", quote(binary)), nil
+}
+
+// quote protège un chemin entre apostrophes, syntaxe comprise par les trois shells
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// bashHooks utilise le piège DEBUG comme preexec et PROMPT_COMMAND comme
+// precmd ; __tmt_at_prompt, posé en fin de PROMPT_COMMAND, évite de compter
+// les commandes de PROMPT_COMMAND elles-mêmes. Un piège DEBUG existant est
+// chaîné après __tmt_preexec, qui lui rend $? intact. Si bash-preexec
+// (atuin, starship...) est déjà chargé, les hooks s'ajoutent à ses listes
+// preexec_functions et precmd_functions au lieu de toucher au piège.
+const bashHooks = `# trackmytime : eval "$(trackmytime shell-init bash)" dans ~/.bashrc (après bash-preexec)
+__tmt_session="$$-$RANDOM$RANDOM"
+__tmt_seq=0
+__tmt_start() {
+    __tmt_running=1
+    __tmt_seq=$((__tmt_seq + 1))
+    (__TMT_BIN__ shell-event start -shell bash -session "$__tmt_session" -seq "$__tmt_seq" -time "$EPOCHREALTIME" -cwd "$PWD" -- "$1" >/dev/null 2>&1 &)
+}
+__tmt_preexec() {
+    local trap_status=$?
+    [[ -n $COMP_LINE || -z $__tmt_at_prompt || $BASH_COMMAND == __tmt_precmd* ]] && return $trap_status
+    unset __tmt_at_prompt
+    local cmd
+    cmd=$(HISTTIMEFORMAT= builtin history 1)
+    [[ $cmd =~ ^[[:space:]]*[0-9]+[*]?[[:space:]]+(.*)$ ]] && cmd=${BASH_REMATCH[1]} || cmd=$BASH_COMMAND
+    __tmt_start "$cmd"
+    return $trap_status
+}
+__tmt_precmd() {
+    local exit_status=$?
+    unset __tmt_at_prompt
+    if [[ -n $__tmt_running ]]; then
+        (__TMT_BIN__ shell-event end -shell bash -session "$__tmt_session" -seq "$__tmt_seq" -time "$EPOCHREALTIME" -status "$exit_status" >/dev/null 2>&1 &)
+        unset __tmt_running
+    fi
+    return $exit_status
+}
+if [[ -n ${bash_preexec_imported:-${__bp_imported:-}} ]]; then
+    [[ " ${preexec_functions[*]} " == *" __tmt_start "* ]] || preexec_functions+=(__tmt_start)
+    [[ " ${precmd_functions[*]} " == *" __tmt_precmd "* ]] || precmd_functions+=(__tmt_precmd)
+else
+    __tmt_prev_debug=$(trap -p DEBUG)
+    __tmt_prev_debug=${__tmt_prev_debug#trap -- }
+    eval "__tmt_prev_debug=${__tmt_prev_debug% DEBUG}"
+    [[ $__tmt_prev_debug == *__tmt_preexec* ]] || trap "__tmt_preexec${__tmt_prev_debug:+; $__tmt_prev_debug}" DEBUG
+    unset __tmt_prev_debug
+    [[ $PROMPT_COMMAND == *__tmt_precmd* ]] || PROMPT_COMMAND="__tmt_precmd${PROMPT_COMMAND:+;$PROMPT_COMMAND};__tmt_at_prompt=1"
+fi
+`
+
+const zshHooks = `# trackmytime : eval "$(trackmytime shell-init zsh)" dans ~/.zshrc
+zmodload zsh/datetime 2>/dev/null
+__tmt_session="$$-$RANDOM$RANDOM"
+__tmt_seq=0
+__tmt_preexec() {
+    __tmt_running=1
+    __tmt_seq=$((__tmt_seq + 1))
+    (__TMT_BIN__ shell-event start -shell zsh -session "$__tmt_session" -seq "$__tmt_seq" -time "$EPOCHREALTIME" -cwd "$PWD" -- "$1" >/dev/null 2>&1 &)
+}
+__tmt_precmd() {
+    local exit_status=$?
+    [[ -z $__tmt_running ]] && return
+    unset __tmt_running
+    (__TMT_BIN__ shell-event end -shell zsh -session "$__tmt_session" -seq "$__tmt_seq" -time "$EPOCHREALTIME" -status "$exit_status" >/dev/null 2>&1 &)
+}
+autoload -Uz add-zsh-hook
+add-zsh-hook preexec __tmt_preexec
+add-zsh-hook precmd __tmt_precmd
+`
+
+const fishHooks = `# trackmytime : trackmytime shell-init fish | source dans ~/.config/fish/config.fish
+set -g __tmt_session $fish_pid-(random)(random)
+set -g __tmt_seq 0
+function __tmt_preexec --on-event fish_preexec
+    set -g __tmt_seq (math $__tmt_seq + 1)
+    command __TMT_BIN__ shell-event start -shell fish -session $__tmt_session -seq $__tmt_seq -cwd "$PWD" -- "$argv" >/dev/null 2>&1 &
+    disown 2>/dev/null
+end
+function __tmt_postexec --on-event fish_postexec
+    set -l exit_status $status
+    command __TMT_BIN__ shell-event end -shell fish -session $__tmt_session -seq $__tmt_seq -status $exit_status -duration $CMD_DURATION >/dev/null 2>&1 &
+    disown 2>/dev/null
+end
+`
@@ -0,0 +1,47 @@
+// Package shell fournit les hooks d'intégration des shells (bash, zsh,
+// fish) qui signalent à l'agent les commandes exécutées, et regroupe ces
+// commandes par programme.
+package shell
+
+import (
+	"path"
+	"strings"
+)
+
+// prefixes sont les commandes qui en lancent une autre
+var prefixes = map[string]bool{
+	"sudo": true, "doas": true, "env": true, "time": true, "nohup": true, "nice": true,
+	"command": true, "builtin": true, "exec": true, "noglob": true,
+}
+
+// subcommandTools sont les outils dont la sous-commande distingue l'usage
+// (go build / go test, docker build / docker push...)
+var subcommandTools = map[string]bool{
+	"go": true, "cargo": true, "npm": true, "pnpm": true, "yarn": true, "bun": true,
+	"git": true, "docker": true, "podman": true, "kubectl": true, "helm": true,
+	"terraform": true, "make": true, "gradle": true, "mvn": true, "dotnet": true,
+	"bundle": true, "rails": true, "mix": true, "poetry": true, "uv": true,
+}
+
+// Program retourne le programme d'une ligne de commande, suivi de sa
+// sous-commande pour les outils qui en ont : "FOO=1 sudo make build" →
+// "make build", "/usr/bin/ls -la" → "ls"
+func Program(command string) string {
+	words := strings.Fields(command)
+	for i, word := range words {
+		if prefixes[word] || strings.HasPrefix(word, "-") ||
+			(strings.Contains(word, "=") && !strings.HasPrefix(word, "=")) {
+			continue
+		}
+		program := path.Base(word)
+		if subcommandTools[program] {
+			for _, next := range words[i+1:] {
+				if !strings.HasPrefix(next, "-") {
+					return program + " " + next
+				}
+			}
+		}
+		return program
+	}
+	return ""
+}
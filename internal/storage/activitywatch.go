@@ -0,0 +1,490 @@
+package storage
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"trackmytime/internal/vault"
+)
+
+// Types des buckets ActivityWatch dont les événements alimentent TrackMyTime
+const (
+	AWTypeWindow = "currentwindow"       // aw-watcher-window : fenêtre active
+	AWTypeAFK    = "afkstatus"           // aw-watcher-afk : présence ou inactivité
+	AWTypeInput  = "os.hid.input"        // aw-watcher-input : touches, clics, souris
+	AWTypeWebTab = "web.tab.current"     // aw-watcher-web : onglet actif
+	AWTypeEditor = "app.editor.activity" // aw-watcher-vim, aw-watcher-vscode : fichier ouvert
+)
+
+// AWBucket est un bucket ActivityWatch : la série d'événements d'un watcher
+type AWBucket struct {
+	ID          string
+	Type        string // currentwindow, web.tab.current, app.editor.activity...
+	Client      string // watcher qui a créé le bucket (aw-watcher-web...)
+	Hostname    string
+	Data        string // métadonnées JSON libres
+	Created     time.Time
+	Events      int
+	LastUpdated time.Time // début du dernier événement, zéro sans événement
+}
+
+// App retourne l'application à laquelle s'appliquent les règles de
+// confidentialité pour les événements du bucket : le navigateur pour
+// aw-watcher-web ("aw-watcher-web-firefox_laptop" → "firefox"), sinon le
+// watcher sans préfixe ("aw-watcher-vim" → "vim")
+func (b AWBucket) App() string {
+	if b.Type == AWTypeWebTab {
+		name, _, _ := strings.Cut(strings.TrimPrefix(b.ID, "aw-watcher-web-"), "_")
+		return name
+	}
+	return strings.TrimPrefix(strings.ToLower(b.Client), "aw-watcher-")
+}
+
+// AWEvent est un événement ActivityWatch
+type AWEvent struct {
+	ID        int64
+	BucketID  string
+	Timestamp time.Time
+	Duration  time.Duration
+	Data      string // objet JSON, sous forme canonique (clés triées)
+}
+
+// CreateAWBucket crée un bucket ; false s'il existait déjà (il n'est alors
+// pas modifié, comme dans aw-server)
+func (db *DB) CreateAWBucket(b AWBucket) (bool, error) {
+	result, err := db.conn.Exec(`
+		INSERT INTO aw_buckets (id, type, client, hostname, data, created) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO NOTHING
+	`, b.ID, b.Type, b.Client, b.Hostname, b.Data, b.Created)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// awBucketColumns sont les colonnes lues par scanAWBucket
+const awBucketColumns = `b.id, b.type, b.client, b.hostname, b.data, b.created,
+	(SELECT COUNT(*) FROM aw_events e WHERE e.bucket_id = b.id),
+	COALESCE((SELECT MAX(e.timestamp) FROM aw_events e WHERE e.bucket_id = b.id), '')`
+
+func scanAWBucket(s scanner) (AWBucket, error) {
+	var b AWBucket
+	var last string
+	err := s.Scan(&b.ID, &b.Type, &b.Client, &b.Hostname, &b.Data, &b.Created, &b.Events, &last)
+	if last != "" {
+		b.LastUpdated, _ = parseStoredTime(last)
+	}
+	return b, err
+}
+
+// ListAWBuckets retourne tous les buckets, par identifiant
+func (db *DB) ListAWBuckets() ([]AWBucket, error) {
+	rows, err := db.conn.Query(`SELECT ` + awBucketColumns + ` FROM aw_buckets b ORDER BY b.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var buckets []AWBucket
+	for rows.Next() {
+		b, err := scanAWBucket(rows)
+		if err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, rows.Err()
+}
+
+// GetAWBucket retourne un bucket, ou nil s'il n'existe pas
+func (db *DB) GetAWBucket(id string) (*AWBucket, error) {
+	b, err := scanAWBucket(db.conn.QueryRow(`SELECT `+awBucketColumns+` FROM aw_buckets b WHERE b.id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// DeleteAWBucket supprime un bucket et ses événements ; false s'il n'existe pas
+func (db *DB) DeleteAWBucket(id string) (bool, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM aw_events WHERE bucket_id = ?`, id); err != nil {
+		return false, err
+	}
+	result, err := tx.Exec(`DELETE FROM aw_buckets WHERE id = ?`, id)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	return true, tx.Commit()
+}
+
+// InsertAWEvents ajoute des événements au bucket et les retourne avec leur
+// identifiant
+func (db *DB) InsertAWEvents(bucketID string, events []AWEvent) ([]AWEvent, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	c, err := db.awCipher(tx, bucketID)
+	if err != nil {
+		return nil, err
+	}
+	inserted := make([]AWEvent, 0, len(events))
+	for _, e := range events {
+		e.BucketID = bucketID
+		if e.ID, err = insertAWEvent(tx, c, e); err != nil {
+			return nil, err
+		}
+		inserted = append(inserted, e)
+	}
+	return inserted, tx.Commit()
+}
+
+// awCipher retourne le chiffrement des données des événements du bucket :
+// celui en vigueur pour les buckets de fenêtre, d'onglet et d'éditeur, dont
+// les données contiennent titres, URL et fichiers ; nil pour les autres
+// (afk, input), lus en SQL par les statistiques
+func (db *DB) awCipher(q queryRower, bucketID string) (*vault.Cipher, error) {
+	var bucketType string
+	err := q.QueryRow(`SELECT type FROM aw_buckets WHERE id = ?`, bucketID).Scan(&bucketType)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	if _, ok := awProtectedFields[bucketType]; !ok {
+		return nil, nil
+	}
+	return db.cipher(q)
+}
+
+func insertAWEvent(e execer, c *vault.Cipher, event AWEvent) (int64, error) {
+	result, err := e.Exec(`INSERT INTO aw_events (bucket_id, timestamp, duration, data) VALUES (?, ?, ?, ?)`,
+		event.BucketID, event.Timestamp, event.Duration.Seconds(), c.Seal(event.Data))
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// AWHeartbeat fusionne un heartbeat avec le dernier événement du bucket,
+// comme aw-server : si les données sont identiques et que le heartbeat
+// arrive au plus pulsetime après la fin de cet événement, celui-ci est
+// prolongé ; sinon le heartbeat devient un nouvel événement. Retourne
+// l'événement résultant et true s'il a été prolongé.
+func (db *DB) AWHeartbeat(bucketID string, heartbeat AWEvent, pulsetime time.Duration) (AWEvent, bool, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return AWEvent{}, false, err
+	}
+	defer tx.Rollback()
+
+	c, err := db.awCipher(tx, bucketID)
+	if err != nil {
+		return AWEvent{}, false, err
+	}
+	heartbeat.BucketID = bucketID
+	var last AWEvent
+	var seconds float64
+	err = tx.QueryRow(`
+		SELECT id, timestamp, duration, data FROM aw_events
+		WHERE bucket_id = ? ORDER BY timestamp DESC, id DESC LIMIT 1
+	`, bucketID).Scan(&last.ID, &last.Timestamp, &seconds, &last.Data)
+	if err != nil && err != sql.ErrNoRows {
+		return AWEvent{}, false, err
+	}
+	found := err == nil
+	if last.Data, err = c.Open(last.Data); err != nil {
+		return AWEvent{}, false, err
+	}
+	last.BucketID = bucketID
+	last.Duration = time.Duration(seconds * float64(time.Second))
+
+	if found && last.Data == heartbeat.Data && !heartbeat.Timestamp.Before(last.Timestamp) &&
+		!heartbeat.Timestamp.After(last.Timestamp.Add(last.Duration+pulsetime)) {
+		if end := heartbeat.Timestamp.Add(heartbeat.Duration).Sub(last.Timestamp); end > last.Duration {
+			last.Duration = end
+			if _, err := tx.Exec(`UPDATE aw_events SET duration = ? WHERE id = ?`, last.Duration.Seconds(), last.ID); err != nil {
+				return AWEvent{}, false, err
+			}
+		}
+		return last, true, tx.Commit()
+	}
+
+	if heartbeat.ID, err = insertAWEvent(tx, c, heartbeat); err != nil {
+		return AWEvent{}, false, err
+	}
+	return heartbeat, false, tx.Commit()
+}
+
+// awEventRange restreint une requête sur aw_events aux événements du bucket
+// qui chevauchent [start, end) ; une borne nulle n'est pas appliquée
+func awEventRange(bucketID string, start, end time.Time) (string, []any) {
+	where := ` WHERE bucket_id = ?`
+	args := []any{bucketID}
+	if !start.IsZero() {
+		where += ` AND julianday(timestamp) + duration / 86400.0 > julianday(?)`
+		args = append(args, start)
+	}
+	if !end.IsZero() {
+		where += ` AND timestamp < ?`
+		args = append(args, end)
+	}
+	return where, args
+}
+
+// ListAWEvents retourne au plus limit événements du bucket chevauchant
+// [start, end), les plus récents d'abord ; limit <= 0 = sans limite
+func (db *DB) ListAWEvents(bucketID string, start, end time.Time, limit int) ([]AWEvent, error) {
+	where, args := awEventRange(bucketID, start, end)
+	query := `SELECT id, timestamp, duration, data FROM aw_events` + where + ` ORDER BY timestamp DESC, id DESC`
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+	c, err := db.cipher(db.conn)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []AWEvent
+	for rows.Next() {
+		e := AWEvent{BucketID: bucketID}
+		var seconds float64
+		if err := rows.Scan(&e.ID, &e.Timestamp, &seconds, &e.Data); err != nil {
+			return nil, err
+		}
+		if e.Data, err = c.Open(e.Data); err != nil {
+			return nil, err
+		}
+		e.Duration = time.Duration(seconds * float64(time.Second))
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// CountAWEvents compte les événements du bucket chevauchant [start, end)
+func (db *DB) CountAWEvents(bucketID string, start, end time.Time) (int, error) {
+	where, args := awEventRange(bucketID, start, end)
+	var count int
+	err := db.conn.QueryRow(`SELECT COUNT(*) FROM aw_events`+where, args...).Scan(&count)
+	return count, err
+}
+
+// GetAWEvent retourne un événement du bucket, ou nil s'il n'existe pas
+func (db *DB) GetAWEvent(bucketID string, id int64) (*AWEvent, error) {
+	c, err := db.cipher(db.conn)
+	if err != nil {
+		return nil, err
+	}
+	e := AWEvent{ID: id, BucketID: bucketID}
+	var seconds float64
+	err = db.conn.QueryRow(`SELECT timestamp, duration, data FROM aw_events WHERE bucket_id = ? AND id = ?`,
+		bucketID, id).Scan(&e.Timestamp, &seconds, &e.Data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if e.Data, err = c.Open(e.Data); err != nil {
+		return nil, err
+	}
+	e.Duration = time.Duration(seconds * float64(time.Second))
+	return &e, nil
+}
+
+// DeleteAWEvent supprime un événement du bucket ; false s'il n'existe pas
+func (db *DB) DeleteAWEvent(bucketID string, id int64) (bool, error) {
+	result, err := db.conn.Exec(`DELETE FROM aw_events WHERE bucket_id = ? AND id = ?`, bucketID, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// RecordAWActivity enregistre l'activité déduite d'un événement de fenêtre
+// ou d'inactivité (IsIdle) d'un watcher ActivityWatch, ou prolonge celle déjà
+// enregistrée pour cet événement quand des heartbeats l'ont fusionné. Les
+// watchers ne comblent que les périodes où l'agent n'a rien enregistré :
+// l'activité s'arrête au début de la suivante, et n'est pas créée si une
+// autre couvre déjà son début. Une période d'inactivité l'emporte sur les
+// fenêtres signalées par les watchers, raccourcies ou supprimées. Retourne
+// false si rien n'a été enregistré.
+func (db *DB) RecordAWActivity(eventID int64, activity *Activity) (bool, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var existing int64
+	err = tx.QueryRow(`SELECT id FROM activities WHERE aw_event_id = ?`, eventID).Scan(&existing)
+	if err != nil && err != sql.ErrNoRows {
+		return false, err
+	}
+
+	if activity.IsIdle {
+		if err := yieldAWWindows(tx, activity.StartTime, activity.EndTime); err != nil {
+			return false, err
+		}
+	}
+
+	var covered int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM activities WHERE id <> ? AND start_time <= ? AND end_time > ?`,
+		existing, activity.StartTime, activity.StartTime).Scan(&covered)
+	if err != nil {
+		return false, err
+	}
+	if covered > 0 {
+		return false, tx.Commit()
+	}
+	var next string
+	err = tx.QueryRow(`SELECT COALESCE(MIN(start_time), '') FROM activities WHERE id <> ? AND start_time > ? AND start_time < ?`,
+		existing, activity.StartTime, activity.EndTime).Scan(&next)
+	if err != nil {
+		return false, err
+	}
+	if t, err := parseStoredTime(next); next != "" && err == nil && t.Before(activity.EndTime) {
+		activity.EndTime = t
+	}
+	activity.DurationSecs = int64(activity.EndTime.Sub(activity.StartTime).Seconds())
+
+	if existing != 0 {
+		activity.ID = existing
+		_, err = tx.Exec(`UPDATE activities SET end_time = ?, duration_seconds = ? WHERE id = ?`,
+			activity.EndTime, activity.DurationSecs, existing)
+	} else {
+		if err := db.insertActivity(tx, activity); err != nil {
+			return false, err
+		}
+		_, err = tx.Exec(`UPDATE activities SET aw_event_id = ? WHERE id = ?`, eventID, activity.ID)
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// yieldAWWindows raccourcit à start les fenêtres signalées par les watchers
+// qui chevauchent [start, end), et supprime celles qui commencent après start
+func yieldAWWindows(tx *sql.Tx, start, end time.Time) error {
+	rows, err := tx.Query(`
+		SELECT id, start_time FROM activities
+		WHERE aw_event_id IS NOT NULL AND is_idle = 0 AND start_time < ? AND end_time > ?
+	`, end, start)
+	if err != nil {
+		return err
+	}
+	type window struct {
+		id    int64
+		start time.Time
+	}
+	var windows []window
+	for rows.Next() {
+		var w window
+		if err := rows.Scan(&w.id, &w.start); err != nil {
+			rows.Close()
+			return err
+		}
+		windows = append(windows, w)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, w := range windows {
+		if !w.start.Before(start) {
+			if err := deleteActivity(tx, w.id); err != nil {
+				return err
+			}
+			continue
+		}
+		_, err := tx.Exec(`UPDATE activities SET end_time = ?, duration_seconds = ? WHERE id = ?`,
+			start, int64(start.Sub(w.start).Seconds()), w.id)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AWInputHour est l'activité du clavier et de la souris sur une heure
+type AWInputHour struct {
+	Hour    int // heure locale, de 0 à 23
+	Presses int64
+	Clicks  int64
+}
+
+// AWInputTotals est l'activité du clavier et de la souris signalée par
+// aw-watcher-input sur une période
+type AWInputTotals struct {
+	Presses    int64   // touches pressées
+	Clicks     int64   // clics
+	MouseMoved float64 // déplacement de la souris, en pixels
+	Scrolled   float64 // défilement, en pixels
+	Active     time.Duration
+	Hours      []AWInputHour // 24 heures, même vides
+}
+
+// GetAWInputTotals additionne les événements des buckets os.hid.input qui
+// commencent dans [start, end)
+func (db *DB) GetAWInputTotals(start, end time.Time) (AWInputTotals, error) {
+	rows, err := db.conn.Query(`
+		SELECT e.timestamp, e.duration,
+			COALESCE(json_extract(e.data, '$.presses'), 0), COALESCE(json_extract(e.data, '$.clicks'), 0),
+			ABS(COALESCE(json_extract(e.data, '$.deltaX'), 0)) + ABS(COALESCE(json_extract(e.data, '$.deltaY'), 0)),
+			ABS(COALESCE(json_extract(e.data, '$.scrollX'), 0)) + ABS(COALESCE(json_extract(e.data, '$.scrollY'), 0))
+		FROM aw_events e
+		JOIN aw_buckets b ON b.id = e.bucket_id
+		WHERE b.type = ? AND julianday(e.timestamp) >= julianday(?) AND julianday(e.timestamp) < julianday(?)
+	`, AWTypeInput, start, end)
+	if err != nil {
+		return AWInputTotals{}, err
+	}
+	defer rows.Close()
+
+	totals := AWInputTotals{Hours: make([]AWInputHour, 24)}
+	for hour := range totals.Hours {
+		totals.Hours[hour].Hour = hour
+	}
+	for rows.Next() {
+		var at time.Time
+		var seconds, moved, scrolled float64
+		var presses, clicks int64
+		if err := rows.Scan(&at, &seconds, &presses, &clicks, &moved, &scrolled); err != nil {
+			return AWInputTotals{}, err
+		}
+		totals.Presses += presses
+		totals.Clicks += clicks
+		totals.MouseMoved += moved
+		totals.Scrolled += scrolled
+		if presses > 0 || clicks > 0 || moved > 0 || scrolled > 0 {
+			totals.Active += time.Duration(seconds * float64(time.Second))
+		}
+		hour := &totals.Hours[at.Local().Hour()]
+		hour.Presses += presses
+		hour.Clicks += clicks
+	}
+	return totals, rows.Err()
+}
@@ -0,0 +1,289 @@
+package storage
+
+import (
+	"database/sql"
+	"net/url"
+	"strings"
+	"time"
+
+	"trackmytime/internal/rules"
+	"trackmytime/internal/vault"
+)
+
+// BrowserEventTimeout est l'écart maximal entre deux signaux d'une même page
+// compté comme une visite continue ; au-delà, l'onglet est considéré comme
+// abandonné depuis le dernier signal
+const BrowserEventTimeout = 2 * time.Minute
+
+// Types de signaux envoyés par l'extension navigateur
+const (
+	BrowserFocus     = "focus"     // l'onglet devient actif
+	BrowserHeartbeat = "heartbeat" // l'onglet actif est toujours affiché
+	BrowserBlur      = "blur"      // l'onglet ou la fenêtre perd le focus
+)
+
+// BrowserSignal est un signal de l'extension navigateur sur l'onglet actif
+type BrowserSignal struct {
+	Type     string // BrowserFocus, BrowserHeartbeat ou BrowserBlur
+	URL      string // peut être vide pour BrowserBlur
+	TabTitle string
+	Browser  string
+	Time     time.Time
+	Duration time.Duration // temps déjà passé sur la page à Time (lots envoyés a posteriori)
+}
+
+// BrowserStat est le temps passé sur un domaine ou une page
+type BrowserStat struct {
+	Domain   string // hôte sans www.
+	Path     string // vide pour les statistiques par domaine
+	Visits   int
+	Seconds  int64
+	LastSeen time.Time
+}
+
+// RecordBrowserSignals regroupe des signaux de l'extension en visites de
+// pages (table browser_events) et retourne le nombre de visites créées.
+// Un focus ou un heartbeat sur la page de la visite en cours la prolonge
+// s'il arrive moins de BrowserEventTimeout après son dernier signal ; une
+// autre page la termine et en commence une nouvelle ; un blur la termine.
+// Les signaux doivent être triés par heure.
+func (db *DB) RecordBrowserSignals(signals []BrowserSignal) (int, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	c, err := db.cipher(tx)
+	if err != nil {
+		return 0, err
+	}
+	visits := 0
+	for _, s := range signals {
+		created, err := recordBrowserSignal(tx, c, s)
+		if err != nil {
+			return 0, err
+		}
+		if created {
+			visits++
+		}
+	}
+	return visits, tx.Commit()
+}
+
+// recordBrowserSignal applique un signal à la dernière visite du navigateur ;
+// true si une nouvelle visite a été créée. L'URL et le titre de l'onglet
+// sont chiffrés avec c ; le domaine et le chemin restent en clair pour les
+// statistiques.
+func recordBrowserSignal(tx *sql.Tx, c *vault.Cipher, s BrowserSignal) (bool, error) {
+	var id int64
+	var lastURL string
+	var start, end time.Time
+	var closed bool
+	err := tx.QueryRow(`
+		SELECT id, url, start_time, end_time, COALESCE(closed, 0) FROM browser_events
+		WHERE COALESCE(browser_name, '') = ? AND end_time IS NOT NULL
+		ORDER BY start_time DESC, id DESC LIMIT 1
+	`, s.Browser).Scan(&id, &lastURL, &start, &end, &closed)
+	if err != nil && err != sql.ErrNoRows {
+		return false, err
+	}
+	if err == nil {
+		if lastURL, err = c.Open(lastURL); err != nil {
+			return false, err
+		}
+	}
+
+	signalEnd := s.Time.Add(s.Duration)
+	open := err == nil && !closed && !s.Time.Before(start) && !s.Time.After(end.Add(BrowserEventTimeout))
+	switch {
+	case s.Type == BrowserBlur:
+		if open {
+			return false, setBrowserEventEnd(tx, id, start, later(end, signalEnd), true)
+		}
+		return false, nil
+	case open && lastURL == s.URL:
+		if err := setBrowserEventEnd(tx, id, start, later(end, signalEnd), false); err != nil {
+			return false, err
+		}
+		if s.TabTitle != "" {
+			// Le titre change sans changer d'URL (applications monopage)
+			_, err := tx.Exec(`UPDATE browser_events SET tab_title = ? WHERE id = ?`, c.Seal(s.TabTitle), id)
+			return false, err
+		}
+		return false, nil
+	case open:
+		// La page précédente est restée affichée jusqu'à ce signal
+		if err := setBrowserEventEnd(tx, id, start, later(end, s.Time), true); err != nil {
+			return false, err
+		}
+	}
+	if s.URL == "" {
+		return false, nil
+	}
+
+	domain, path := urlParts(s.URL)
+	_, err = tx.Exec(`
+		INSERT INTO browser_events (url, domain, path, tab_title, browser_name, start_time, end_time, duration_seconds, closed)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, 0)
+	`, c.Seal(s.URL), nullIfEmpty(domain), nullIfEmpty(path), nullIfEmpty(c.Seal(s.TabTitle)), s.Browser,
+		s.Time, signalEnd, int64(s.Duration.Seconds()))
+	return err == nil, err
+}
+
+// setBrowserEventEnd fixe la fin d'une visite, et la termine si closed
+func setBrowserEventEnd(tx *sql.Tx, id int64, start, end time.Time, closed bool) error {
+	_, err := tx.Exec(`UPDATE browser_events SET end_time = ?, duration_seconds = ?, closed = ? WHERE id = ?`,
+		end, int64(end.Sub(start).Seconds()), closed, id)
+	return err
+}
+
+// later retourne la plus tardive de deux heures
+func later(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// urlParts retourne le domaine (hôte sans www.) et le chemin d'une URL
+// http(s) ; les autres pages (nouvel onglet, about:, fichiers) n'en ont pas
+func urlParts(rawURL string) (domain, path string) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return "", ""
+	}
+	path = u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www."), path
+}
+
+// BrowserURL retourne l'URL de la page la plus longtemps affichée pendant
+// [start, end), ou une chaîne vide si l'extension n'a rien signalé
+func (db *DB) BrowserURL(start, end time.Time) (string, error) {
+	c, err := db.cipher(db.conn)
+	if err != nil {
+		return "", err
+	}
+	var rawURL string
+	err = db.conn.QueryRow(`
+		SELECT url FROM browser_events
+		WHERE start_time < ? AND end_time > ?
+		ORDER BY MIN(julianday(end_time), julianday(?)) - MAX(julianday(start_time), julianday(?)) DESC, start_time DESC
+		LIMIT 1
+	`, end, start, end, start).Scan(&rawURL)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return c.Open(rawURL)
+}
+
+// BrowserURLChanges retourne les activités de navigateur chevauchant
+// [start, end) dont la page la plus longtemps affichée selon l'extension
+// diffère de l'URL enregistrée, avec URL remplacée par celle de cette page.
+// Les activités sont lues comme par ReprocessActivities, pour être
+// réenrichies.
+func (db *DB) BrowserURLChanges(start, end time.Time) ([]Activity, error) {
+	c, err := db.cipher(db.conn)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.conn.Query(`SELECT `+reprocessColumns+` FROM activities
+		WHERE is_idle = 0 AND app_name <> 'IDLE' AND start_time < ? AND end_time > ?
+		ORDER BY id`, end, start)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []Activity
+	for rows.Next() {
+		a, err := scanReprocessActivity(rows, c)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if rules.IsBrowser(a.AppName) {
+			candidates = append(candidates, a)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var changed []Activity
+	for _, a := range candidates {
+		rawURL, err := db.BrowserURL(a.StartTime, a.EndTime)
+		if err != nil {
+			return nil, err
+		}
+		if rawURL != "" && rawURL != a.URL {
+			a.URL = rawURL
+			changed = append(changed, a)
+		}
+	}
+	return changed, nil
+}
+
+// browserRange restreint une requête sur browser_events aux visites web qui
+// chevauchent [start, end) ; les arguments commencent par les bornes de
+// browserSecondsExpr
+func browserRange(start, end time.Time) (string, []any) {
+	return ` WHERE domain IS NOT NULL AND start_time < ? AND end_time > ?`, []any{end, start, end, start}
+}
+
+// browserSecondsExpr est la durée d'une visite limitée à la période
+const browserSecondsExpr = `CAST(ROUND((MIN(julianday(end_time), julianday(?)) - MAX(julianday(start_time), julianday(?))) * 86400) AS INTEGER)`
+
+// GetDomainStats retourne au plus limit domaines visités sur la période, le
+// temps le plus long d'abord
+func (db *DB) GetDomainStats(start, end time.Time, limit int) ([]BrowserStat, error) {
+	where, args := browserRange(start, end)
+	return db.browserStats(`
+		SELECT domain, '', COUNT(*), SUM(`+browserSecondsExpr+`) AS total, MAX(end_time)
+		FROM browser_events`+where+`
+		GROUP BY domain
+		ORDER BY total DESC, domain
+		LIMIT ?`, append(args, limit)...)
+}
+
+// GetPathStats retourne au plus limit pages (domaine et chemin, sans
+// paramètres) visitées sur la période, d'un seul domaine si domain n'est pas
+// vide, le temps le plus long d'abord
+func (db *DB) GetPathStats(start, end time.Time, domain string, limit int) ([]BrowserStat, error) {
+	where, args := browserRange(start, end)
+	if domain != "" {
+		where += ` AND domain = ?`
+		args = append(args, strings.TrimPrefix(strings.ToLower(domain), "www."))
+	}
+	return db.browserStats(`
+		SELECT domain, COALESCE(path, '/'), COUNT(*), SUM(`+browserSecondsExpr+`) AS total, MAX(end_time)
+		FROM browser_events`+where+`
+		GROUP BY domain, path
+		ORDER BY total DESC, domain, path
+		LIMIT ?`, append(args, limit)...)
+}
+
+func (db *DB) browserStats(query string, args ...any) ([]BrowserStat, error) {
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []BrowserStat
+	for rows.Next() {
+		var s BrowserStat
+		var last string
+		if err := rows.Scan(&s.Domain, &s.Path, &s.Visits, &s.Seconds, &last); err != nil {
+			return nil, err
+		}
+		s.LastSeen, _ = parseStoredTime(last)
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"errors"
+	"time"
+
+	"trackmytime/internal/rules"
+)
+
+// ErrCategoryNotFound est retourné quand une catégorie n'existe pas
+var ErrCategoryNotFound = errors.New("catégorie introuvable")
+
+const configCategoriesSeeded = "categories_seeded"
+
+// productivityExpr donne la productivité d'une activité (jointure c sur
+// categories) ; les activités sans catégorie ou de catégorie inconnue sont neutres
+const productivityExpr = `COALESCE(c.productivity, 'neutral')`
+
+// ListCategories retourne les catégories triées par nom
+func (db *DB) ListCategories() ([]rules.Category, error) {
+	rows, err := db.conn.Query(`SELECT name, productivity FROM categories ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []rules.Category
+	for rows.Next() {
+		var c rules.Category
+		if err := rows.Scan(&c.Name, &c.Productivity); err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+	return categories, rows.Err()
+}
+
+// SetCategory crée une catégorie ou change sa productivité. Le changement
+// s'applique aussi aux activités déjà enregistrées.
+func (db *DB) SetCategory(c rules.Category) error {
+	_, err := db.conn.Exec(`
+		INSERT INTO categories (name, productivity) VALUES (?, ?)
+		ON CONFLICT(name) DO UPDATE SET productivity = excluded.productivity
+	`, c.Name, c.Productivity)
+	return err
+}
+
+// DeleteCategory supprime une catégorie ; ses activités deviennent neutres
+func (db *DB) DeleteCategory(name string) error {
+	result, err := db.conn.Exec(`DELETE FROM categories WHERE name = ?`, name)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrCategoryNotFound
+	}
+	return nil
+}
+
+// EnsureDefaultCategories installe les catégories par défaut au premier démarrage
+func (db *DB) EnsureDefaultCategories() error {
+	seeded, err := db.GetConfig(configCategoriesSeeded)
+	if err != nil || seeded != "" {
+		return err
+	}
+
+	for _, c := range rules.DefaultCategories() {
+		if _, err := db.conn.Exec(`INSERT OR IGNORE INTO categories (name, productivity) VALUES (?, ?)`, c.Name, c.Productivity); err != nil {
+			return err
+		}
+	}
+	return db.SetConfig(configCategoriesSeeded, "1")
+}
+
+// CategoryStat est le temps actif d'une catégorie
+type CategoryStat struct {
+	Category     string // vide = sans catégorie
+	Productivity rules.Productivity
+	Seconds      int64
+}
+
+// GetStatsByCategory retourne le temps actif par catégorie, le plus long d'abord
+func (db *DB) GetStatsByCategory(start, end time.Time) ([]CategoryStat, error) {
+	query := `
+		SELECT COALESCE(a.category, '') AS cat, ` + productivityExpr + `, SUM(a.duration_seconds) AS total_duration
+		FROM activities a
+		LEFT JOIN categories c ON c.name = a.category
+		WHERE a.start_time >= ? AND a.start_time < ? AND a.is_idle = 0
+		GROUP BY cat
+		ORDER BY total_duration DESC
+	`
+
+	rows, err := db.conn.Query(query, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []CategoryStat
+	for rows.Next() {
+		var s CategoryStat
+		if err := rows.Scan(&s.Category, &s.Productivity, &s.Seconds); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+// DailyProductivity est le temps actif d'une journée par niveau de productivité
+type DailyProductivity struct {
+	Date        time.Time // minuit local
+	Productive  int64
+	Neutral     int64
+	Distracting int64
+}
+
+// Add ajoute seconds au niveau de productivité p (neutre si inconnu)
+func (d *DailyProductivity) Add(p rules.Productivity, seconds int64) {
+	switch p {
+	case rules.Productive:
+		d.Productive += seconds
+	case rules.Distracting:
+		d.Distracting += seconds
+	default:
+		d.Neutral += seconds
+	}
+}
+
+// Score retourne le score de productivité de 0 (distrayant) à 100 (productif)
+func (d DailyProductivity) Score() float64 {
+	total := d.Productive + d.Neutral + d.Distracting
+	if total == 0 {
+		return 0
+	}
+	weighted := float64(d.Productive)*rules.Productive.Weight() +
+		float64(d.Neutral)*rules.Neutral.Weight() +
+		float64(d.Distracting)*rules.Distracting.Weight()
+	return 100 * weighted / float64(total)
+}
+
+// GetDailyProductivity retourne le temps actif par jour et par niveau de
+// productivité, pour chaque jour ayant de l'activité
+func (db *DB) GetDailyProductivity(start, end time.Time) ([]DailyProductivity, error) {
+	query := `
+		SELECT date(a.start_time, 'localtime') AS day, ` + productivityExpr + ` AS productivity, SUM(a.duration_seconds)
+		FROM activities a
+		LEFT JOIN categories c ON c.name = a.category
+		WHERE a.start_time >= ? AND a.start_time < ? AND a.is_idle = 0
+		GROUP BY day, productivity
+		ORDER BY day
+	`
+
+	rows, err := db.conn.Query(query, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var days []DailyProductivity
+	for rows.Next() {
+		var dayStr string
+		var productivity rules.Productivity
+		var seconds int64
+		if err := rows.Scan(&dayStr, &productivity, &seconds); err != nil {
+			return nil, err
+		}
+
+		day, err := time.ParseInLocation("2006-01-02", dayStr, time.Local)
+		if err != nil {
+			continue
+		}
+		if len(days) == 0 || !days[len(days)-1].Date.Equal(day) {
+			days = append(days, DailyProductivity{Date: day})
+		}
+
+		days[len(days)-1].Add(productivity, seconds)
+	}
+	return days, rows.Err()
+}
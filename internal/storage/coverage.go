@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"time"
+
+	"trackmytime/internal/rules"
+)
+
+// unclassifiedExpr est vrai pour une activité qu'aucune règle n'a nommée :
+// nom enrichi égal au nom de l'application, ou onglet regroupé sous "Autres"
+const unclassifiedExpr = `(COALESCE(enriched_name, app_name) = app_name OR enriched_name = ?)`
+
+// Coverage est la part du temps actif nommée par les règles d'enrichissement
+type Coverage struct {
+	TotalSeconds    int64
+	FallbackSeconds int64 // nom enrichi = nom de l'application
+	OtherSeconds    int64 // onglets regroupés sous rules.OtherSites
+}
+
+// UnclassifiedSeconds est le temps qu'aucune règle n'a nommé
+func (c Coverage) UnclassifiedSeconds() int64 {
+	return c.FallbackSeconds + c.OtherSeconds
+}
+
+// GetCoverage mesure la part du temps actif de la période nommée par les règles
+func (db *DB) GetCoverage(start, end time.Time) (Coverage, error) {
+	var c Coverage
+	err := db.conn.QueryRow(`
+		SELECT COALESCE(SUM(duration_seconds), 0),
+			COALESCE(SUM(CASE WHEN COALESCE(enriched_name, app_name) = app_name THEN duration_seconds END), 0),
+			COALESCE(SUM(CASE WHEN enriched_name = ? AND enriched_name <> app_name THEN duration_seconds END), 0)
+		FROM activities
+		WHERE start_time >= ? AND start_time < ? AND is_idle = 0
+	`, rules.OtherSites, start, end).Scan(&c.TotalSeconds, &c.FallbackSeconds, &c.OtherSeconds)
+	return c, err
+}
+
+// UnclassifiedTitle est un titre de fenêtre qu'aucune règle n'a nommé
+type UnclassifiedTitle struct {
+	AppName      string
+	EnrichedName string // nom de l'application ou rules.OtherSites
+	WindowTitle  string
+	Activities   int
+	Seconds      int64
+}
+
+// GetUnclassifiedTitles retourne au plus limit titres non classés de la
+// période, le temps le plus long d'abord. Les titres chiffrés sont regroupés
+// par leur index : seul le titre retenu pour chaque groupe est déchiffré.
+func (db *DB) GetUnclassifiedTitles(start, end time.Time, limit int) ([]UnclassifiedTitle, error) {
+	c, err := db.cipher(db.conn)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.conn.Query(`
+		SELECT app_name, COALESCE(enriched_name, app_name), COALESCE(MAX(window_title), ''),
+			COUNT(*), SUM(duration_seconds) AS total_duration
+		FROM activities
+		WHERE start_time >= ? AND start_time < ? AND is_idle = 0 AND `+unclassifiedExpr+`
+		GROUP BY app_name, COALESCE(title_index, window_title, '')
+		ORDER BY total_duration DESC
+		LIMIT ?
+	`, start, end, rules.OtherSites, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var titles []UnclassifiedTitle
+	for rows.Next() {
+		var t UnclassifiedTitle
+		if err := rows.Scan(&t.AppName, &t.EnrichedName, &t.WindowTitle, &t.Activities, &t.Seconds); err != nil {
+			return nil, err
+		}
+		if t.WindowTitle, err = c.Open(t.WindowTitle); err != nil {
+			return nil, err
+		}
+		titles = append(titles, t)
+	}
+	return titles, rows.Err()
+}
@@ -2,9 +2,18 @@ package storage
 
 import (
 	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
+
+	"trackmytime/internal/documents"
+	"trackmytime/internal/entities"
+	"trackmytime/internal/gitinfo"
+	"trackmytime/internal/rules"
+	"trackmytime/internal/vault"
 )
 
 // Activity représente une activité trackée
@@ -12,6 +21,20 @@ type Activity struct {
 	ID           int64
 	AppName      string
 	EnrichedName string
+	Category     string
+	Productivity rules.Productivity // déduite de la catégorie (vide pour l'inactivité)
+	Project      string
+	Client       string // client du projet
+	Tags         []string
+	Entities     []entities.Entity  // tickets, dépôts, PR et MR extraits du titre et de l'URL
+	Document     documents.Document // fichier ouvert dans un éditeur (vide sinon)
+	Command      string             // commande au premier plan d'un terminal
+	WorkingDir   string             // dossier courant de Command
+	SSHHost      string             // hôte distant si Command est ssh
+	Repo         gitinfo.Repo       // dépôt git du terminal ou de l'éditeur (vide sinon)
+	InferredSite string             // site déduit du titre, même sous le seuil de confiance
+	Confidence   *float64           // confiance de InferredSite (nil = pas de déduction)
+	URL          string             // URL de l'onglet signalée par l'extension (navigateurs)
 	WindowTitle  string
 	ProcessPath  string
 	StartTime    time.Time
@@ -23,6 +46,12 @@ type Activity struct {
 // DB gère la connexion à la base de données
 type DB struct {
 	conn *sql.DB
+
+	// Chiffrement des titres et chemins, relu par cipher quand les
+	// paramètres enregistrés (vaultConfig) changent
+	vaultMu     sync.Mutex
+	vaultConfig string
+	vault       *vault.Cipher
 }
 
 // NewDB crée une nouvelle connexion à la base de données
@@ -39,6 +68,12 @@ func NewDB(dbPath string) (*DB, error) {
 		return nil, err
 	}
 
+	// Vérifier dès l'ouverture que la clé d'une base chiffrée est disponible
+	if _, err := db.cipher(db.conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("base chiffrée: %w", err)
+	}
+
 	return db, nil
 }
 
@@ -47,19 +82,69 @@ func (db *DB) Close() error {
 	return db.conn.Close()
 }
 
-// InsertActivity insère une nouvelle activité
+// InsertActivity insère une nouvelle activité, ses tags et ses entités.
+// Les tags invalides sont ignorés.
 func (db *DB) InsertActivity(activity *Activity) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := db.insertActivity(tx, activity); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// insertActivity insère une activité dans la transaction tx ; activity.ID
+// et activity.Tags sont mis à jour
+func (db *DB) insertActivity(tx *sql.Tx, activity *Activity) error {
+	var tags []string
+	for _, tag := range activity.Tags {
+		if name, err := NormalizeTag(tag); err == nil {
+			tags = append(tags, name)
+		}
+	}
+
+	if activity.Project != "" {
+		if err := ensureProject(tx, activity.Project); err != nil {
+			return err
+		}
+	}
+	c, err := db.cipher(tx)
+	if err != nil {
+		return err
+	}
+
 	query := `
-		INSERT INTO activities (app_name, enriched_name, window_title, process_path, start_time, end_time, duration_seconds, is_idle)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO activities (app_name, enriched_name, category, project, inferred_site, site_confidence,
+			file_name, file_extension, language, command, cwd, ssh_host, repo_root, repo_remote, branch, url,
+			window_title, title_index, process_path, start_time, end_time, duration_seconds, is_idle)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	result, err := db.conn.Exec(
+	result, err := tx.Exec(
 		query,
 		activity.AppName,
 		activity.EnrichedName,
-		activity.WindowTitle,
-		activity.ProcessPath,
+		nullIfEmpty(activity.Category),
+		nullIfEmpty(activity.Project),
+		nullIfEmpty(activity.InferredSite),
+		activity.Confidence,
+		nullIfEmpty(activity.Document.FileName),
+		nullIfEmpty(activity.Document.Extension),
+		nullIfEmpty(activity.Document.Language),
+		nullIfEmpty(activity.Command),
+		nullIfEmpty(activity.WorkingDir),
+		nullIfEmpty(activity.SSHHost),
+		nullIfEmpty(activity.Repo.Root),
+		nullIfEmpty(activity.Repo.Remote),
+		nullIfEmpty(activity.Repo.Branch),
+		nullIfEmpty(c.Seal(activity.URL)),
+		c.Seal(activity.WindowTitle),
+		nullIfEmpty(c.Index(activity.WindowTitle)),
+		c.Seal(activity.ProcessPath),
 		activity.StartTime,
 		activity.EndTime,
 		activity.DurationSecs,
@@ -75,20 +160,108 @@ func (db *DB) InsertActivity(activity *Activity) error {
 		return err
 	}
 
+	if err := addTags(tx, id, tags, TagSourceRule); err != nil {
+		return err
+	}
+	if err := addEntities(tx, id, activity.Entities); err != nil {
+		return err
+	}
 	activity.ID = id
+	activity.Tags = tags
+	return nil
+}
+
+// deleteActivity supprime une activité avec ses tags et ses entités
+func deleteActivity(e execer, id int64) error {
+	for _, query := range []string{
+		`DELETE FROM activity_tags WHERE activity_id = ?`,
+		`DELETE FROM activity_entities WHERE activity_id = ?`,
+		`DELETE FROM activities WHERE id = ?`,
+	} {
+		if _, err := e.Exec(query, id); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// nullIfEmpty stocke NULL plutôt qu'une chaîne vide
+func nullIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// ActivityFilter restreint les activités retournées par QueryActivities
+type ActivityFilter struct {
+	Start        time.Time
+	End          time.Time
+	AppName      string // vide = toutes les applications
+	EnrichedName string // vide = tous les noms enrichis
+	Project      string // vide = tous les projets
+	Unassigned   bool   // uniquement le temps non attribué à un projet
+	Tag          string // vide = tous les tags
+	ExcludeIdle  bool
+	Limit        int // 0 = pas de limite
+}
+
 // GetActivitiesByDateRange retourne les activités dans une plage de dates
 func (db *DB) GetActivitiesByDateRange(start, end time.Time) ([]Activity, error) {
+	return db.QueryActivities(ActivityFilter{Start: start, End: end})
+}
+
+// QueryActivities retourne les activités correspondant au filtre, les plus récentes d'abord
+func (db *DB) QueryActivities(filter ActivityFilter) ([]Activity, error) {
 	query := `
-		SELECT id, app_name, window_title, process_path, start_time, end_time, duration_seconds, is_idle
+		SELECT activities.id, app_name, COALESCE(enriched_name, app_name),
+			COALESCE(category, ''), CASE WHEN is_idle THEN '' ELSE ` + productivityExpr + ` END,
+			COALESCE(p.name, activities.project, ''), COALESCE(p.client, ''), ` + tagNamesExpr + `, COALESCE(inferred_site, ''), site_confidence,
+			COALESCE(file_name, ''), COALESCE(file_extension, ''), COALESCE(language, ''),
+			COALESCE(command, ''), COALESCE(cwd, ''), COALESCE(ssh_host, ''),
+			COALESCE(repo_root, ''), COALESCE(repo_remote, ''), COALESCE(branch, ''), COALESCE(url, ''), COALESCE(window_title, ''), COALESCE(process_path, ''), start_time, end_time, duration_seconds, is_idle
 		FROM activities
+		LEFT JOIN categories c ON c.name = activities.category
+		LEFT JOIN projects p ON p.name = activities.project
 		WHERE start_time >= ? AND start_time < ?
-		ORDER BY start_time DESC
 	`
+	args := []any{filter.Start, filter.End}
 
-	rows, err := db.conn.Query(query, start, end)
+	if filter.AppName != "" {
+		query += " AND app_name = ?"
+		args = append(args, filter.AppName)
+	}
+	if filter.EnrichedName != "" {
+		query += " AND COALESCE(enriched_name, app_name) = ?"
+		args = append(args, filter.EnrichedName)
+	}
+	if filter.Project != "" {
+		query += " AND activities.project = ? COLLATE NOCASE"
+		args = append(args, filter.Project)
+	}
+	if filter.Unassigned {
+		query += " AND p.id IS NULL"
+	}
+	if filter.Tag != "" {
+		tagSQL, tagArgs := tagFilter(filter.Tag)
+		query += tagSQL
+		args = append(args, tagArgs...)
+	}
+	if filter.ExcludeIdle {
+		query += " AND is_idle = 0"
+	}
+
+	query += " ORDER BY start_time DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	c, err := db.cipher(db.conn)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.conn.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -97,9 +270,28 @@ func (db *DB) GetActivitiesByDateRange(start, end time.Time) ([]Activity, error)
 	var activities []Activity
 	for rows.Next() {
 		var a Activity
+		var tags string
 		err := rows.Scan(
 			&a.ID,
 			&a.AppName,
+			&a.EnrichedName,
+			&a.Category,
+			&a.Productivity,
+			&a.Project,
+			&a.Client,
+			&tags,
+			&a.InferredSite,
+			&a.Confidence,
+			&a.Document.FileName,
+			&a.Document.Extension,
+			&a.Document.Language,
+			&a.Command,
+			&a.WorkingDir,
+			&a.SSHHost,
+			&a.Repo.Root,
+			&a.Repo.Remote,
+			&a.Repo.Branch,
+			&a.URL,
 			&a.WindowTitle,
 			&a.ProcessPath,
 			&a.StartTime,
@@ -110,10 +302,16 @@ func (db *DB) GetActivitiesByDateRange(start, end time.Time) ([]Activity, error)
 		if err != nil {
 			return nil, err
 		}
+		if err := openActivity(c, &a); err != nil {
+			return nil, err
+		}
+		if tags != "" {
+			a.Tags = strings.Split(tags, ",")
+		}
 		activities = append(activities, a)
 	}
 
-	return activities, nil
+	return activities, rows.Err()
 }
 
 // GetTodayActivities retourne les activités du jour
@@ -170,15 +368,43 @@ func (db *DB) SetConfig(key, value string) error {
 
 // GetStatsByApp retourne les statistiques groupées par application
 func (db *DB) GetStatsByApp(start, end time.Time) (map[string]int64, error) {
+	return db.GetTaggedStatsByApp(start, end, "")
+}
+
+// ActivityTotals compte les activités d'une période
+type ActivityTotals struct {
+	Activities  int
+	IdleSeconds int64
+}
+
+// GetActivityTotals compte les activités commençant dans [start, end) qui
+// portent le tag (toutes si tag est vide), comme QueryActivities, et
+// additionne leur temps d'inactivité ; les titres et chemins ne sont ni lus
+// ni déchiffrés
+func (db *DB) GetActivityTotals(start, end time.Time, tag string) (ActivityTotals, error) {
+	tagSQL, tagArgs := tagFilter(tag)
+	var totals ActivityTotals
+	err := db.conn.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(CASE WHEN is_idle THEN duration_seconds ELSE 0 END), 0)
+		FROM activities
+		WHERE start_time >= ? AND start_time < ?`+tagSQL,
+		append([]any{start, end}, tagArgs...)...).Scan(&totals.Activities, &totals.IdleSeconds)
+	return totals, err
+}
+
+// GetTaggedStatsByApp retourne les statistiques par application des
+// activités portant le tag donné (toutes si tag est vide)
+func (db *DB) GetTaggedStatsByApp(start, end time.Time, tag string) (map[string]int64, error) {
+	tagSQL, tagArgs := tagFilter(tag)
 	query := `
 		SELECT app_name, SUM(duration_seconds) as total_duration
 		FROM activities
-		WHERE start_time >= ? AND start_time <= ? AND is_idle = 0
+		WHERE start_time >= ? AND start_time <= ? AND is_idle = 0` + tagSQL + `
 		GROUP BY app_name
 		ORDER BY total_duration DESC
 	`
 
-	rows, err := db.conn.Query(query, start, end)
+	rows, err := db.conn.Query(query, append([]any{start, end}, tagArgs...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -283,18 +509,25 @@ func (db *DB) GetDailyStats(start, end time.Time) ([]int64, error) {
 
 // GetGroupedStats retourne les stats groupées par app puis par enriched_name
 func (db *DB) GetGroupedStats(start, end time.Time) (map[string]map[string]int64, error) {
+	return db.GetTaggedGroupedStats(start, end, "")
+}
+
+// GetTaggedGroupedStats retourne les stats groupées par app puis par
+// enriched_name des activités portant le tag donné (toutes si tag est vide)
+func (db *DB) GetTaggedGroupedStats(start, end time.Time, tag string) (map[string]map[string]int64, error) {
+	tagSQL, tagArgs := tagFilter(tag)
 	query := `
 		SELECT 
 			app_name,
 			COALESCE(enriched_name, app_name) as enriched,
 			SUM(duration_seconds) as total_duration
 		FROM activities
-		WHERE start_time >= ? AND start_time <= ? AND is_idle = 0
+		WHERE start_time >= ? AND start_time <= ? AND is_idle = 0` + tagSQL + `
 		GROUP BY app_name, enriched
 		ORDER BY app_name, total_duration DESC
 	`
 
-	rows, err := db.conn.Query(query, start, end)
+	rows, err := db.conn.Query(query, append([]any{start, end}, tagArgs...)...)
 	if err != nil {
 		return nil, err
 	}
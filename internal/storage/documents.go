@@ -0,0 +1,96 @@
+package storage
+
+import "time"
+
+// LanguageStat est le temps actif passé sur des fichiers d'un langage
+type LanguageStat struct {
+	Language   string
+	Files      int // fichiers distincts
+	Activities int
+	Seconds    int64
+}
+
+// FileStat est le temps actif passé sur un fichier dans un éditeur
+type FileStat struct {
+	FileName   string
+	Extension  string
+	Language   string
+	Project    string
+	Activities int
+	Seconds    int64
+	LastSeen   time.Time
+}
+
+// documentFilter restreint une requête sur activities (alias a) à la
+// période, aux fichiers d'éditeur et, si project n'est pas vide, à un projet
+func documentFilter(start, end time.Time, project string) (string, []any) {
+	where := ` WHERE a.start_time >= ? AND a.start_time < ? AND a.is_idle = 0 AND a.file_name IS NOT NULL`
+	args := []any{start, end}
+	if project != "" {
+		where += ` AND a.project = ? COLLATE NOCASE`
+		args = append(args, project)
+	}
+	return where, args
+}
+
+// GetStatsByLanguage retourne le temps passé par langage sur la période (d'un
+// seul projet si project n'est pas vide), le temps le plus long d'abord. Les
+// fichiers de langage inconnu sont regroupés sous un langage vide.
+func (db *DB) GetStatsByLanguage(start, end time.Time, project string) ([]LanguageStat, error) {
+	where, args := documentFilter(start, end, project)
+	rows, err := db.conn.Query(`
+		SELECT COALESCE(a.language, '') AS lang, COUNT(DISTINCT a.file_name), COUNT(*),
+			SUM(a.duration_seconds) AS total_duration
+		FROM activities a`+where+`
+		GROUP BY lang
+		ORDER BY total_duration DESC
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []LanguageStat
+	for rows.Next() {
+		var s LanguageStat
+		if err := rows.Scan(&s.Language, &s.Files, &s.Activities, &s.Seconds); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+// GetTopFiles retourne au plus limit fichiers de la période (d'un seul projet
+// si project n'est pas vide), le temps le plus long d'abord. Un même nom de
+// fichier compte séparément dans chaque projet.
+func (db *DB) GetTopFiles(start, end time.Time, project string, limit int) ([]FileStat, error) {
+	where, args := documentFilter(start, end, project)
+	rows, err := db.conn.Query(`
+		SELECT a.file_name, COALESCE(a.file_extension, ''), COALESCE(a.language, ''),
+			COALESCE(p.name, a.project, '') AS proj, COUNT(*), SUM(a.duration_seconds) AS total_duration,
+			MAX(a.end_time)
+		FROM activities a
+		LEFT JOIN projects p ON p.name = a.project`+where+`
+		GROUP BY a.file_name, proj
+		ORDER BY total_duration DESC
+		LIMIT ?
+	`, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []FileStat
+	for rows.Next() {
+		var s FileStat
+		var last string
+		err := rows.Scan(&s.FileName, &s.Extension, &s.Language, &s.Project, &s.Activities, &s.Seconds, &last)
+		if err != nil {
+			return nil, err
+		}
+		s.LastSeen, _ = parseStoredTime(last)
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
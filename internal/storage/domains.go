@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"trackmytime/internal/domains"
+)
+
+// siteRulesKey est la clé de config des règles de regroupement des sites
+const siteRulesKey = "site_rules"
+
+// SiteStat est le temps actif passé sur un site (domaine enregistrable,
+// éventuellement suivi des segments de chemin d'une règle)
+type SiteStat struct {
+	Site       string // github.com/golang, google.com
+	Domain     string // domaine enregistrable
+	Hosts      []string
+	Activities int
+	Seconds    int64
+	LastSeen   time.Time
+}
+
+// SiteReport détaille le temps passé sur un site ou un domaine : par hôte,
+// par site (sous-chemins regroupés par une règle) et par page
+type SiteReport struct {
+	Total SiteStat
+	Hosts []SiteStat // Site est l'hôte (docs.google.com)
+	Sites []SiteStat // Site est le nom du regroupement (github.com/golang)
+	Pages []SiteStat // Site est l'hôte suivi du chemin
+}
+
+// SiteRules retourne les règles de regroupement des sites, ou les règles
+// par défaut si elles n'ont pas été personnalisées
+func (db *DB) SiteRules() ([]domains.SiteRule, error) {
+	value, err := db.GetConfig(siteRulesKey)
+	if err != nil || value == "" {
+		return domains.Defaults(), err
+	}
+	var rules []domains.SiteRule
+	if err := json.Unmarshal([]byte(value), &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// SetSiteRules remplace les règles de regroupement des sites ; nil revient
+// aux règles par défaut. Les statistiques les appliquent à la lecture : rien
+// n'est à retraiter.
+func (db *DB) SetSiteRules(rules []domains.SiteRule) error {
+	if rules == nil {
+		_, err := db.conn.Exec(`DELETE FROM config WHERE key = ?`, siteRulesKey)
+		return err
+	}
+	encoded, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+	_, err = db.conn.Exec(`
+		INSERT INTO config (key, value) VALUES (?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = CURRENT_TIMESTAMP
+	`, siteRulesKey, string(encoded))
+	return err
+}
+
+// siteActivity est une activité web réduite à son site
+type siteActivity struct {
+	site    domains.Site
+	seconds int64
+	end     time.Time
+}
+
+// siteActivities retourne les activités actives de la période dont l'URL
+// est une page web, avec leur site selon g. Les URL sont déchiffrées : le
+// site (chemin compris pour github.com/golang) ne peut pas être calculé en SQL.
+func (db *DB) siteActivities(start, end time.Time, g *domains.Grouper) ([]siteActivity, error) {
+	c, err := db.cipher(db.conn)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.conn.Query(`
+		SELECT url, duration_seconds, end_time FROM activities
+		WHERE start_time >= ? AND start_time <= ? AND is_idle = 0 AND url IS NOT NULL AND url <> ''
+	`, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var activities []siteActivity
+	for rows.Next() {
+		var rawURL string
+		var a siteActivity
+		if err := rows.Scan(&rawURL, &a.seconds, &a.end); err != nil {
+			return nil, err
+		}
+		if rawURL, err = c.Open(rawURL); err != nil {
+			return nil, err
+		}
+		site, ok := g.Parse(rawURL)
+		if !ok {
+			continue
+		}
+		a.site = site
+		activities = append(activities, a)
+	}
+	return activities, rows.Err()
+}
+
+// GetStatsBySite retourne au plus limit sites de la période, regroupés selon
+// g, le temps le plus long d'abord
+func (db *DB) GetStatsBySite(start, end time.Time, g *domains.Grouper, limit int) ([]SiteStat, error) {
+	activities, err := db.siteActivities(start, end, g)
+	if err != nil {
+		return nil, err
+	}
+	stats := groupSites(activities, func(s domains.Site) string { return s.Name })
+	if limit > 0 && len(stats) > limit {
+		stats = stats[:limit]
+	}
+	return stats, nil
+}
+
+// GetSiteReport détaille le temps passé sur name, un site (github.com/golang)
+// ou un domaine enregistrable (google.com, qui couvre tous ses hôtes et
+// sites). Total.Activities vaut 0 si rien n'a été visité.
+func (db *DB) GetSiteReport(start, end time.Time, g *domains.Grouper, name string) (SiteReport, error) {
+	name = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), "/")
+	name = strings.TrimPrefix(name, "www.")
+	report := SiteReport{Total: SiteStat{Site: name}}
+
+	activities, err := db.siteActivities(start, end, g)
+	if err != nil {
+		return report, err
+	}
+	var matched []siteActivity
+	for _, a := range activities {
+		if a.site.Name == name || a.site.Domain == name {
+			matched = append(matched, a)
+		}
+	}
+
+	for _, a := range matched {
+		addSiteActivity(&report.Total, a)
+	}
+	report.Total.Site = name
+	report.Hosts = groupSites(matched, func(s domains.Site) string { return s.Host })
+	report.Sites = groupSites(matched, func(s domains.Site) string { return s.Name })
+	report.Pages = groupSites(matched, func(s domains.Site) string { return s.Host + s.Path })
+	return report, nil
+}
+
+// groupSites agrège des activités par la clé retournée par key, le temps le
+// plus long d'abord
+func groupSites(activities []siteActivity, key func(domains.Site) string) []SiteStat {
+	index := make(map[string]int)
+	var stats []SiteStat
+	for _, a := range activities {
+		k := key(a.site)
+		i, ok := index[k]
+		if !ok {
+			i = len(stats)
+			index[k] = i
+			stats = append(stats, SiteStat{Site: k, Domain: a.site.Domain})
+		}
+		addSiteActivity(&stats[i], a)
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		if stats[i].Seconds != stats[j].Seconds {
+			return stats[i].Seconds > stats[j].Seconds
+		}
+		return stats[i].Site < stats[j].Site
+	})
+	return stats
+}
+
+// addSiteActivity ajoute une activité au total d'un site
+func addSiteActivity(stat *SiteStat, a siteActivity) {
+	if stat.Domain == "" {
+		stat.Domain = a.site.Domain
+	}
+	stat.Activities++
+	stat.Seconds += a.seconds
+	if a.end.After(stat.LastSeen) {
+		stat.LastSeen = a.end
+	}
+	for _, host := range stat.Hosts {
+		if host == a.site.Host {
+			return
+		}
+	}
+	stat.Hosts = append(stat.Hosts, a.site.Host)
+	sort.Strings(stat.Hosts)
+}
@@ -0,0 +1,306 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"trackmytime/internal/vault"
+)
+
+// encryptionKey est la clé de config des paramètres de chiffrement
+const encryptionKey = "encryption"
+
+// recryptBatch est le nombre d'activités relues par requête lors d'un
+// changement de clé
+const recryptBatch = 500
+
+// queryRower est implémenté par *sql.DB et *sql.Tx
+type queryRower interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// cipher retourne le chiffrement en vigueur (nil = en clair). Les paramètres
+// sont relus à chaque appel, dans la transaction d'écriture le cas échéant :
+// une activation ou une rotation faite par la commande encryption est prise
+// en compte par l'agent sans redémarrage, et aucune activité n'est écrite
+// avec une clé retirée.
+func (db *DB) cipher(q queryRower) (*vault.Cipher, error) {
+	var value string
+	err := q.QueryRow(`SELECT value FROM config WHERE key = ?`, encryptionKey).Scan(&value)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	db.vaultMu.Lock()
+	defer db.vaultMu.Unlock()
+	if value == db.vaultConfig && (value == "" || db.vault != nil) {
+		return db.vault, nil
+	}
+	if value == "" {
+		db.vault, db.vaultConfig = nil, ""
+		return nil, nil
+	}
+	var s vault.Settings
+	if err := json.Unmarshal([]byte(value), &s); err != nil {
+		return nil, err
+	}
+	c, err := s.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	db.vault, db.vaultConfig = c, value
+	return c, nil
+}
+
+// openActivity déchiffre le titre, le chemin et l'URL d'une activité lue en base
+func openActivity(c *vault.Cipher, a *Activity) error {
+	var err error
+	if a.WindowTitle, err = c.Open(a.WindowTitle); err != nil {
+		return err
+	}
+	if a.ProcessPath, err = c.Open(a.ProcessPath); err != nil {
+		return err
+	}
+	a.URL, err = c.Open(a.URL)
+	return err
+}
+
+// Encryption retourne les paramètres du chiffrement, nil s'il est désactivé
+func (db *DB) Encryption() (*vault.Settings, error) {
+	value, err := db.GetConfig(encryptionKey)
+	if err != nil || value == "" {
+		return nil, err
+	}
+	var s vault.Settings
+	if err := json.Unmarshal([]byte(value), &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// sealedTable décrit les colonnes chiffrées d'une table autre que
+// activities ; where restreint les lignes concernées
+type sealedTable struct {
+	name    string
+	columns []string
+	where   string
+}
+
+// sealedTables sont les tables annexes chiffrées avec les activités :
+// onglets de l'extension (le domaine et le chemin restent en clair pour les
+// statistiques), données des événements ActivityWatch de fenêtre, d'onglet
+// et d'éditeur, et dernier titre des sites candidats
+var sealedTables = []sealedTable{
+	{name: "browser_events", columns: []string{"url", "tab_title"}},
+	{name: "aw_events", columns: []string{"data"}, where: `bucket_id IN (SELECT id FROM aw_buckets WHERE type IN ('` +
+		AWTypeWindow + `', '` + AWTypeWebTab + `', '` + AWTypeEditor + `'))`},
+	{name: "site_candidates", columns: []string{"last_title"}},
+}
+
+// condition retourne la condition SQL des lignes de t ayant une valeur, ou
+// une valeur chiffrée si sealed
+func (t sealedTable) condition(sealed bool) string {
+	var parts []string
+	for _, column := range t.columns {
+		if sealed {
+			parts = append(parts, column+` LIKE '`+vault.Prefix+`%'`)
+		} else {
+			parts = append(parts, `COALESCE(`+column+`, '') <> ''`)
+		}
+	}
+	condition := `(` + strings.Join(parts, ` OR `) + `)`
+	if t.where != "" && !sealed {
+		condition += ` AND ` + t.where
+	}
+	return condition
+}
+
+// EncryptionCount compte les lignes d'une table ayant une valeur à
+// chiffrer, et celles dont une valeur est chiffrée
+type EncryptionCount struct {
+	Table  string
+	Total  int
+	Sealed int
+}
+
+// EncryptionCounts compte, pour les activités (titre, chemin ou URL) puis
+// pour chaque table annexe, les lignes à chiffrer et celles chiffrées
+func (db *DB) EncryptionCounts() ([]EncryptionCount, error) {
+	tables := append([]sealedTable{{name: "activities", columns: []string{"window_title", "process_path", "url"}}}, sealedTables...)
+	counts := make([]EncryptionCount, 0, len(tables))
+	for _, t := range tables {
+		c := EncryptionCount{Table: t.name}
+		err := db.conn.QueryRow(`
+			SELECT COUNT(*), COALESCE(SUM(CASE WHEN `+t.condition(true)+` THEN 1 END), 0)
+			FROM `+t.name+` WHERE `+t.condition(false)+` OR `+t.condition(true)).Scan(&c.Total, &c.Sealed)
+		if err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+	return counts, nil
+}
+
+// SetEncryption chiffre tous les titres, chemins et URL des activités et
+// les colonnes de sealedTables avec la clé next (activation ou rotation), ou
+// les remet en clair si next est nil, et enregistre settings dans la même
+// transaction : la base n'est jamais chiffrée avec deux clés. Retourne le
+// nombre de lignes réécrites.
+func (db *DB) SetEncryption(settings *vault.Settings, next *vault.Cipher) (int, error) {
+	if (settings == nil) != (next == nil) {
+		return 0, errors.New("paramètres et clé de chiffrement incohérents")
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	current, err := db.cipher(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	type row struct {
+		id               int64
+		title, path, url string
+	}
+	var rewritten int
+	var afterID int64
+	for {
+		rows, err := tx.Query(`
+			SELECT id, COALESCE(window_title, ''), COALESCE(process_path, ''), COALESCE(url, '') FROM activities
+			WHERE id > ? AND (COALESCE(window_title, '') <> '' OR COALESCE(process_path, '') <> '' OR COALESCE(url, '') <> '')
+			ORDER BY id LIMIT ?
+		`, afterID, recryptBatch)
+		if err != nil {
+			return 0, err
+		}
+		var batch []row
+		for rows.Next() {
+			var r row
+			if err := rows.Scan(&r.id, &r.title, &r.path, &r.url); err != nil {
+				rows.Close()
+				return 0, err
+			}
+			batch = append(batch, r)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		afterID = batch[len(batch)-1].id
+
+		for _, r := range batch {
+			a := Activity{WindowTitle: r.title, ProcessPath: r.path, URL: r.url}
+			if err := openActivity(current, &a); err != nil {
+				return 0, err
+			}
+			_, err = tx.Exec(`UPDATE activities SET window_title = ?, process_path = ?, url = ?, title_index = ? WHERE id = ?`,
+				next.Seal(a.WindowTitle), next.Seal(a.ProcessPath), nullIfEmpty(next.Seal(a.URL)),
+				nullIfEmpty(next.Index(a.WindowTitle)), r.id)
+			if err != nil {
+				return 0, err
+			}
+			rewritten++
+		}
+	}
+
+	for _, t := range sealedTables {
+		n, err := recryptTable(tx, t, current, next)
+		if err != nil {
+			return 0, err
+		}
+		rewritten += n
+	}
+
+	if settings == nil {
+		_, err = tx.Exec(`DELETE FROM config WHERE key = ?`, encryptionKey)
+	} else {
+		var encoded []byte
+		if encoded, err = json.Marshal(settings); err != nil {
+			return 0, err
+		}
+		_, err = tx.Exec(`
+			INSERT INTO config (key, value) VALUES (?, ?)
+			ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = CURRENT_TIMESTAMP
+		`, encryptionKey, string(encoded))
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return rewritten, nil
+}
+
+// recryptTable déchiffre les colonnes de t avec current et les chiffre avec
+// next, par lots de recryptBatch lignes ; retourne le nombre de lignes
+// réécrites
+func recryptTable(tx *sql.Tx, t sealedTable, current, next *vault.Cipher) (int, error) {
+	columns := make([]string, 0, len(t.columns))
+	assignments := make([]string, 0, len(t.columns))
+	for _, column := range t.columns {
+		columns = append(columns, `COALESCE(`+column+`, '')`)
+		assignments = append(assignments, column+` = ?`)
+	}
+	query := `SELECT rowid, ` + strings.Join(columns, `, `) + ` FROM ` + t.name + `
+		WHERE rowid > ? AND (` + t.condition(false) + ` OR ` + t.condition(true) + `)
+		ORDER BY rowid LIMIT ?`
+	update := `UPDATE ` + t.name + ` SET ` + strings.Join(assignments, `, `) + ` WHERE rowid = ?`
+
+	var rewritten int
+	var afterID int64
+	for {
+		rows, err := tx.Query(query, afterID, recryptBatch)
+		if err != nil {
+			return 0, err
+		}
+		var ids []int64
+		var batch [][]string
+		for rows.Next() {
+			var id int64
+			values := make([]string, len(t.columns))
+			dest := []any{&id}
+			for i := range values {
+				dest = append(dest, &values[i])
+			}
+			if err := rows.Scan(dest...); err != nil {
+				rows.Close()
+				return 0, err
+			}
+			ids = append(ids, id)
+			batch = append(batch, values)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+		if len(batch) == 0 {
+			return rewritten, nil
+		}
+		afterID = ids[len(ids)-1]
+
+		for i, values := range batch {
+			args := make([]any, 0, len(values)+1)
+			for _, value := range values {
+				plain, err := current.Open(value)
+				if err != nil {
+					return 0, err
+				}
+				args = append(args, nullIfEmpty(next.Seal(plain)))
+			}
+			if _, err := tx.Exec(update, append(args, ids[i])...); err != nil {
+				return 0, err
+			}
+			rewritten++
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package storage
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"trackmytime/internal/entities"
+)
+
+// entityPatternsKey est la clé de config des motifs d'extraction personnalisés
+const entityPatternsKey = "entity_patterns"
+
+// EntityPatterns retourne les motifs d'extraction d'entités, ou les motifs
+// par défaut s'ils n'ont pas été personnalisés
+func (db *DB) EntityPatterns() ([]entities.Pattern, error) {
+	value, err := db.GetConfig(entityPatternsKey)
+	if err != nil || value == "" {
+		return entities.Defaults(), err
+	}
+	var patterns []entities.Pattern
+	if err := json.Unmarshal([]byte(value), &patterns); err != nil {
+		return nil, err
+	}
+	return patterns, nil
+}
+
+// SetEntityPatterns remplace les motifs d'extraction ; nil revient aux
+// motifs par défaut. L'agent les recharge avec les règles.
+func (db *DB) SetEntityPatterns(patterns []entities.Pattern) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if patterns == nil {
+		_, err = tx.Exec(`DELETE FROM config WHERE key = ?`, entityPatternsKey)
+	} else {
+		var encoded []byte
+		if encoded, err = json.Marshal(patterns); err != nil {
+			return err
+		}
+		_, err = tx.Exec(`
+			INSERT INTO config (key, value) VALUES (?, ?)
+			ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = CURRENT_TIMESTAMP
+		`, entityPatternsKey, string(encoded))
+	}
+	if err != nil {
+		return err
+	}
+	if err := bumpRulesVersion(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// addEntities enregistre les entités extraites d'une activité
+func addEntities(e execer, activityID int64, found []entities.Entity) error {
+	for _, entity := range found {
+		_, err := e.Exec(`INSERT OR IGNORE INTO activity_entities (activity_id, kind, value) VALUES (?, ?, ?)`,
+			activityID, entity.Kind, entity.Value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// entityListExpr liste les entités d'une activité (table activities) sous la forme kind:value
+const entityListExpr = `COALESCE((SELECT GROUP_CONCAT(e.kind || ':' || e.value, char(10)) FROM activity_entities e WHERE e.activity_id = activities.id), '')`
+
+// parseEntityList décode entityListExpr, trié
+func parseEntityList(list string) []entities.Entity {
+	if list == "" {
+		return nil
+	}
+	var found []entities.Entity
+	for _, item := range strings.Split(list, "\n") {
+		if kind, value, ok := strings.Cut(item, ":"); ok {
+			found = append(found, entities.Entity{Kind: entities.Kind(kind), Value: value})
+		}
+	}
+	entities.Sort(found)
+	return found
+}
+
+// EntityStat est le temps actif passé sur une entité
+type EntityStat struct {
+	Kind       entities.Kind
+	Value      string
+	Activities int
+	Seconds    int64
+	FirstSeen  time.Time
+	LastSeen   time.Time
+}
+
+// GetStatsByEntity retourne au plus limit entités de la période (d'un seul
+// type si kind n'est pas vide), le temps le plus long d'abord
+func (db *DB) GetStatsByEntity(start, end time.Time, kind entities.Kind, limit int) ([]EntityStat, error) {
+	query := `
+		SELECT e.kind, e.value, COUNT(*), SUM(a.duration_seconds) AS total_duration,
+			MIN(a.start_time), MAX(a.end_time)
+		FROM activity_entities e
+		JOIN activities a ON a.id = e.activity_id
+		WHERE a.start_time >= ? AND a.start_time < ? AND a.is_idle = 0`
+	args := []any{start, end}
+	if kind != "" {
+		query += ` AND e.kind = ?`
+		args = append(args, kind)
+	}
+	query += `
+		GROUP BY e.kind, e.value
+		ORDER BY total_duration DESC
+		LIMIT ?`
+	args = append(args, limit)
+
+	return db.queryEntityStats(query, args...)
+}
+
+// GetEntityStat retourne le temps passé sur une entité sur tout l'historique
+func (db *DB) GetEntityStat(kind entities.Kind, value string) (EntityStat, error) {
+	stats, err := db.queryEntityStats(`
+		SELECT e.kind, e.value, COUNT(*), SUM(a.duration_seconds), MIN(a.start_time), MAX(a.end_time)
+		FROM activity_entities e
+		JOIN activities a ON a.id = e.activity_id
+		WHERE e.kind = ? AND e.value = ? AND a.is_idle = 0
+		GROUP BY e.kind, e.value
+	`, kind, value)
+	if err != nil || len(stats) == 0 {
+		return EntityStat{Kind: kind, Value: value}, err
+	}
+	return stats[0], nil
+}
+
+func (db *DB) queryEntityStats(query string, args ...any) ([]EntityStat, error) {
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []EntityStat
+	for rows.Next() {
+		var s EntityStat
+		var first, last string
+		if err := rows.Scan(&s.Kind, &s.Value, &s.Activities, &s.Seconds, &first, &last); err != nil {
+			return nil, err
+		}
+		s.FirstSeen, _ = parseStoredTime(first)
+		s.LastSeen, _ = parseStoredTime(last)
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+// entityFilter restreint une requête sur activities (alias a) à une entité
+func entityFilter(kind entities.Kind, value string) (string, []any) {
+	return ` AND EXISTS (SELECT 1 FROM activity_entities e WHERE e.activity_id = a.id AND e.kind = ? AND e.value = ?)`,
+		[]any{kind, value}
+}
+
+// GetEntityGroupedStats retourne le temps passé sur une entité, sur tout
+// l'historique, groupé par app puis par enriched_name
+func (db *DB) GetEntityGroupedStats(kind entities.Kind, value string) (map[string]map[string]int64, error) {
+	filter, args := entityFilter(kind, value)
+	rows, err := db.conn.Query(`
+		SELECT a.app_name, COALESCE(a.enriched_name, a.app_name) AS enriched, SUM(a.duration_seconds)
+		FROM activities a
+		WHERE a.is_idle = 0`+filter+`
+		GROUP BY a.app_name, enriched
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	grouped := make(map[string]map[string]int64)
+	for rows.Next() {
+		var appName, enrichedName string
+		var duration int64
+		if err := rows.Scan(&appName, &enrichedName, &duration); err != nil {
+			return nil, err
+		}
+		if grouped[appName] == nil {
+			grouped[appName] = make(map[string]int64)
+		}
+		grouped[appName][enrichedName] = duration
+	}
+	return grouped, rows.Err()
+}
+
+// GetEntityDailyStats retourne le temps passé sur une entité pour chaque
+// jour où elle apparaît
+func (db *DB) GetEntityDailyStats(kind entities.Kind, value string) ([]DailySeconds, error) {
+	filter, args := entityFilter(kind, value)
+	rows, err := db.conn.Query(`
+		SELECT date(a.start_time, 'localtime') AS day, SUM(a.duration_seconds)
+		FROM activities a
+		WHERE a.is_idle = 0`+filter+`
+		GROUP BY day
+		ORDER BY day
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var days []DailySeconds
+	for rows.Next() {
+		var dayStr string
+		var d DailySeconds
+		if err := rows.Scan(&dayStr, &d.Seconds); err != nil {
+			return nil, err
+		}
+		if d.Date, err = time.ParseInLocation("2006-01-02", dayStr, time.Local); err != nil {
+			continue
+		}
+		days = append(days, d)
+	}
+	return days, rows.Err()
+}
@@ -0,0 +1,282 @@
+package storage
+
+import (
+	"database/sql"
+	"sort"
+	"strings"
+	"time"
+
+	"trackmytime/internal/documents"
+	"trackmytime/internal/gitinfo"
+)
+
+// HeartbeatTimeout est l'écart maximal entre deux heartbeats compté comme du
+// temps continu, comme le délai par défaut de WakaTime
+const HeartbeatTimeout = 15 * time.Minute
+
+// Heartbeat est un signal envoyé par un plugin d'éditeur WakaTime quand un
+// fichier est ouvert, modifié ou enregistré
+type Heartbeat struct {
+	ID        int64
+	Time      time.Time
+	Entity    string             // chemin du fichier, domaine ou application
+	Type      string             // file, app, domain ou url
+	Category  string             // coding, debugging, building...
+	Document  documents.Document // fichier de Entity (vide si Type n'est pas file)
+	Project   string
+	Repo      gitinfo.Repo // dépôt du fichier ; Branch est celle signalée par le plugin
+	IsWrite   bool
+	Lines     int
+	LineNo    int
+	Editor    string // éditeur déduit du user agent ("VS Code", "Neovim"...)
+	UserAgent string
+}
+
+// HeartbeatStat est le temps compté sur une valeur (projet, langage...)
+type HeartbeatStat struct {
+	Name     string // vide pour les heartbeats sans cette information
+	Duration time.Duration
+}
+
+// HeartbeatTotals est le temps compté à partir des heartbeats d'une période
+type HeartbeatTotals struct {
+	Total      time.Duration
+	Projects   []HeartbeatStat // triés par durée décroissante
+	Languages  []HeartbeatStat
+	Editors    []HeartbeatStat
+	Categories []HeartbeatStat
+	Branches   []HeartbeatStat
+}
+
+// RecordHeartbeats enregistre des heartbeats et retourne le nombre de
+// nouveaux ; ceux déjà reçus (même heure et même entité, renvoyés par la file
+// hors ligne des plugins) sont ignorés
+func (db *DB) RecordHeartbeats(heartbeats []Heartbeat) (int, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO heartbeats (time, entity, type, category, file_name, file_extension, language, project,
+			branch, repo_root, repo_remote, is_write, lines, lineno, editor, user_agent)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (time, entity) DO NOTHING
+	`)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	inserted := 0
+	for _, h := range heartbeats {
+		result, err := stmt.Exec(h.Time, h.Entity, h.Type, h.Category,
+			nullIfEmpty(h.Document.FileName), nullIfEmpty(h.Document.Extension), nullIfEmpty(h.Document.Language),
+			nullIfEmpty(h.Project), nullIfEmpty(h.Repo.Branch), nullIfEmpty(h.Repo.Root), nullIfEmpty(h.Repo.Remote),
+			h.IsWrite, h.Lines, h.LineNo, nullIfEmpty(h.Editor), nullIfEmpty(h.UserAgent))
+		if err != nil {
+			return 0, err
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			inserted++
+		}
+	}
+	return inserted, tx.Commit()
+}
+
+// MergeHeartbeats reporte sur les activités d'éditeur chevauchant
+// [start, end) le dernier heartbeat de fichier reçu pendant chacune :
+// fichier, langage, et, s'ils manquent, projet et dépôt git. Seules les
+// activités d'un éditeur (voir heartbeatTarget) sont concernées ; celles dont
+// le titre désigne un autre fichier, ou dont les règles de confidentialité
+// n'ont gardé que l'application (titre vide), sont laissées telles quelles.
+// Retourne le nombre d'activités modifiées.
+func (db *DB) MergeHeartbeats(start, end time.Time) (int, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, app_name, COALESCE(command, ''), COALESCE(file_name, ''), COALESCE(file_extension, ''),
+			COALESCE(language, ''), COALESCE(project, ''), COALESCE(repo_root, ''), start_time, end_time
+		FROM activities
+		WHERE is_idle = 0 AND COALESCE(window_title, '') <> '' AND start_time < ? AND end_time > ?
+	`, end, start)
+	if err != nil {
+		return 0, err
+	}
+	type target struct {
+		id         int64
+		appName    string
+		command    string
+		document   documents.Document
+		project    string
+		repoRoot   string
+		start, end time.Time
+	}
+	var targets []target
+	for rows.Next() {
+		var t target
+		err := rows.Scan(&t.id, &t.appName, &t.command, &t.document.FileName, &t.document.Extension, &t.document.Language,
+			&t.project, &t.repoRoot, &t.start, &t.end)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		targets = append(targets, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	merged := 0
+	for _, t := range targets {
+		var h Heartbeat
+		err := tx.QueryRow(`
+			SELECT file_name, COALESCE(file_extension, ''), COALESCE(language, ''), COALESCE(project, ''),
+				COALESCE(repo_root, ''), COALESCE(repo_remote, ''), COALESCE(branch, ''), COALESCE(editor, '')
+			FROM heartbeats
+			WHERE type = 'file' AND file_name IS NOT NULL AND time >= ? AND time < ?
+			ORDER BY time DESC LIMIT 1
+		`, t.start, t.end).Scan(&h.Document.FileName, &h.Document.Extension, &h.Document.Language,
+			&h.Project, &h.Repo.Root, &h.Repo.Remote, &h.Repo.Branch, &h.Editor)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if !heartbeatTarget(t.appName, t.command, t.document.FileName != "", h.Editor) {
+			continue
+		}
+		if t.document.FileName != "" && t.document.FileName != h.Document.FileName {
+			continue
+		}
+
+		document := h.Document
+		if document.Language == "" {
+			document.Language = t.document.Language
+		}
+		project := t.project
+		if project == "" {
+			project = h.Project
+		}
+		setRepo := t.repoRoot == "" && h.Repo.Root != ""
+		if document == t.document && project == t.project && !setRepo {
+			continue
+		}
+
+		if project != t.project {
+			if err := ensureProject(tx, project); err != nil {
+				return 0, err
+			}
+		}
+		_, err = tx.Exec(`UPDATE activities SET file_name = ?, file_extension = ?, language = ?, project = ? WHERE id = ?`,
+			document.FileName, nullIfEmpty(document.Extension), nullIfEmpty(document.Language), nullIfEmpty(project), t.id)
+		if err != nil {
+			return 0, err
+		}
+		if setRepo {
+			_, err = tx.Exec(`UPDATE activities SET repo_root = ?, repo_remote = ?, branch = ? WHERE id = ?`,
+				h.Repo.Root, nullIfEmpty(h.Repo.Remote), nullIfEmpty(h.Repo.Branch), t.id)
+			if err != nil {
+				return 0, err
+			}
+		}
+		merged++
+	}
+	return merged, tx.Commit()
+}
+
+// heartbeatTarget indique si un heartbeat de l'éditeur editor peut décrire
+// une activité : fenêtre d'un éditeur reconnu ou dont le titre désignait déjà
+// un fichier, éditeur lancé dans un terminal, ou application portant le nom
+// de l'éditeur du heartbeat. Slack, les navigateurs ou un lecteur vidéo
+// affichés pendant qu'un plugin envoie des heartbeats ne sont pas concernés.
+func heartbeatTarget(appName, command string, hasDocument bool, editor string) bool {
+	if hasDocument || documents.IsEditor(appName) || documents.IsEditorCommand(command) {
+		return true
+	}
+	app := strings.ToLower(strings.ReplaceAll(appName, " ", ""))
+	editor = strings.ToLower(strings.ReplaceAll(editor, " ", ""))
+	return app != "" && editor != "" && (strings.Contains(app, editor) || strings.Contains(editor, app))
+}
+
+// GetHeartbeatTotals compte le temps passé dans les éditeurs sur la période
+// selon la méthode de WakaTime : chaque heartbeat dure jusqu'au suivant, sauf
+// si l'écart dépasse HeartbeatTimeout
+func (db *DB) GetHeartbeatTotals(start, end time.Time) (HeartbeatTotals, error) {
+	rows, err := db.conn.Query(`
+		SELECT time, category, COALESCE(language, ''), COALESCE(project, ''), COALESCE(branch, ''),
+			COALESCE(editor, '')
+		FROM heartbeats
+		WHERE time >= ? AND time < ?
+		ORDER BY time
+	`, start, end)
+	if err != nil {
+		return HeartbeatTotals{}, err
+	}
+	defer rows.Close()
+
+	var totals HeartbeatTotals
+	projects, languages, editors, categories, branches :=
+		map[string]time.Duration{}, map[string]time.Duration{}, map[string]time.Duration{},
+		map[string]time.Duration{}, map[string]time.Duration{}
+	var previous Heartbeat
+	first := true
+	add := func(h Heartbeat, d time.Duration) {
+		totals.Total += d
+		projects[h.Project] += d
+		languages[h.Document.Language] += d
+		editors[h.Editor] += d
+		categories[h.Category] += d
+		branches[h.Repo.Branch] += d
+	}
+	for rows.Next() {
+		var h Heartbeat
+		if err := rows.Scan(&h.Time, &h.Category, &h.Document.Language, &h.Project, &h.Repo.Branch, &h.Editor); err != nil {
+			return HeartbeatTotals{}, err
+		}
+		if !first {
+			gap := h.Time.Sub(previous.Time)
+			if gap > HeartbeatTimeout {
+				gap = 0
+			}
+			add(previous, gap)
+		}
+		previous, first = h, false
+	}
+	if err := rows.Err(); err != nil {
+		return HeartbeatTotals{}, err
+	}
+	if !first {
+		// Le dernier heartbeat ne dure rien, mais sa valeur doit apparaître
+		add(previous, 0)
+	}
+
+	totals.Projects = sortedHeartbeatStats(projects)
+	totals.Languages = sortedHeartbeatStats(languages)
+	totals.Editors = sortedHeartbeatStats(editors)
+	totals.Categories = sortedHeartbeatStats(categories)
+	totals.Branches = sortedHeartbeatStats(branches)
+	return totals, nil
+}
+
+// sortedHeartbeatStats trie les durées par valeur, la plus longue d'abord
+func sortedHeartbeatStats(durations map[string]time.Duration) []HeartbeatStat {
+	stats := make([]HeartbeatStat, 0, len(durations))
+	for name, d := range durations {
+		stats = append(stats, HeartbeatStat{Name: name, Duration: d})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Duration != stats[j].Duration {
+			return stats[i].Duration > stats[j].Duration
+		}
+		return stats[i].Name < stats[j].Name
+	})
+	return stats
+}
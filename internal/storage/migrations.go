@@ -35,6 +35,160 @@ func (db *DB) migrate() error {
 		// Ajouter enriched_name si elle n'existe pas
 		`ALTER TABLE activities ADD COLUMN enriched_name TEXT`,
 		`CREATE INDEX IF NOT EXISTS idx_activities_enriched_name ON activities(enriched_name)`,
+		// Règles d'enrichissement (conditions et action en JSON)
+		`CREATE TABLE IF NOT EXISTS rules (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			position INTEGER NOT NULL,
+			enabled BOOLEAN DEFAULT 1,
+			conditions TEXT NOT NULL,
+			action TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		// Résultat des actions des règles
+		`ALTER TABLE activities ADD COLUMN category TEXT`,
+		`ALTER TABLE activities ADD COLUMN project TEXT`,
+		`ALTER TABLE activities ADD COLUMN tags TEXT`,
+		// Site déduit du titre et confiance associée (règles infer_site)
+		`ALTER TABLE activities ADD COLUMN inferred_site TEXT`,
+		`ALTER TABLE activities ADD COLUMN site_confidence REAL`,
+		// Catégories d'activité et leur niveau de productivité
+		`CREATE TABLE IF NOT EXISTS categories (
+			name TEXT PRIMARY KEY,
+			productivity TEXT NOT NULL DEFAULT 'neutral',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_activities_category ON activities(category)`,
+		// Projets (éventuellement par client) auxquels les règles attribuent le temps
+		`CREATE TABLE IF NOT EXISTS projects (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+			client TEXT,
+			color TEXT,
+			archived BOOLEAN DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_activities_project ON activities(project)`,
+		// Tags libres (#oncall, #learning...) posés par les règles ou manuellement
+		`CREATE TABLE IF NOT EXISTS tags (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS activity_tags (
+			activity_id INTEGER NOT NULL,
+			tag_id INTEGER NOT NULL,
+			source TEXT NOT NULL DEFAULT 'rule',
+			PRIMARY KEY (activity_id, tag_id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_activity_tags_tag ON activity_tags(tag_id)`,
+		// Entités (tickets, dépôts, PR, MR) extraites des titres et des URL
+		`CREATE TABLE IF NOT EXISTS activity_entities (
+			activity_id INTEGER NOT NULL,
+			kind TEXT NOT NULL,
+			value TEXT NOT NULL,
+			PRIMARY KEY (activity_id, kind, value)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_activity_entities_value ON activity_entities(kind, value)`,
+		// Fichier ouvert dans les éditeurs et langage déduit de son extension
+		`ALTER TABLE activities ADD COLUMN file_name TEXT`,
+		`ALTER TABLE activities ADD COLUMN file_extension TEXT`,
+		`ALTER TABLE activities ADD COLUMN language TEXT`,
+		`CREATE INDEX IF NOT EXISTS idx_activities_language ON activities(language)`,
+		// Commande au premier plan des terminaux, dossier courant et hôte ssh
+		`ALTER TABLE activities ADD COLUMN command TEXT`,
+		`ALTER TABLE activities ADD COLUMN cwd TEXT`,
+		`ALTER TABLE activities ADD COLUMN ssh_host TEXT`,
+		`CREATE INDEX IF NOT EXISTS idx_activities_command ON activities(command)`,
+		// Dépôt git et branche du terminal ou de l'espace de travail de l'éditeur
+		`ALTER TABLE activities ADD COLUMN repo_root TEXT`,
+		`ALTER TABLE activities ADD COLUMN repo_remote TEXT`,
+		`ALTER TABLE activities ADD COLUMN branch TEXT`,
+		`CREATE INDEX IF NOT EXISTS idx_activities_repo ON activities(repo_root, branch)`,
+		// Sites candidats vus dans des titres différents (apprentissage)
+		`CREATE TABLE IF NOT EXISTS site_candidates (
+			name TEXT PRIMARY KEY COLLATE NOCASE,
+			occurrences INTEGER NOT NULL DEFAULT 0,
+			total_seconds INTEGER NOT NULL DEFAULT 0,
+			last_title TEXT,
+			first_seen DATETIME DEFAULT CURRENT_TIMESTAMP,
+			last_seen DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		// Commandes signalées par les hooks de shell
+		`CREATE TABLE IF NOT EXISTS shell_commands (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			session TEXT NOT NULL,
+			seq INTEGER NOT NULL,
+			shell TEXT NOT NULL,
+			command TEXT NOT NULL,
+			program TEXT NOT NULL,
+			cwd TEXT,
+			started_at DATETIME NOT NULL,
+			ended_at DATETIME,
+			duration_ms INTEGER,
+			exit_code INTEGER
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_shell_commands_started ON shell_commands(started_at)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_shell_commands_session ON shell_commands(session, seq)`,
+		// Heartbeats des plugins d'éditeur WakaTime
+		`CREATE TABLE IF NOT EXISTS heartbeats (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			time DATETIME NOT NULL,
+			entity TEXT NOT NULL,
+			type TEXT NOT NULL,
+			category TEXT NOT NULL,
+			file_name TEXT,
+			file_extension TEXT,
+			language TEXT,
+			project TEXT,
+			branch TEXT,
+			repo_root TEXT,
+			repo_remote TEXT,
+			is_write INTEGER NOT NULL DEFAULT 0,
+			lines INTEGER,
+			lineno INTEGER,
+			editor TEXT,
+			user_agent TEXT
+		)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_heartbeats_time ON heartbeats(time, entity)`,
+		// Buckets et événements reçus par l'API compatible ActivityWatch
+		`CREATE TABLE IF NOT EXISTS aw_buckets (
+			id TEXT PRIMARY KEY,
+			type TEXT NOT NULL,
+			client TEXT NOT NULL,
+			hostname TEXT NOT NULL,
+			data TEXT NOT NULL DEFAULT '{}',
+			created DATETIME NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS aw_events (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			bucket_id TEXT NOT NULL,
+			timestamp DATETIME NOT NULL,
+			duration REAL NOT NULL DEFAULT 0,
+			data TEXT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_aw_events_bucket ON aw_events(bucket_id, timestamp)`,
+		// Événement ActivityWatch (fenêtre ou inactivité) dont l'activité
+		// est déduite, pour la prolonger au fil des heartbeats
+		`ALTER TABLE activities ADD COLUMN aw_event_id INTEGER`,
+		`CREATE INDEX IF NOT EXISTS idx_activities_aw_event ON activities(aw_event_id)`,
+		// Pages visitées signalées par l'extension navigateur, et URL réelle
+		// de l'onglet des activités de navigateur
+		`ALTER TABLE browser_events ADD COLUMN domain TEXT`,
+		`ALTER TABLE browser_events ADD COLUMN path TEXT`,
+		`ALTER TABLE browser_events ADD COLUMN closed BOOLEAN DEFAULT 0`,
+		`CREATE INDEX IF NOT EXISTS idx_browser_events_domain ON browser_events(domain, path)`,
+		`ALTER TABLE activities ADD COLUMN url TEXT`,
+		// Empreinte HMAC du titre quand les titres sont chiffrés, pour les
+		// regroupements par titre
+		`ALTER TABLE activities ADD COLUMN title_index TEXT`,
+		// Titres distincts dans lesquels chaque site candidat a été vu
+		`CREATE TABLE IF NOT EXISTS site_candidate_titles (
+			name TEXT NOT NULL COLLATE NOCASE,
+			title_index TEXT NOT NULL,
+			PRIMARY KEY (name, title_index)
+		)`,
 	}
 
 	for _, migration := range migrations {
@@ -42,5 +196,8 @@ func (db *DB) migrate() error {
 		db.conn.Exec(migration)
 	}
 
-	return nil
+	if err := db.migrateLegacyProjects(); err != nil {
+		return err
+	}
+	return db.migrateLegacyTags()
 }
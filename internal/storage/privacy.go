@@ -0,0 +1,538 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"time"
+
+	"trackmytime/internal/documents"
+	"trackmytime/internal/privacy"
+	"trackmytime/internal/rules"
+	"trackmytime/internal/vault"
+)
+
+// privacyRulesKey est la clé de config des règles de confidentialité
+const privacyRulesKey = "privacy_rules"
+
+// PrivacyRules retourne les règles de confidentialité, dans leur ordre
+// d'évaluation (aucune par défaut)
+func (db *DB) PrivacyRules() ([]privacy.Rule, error) {
+	value, err := db.GetConfig(privacyRulesKey)
+	if err != nil || value == "" {
+		return []privacy.Rule{}, err
+	}
+	var rs []privacy.Rule
+	if err := json.Unmarshal([]byte(value), &rs); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// SetPrivacyRules remplace les règles de confidentialité ; nil les supprime.
+// L'agent les recharge avec les règles d'enrichissement.
+func (db *DB) SetPrivacyRules(rs []privacy.Rule) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if len(rs) == 0 {
+		_, err = tx.Exec(`DELETE FROM config WHERE key = ?`, privacyRulesKey)
+	} else {
+		var encoded []byte
+		if encoded, err = json.Marshal(rs); err != nil {
+			return err
+		}
+		_, err = tx.Exec(`
+			INSERT INTO config (key, value) VALUES (?, ?)
+			ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = CURRENT_TIMESTAMP
+		`, privacyRulesKey, string(encoded))
+	}
+	if err != nil {
+		return err
+	}
+	if err := bumpRulesVersion(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ScrubActivities applique les règles de confidentialité à l'historique :
+// les activités de updates sont réécrites (titre, URL, commande, dossier,
+// hôte ssh, dépôt git, puis enrichissement comme UpdateEnrichment) et celles
+// de deleted supprimées avec leurs tags et entités, dans une même transaction
+func (db *DB) ScrubActivities(updates []Activity, deleted []int64) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	c, err := db.cipher(tx)
+	if err != nil {
+		return err
+	}
+	for _, a := range updates {
+		_, err := tx.Exec(`
+			UPDATE activities SET window_title = ?, title_index = ?, command = ?, cwd = ?, ssh_host = ?,
+				repo_root = ?, repo_remote = ?, branch = ?
+			WHERE id = ?
+		`, c.Seal(a.WindowTitle), nullIfEmpty(c.Index(a.WindowTitle)), nullIfEmpty(a.Command),
+			nullIfEmpty(a.WorkingDir), nullIfEmpty(a.SSHHost), nullIfEmpty(a.Repo.Root),
+			nullIfEmpty(a.Repo.Remote), nullIfEmpty(a.Repo.Branch), a.ID)
+		if err != nil {
+			return err
+		}
+	}
+	if err := updateEnrichment(tx, c, updates); err != nil {
+		return err
+	}
+
+	for _, id := range deleted {
+		if err := deleteActivity(tx, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ProtectFunc applique les règles de confidentialité à ce que décrit in,
+// modifié en place (tracker.Protect)
+type ProtectFunc func(in *rules.Input) privacy.Decision
+
+// Protect applique protect à l'onglet signalé. Un onglet exclu, ou réduit
+// au navigateur (sans URL), devient un blur : la page précédente se
+// termine sans que celle-ci soit enregistrée.
+func (s *BrowserSignal) Protect(protect ProtectFunc) {
+	if s.URL == "" {
+		return
+	}
+	in := rules.Input{AppName: s.Browser, WindowTitle: s.TabTitle, URL: s.URL}
+	if protect(&in).Action == privacy.ActionDrop || in.URL == "" {
+		s.Type, s.URL, s.TabTitle = BrowserBlur, "", ""
+		return
+	}
+	s.URL, s.TabTitle = in.URL, in.WindowTitle
+}
+
+// Protect applique protect au fichier (titre et dossier pour les règles)
+// ou à l'URL d'un heartbeat ; false s'il ne doit pas être enregistré :
+// exclu, ou réduit à l'éditeur. Un heartbeat d'application n'est
+// qu'exclu ou gardé.
+func (h *Heartbeat) Protect(protect ProtectFunc) bool {
+	in := rules.Input{AppName: h.Editor}
+	switch h.Type {
+	case "file":
+		in.WindowTitle = h.Entity
+		if filepath.IsAbs(h.Entity) {
+			in.WorkingDir = filepath.Dir(h.Entity)
+		}
+	case "url", "domain":
+		in.URL = h.Entity
+	default:
+		in.AppName = h.Entity
+		return protect(&in).Action != privacy.ActionDrop
+	}
+	if protect(&in).Action == privacy.ActionDrop {
+		return false
+	}
+
+	entity := in.WindowTitle
+	if h.Type != "file" {
+		entity = in.URL
+	}
+	if entity == "" {
+		return false
+	}
+	if entity != h.Entity && h.Type == "file" {
+		language := h.Document.Language
+		h.Document = documents.ForFile(entity)
+		if language != "" {
+			h.Document.Language = language
+		}
+	}
+	h.Entity = entity
+	return true
+}
+
+// Protect applique protect à une commande de shell ; une commande exclue ou
+// réduite au shell est effacée (commande vide, cachée des listes et des
+// stats) mais garde sa place dans la session
+func (c *ShellCommand) Protect(protect ProtectFunc) {
+	in := rules.Input{AppName: c.Shell, Command: c.Command, WorkingDir: c.WorkingDir}
+	if protect(&in).Action == privacy.ActionDrop || in.Command == "" {
+		c.Command, c.Program, c.WorkingDir = "", "", ""
+		return
+	}
+	c.Command, c.WorkingDir = in.Command, in.WorkingDir
+}
+
+// awProtectedFields sont, par type de bucket, les champs des données d'un
+// événement soumis aux règles de confidentialité
+var awProtectedFields = map[string]struct{ title, url string }{
+	AWTypeWindow: {"title", "url"},
+	AWTypeWebTab: {"title", "url"},
+	AWTypeEditor: {"file", ""},
+}
+
+// Protect applique protect aux données d'un événement d'aw-watcher-window,
+// aw-watcher-web ou d'un watcher d'éditeur ; false s'il ne doit pas être
+// enregistré : exclu, ou sans page ni fichier une fois protégé. Les
+// événements des autres buckets sont inchangés.
+func (e *AWEvent) Protect(bucket AWBucket, protect ProtectFunc) bool {
+	fields, ok := awProtectedFields[bucket.Type]
+	if !ok {
+		return true
+	}
+	var data map[string]any
+	if err := json.Unmarshal([]byte(e.Data), &data); err != nil {
+		return true
+	}
+	text := func(key string) string {
+		value, _ := data[key].(string)
+		return value
+	}
+
+	in := rules.Input{AppName: bucket.App(), WindowTitle: text(fields.title)}
+	if bucket.Type == AWTypeWindow {
+		in.AppName = text("app")
+	}
+	if fields.url != "" {
+		in.URL = text(fields.url)
+	}
+	if protect(&in).Action == privacy.ActionDrop {
+		return false
+	}
+	if (bucket.Type == AWTypeWebTab && in.URL == "") || (bucket.Type == AWTypeEditor && in.WindowTitle == "") {
+		return false
+	}
+
+	for key, value := range map[string]string{fields.title: in.WindowTitle, fields.url: in.URL} {
+		switch {
+		case key == "":
+		case value == "":
+			delete(data, key)
+		default:
+			data[key] = value
+		}
+	}
+	canonical, err := json.Marshal(data)
+	if err != nil {
+		return true
+	}
+	e.Data = string(canonical)
+	return true
+}
+
+// ScrubCounts compte les lignes d'une table réécrites ou supprimées par
+// ScrubRecords
+type ScrubCounts struct {
+	Table   string
+	Updated int
+	Deleted int // supprimées, ou effacées pour les commandes et les sites candidats
+}
+
+// ScrubRecords applique les règles de confidentialité aux données
+// enregistrées hors des activités, sur [start, end) (une borne nulle n'est
+// pas appliquée) : onglets de l'extension, événements ActivityWatch,
+// heartbeats, commandes de shell et derniers titres des sites candidats.
+// Tout est réécrit dans une même transaction, annulée si dryRun ; les
+// valeurs chiffrées sont déchiffrées pour être évaluées puis rechiffrées.
+func (db *DB) ScrubRecords(start, end time.Time, protect ProtectFunc, dryRun bool) ([]ScrubCounts, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	c, err := db.cipher(tx)
+	if err != nil {
+		return nil, err
+	}
+	var counts []ScrubCounts
+	for _, scrub := range []func(*sql.Tx, *vault.Cipher, time.Time, time.Time, ProtectFunc) (ScrubCounts, error){
+		scrubBrowserEvents, scrubAWEvents, scrubHeartbeats, scrubShellCommands, scrubSiteCandidates,
+	} {
+		n, err := scrub(tx, c, start, end, protect)
+		if err != nil {
+			return nil, err
+		}
+		counts = append(counts, n)
+	}
+	if dryRun {
+		return counts, nil
+	}
+	return counts, tx.Commit()
+}
+
+// scrubRange restreint une requête aux lignes dont column est dans
+// [start, end) ; une borne nulle n'est pas appliquée
+func scrubRange(column string, start, end time.Time) (string, []any) {
+	where, args := ` WHERE 1 = 1`, []any{}
+	if !start.IsZero() {
+		where += ` AND julianday(` + column + `) >= julianday(?)`
+		args = append(args, start)
+	}
+	if !end.IsZero() {
+		where += ` AND julianday(` + column + `) < julianday(?)`
+		args = append(args, end)
+	}
+	return where, args
+}
+
+// scrubBrowserEvents protège les onglets enregistrés ; ceux qui auraient
+// été réduits à un blur sont supprimés
+func scrubBrowserEvents(tx *sql.Tx, c *vault.Cipher, start, end time.Time, protect ProtectFunc) (ScrubCounts, error) {
+	counts := ScrubCounts{Table: "browser_events"}
+	where, args := scrubRange("start_time", start, end)
+	rows, err := tx.Query(`SELECT id, COALESCE(browser_name, ''), url, COALESCE(tab_title, '') FROM browser_events`+where, args...)
+	if err != nil {
+		return counts, err
+	}
+	type event struct {
+		id     int64
+		signal BrowserSignal
+	}
+	var events []event
+	for rows.Next() {
+		e := event{signal: BrowserSignal{Type: BrowserHeartbeat}}
+		if err := rows.Scan(&e.id, &e.signal.Browser, &e.signal.URL, &e.signal.TabTitle); err != nil {
+			rows.Close()
+			return counts, err
+		}
+		if e.signal.URL, err = c.Open(e.signal.URL); err == nil {
+			e.signal.TabTitle, err = c.Open(e.signal.TabTitle)
+		}
+		if err != nil {
+			rows.Close()
+			return counts, err
+		}
+		events = append(events, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return counts, err
+	}
+
+	for _, e := range events {
+		before := e.signal
+		e.signal.Protect(protect)
+		switch {
+		case e.signal.URL == "":
+			if _, err := tx.Exec(`DELETE FROM browser_events WHERE id = ?`, e.id); err != nil {
+				return counts, err
+			}
+			counts.Deleted++
+		case e.signal != before:
+			domain, path := urlParts(e.signal.URL)
+			_, err := tx.Exec(`UPDATE browser_events SET url = ?, domain = ?, path = ?, tab_title = ? WHERE id = ?`,
+				c.Seal(e.signal.URL), nullIfEmpty(domain), nullIfEmpty(path), nullIfEmpty(c.Seal(e.signal.TabTitle)), e.id)
+			if err != nil {
+				return counts, err
+			}
+			counts.Updated++
+		}
+	}
+	return counts, nil
+}
+
+// scrubAWEvents protège les événements des fenêtres, onglets et éditeurs
+// reçus par l'API ActivityWatch
+func scrubAWEvents(tx *sql.Tx, c *vault.Cipher, start, end time.Time, protect ProtectFunc) (ScrubCounts, error) {
+	counts := ScrubCounts{Table: "aw_events"}
+	where, args := scrubRange("e.timestamp", start, end)
+	rows, err := tx.Query(`
+		SELECT e.id, e.data, b.id, b.type, b.client FROM aw_events e
+		JOIN aw_buckets b ON b.id = e.bucket_id`+where+` AND b.type IN (?, ?, ?)`,
+		append(args, AWTypeWindow, AWTypeWebTab, AWTypeEditor)...)
+	if err != nil {
+		return counts, err
+	}
+	type event struct {
+		event  AWEvent
+		bucket AWBucket
+	}
+	var events []event
+	for rows.Next() {
+		var e event
+		if err := rows.Scan(&e.event.ID, &e.event.Data, &e.bucket.ID, &e.bucket.Type, &e.bucket.Client); err != nil {
+			rows.Close()
+			return counts, err
+		}
+		if e.event.Data, err = c.Open(e.event.Data); err != nil {
+			rows.Close()
+			return counts, err
+		}
+		events = append(events, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return counts, err
+	}
+
+	for _, e := range events {
+		data := e.event.Data
+		switch {
+		case !e.event.Protect(e.bucket, protect):
+			if _, err := tx.Exec(`DELETE FROM aw_events WHERE id = ?`, e.event.ID); err != nil {
+				return counts, err
+			}
+			counts.Deleted++
+		case e.event.Data != data:
+			if _, err := tx.Exec(`UPDATE aw_events SET data = ? WHERE id = ?`, c.Seal(e.event.Data), e.event.ID); err != nil {
+				return counts, err
+			}
+			counts.Updated++
+		}
+	}
+	return counts, nil
+}
+
+// scrubHeartbeats protège les fichiers et URL des heartbeats d'éditeur
+func scrubHeartbeats(tx *sql.Tx, _ *vault.Cipher, start, end time.Time, protect ProtectFunc) (ScrubCounts, error) {
+	counts := ScrubCounts{Table: "heartbeats"}
+	where, args := scrubRange("time", start, end)
+	rows, err := tx.Query(`
+		SELECT id, entity, type, COALESCE(editor, ''), COALESCE(file_name, ''), COALESCE(file_extension, ''),
+			COALESCE(language, '')
+		FROM heartbeats`+where, args...)
+	if err != nil {
+		return counts, err
+	}
+	var heartbeats []Heartbeat
+	for rows.Next() {
+		var h Heartbeat
+		if err := rows.Scan(&h.ID, &h.Entity, &h.Type, &h.Editor, &h.Document.FileName,
+			&h.Document.Extension, &h.Document.Language); err != nil {
+			rows.Close()
+			return counts, err
+		}
+		heartbeats = append(heartbeats, h)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return counts, err
+	}
+
+	for _, h := range heartbeats {
+		entity := h.Entity
+		switch {
+		case !h.Protect(protect):
+			if _, err := tx.Exec(`DELETE FROM heartbeats WHERE id = ?`, h.ID); err != nil {
+				return counts, err
+			}
+			counts.Deleted++
+		case h.Entity != entity:
+			// Un autre heartbeat peut déjà porter l'entité protégée au même instant
+			_, err := tx.Exec(`UPDATE OR REPLACE heartbeats SET entity = ?, file_name = ?, file_extension = ?, language = ? WHERE id = ?`,
+				h.Entity, nullIfEmpty(h.Document.FileName), nullIfEmpty(h.Document.Extension),
+				nullIfEmpty(h.Document.Language), h.ID)
+			if err != nil {
+				return counts, err
+			}
+			counts.Updated++
+		}
+	}
+	return counts, nil
+}
+
+// scrubShellCommands protège les commandes de shell ; une commande exclue
+// est effacée
+func scrubShellCommands(tx *sql.Tx, _ *vault.Cipher, start, end time.Time, protect ProtectFunc) (ScrubCounts, error) {
+	counts := ScrubCounts{Table: "shell_commands"}
+	where, args := scrubRange("started_at", start, end)
+	rows, err := tx.Query(`SELECT id, shell, command, program, COALESCE(cwd, '') FROM shell_commands`+where+` AND command != ''`, args...)
+	if err != nil {
+		return counts, err
+	}
+	var commands []ShellCommand
+	for rows.Next() {
+		var c ShellCommand
+		if err := rows.Scan(&c.ID, &c.Shell, &c.Command, &c.Program, &c.WorkingDir); err != nil {
+			rows.Close()
+			return counts, err
+		}
+		commands = append(commands, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return counts, err
+	}
+
+	for _, c := range commands {
+		before := c
+		c.Protect(protect)
+		if c == before {
+			continue
+		}
+		_, err := tx.Exec(`UPDATE shell_commands SET command = ?, program = ?, cwd = ? WHERE id = ?`,
+			c.Command, c.Program, nullIfEmpty(c.WorkingDir), c.ID)
+		if err != nil {
+			return counts, err
+		}
+		if c.Command == "" {
+			counts.Deleted++
+		} else {
+			counts.Updated++
+		}
+	}
+	return counts, nil
+}
+
+// scrubSiteCandidates protège le dernier titre des sites candidats, évalué
+// avec l'application de la dernière activité où le site a été déduit ; un
+// titre exclu est effacé
+func scrubSiteCandidates(tx *sql.Tx, cipher *vault.Cipher, start, end time.Time, protect ProtectFunc) (ScrubCounts, error) {
+	counts := ScrubCounts{Table: "site_candidates"}
+	where, args := scrubRange("last_seen", start, end)
+	rows, err := tx.Query(`
+		SELECT name, last_title, COALESCE((SELECT a.app_name FROM activities a WHERE a.inferred_site = s.name
+			ORDER BY a.start_time DESC LIMIT 1), '')
+		FROM site_candidates s`+where+` AND COALESCE(last_title, '') <> ''`, args...)
+	if err != nil {
+		return counts, err
+	}
+	type candidate struct {
+		name string
+		in   rules.Input
+	}
+	var candidates []candidate
+	for rows.Next() {
+		var c candidate
+		if err := rows.Scan(&c.name, &c.in.WindowTitle, &c.in.AppName); err != nil {
+			rows.Close()
+			return counts, err
+		}
+		if c.in.WindowTitle, err = cipher.Open(c.in.WindowTitle); err != nil {
+			rows.Close()
+			return counts, err
+		}
+		candidates = append(candidates, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return counts, err
+	}
+
+	for _, c := range candidates {
+		title := c.in.WindowTitle
+		if protect(&c.in).Action == privacy.ActionDrop {
+			c.in.WindowTitle = ""
+		}
+		if c.in.WindowTitle == title {
+			continue
+		}
+		_, err := tx.Exec(`UPDATE site_candidates SET last_title = ? WHERE name = ?`, nullIfEmpty(cipher.Seal(c.in.WindowTitle)), c.name)
+		if err != nil {
+			return counts, err
+		}
+		if c.in.WindowTitle == "" {
+			counts.Deleted++
+		} else {
+			counts.Updated++
+		}
+	}
+	return counts, nil
+}
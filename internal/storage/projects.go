@@ -0,0 +1,405 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+	"time"
+
+	"trackmytime/internal/rules"
+)
+
+var (
+	// ErrProjectNotFound est retourné quand un projet n'existe pas
+	ErrProjectNotFound = errors.New("projet introuvable")
+	// ErrProjectExists est retourné quand un autre projet porte déjà ce nom
+	ErrProjectExists = errors.New("un projet porte déjà ce nom")
+)
+
+// Project est un projet auquel le temps est attribué, éventuellement pour un client
+type Project struct {
+	ID        int64
+	Name      string // unique, insensible à la casse
+	Client    string
+	Color     string // #rrggbb
+	Archived  bool   // masqué des listes, le temps reste attribué
+	CreatedAt time.Time
+}
+
+// projectColors sont les couleurs attribuées aux projets créés sans couleur
+var projectColors = []string{
+	"#667eea", "#f5576c", "#43e97b", "#fa709a", "#4facfe",
+	"#f6d365", "#a18cd1", "#38f9d7", "#fda085", "#30cfd0",
+}
+
+var projectColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// ValidateProject vérifie le nom et la couleur d'un projet
+func ValidateProject(p Project) error {
+	if strings.TrimSpace(p.Name) == "" {
+		return errors.New("nom de projet requis")
+	}
+	if p.Color != "" && !projectColor.MatchString(p.Color) {
+		return fmt.Errorf("couleur invalide %q (format #rrggbb)", p.Color)
+	}
+	return nil
+}
+
+// defaultProjectColor choisit une couleur stable à partir du nom
+func defaultProjectColor(name string) string {
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(name)))
+	return projectColors[h.Sum32()%uint32(len(projectColors))]
+}
+
+const projectColumns = `id, name, COALESCE(client, ''), COALESCE(color, ''), archived, created_at`
+
+// ListProjects retourne les projets triés par client puis par nom
+func (db *DB) ListProjects(includeArchived bool) ([]Project, error) {
+	query := `SELECT ` + projectColumns + ` FROM projects`
+	if !includeArchived {
+		query += ` WHERE archived = 0`
+	}
+	query += ` ORDER BY COALESCE(client, '') COLLATE NOCASE, name COLLATE NOCASE`
+
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var projects []Project
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, p)
+	}
+	return projects, rows.Err()
+}
+
+// GetProject retourne un projet par son identifiant
+func (db *DB) GetProject(id int64) (Project, error) {
+	p, err := scanProject(db.conn.QueryRow(`SELECT `+projectColumns+` FROM projects WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Project{}, ErrProjectNotFound
+	}
+	return p, err
+}
+
+// CreateProject crée un projet ; une couleur est choisie si elle est vide
+func (db *DB) CreateProject(p *Project) error {
+	if p.Color == "" {
+		p.Color = defaultProjectColor(p.Name)
+	}
+
+	result, err := db.conn.Exec(`
+		INSERT INTO projects (name, client, color, archived) VALUES (?, ?, ?, ?)
+		ON CONFLICT(name) DO NOTHING
+	`, p.Name, nullIfEmpty(p.Client), p.Color, p.Archived)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrProjectExists
+	}
+
+	p.ID, err = result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	created, err := db.GetProject(p.ID)
+	if err != nil {
+		return err
+	}
+	p.CreatedAt = created.CreatedAt
+	return nil
+}
+
+// UpdateProject modifie un projet. Un renommage est reporté sur les
+// activités et les règles qui lui attribuent du temps.
+func (db *DB) UpdateProject(p Project) error {
+	previous, err := db.GetProject(p.ID)
+	if err != nil {
+		return err
+	}
+	if p.Color == "" {
+		p.Color = previous.Color
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var conflict int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM projects WHERE name = ? AND id <> ?`, p.Name, p.ID).Scan(&conflict)
+	if err != nil {
+		return err
+	}
+	if conflict > 0 {
+		return ErrProjectExists
+	}
+
+	_, err = tx.Exec(`
+		UPDATE projects SET name = ?, client = ?, color = ?, archived = ? WHERE id = ?
+	`, p.Name, nullIfEmpty(p.Client), p.Color, p.Archived, p.ID)
+	if err != nil {
+		return err
+	}
+
+	if p.Name != previous.Name {
+		if _, err := tx.Exec(`UPDATE activities SET project = ? WHERE project = ? COLLATE NOCASE`, p.Name, previous.Name); err != nil {
+			return err
+		}
+		if err := renameRuleProject(tx, previous.Name, p.Name); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// DeleteProject supprime un projet : son temps redevient non attribué et
+// les règles cessent de lui attribuer des activités
+func (db *DB) DeleteProject(id int64) error {
+	p, err := db.GetProject(id)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM projects WHERE id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE activities SET project = NULL WHERE project = ? COLLATE NOCASE`, p.Name); err != nil {
+		return err
+	}
+	if err := renameRuleProject(tx, p.Name, ""); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// renameRuleProject remplace le projet old par name dans l'action des règles
+func renameRuleProject(tx *sql.Tx, old, name string) error {
+	rows, err := tx.Query(`SELECT id, action FROM rules`)
+	if err != nil {
+		return err
+	}
+
+	changed := map[int64]string{}
+	for rows.Next() {
+		var id int64
+		var encoded string
+		if err := rows.Scan(&id, &encoded); err != nil {
+			rows.Close()
+			return err
+		}
+		var action rules.Action
+		if err := json.Unmarshal([]byte(encoded), &action); err != nil || !strings.EqualFold(action.Project, old) {
+			continue
+		}
+		action.Project = name
+		updated, err := json.Marshal(action)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		changed[id] = string(updated)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	for id, action := range changed {
+		if _, err := tx.Exec(`UPDATE rules SET action = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`, action, id); err != nil {
+			return err
+		}
+	}
+	return bumpRulesVersion(tx)
+}
+
+// projectsBackfilledKey marque la reprise des projets de l'historique
+const projectsBackfilledKey = "projects_backfilled"
+
+// migrateLegacyProjects crée les projets des activités enregistrées avant
+// la table projects. La reprise n'est faite qu'une fois : elle parcourrait
+// sinon tout l'historique à chaque démarrage.
+func (db *DB) migrateLegacyProjects() error {
+	done, err := db.GetConfig(projectsBackfilledKey)
+	if err != nil || done != "" {
+		return err
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`INSERT OR IGNORE INTO projects (name)
+		SELECT DISTINCT project FROM activities WHERE project IS NOT NULL AND project <> ''`)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT INTO config (key, value) VALUES (?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value`, projectsBackfilledKey, time.Now().Format(time.RFC3339))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ensureProject crée le projet attribué par une règle s'il n'existe pas encore
+func ensureProject(e execer, name string) error {
+	_, err := e.Exec(`INSERT OR IGNORE INTO projects (name, color) VALUES (?, ?)`, name, defaultProjectColor(name))
+	return err
+}
+
+func scanProject(s scanner) (Project, error) {
+	var p Project
+	err := s.Scan(&p.ID, &p.Name, &p.Client, &p.Color, &p.Archived, &p.CreatedAt)
+	if p.Color == "" {
+		// Projets repris de l'historique par la migration
+		p.Color = defaultProjectColor(p.Name)
+	}
+	return p, err
+}
+
+// ProjectStat est le temps actif attribué à un projet
+type ProjectStat struct {
+	ProjectID int64 // 0 = temps non attribué
+	Project   string
+	Client    string
+	Color     string
+	Archived  bool
+	Seconds   int64
+}
+
+// GetStatsByProject retourne le temps actif par projet, le plus long
+// d'abord ; le temps non attribué a un ProjectID nul
+func (db *DB) GetStatsByProject(start, end time.Time) ([]ProjectStat, error) {
+	query := `
+		SELECT COALESCE(p.id, 0), COALESCE(p.name, ''), COALESCE(p.client, ''), COALESCE(p.color, ''),
+			COALESCE(p.archived, 0), SUM(a.duration_seconds) AS total_duration
+		FROM activities a
+		LEFT JOIN projects p ON p.name = a.project
+		WHERE a.start_time >= ? AND a.start_time < ? AND a.is_idle = 0
+		GROUP BY COALESCE(p.id, 0)
+		ORDER BY total_duration DESC
+	`
+
+	rows, err := db.conn.Query(query, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []ProjectStat
+	for rows.Next() {
+		var s ProjectStat
+		if err := rows.Scan(&s.ProjectID, &s.Project, &s.Client, &s.Color, &s.Archived, &s.Seconds); err != nil {
+			return nil, err
+		}
+		if s.Color == "" && s.ProjectID != 0 {
+			s.Color = defaultProjectColor(s.Project)
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+// projectFilter restreint une requête sur activities (alias a) au projet
+// donné, ou au temps non attribué si project est vide
+func projectFilter(project string) (string, []any) {
+	if project == "" {
+		return ` AND NOT EXISTS (SELECT 1 FROM projects p WHERE p.name = a.project)`, nil
+	}
+	return ` AND a.project = ? COLLATE NOCASE`, []any{project}
+}
+
+// GetProjectGroupedStats retourne le temps d'un projet (ou le temps non
+// attribué si project est vide) groupé par app puis par enriched_name
+func (db *DB) GetProjectGroupedStats(project string, start, end time.Time) (map[string]map[string]int64, error) {
+	filter, args := projectFilter(project)
+	query := `
+		SELECT a.app_name, COALESCE(a.enriched_name, a.app_name) AS enriched, SUM(a.duration_seconds)
+		FROM activities a
+		WHERE a.start_time >= ? AND a.start_time < ? AND a.is_idle = 0` + filter + `
+		GROUP BY a.app_name, enriched
+	`
+
+	rows, err := db.conn.Query(query, append([]any{start, end}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	grouped := make(map[string]map[string]int64)
+	for rows.Next() {
+		var appName, enrichedName string
+		var duration int64
+		if err := rows.Scan(&appName, &enrichedName, &duration); err != nil {
+			return nil, err
+		}
+		if grouped[appName] == nil {
+			grouped[appName] = make(map[string]int64)
+		}
+		grouped[appName][enrichedName] = duration
+	}
+	return grouped, rows.Err()
+}
+
+// DailySeconds est le temps actif d'une journée
+type DailySeconds struct {
+	Date    time.Time // minuit local
+	Seconds int64
+}
+
+// GetProjectDailyStats retourne le temps actif d'un projet (ou non attribué
+// si project est vide) pour chaque jour ayant de l'activité
+func (db *DB) GetProjectDailyStats(project string, start, end time.Time) ([]DailySeconds, error) {
+	filter, args := projectFilter(project)
+	query := `
+		SELECT date(a.start_time, 'localtime') AS day, SUM(a.duration_seconds)
+		FROM activities a
+		WHERE a.start_time >= ? AND a.start_time < ? AND a.is_idle = 0` + filter + `
+		GROUP BY day
+		ORDER BY day
+	`
+
+	rows, err := db.conn.Query(query, append([]any{start, end}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var days []DailySeconds
+	for rows.Next() {
+		var dayStr string
+		var d DailySeconds
+		if err := rows.Scan(&dayStr, &d.Seconds); err != nil {
+			return nil, err
+		}
+		if d.Date, err = time.ParseInLocation("2006-01-02", dayStr, time.Local); err != nil {
+			continue
+		}
+		days = append(days, d)
+	}
+	return days, rows.Err()
+}
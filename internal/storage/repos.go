@@ -0,0 +1,94 @@
+package storage
+
+import "time"
+
+// repoExpr est le nom d'un dépôt : son distant s'il est connu, sinon sa racine
+const repoExpr = `COALESCE(repo_remote, repo_root)`
+
+// RepoStat est le temps actif passé dans un dépôt git
+type RepoStat struct {
+	Repo       string // distant ("github.com/acme/api"), sinon racine locale
+	Root       string // dernière racine locale utilisée
+	Branches   int    // branches distinctes
+	Activities int
+	Seconds    int64
+	LastSeen   time.Time
+}
+
+// BranchStat est le temps actif passé sur une branche d'un dépôt git
+type BranchStat struct {
+	Repo       string
+	Branch     string
+	Activities int
+	Seconds    int64
+	LastSeen   time.Time
+}
+
+// GetRepoStats retourne au plus limit dépôts git de la période, le temps le
+// plus long d'abord. Les clones d'un même distant sont cumulés.
+func (db *DB) GetRepoStats(start, end time.Time, limit int) ([]RepoStat, error) {
+	// Avec un seul MAX, SQLite prend repo_root sur la ligne de la dernière activité
+	rows, err := db.conn.Query(`
+		SELECT `+repoExpr+` AS repo, repo_root, COUNT(DISTINCT branch), COUNT(*),
+			SUM(duration_seconds) AS total_duration, MAX(end_time)
+		FROM activities
+		WHERE start_time >= ? AND start_time < ? AND is_idle = 0 AND repo_root IS NOT NULL
+		GROUP BY repo
+		ORDER BY total_duration DESC
+		LIMIT ?
+	`, start, end, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []RepoStat
+	for rows.Next() {
+		var s RepoStat
+		var last string
+		if err := rows.Scan(&s.Repo, &s.Root, &s.Branches, &s.Activities, &s.Seconds, &last); err != nil {
+			return nil, err
+		}
+		s.LastSeen, _ = parseStoredTime(last)
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+// GetBranchStats retourne au plus limit branches de la période (d'un seul
+// dépôt si repo n'est pas vide, désigné par son distant ou sa racine), le
+// temps le plus long d'abord
+func (db *DB) GetBranchStats(start, end time.Time, repo string, limit int) ([]BranchStat, error) {
+	query := `
+		SELECT ` + repoExpr + ` AS repo, COALESCE(branch, '') AS br, COUNT(*),
+			SUM(duration_seconds) AS total_duration, MAX(end_time)
+		FROM activities
+		WHERE start_time >= ? AND start_time < ? AND is_idle = 0 AND repo_root IS NOT NULL`
+	args := []any{start, end}
+	if repo != "" {
+		query += ` AND (repo_remote = ? OR repo_root = ?)`
+		args = append(args, repo, repo)
+	}
+	query += `
+		GROUP BY repo, br
+		ORDER BY total_duration DESC
+		LIMIT ?`
+
+	rows, err := db.conn.Query(query, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []BranchStat
+	for rows.Next() {
+		var s BranchStat
+		var last string
+		if err := rows.Scan(&s.Repo, &s.Branch, &s.Activities, &s.Seconds, &last); err != nil {
+			return nil, err
+		}
+		s.LastSeen, _ = parseStoredTime(last)
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
@@ -0,0 +1,202 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"trackmytime/internal/vault"
+)
+
+// reprocessCheckpointKey est la clé de config du point de reprise du retraitement
+const reprocessCheckpointKey = "reprocess_checkpoint"
+
+// ReprocessCheckpoint est l'avancement d'un retraitement de l'historique :
+// les activités de la plage jusqu'à LastID inclus ont été traitées
+type ReprocessCheckpoint struct {
+	Start  time.Time `json:"start"` // zéro = depuis le début
+	End    time.Time `json:"end"`   // zéro = jusqu'à la fin
+	LastID int64     `json:"last_id"`
+}
+
+// reprocessFilter restreint une requête sur activities à la plage du
+// retraitement et aux activités d'identifiant supérieur à afterID. Les
+// périodes d'inactivité détectées (application IDLE) ne sont pas des
+// fenêtres et sont exclues.
+func reprocessFilter(start, end time.Time, afterID int64) (string, []any) {
+	where := ` WHERE id > ? AND app_name <> 'IDLE'`
+	args := []any{afterID}
+	if !start.IsZero() {
+		where += ` AND start_time >= ?`
+		args = append(args, start)
+	}
+	if !end.IsZero() {
+		where += ` AND start_time < ?`
+		args = append(args, end)
+	}
+	return where, args
+}
+
+// CountReprocessActivities compte les activités de [start, end) restant à
+// retraiter après afterID
+func (db *DB) CountReprocessActivities(start, end time.Time, afterID int64) (int, error) {
+	where, args := reprocessFilter(start, end, afterID)
+	var count int
+	err := db.conn.QueryRow(`SELECT COUNT(*) FROM activities`+where, args...).Scan(&count)
+	return count, err
+}
+
+// ReprocessActivities retourne au plus limit activités de [start, end)
+// d'identifiant supérieur à afterID, par identifiant croissant. Tags ne
+// contient que les tags posés par les règles ; tags et entités sont triés.
+func (db *DB) ReprocessActivities(start, end time.Time, afterID int64, limit int) ([]Activity, error) {
+	where, args := reprocessFilter(start, end, afterID)
+	c, err := db.cipher(db.conn)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.conn.Query(`SELECT `+reprocessColumns+` FROM activities`+where+` ORDER BY id LIMIT ?`,
+		append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var activities []Activity
+	for rows.Next() {
+		a, err := scanReprocessActivity(rows, c)
+		if err != nil {
+			return nil, err
+		}
+		activities = append(activities, a)
+	}
+	return activities, rows.Err()
+}
+
+// reprocessColumns sont les colonnes d'activité lues par scanReprocessActivity
+const reprocessColumns = `id, app_name, COALESCE(enriched_name, app_name), COALESCE(category, ''), COALESCE(project, ''),
+	COALESCE((SELECT GROUP_CONCAT(t.name, ',') FROM activity_tags at JOIN tags t ON t.id = at.tag_id
+		WHERE at.activity_id = activities.id AND at.source = 'rule'), ''), ` + entityListExpr + `,
+	COALESCE(inferred_site, ''), site_confidence, COALESCE(file_name, ''), COALESCE(file_extension, ''),
+	COALESCE(language, ''), COALESCE(command, ''), COALESCE(cwd, ''), COALESCE(ssh_host, ''), COALESCE(url, ''),
+	COALESCE(repo_root, ''), COALESCE(repo_remote, ''), COALESCE(branch, ''), COALESCE(window_title, ''), COALESCE(process_path, ''), start_time, end_time, duration_seconds, is_idle`
+
+// scanReprocessActivity lit une activité à réenrichir et la déchiffre avec
+// c ; Tags ne contient que les tags posés par les règles, triés comme les
+// entités
+func scanReprocessActivity(s scanner, c *vault.Cipher) (Activity, error) {
+	var a Activity
+	var tags, found string
+	err := s.Scan(&a.ID, &a.AppName, &a.EnrichedName, &a.Category, &a.Project, &tags, &found,
+		&a.InferredSite, &a.Confidence, &a.Document.FileName, &a.Document.Extension, &a.Document.Language,
+		&a.Command, &a.WorkingDir, &a.SSHHost, &a.URL, &a.Repo.Root, &a.Repo.Remote, &a.Repo.Branch,
+		&a.WindowTitle, &a.ProcessPath,
+		&a.StartTime, &a.EndTime, &a.DurationSecs, &a.IsIdle)
+	if err != nil {
+		return Activity{}, err
+	}
+	if err := openActivity(c, &a); err != nil {
+		return Activity{}, err
+	}
+	if tags != "" {
+		a.Tags = strings.Split(tags, ",")
+		sort.Strings(a.Tags)
+	}
+	a.Entities = parseEntityList(found)
+	return a, nil
+}
+
+// UpdateEnrichment réécrit l'enrichissement des activités (nom enrichi,
+// catégorie, projet, site déduit, fichier, inactivité, entités et tags posés
+// par les règles ; les tags manuels sont conservés) et enregistre le point de
+// reprise dans la même transaction
+func (db *DB) UpdateEnrichment(activities []Activity, checkpoint ReprocessCheckpoint) error {
+	encoded, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	c, err := db.cipher(tx)
+	if err != nil {
+		return err
+	}
+	if err := updateEnrichment(tx, c, activities); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO config (key, value) VALUES (?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = CURRENT_TIMESTAMP
+	`, reprocessCheckpointKey, string(encoded))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// updateEnrichment réécrit l'enrichissement des activités dans la transaction ;
+// l'URL est chiffrée avec c
+func updateEnrichment(tx *sql.Tx, c *vault.Cipher, activities []Activity) error {
+	for _, a := range activities {
+		tags, err := normalizeTags(a.Tags)
+		if err != nil {
+			return err
+		}
+		if a.Project != "" {
+			if err := ensureProject(tx, a.Project); err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.Exec(`
+			UPDATE activities SET enriched_name = ?, category = ?, project = ?, inferred_site = ?,
+				site_confidence = ?, file_name = ?, file_extension = ?, language = ?, url = ?, is_idle = ?
+			WHERE id = ?
+		`, a.EnrichedName, nullIfEmpty(a.Category), nullIfEmpty(a.Project), nullIfEmpty(a.InferredSite),
+			a.Confidence, nullIfEmpty(a.Document.FileName), nullIfEmpty(a.Document.Extension),
+			nullIfEmpty(a.Document.Language), nullIfEmpty(c.Seal(a.URL)), a.IsIdle, a.ID)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM activity_tags WHERE activity_id = ? AND source = ?`, a.ID, TagSourceRule); err != nil {
+			return err
+		}
+		if err := addTags(tx, a.ID, tags, TagSourceRule); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM activity_entities WHERE activity_id = ?`, a.ID); err != nil {
+			return err
+		}
+		if err := addEntities(tx, a.ID, a.Entities); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetReprocessCheckpoint retourne le point de reprise du dernier retraitement
+// interrompu, ou nil s'il n'y en a pas
+func (db *DB) GetReprocessCheckpoint() (*ReprocessCheckpoint, error) {
+	value, err := db.GetConfig(reprocessCheckpointKey)
+	if err != nil || value == "" {
+		return nil, err
+	}
+	var checkpoint ReprocessCheckpoint
+	if err := json.Unmarshal([]byte(value), &checkpoint); err != nil {
+		return nil, err
+	}
+	return &checkpoint, nil
+}
+
+// ClearReprocessCheckpoint oublie le point de reprise (retraitement terminé)
+func (db *DB) ClearReprocessCheckpoint() error {
+	_, err := db.conn.Exec(`DELETE FROM config WHERE key = ?`, reprocessCheckpointKey)
+	return err
+}
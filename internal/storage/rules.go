@@ -0,0 +1,262 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"trackmytime/internal/rules"
+)
+
+// ErrRuleNotFound est retourné quand une règle n'existe pas
+var ErrRuleNotFound = errors.New("règle introuvable")
+
+// Clés de la table config utilisées par les règles
+const (
+	configRulesSeeded  = "rules_seeded"
+	configRulesVersion = "rules_version"
+)
+
+// execer est commun à *sql.DB et *sql.Tx
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// ListRules retourne toutes les règles dans leur ordre d'évaluation
+func (db *DB) ListRules() ([]rules.Rule, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, name, position, enabled, conditions, action
+		FROM rules
+		ORDER BY position, id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rs []rules.Rule
+	for rows.Next() {
+		r, err := scanRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rs = append(rs, r)
+	}
+	return rs, rows.Err()
+}
+
+// GetRule retourne une règle par son identifiant
+func (db *DB) GetRule(id int64) (rules.Rule, error) {
+	row := db.conn.QueryRow(`
+		SELECT id, name, position, enabled, conditions, action
+		FROM rules
+		WHERE id = ?
+	`, id)
+	r, err := scanRule(row)
+	if err == sql.ErrNoRows {
+		return rules.Rule{}, ErrRuleNotFound
+	}
+	return r, err
+}
+
+// CreateRule insère une règle ; une position nulle la place en dernier
+func (db *DB) CreateRule(r *rules.Rule) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if r.Position <= 0 {
+		if err := tx.QueryRow(`SELECT COALESCE(MAX(position), 0) + 1 FROM rules`).Scan(&r.Position); err != nil {
+			return err
+		}
+	}
+	if err := insertRule(tx, r); err != nil {
+		return err
+	}
+	if err := bumpRulesVersion(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateRule remplace le contenu d'une règle existante
+func (db *DB) UpdateRule(r rules.Rule) error {
+	conditions, action, err := encodeRule(r)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE rules
+		SET name = ?, position = ?, enabled = ?, conditions = ?, action = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, r.Name, r.Position, r.Enabled, conditions, action, r.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrRuleNotFound
+	}
+	if err := bumpRulesVersion(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteRule supprime une règle
+func (db *DB) DeleteRule(id int64) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM rules WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrRuleNotFound
+	}
+	if err := bumpRulesVersion(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ReplaceRules remplace toutes les règles (import, retour aux règles par
+// défaut). Les positions sont renumérotées dans l'ordre de rs.
+func (db *DB) ReplaceRules(rs []rules.Rule) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM rules`); err != nil {
+		return err
+	}
+	for i := range rs {
+		rs[i].Position = i + 1
+		if err := insertRule(tx, &rs[i]); err != nil {
+			return err
+		}
+	}
+	if err := bumpRulesVersion(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// EnsureDefaultRules installe les règles par défaut lors du premier
+// démarrage, puis ajoute à la suite celles apparues dans une version plus
+// récente. Les règles supprimées par l'utilisateur ne sont pas réinstallées.
+func (db *DB) EnsureDefaultRules() error {
+	seeded, err := db.GetConfig(configRulesSeeded)
+	if err != nil {
+		return err
+	}
+
+	version := 0
+	if seeded != "" {
+		if version, err = strconv.Atoi(seeded); err != nil {
+			return fmt.Errorf("version des règles par défaut invalide: %q", seeded)
+		}
+	}
+	if version >= rules.DefaultsVersion {
+		return nil
+	}
+
+	if version == 0 {
+		err = db.ReplaceRules(rules.Defaults())
+	} else {
+		for _, r := range rules.DefaultsSince(version) {
+			r.Position = 0
+			if err = db.CreateRule(&r); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return db.SetConfig(configRulesSeeded, strconv.Itoa(rules.DefaultsVersion))
+}
+
+// RulesVersion retourne un compteur incrémenté à chaque modification des
+// règles, pour qu'un autre processus (l'agent) sache quand les recharger
+func (db *DB) RulesVersion() (string, error) {
+	return db.GetConfig(configRulesVersion)
+}
+
+// bumpRulesVersion incrémente la version des règles
+func bumpRulesVersion(tx execer) error {
+	_, err := tx.Exec(`
+		INSERT INTO config (key, value) VALUES (?, '1')
+		ON CONFLICT(key) DO UPDATE SET value = CAST(value AS INTEGER) + 1, updated_at = CURRENT_TIMESTAMP
+	`, configRulesVersion)
+	return err
+}
+
+// insertRule insère une règle et renseigne son ID
+func insertRule(tx execer, r *rules.Rule) error {
+	conditions, action, err := encodeRule(*r)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO rules (name, position, enabled, conditions, action)
+		VALUES (?, ?, ?, ?, ?)
+	`, r.Name, r.Position, r.Enabled, conditions, action)
+	if err != nil {
+		return err
+	}
+
+	r.ID, err = result.LastInsertId()
+	return err
+}
+
+// encodeRule sérialise les conditions et l'action en JSON
+func encodeRule(r rules.Rule) (string, string, error) {
+	conditions, err := json.Marshal(r.Conditions)
+	if err != nil {
+		return "", "", err
+	}
+	action, err := json.Marshal(r.Action)
+	if err != nil {
+		return "", "", err
+	}
+	return string(conditions), string(action), nil
+}
+
+// scanner est commun à *sql.Row et *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanRule lit une ligne de la table rules
+func scanRule(row scanner) (rules.Rule, error) {
+	var r rules.Rule
+	var conditions, action string
+	if err := row.Scan(&r.ID, &r.Name, &r.Position, &r.Enabled, &conditions, &action); err != nil {
+		return rules.Rule{}, err
+	}
+	if err := json.Unmarshal([]byte(conditions), &r.Conditions); err != nil {
+		return rules.Rule{}, fmt.Errorf("règle %d: conditions invalides: %w", r.ID, err)
+	}
+	if err := json.Unmarshal([]byte(action), &r.Action); err != nil {
+		return rules.Rule{}, fmt.Errorf("règle %d: action invalide: %w", r.ID, err)
+	}
+	return r, nil
+}
@@ -0,0 +1,198 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ShellCommand est une commande signalée par les hooks de shell
+type ShellCommand struct {
+	ID         int64
+	Session    string // identifiant du shell, unique pour chaque shell lancé
+	Seq        int64  // numéro de la commande dans la session
+	Shell      string // bash, zsh ou fish
+	Command    string
+	Program    string // programme et sous-commande ("go test")
+	WorkingDir string
+	StartedAt  time.Time
+	EndedAt    time.Time     // zéro tant que la commande tourne
+	Duration   time.Duration // zéro tant que la commande tourne
+	ExitCode   *int          // nil tant que la commande tourne ou si sa fin est perdue
+
+	// Activité du terminal au démarrage de la commande (0 si inconnue) et
+	// temps pendant lequel l'application de ce terminal avait le focus
+	ActivityID   int64
+	FocusSeconds int64
+}
+
+// ShellCommandFilter restreint ListShellCommands
+type ShellCommandFilter struct {
+	Start, End time.Time
+	Program    string // programme exact ("go test"), vide = tous
+	Failed     bool   // uniquement les commandes au code de sortie non nul
+	Limit      int
+}
+
+// ShellStat est le temps passé dans les commandes d'un programme
+type ShellStat struct {
+	Program  string
+	Runs     int
+	Failures int
+	Duration time.Duration // cumul des durées des commandes terminées
+	Longest  time.Duration
+	LastRun  time.Time
+}
+
+// RecordShellStart enregistre le démarrage d'une commande et retourne son
+// identifiant. Les hooks envoient les événements en arrière-plan : la fin
+// peut arriver avant le début, d'où l'identification par session et numéro.
+// Les commandes précédentes de la session restées ouvertes (fin perdue) sont
+// closes à cet instant, sans code de sortie.
+func (db *DB) RecordShellStart(c ShellCommand) (int64, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE shell_commands SET ended_at = ?,
+			duration_ms = MAX(0, CAST((julianday(?) - julianday(started_at)) * 86400000 AS INTEGER))
+		WHERE session = ? AND seq < ? AND ended_at IS NULL
+	`, c.StartedAt, c.StartedAt, c.Session, c.Seq)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err = tx.QueryRow(`
+		INSERT INTO shell_commands (session, seq, shell, command, program, cwd, started_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (session, seq) DO UPDATE SET shell = excluded.shell, command = excluded.command,
+			program = excluded.program, cwd = excluded.cwd, started_at = excluded.started_at,
+			duration_ms = CASE WHEN ended_at IS NOT NULL AND COALESCE(duration_ms, 0) = 0
+				THEN MAX(0, CAST((julianday(ended_at) - julianday(excluded.started_at)) * 86400000 AS INTEGER))
+				ELSE duration_ms END
+		RETURNING id
+	`, c.Session, c.Seq, c.Shell, c.Command, c.Program, nullIfEmpty(c.WorkingDir), c.StartedAt).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// RecordShellEnd enregistre la fin de la commande seq de la session et
+// retourne son identifiant. duration vaut, si nulle, l'écart entre le début
+// et endedAt.
+func (db *DB) RecordShellEnd(session string, seq int64, endedAt time.Time, exitCode int, duration time.Duration) (int64, error) {
+	var id int64
+	err := db.conn.QueryRow(`
+		INSERT INTO shell_commands (session, seq, shell, command, program, started_at, ended_at, duration_ms, exit_code)
+		VALUES (?, ?, '', '', '', ?, ?, ?, ?)
+		ON CONFLICT (session, seq) DO UPDATE SET ended_at = excluded.ended_at, exit_code = excluded.exit_code,
+			duration_ms = CASE WHEN excluded.duration_ms > 0 THEN excluded.duration_ms
+				ELSE MAX(0, CAST((julianday(excluded.ended_at) - julianday(started_at)) * 86400000 AS INTEGER)) END
+		RETURNING id
+	`, session, seq, endedAt.Add(-duration), endedAt, duration.Milliseconds(), exitCode).Scan(&id)
+	return id, err
+}
+
+// ListShellCommands retourne les commandes démarrées dans [Start, End), les
+// plus récentes d'abord, avec l'activité du terminal où elles ont été lancées
+func (db *DB) ListShellCommands(filter ShellCommandFilter) ([]ShellCommand, error) {
+	now := time.Now()
+	// L'activité au démarrage est celle du terminal où la commande a été
+	// tapée ; le focus cumule le temps actif de la même application jusqu'à
+	// la fin de la commande (maintenant si elle tourne encore)
+	query := `
+		SELECT c.id, c.session, c.seq, c.shell, c.command, c.program, COALESCE(c.cwd, ''), c.started_at,
+			COALESCE(c.ended_at, ''), COALESCE(c.duration_ms, 0), c.exit_code, COALESCE(l.id, 0),
+			COALESCE((SELECT SUM(MAX(0, MIN(julianday(a.end_time), julianday(COALESCE(c.ended_at, ?)))
+					- MAX(julianday(a.start_time), julianday(c.started_at))))
+				FROM activities a
+				WHERE a.app_name = l.app_name AND a.is_idle = 0
+					AND a.start_time < COALESCE(c.ended_at, ?) AND a.end_time > c.started_at) * 86400, 0)
+		FROM shell_commands c
+		LEFT JOIN activities l ON l.id = (
+			SELECT a.id FROM activities a
+			WHERE a.is_idle = 0 AND a.start_time <= c.started_at AND a.end_time > c.started_at
+			ORDER BY a.start_time DESC LIMIT 1)
+		WHERE c.started_at >= ? AND c.started_at < ? AND c.command != ''`
+	args := []any{now, now, filter.Start, filter.End}
+	if filter.Program != "" {
+		query += ` AND c.program = ?`
+		args = append(args, filter.Program)
+	}
+	if filter.Failed {
+		query += ` AND c.exit_code != 0`
+	}
+	query += ` ORDER BY c.started_at DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var commands []ShellCommand
+	for rows.Next() {
+		var c ShellCommand
+		var ended string
+		var durationMs int64
+		var exitCode sql.NullInt64
+		var focus float64
+		err := rows.Scan(&c.ID, &c.Session, &c.Seq, &c.Shell, &c.Command, &c.Program, &c.WorkingDir, &c.StartedAt,
+			&ended, &durationMs, &exitCode, &c.ActivityID, &focus)
+		if err != nil {
+			return nil, err
+		}
+		if ended != "" {
+			c.EndedAt, _ = parseStoredTime(ended)
+		}
+		c.Duration = time.Duration(durationMs) * time.Millisecond
+		if exitCode.Valid {
+			code := int(exitCode.Int64)
+			c.ExitCode = &code
+		}
+		c.FocusSeconds = int64(focus + 0.5)
+		commands = append(commands, c)
+	}
+	return commands, rows.Err()
+}
+
+// GetShellStats retourne au plus limit programmes lancés sur la période, le
+// temps cumulé le plus long d'abord
+func (db *DB) GetShellStats(start, end time.Time, limit int) ([]ShellStat, error) {
+	rows, err := db.conn.Query(`
+		SELECT program, COUNT(*), COUNT(CASE WHEN exit_code != 0 THEN 1 END),
+			COALESCE(SUM(duration_ms), 0) AS total, COALESCE(MAX(duration_ms), 0), MAX(started_at)
+		FROM shell_commands
+		WHERE started_at >= ? AND started_at < ? AND command != ''
+		GROUP BY program
+		ORDER BY total DESC
+		LIMIT ?
+	`, start, end, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []ShellStat
+	for rows.Next() {
+		var s ShellStat
+		var totalMs, longestMs int64
+		var last string
+		if err := rows.Scan(&s.Program, &s.Runs, &s.Failures, &totalMs, &longestMs, &last); err != nil {
+			return nil, err
+		}
+		s.Duration = time.Duration(totalMs) * time.Millisecond
+		s.Longest = time.Duration(longestMs) * time.Millisecond
+		s.LastRun, _ = parseStoredTime(last)
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
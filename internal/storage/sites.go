@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"trackmytime/internal/vault"
+)
+
+// RecordSiteCandidate comptabilise un site déduit d'un titre d'onglet et
+// retourne le nombre de titres différents dans lesquels il a été vu : un
+// titre déjà vu pour ce site n'est pas recompté. Les titres sont retenus par
+// leur empreinte (celle de la clé de chiffrement si les titres sont
+// chiffrés) ; seul le dernier est gardé, chiffré.
+func (db *DB) RecordSiteCandidate(name, title string, duration time.Duration) (int, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	c, err := db.cipher(tx)
+	if err != nil {
+		return 0, err
+	}
+	result, err := tx.Exec(`INSERT OR IGNORE INTO site_candidate_titles (name, title_index) VALUES (?, ?)`,
+		name, siteTitleIndex(c, title))
+	if err != nil {
+		return 0, err
+	}
+	added, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO site_candidates (name, occurrences, total_seconds, last_title)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			occurrences = occurrences + excluded.occurrences,
+			total_seconds = total_seconds + excluded.total_seconds,
+			last_title = excluded.last_title,
+			last_seen = CURRENT_TIMESTAMP
+	`, name, added, int64(duration.Seconds()), c.Seal(title))
+	if err != nil {
+		return 0, err
+	}
+
+	var occurrences int
+	if err := tx.QueryRow(`SELECT occurrences FROM site_candidates WHERE name = ?`, name).Scan(&occurrences); err != nil {
+		return 0, err
+	}
+	return occurrences, tx.Commit()
+}
+
+// siteTitleIndex retourne l'empreinte d'un titre de site candidat : l'index
+// de la clé si les titres sont chiffrés, sinon un SHA-256 tronqué de même
+// longueur. Après un changement de clé, un titre déjà vu peut être compté
+// une seconde fois.
+func siteTitleIndex(c *vault.Cipher, title string) string {
+	if index := c.Index(title); index != "" {
+		return index
+	}
+	sum := sha256.Sum256([]byte(title))
+	return hex.EncodeToString(sum[:16])
+}
+
+// ConfirmedSites retourne les sites candidats vus dans au moins minOccurrences titres différents
+func (db *DB) ConfirmedSites(minOccurrences int) ([]string, error) {
+	rows, err := db.conn.Query(`SELECT name FROM site_candidates WHERE occurrences >= ?`, minOccurrences)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"strings"
+	"time"
+)
+
+// storedTimeFormats sont les formats d'écriture des dates par le pilote SQLite
+var storedTimeFormats = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05",
+}
+
+// parseStoredTime lit une date stockée en texte. Les agrégats MIN et MAX
+// sur une colonne DATETIME retournent ce texte et non une date : le pilote ne
+// les convertit pas, contrairement aux colonnes lues directement.
+func parseStoredTime(s string) (time.Time, error) {
+	s = strings.TrimSuffix(s, "Z")
+	var err error
+	for _, layout := range storedTimeFormats {
+		var t time.Time
+		if t, err = time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
@@ -0,0 +1,369 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrActivityNotFound est retourné quand une activité n'existe pas
+var ErrActivityNotFound = errors.New("activité introuvable")
+
+var (
+	// ErrTagNotFound est retourné quand un tag n'existe pas
+	ErrTagNotFound = errors.New("tag introuvable")
+	// ErrInvalidTag est retourné pour un tag vide ou contenant des caractères interdits
+	ErrInvalidTag = errors.New("tag invalide")
+)
+
+// Origine d'un tag sur une activité : les tags posés par les règles sont
+// recalculés au retraitement, les tags manuels sont conservés
+const (
+	TagSourceRule   = "rule"
+	TagSourceManual = "manual"
+)
+
+var tagPattern = regexp.MustCompile(`^[\p{L}\p{N}][\p{L}\p{N}_.:/+-]*$`)
+
+// NormalizeTag retire le # initial et met le tag en minuscules ; les tags
+// vides ou contenant des espaces sont refusés
+func NormalizeTag(tag string) (string, error) {
+	name := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+	if len(name) > 64 || !tagPattern.MatchString(name) {
+		return "", fmt.Errorf("%w %q (lettres, chiffres et _ . : / + -)", ErrInvalidTag, tag)
+	}
+	return name, nil
+}
+
+// normalizeTags normalise une liste de tags et retire les doublons
+func normalizeTags(tags []string) ([]string, error) {
+	var names []string
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		name, err := NormalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// tagNamesExpr liste les tags d'une activité (table activities), séparés par des virgules
+const tagNamesExpr = `COALESCE((SELECT GROUP_CONCAT(t.name, ',') FROM activity_tags at JOIN tags t ON t.id = at.tag_id WHERE at.activity_id = activities.id), '')`
+
+// tagFilter restreint une requête sur la table activities au tag donné (aucun filtre si vide)
+func tagFilter(tag string) (string, []any) {
+	if tag == "" {
+		return "", nil
+	}
+	name, err := NormalizeTag(tag)
+	if err != nil {
+		name = tag
+	}
+	return ` AND EXISTS (SELECT 1 FROM activity_tags at JOIN tags t ON t.id = at.tag_id
+		WHERE at.activity_id = activities.id AND t.name = ?)`, []any{name}
+}
+
+// addTags pose des tags (déjà normalisés) sur une activité
+func addTags(e execer, activityID int64, names []string, source string) error {
+	for _, name := range names {
+		if _, err := e.Exec(`INSERT OR IGNORE INTO tags (name) VALUES (?)`, name); err != nil {
+			return err
+		}
+		_, err := e.Exec(`
+			INSERT OR IGNORE INTO activity_tags (activity_id, tag_id, source)
+			SELECT ?, id, ? FROM tags WHERE name = ?
+		`, activityID, source, name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TagUsage est un tag et son utilisation
+type TagUsage struct {
+	Name       string
+	Activities int
+	Seconds    int64 // temps actif tagué
+}
+
+// ListTags retourne les tags triés par nom avec leur utilisation
+func (db *DB) ListTags() ([]TagUsage, error) {
+	rows, err := db.conn.Query(`
+		SELECT t.name, COUNT(a.id), COALESCE(SUM(CASE WHEN a.is_idle = 0 THEN a.duration_seconds END), 0)
+		FROM tags t
+		LEFT JOIN activity_tags at ON at.tag_id = t.id
+		LEFT JOIN activities a ON a.id = at.activity_id
+		GROUP BY t.id
+		ORDER BY t.name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []TagUsage
+	for rows.Next() {
+		var t TagUsage
+		if err := rows.Scan(&t.Name, &t.Activities, &t.Seconds); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
+// DeleteTag supprime un tag de toutes les activités
+func (db *DB) DeleteTag(tag string) error {
+	name, err := NormalizeTag(tag)
+	if err != nil {
+		return ErrTagNotFound
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM activity_tags WHERE tag_id IN (SELECT id FROM tags WHERE name = ?)`, name); err != nil {
+		return err
+	}
+	result, err := tx.Exec(`DELETE FROM tags WHERE name = ?`, name)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrTagNotFound
+	}
+	return tx.Commit()
+}
+
+// ActivityTags retourne les tags d'une activité triés par nom
+func (db *DB) ActivityTags(activityID int64) ([]string, error) {
+	var exists int
+	if err := db.conn.QueryRow(`SELECT COUNT(*) FROM activities WHERE id = ?`, activityID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if exists == 0 {
+		return nil, ErrActivityNotFound
+	}
+
+	rows, err := db.conn.Query(`
+		SELECT t.name FROM activity_tags at JOIN tags t ON t.id = at.tag_id
+		WHERE at.activity_id = ? ORDER BY t.name
+	`, activityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tags = append(tags, name)
+	}
+	return tags, rows.Err()
+}
+
+// AddActivityTags pose manuellement des tags sur une activité
+func (db *DB) AddActivityTags(activityID int64, tags []string) error {
+	names, err := normalizeTags(tags)
+	if err != nil {
+		return err
+	}
+	if _, err := db.ActivityTags(activityID); err != nil {
+		return err
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := addTags(tx, activityID, names, TagSourceManual); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RemoveActivityTags retire des tags d'une activité, quelle que soit leur origine
+func (db *DB) RemoveActivityTags(activityID int64, tags []string) error {
+	names, err := normalizeTags(tags)
+	if err != nil {
+		return err
+	}
+	if _, err := db.ActivityTags(activityID); err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		_, err := db.conn.Exec(`
+			DELETE FROM activity_tags
+			WHERE activity_id = ? AND tag_id IN (SELECT id FROM tags WHERE name = ?)
+		`, activityID, name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rangeActivities est la sélection des activités actives chevauchant [start, end)
+const rangeActivities = `SELECT id FROM activities WHERE start_time < ? AND end_time > ? AND is_idle = 0`
+
+// TagRange pose manuellement des tags sur les activités actives qui
+// chevauchent [start, end) et retourne le nombre d'activités concernées
+func (db *DB) TagRange(start, end time.Time, tags []string) (int64, error) {
+	names, err := normalizeTags(tags)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var count int64
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM (`+rangeActivities+`)`, end, start).Scan(&count); err != nil {
+		return 0, err
+	}
+	for _, name := range names {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO tags (name) VALUES (?)`, name); err != nil {
+			return 0, err
+		}
+		_, err := tx.Exec(`
+			INSERT OR IGNORE INTO activity_tags (activity_id, tag_id, source)
+			SELECT a.id, t.id, ? FROM (`+rangeActivities+`) a, tags t WHERE t.name = ?
+		`, TagSourceManual, end, start, name)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return count, tx.Commit()
+}
+
+// UntagRange retire des tags des activités actives qui chevauchent
+// [start, end) et retourne le nombre de tags retirés
+func (db *DB) UntagRange(start, end time.Time, tags []string) (int64, error) {
+	names, err := normalizeTags(tags)
+	if err != nil {
+		return 0, err
+	}
+
+	var removed int64
+	for _, name := range names {
+		result, err := db.conn.Exec(`
+			DELETE FROM activity_tags
+			WHERE activity_id IN (`+rangeActivities+`)
+				AND tag_id IN (SELECT id FROM tags WHERE name = ?)
+		`, end, start, name)
+		if err != nil {
+			return removed, err
+		}
+		n, _ := result.RowsAffected()
+		removed += n
+	}
+	return removed, nil
+}
+
+// TagStat est le temps actif portant un tag
+type TagStat struct {
+	Tag        string
+	Activities int
+	Seconds    int64
+}
+
+// GetStatsByTag retourne le temps actif par tag, le plus long d'abord, et le
+// temps sans tag. Une activité portant plusieurs tags compte pour chacun.
+func (db *DB) GetStatsByTag(start, end time.Time) ([]TagStat, int64, error) {
+	rows, err := db.conn.Query(`
+		SELECT t.name, COUNT(*), SUM(a.duration_seconds) AS total_duration
+		FROM activities a
+		JOIN activity_tags at ON at.activity_id = a.id
+		JOIN tags t ON t.id = at.tag_id
+		WHERE a.start_time >= ? AND a.start_time < ? AND a.is_idle = 0
+		GROUP BY t.id
+		ORDER BY total_duration DESC
+	`, start, end)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var stats []TagStat
+	for rows.Next() {
+		var s TagStat
+		if err := rows.Scan(&s.Tag, &s.Activities, &s.Seconds); err != nil {
+			return nil, 0, err
+		}
+		stats = append(stats, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var untagged int64
+	err = db.conn.QueryRow(`
+		SELECT COALESCE(SUM(duration_seconds), 0) FROM activities a
+		WHERE a.start_time >= ? AND a.start_time < ? AND a.is_idle = 0
+			AND NOT EXISTS (SELECT 1 FROM activity_tags at WHERE at.activity_id = a.id)
+	`, start, end).Scan(&untagged)
+	return stats, untagged, err
+}
+
+// migrateLegacyTags reprend les tags de l'ancienne colonne activities.tags
+// (séparés par des virgules) dans la table activity_tags
+func (db *DB) migrateLegacyTags() error {
+	rows, err := db.conn.Query(`SELECT id, tags FROM activities WHERE tags IS NOT NULL AND tags <> ''`)
+	if err != nil {
+		return err
+	}
+	legacy := map[int64][]string{}
+	for rows.Next() {
+		var id int64
+		var tags string
+		if err := rows.Scan(&id, &tags); err != nil {
+			rows.Close()
+			return err
+		}
+		for _, tag := range strings.Split(tags, ",") {
+			if name, err := NormalizeTag(tag); err == nil {
+				legacy[id] = append(legacy[id], name)
+			}
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || len(legacy) == 0 {
+		return err
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for id, names := range legacy {
+		if err := addTags(tx, id, names, TagSourceRule); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`UPDATE activities SET tags = NULL WHERE tags IS NOT NULL`); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
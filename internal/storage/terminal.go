@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// TerminalDimension est le regroupement des statistiques de terminal
+type TerminalDimension string
+
+const (
+	TerminalByCommand TerminalDimension = "command"  // commande au premier plan
+	TerminalByHost    TerminalDimension = "ssh_host" // hôte distant des sessions ssh
+	TerminalByCwd     TerminalDimension = "cwd"      // dossier courant
+)
+
+// TerminalStat est le temps actif passé dans les terminaux pour une valeur
+// de la dimension demandée
+type TerminalStat struct {
+	Value      string
+	Activities int
+	Seconds    int64
+	LastSeen   time.Time
+}
+
+// GetTerminalStats retourne au plus limit valeurs de la dimension by sur la
+// période, le temps le plus long d'abord. Seules les activités dont la
+// dimension est connue sont comptées (les sessions ssh pour TerminalByHost).
+func (db *DB) GetTerminalStats(start, end time.Time, by TerminalDimension, limit int) ([]TerminalStat, error) {
+	switch by {
+	case TerminalByCommand, TerminalByHost, TerminalByCwd:
+	default:
+		return nil, fmt.Errorf("regroupement de terminal inconnu: %q", by)
+	}
+
+	column := string(by)
+	rows, err := db.conn.Query(`
+		SELECT `+column+`, COUNT(*), SUM(duration_seconds) AS total_duration, MAX(end_time)
+		FROM activities
+		WHERE start_time >= ? AND start_time < ? AND is_idle = 0 AND `+column+` <> ''
+		GROUP BY `+column+`
+		ORDER BY total_duration DESC
+		LIMIT ?
+	`, start, end, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []TerminalStat
+	for rows.Next() {
+		var s TerminalStat
+		var last string
+		if err := rows.Scan(&s.Value, &s.Activities, &s.Seconds, &last); err != nil {
+			return nil, err
+		}
+		s.LastSeen, _ = parseStoredTime(last)
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
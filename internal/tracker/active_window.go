@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/shirou/gopsutil/v3/process"
+
+	"trackmytime/internal/gitinfo"
 )
 
 // WindowInfo contient les informations de la fenêtre active
@@ -15,21 +17,34 @@ type WindowInfo struct {
 	AppName     string
 	WindowTitle string
 	ProcessPath string
+	URL         string       // URL de l'onglet actif si connue (navigateurs)
+	Domain      string       // hôte de URL sans www.
+	Command     string       // commande au premier plan (terminaux Linux)
+	WorkingDir  string       // dossier courant de Command
+	SSHHost     string       // hôte distant si Command est ssh
+	Repo        gitinfo.Repo // dépôt git du terminal ou de l'espace de travail de l'éditeur
 	Timestamp   time.Time
 }
 
 // GetActiveWindow retourne les informations de la fenêtre active selon l'OS
 func GetActiveWindow() (*WindowInfo, error) {
+	var window *WindowInfo
+	var err error
 	switch runtime.GOOS {
 	case "darwin":
-		return getActiveWindowMac()
+		window, err = getActiveWindowMac()
 	case "windows":
-		return getActiveWindowWindows()
+		window, err = getActiveWindowWindows()
 	case "linux":
-		return getActiveWindowLinux()
+		window, err = getActiveWindowLinux()
 	default:
 		return nil, fmt.Errorf("OS non supporté: %s", runtime.GOOS)
 	}
+	if err != nil {
+		return nil, err
+	}
+	window.resolveRepo()
+	return window, nil
 }
 
 // getActiveWindowMac récupère la fenêtre active sur macOS
@@ -172,12 +187,26 @@ func getActiveWindowLinux() (*WindowInfo, error) {
 	appName, _ := proc.Name()
 	processPath, _ := proc.Exe()
 
-	return &WindowInfo{
+	window := &WindowInfo{
 		AppName:     appName,
 		WindowTitle: windowTitle,
 		ProcessPath: processPath,
 		Timestamp:   time.Now(),
-	}, nil
+	}
+	if IsTerminal(appName) {
+		window.resolveTerminal(int(pid))
+	}
+	return window, nil
+}
+
+// SameActivity indique si deux relevés de fenêtre décrivent la même activité
+func (w *WindowInfo) SameActivity(other *WindowInfo) bool {
+	return w.AppName == other.AppName &&
+		w.WindowTitle == other.WindowTitle &&
+		w.Command == other.Command &&
+		w.WorkingDir == other.WorkingDir &&
+		w.SSHHost == other.SSHHost &&
+		w.Repo == other.Repo
 }
 
 // getProcessPath tente de récupérer le chemin du processus par son nom
@@ -203,241 +232,3 @@ func getProcessPath(appName string) (string, error) {
 
 	return "", fmt.Errorf("processus non trouvé: %s", appName)
 }
-
-// GetEnrichedName extrait un nom contextualisé depuis le titre de la fenêtre
-func (w *WindowInfo) GetEnrichedName() string {
-	// Navigateurs
-	if isBrowser(w.AppName) {
-		site := extractWebsiteName(w.WindowTitle)
-		if site != "" {
-			return site
-		}
-	}
-
-	// Electron/VSCode
-	if isElectronApp(w.AppName) {
-		project := extractProjectName(w.WindowTitle)
-		if project != "" {
-			return project
-		}
-	}
-
-	// Fallback : garder le nom de l'app
-	return w.AppName
-}
-
-func isBrowser(appName string) bool {
-	browsers := []string{"Brave Browser", "Google Chrome", "Safari",
-		"Firefox", "Microsoft Edge", "Arc", "Zen Browser", "Zen",
-		"Opera", "Vivaldi", "Chromium", "Waterfox", "LibreWolf"}
-	for _, b := range browsers {
-		if strings.Contains(appName, b) {
-			return true
-		}
-	}
-	return false
-}
-
-func extractWebsiteName(title string) string {
-	if title == "" {
-		return ""
-	}
-
-	// Enlever les suffixes navigateur courants
-	suffixes := []string{" – Brave", " - Brave", " – Chrome", " - Chrome",
-		" – Safari", " - Safari", " – Firefox", " - Firefox",
-		" – Edge", " - Edge", " – Arc", " - Arc",
-		" – Zen", " - Zen", " – Opera", " - Opera",
-		" – Vivaldi", " - Vivaldi", " – Chromium", " - Chromium"}
-
-	for _, suffix := range suffixes {
-		if idx := strings.Index(title, suffix); idx > 0 {
-			title = title[:idx]
-		}
-	}
-
-	title = strings.TrimSpace(title)
-	titleLower := strings.ToLower(title)
-
-	// Détecter les sites populaires avec patterns spécifiques
-
-	// X (Twitter) - plusieurs patterns possibles
-	// "Username sur X : "tweet"" / X" → "X"
-	// "Accueil / X" → "X"
-	// "(123) X" → "X"
-	if strings.Contains(titleLower, " sur x :") ||
-		strings.Contains(titleLower, " sur x ") ||
-		strings.Contains(titleLower, "accueil / x") ||
-		strings.HasSuffix(titleLower, " / x") ||
-		strings.Contains(titleLower, ") x") {
-		return "X"
-	}
-
-	// YouTube - "Video Title - YouTube" → "YouTube"
-	if strings.Contains(titleLower, "youtube") {
-		return "YouTube"
-	}
-
-	// Twitch - "Username - Twitch" → "Twitch"
-	if strings.Contains(titleLower, "twitch") {
-		return "Twitch"
-	}
-
-	// TikTok
-	if strings.Contains(titleLower, "tiktok") {
-		return "TikTok"
-	}
-
-	// Gmail - plusieurs patterns
-	if strings.Contains(titleLower, "gmail") || strings.Contains(titleLower, "inbox") {
-		return "Gmail"
-	}
-
-	// GitHub
-	if strings.Contains(titleLower, "github") {
-		return "GitHub"
-	}
-
-	// LinkedIn
-	if strings.Contains(titleLower, "linkedin") {
-		return "LinkedIn"
-	}
-
-	// Reddit
-	if strings.Contains(titleLower, "reddit") {
-		return "Reddit"
-	}
-
-	// Instagram
-	if strings.Contains(titleLower, "instagram") {
-		return "Instagram"
-	}
-
-	// Facebook
-	if strings.Contains(titleLower, "facebook") {
-		return "Facebook"
-	}
-
-	// Discord
-	if strings.Contains(titleLower, "discord") {
-		return "Discord"
-	}
-
-	// Slack
-	if strings.Contains(titleLower, "slack") {
-		return "Slack"
-	}
-
-	// Notion
-	if strings.Contains(titleLower, "notion") {
-		return "Notion"
-	}
-
-	// Google Drive / Docs / Sheets
-	if strings.Contains(titleLower, "google drive") ||
-		strings.Contains(titleLower, "google docs") ||
-		strings.Contains(titleLower, "google sheets") {
-		return "Google Drive"
-	}
-
-	// Stack Overflow
-	if strings.Contains(titleLower, "stack overflow") {
-		return "Stack Overflow"
-	}
-
-	// ChatGPT
-	if strings.Contains(titleLower, "chatgpt") {
-		return "ChatGPT"
-	}
-
-	// Claude
-	if strings.Contains(titleLower, "claude") {
-		return "Claude"
-	}
-
-	// Netflix
-	if strings.Contains(titleLower, "netflix") {
-		return "Netflix"
-	}
-
-	// Spotify
-	if strings.Contains(titleLower, "spotify") {
-		return "Spotify"
-	}
-
-	// Tous les autres sites non reconnus → "Autres"
-	// On retourne "Autres" au lieu d'essayer d'extraire un nom personnalisé
-	return "Autres"
-}
-
-func isElectronApp(appName string) bool {
-	electronApps := []string{"Electron", "Code", "Visual Studio Code",
-		"Cursor", "VSCodium"}
-	for _, app := range electronApps {
-		if strings.Contains(appName, app) {
-			return true
-		}
-	}
-	return false
-}
-
-func extractProjectName(title string) string {
-	if title == "" {
-		return ""
-	}
-
-	// Patterns VSCode/Cursor :
-	// "file.md — TrackMyTime — Perso" → "TrackMyTime" (3 parts)
-	// "TrackMyTime — Perso" → "TrackMyTime" (2 parts, ignore "Perso")
-	// "file.py — my-project" → "my-project"
-
-	parts := strings.Split(title, " — ")
-
-	// Si 3+ parties : prendre la partie du milieu (parts[1])
-	// Exemple: ["file.md", "TrackMyTime", "Perso"] → "TrackMyTime"
-	if len(parts) >= 3 {
-		projectName := strings.TrimSpace(parts[1])
-		if projectName != "" {
-			return projectName
-		}
-	}
-
-	// Si 2 parties : prendre la dernière sauf si générique
-	// Exemple: ["TrackMyTime", "Perso"] → "TrackMyTime"
-	if len(parts) == 2 {
-		// Vérifier la dernière partie d'abord
-		lastPart := strings.TrimSpace(parts[1])
-		genericNames := []string{"Perso", "Workspace", "Visual Studio Code"}
-		isLastGeneric := false
-		for _, generic := range genericNames {
-			if strings.EqualFold(lastPart, generic) {
-				isLastGeneric = true
-				break
-			}
-		}
-
-		// Si dernière partie est générique, prendre la première
-		if isLastGeneric {
-			projectName := strings.TrimSpace(parts[0])
-			if projectName != "" {
-				return projectName
-			}
-		} else {
-			// Sinon prendre la dernière
-			if lastPart != "" {
-				return lastPart
-			}
-		}
-	}
-
-	// Fallback : chercher entre []
-	// "[TrackMyTime] file.md"
-	if strings.HasPrefix(title, "[") {
-		end := strings.Index(title, "]")
-		if end > 1 {
-			return title[1:end]
-		}
-	}
-
-	return ""
-}
@@ -0,0 +1,101 @@
+package tracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"trackmytime/internal/rules"
+)
+
+// chromiumApps sont les navigateurs qui exposent le protocole DevTools
+// (noms d'application macOS et Windows, noms de processus Linux)
+var chromiumApps = map[string]bool{
+	"google chrome":    true,
+	"chrome":           true,
+	"chromium":         true,
+	"chromium-browser": true,
+	"brave browser":    true,
+	"brave":            true,
+	"brave-browser":    true,
+	"microsoft edge":   true,
+	"msedge":           true,
+	"vivaldi":          true,
+	"vivaldi-bin":      true,
+	"opera":            true,
+	"arc":              true,
+}
+
+// DevToolsSource lit l'URL de l'onglet actif d'un navigateur Chromium lancé
+// avec --remote-debugging-port, pour les navigateurs où l'extension ne peut
+// pas être installée
+type DevToolsSource struct {
+	endpoint string
+	client   *http.Client
+}
+
+// devToolsTarget est une cible listée par l'endpoint /json de DevTools
+type devToolsTarget struct {
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// NewDevToolsSource crée une source interrogeant l'endpoint DevTools local,
+// ex: http://127.0.0.1:9222
+func NewDevToolsSource(endpoint string) *DevToolsSource {
+	return &DevToolsSource{
+		endpoint: strings.TrimRight(endpoint, "/"),
+		// Interrogé à chaque relevé : un navigateur qui ne répond pas ne doit
+		// pas retarder le suivi
+		client: &http.Client{Timeout: 500 * time.Millisecond},
+	}
+}
+
+// Attach renseigne l'URL et le domaine de l'onglet actif si la fenêtre est
+// celle d'un navigateur Chromium et que son URL n'est pas déjà connue
+func (d *DevToolsSource) Attach(w *WindowInfo) error {
+	if w.URL != "" || !chromiumApps[strings.ToLower(w.AppName)] {
+		return nil
+	}
+	rawURL, err := d.ActiveTab(w.WindowTitle)
+	if err != nil {
+		return err
+	}
+	w.URL = rawURL
+	w.Domain = rules.URLHost(rawURL)
+	return nil
+}
+
+// ActiveTab retourne l'URL de l'onglet affiché dans la fenêtre de titre
+// windowTitle : celui dont le titre commence le titre de la fenêtre.
+// Retourne une chaîne vide si aucun onglet ne correspond : l'URL d'un autre
+// onglet ou d'une autre fenêtre serait attribuée à tort à l'activité.
+func (d *DevToolsSource) ActiveTab(windowTitle string) (string, error) {
+	resp, err := d.client.Get(d.endpoint + "/json")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("DevTools %s: statut %d", d.endpoint, resp.StatusCode)
+	}
+
+	var targets []devToolsTarget
+	if err := json.NewDecoder(resp.Body).Decode(&targets); err != nil {
+		return "", fmt.Errorf("DevTools %s: %w", d.endpoint, err)
+	}
+
+	for _, t := range targets {
+		// Les outils de développement et les pages d'extension ne sont pas des onglets
+		if t.Type != "page" || strings.HasPrefix(t.URL, "devtools://") || strings.HasPrefix(t.URL, "chrome-extension://") {
+			continue
+		}
+		if t.Title != "" && strings.HasPrefix(windowTitle, t.Title) {
+			return t.URL, nil
+		}
+	}
+	return "", nil
+}
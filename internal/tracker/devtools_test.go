@@ -0,0 +1,135 @@
+package tracker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// devToolsTargets est une réponse de /json : les onglets du plus récemment
+// activé au plus ancien, mêlés aux outils de développement et aux pages
+// d'extension
+const devToolsTargets = `[
+	{"type": "page", "title": "DevTools - github.com/golang/go", "url": "devtools://devtools/bundled/inspector.html"},
+	{"type": "page", "title": "Gestionnaire de mots de passe", "url": "chrome-extension://abcdef/popup.html"},
+	{"type": "service_worker", "title": "Service Worker", "url": "https://example.com/sw.js"},
+	{"type": "page", "title": "Go Documentation", "url": "https://go.dev/doc/"},
+	{"type": "page", "title": "golang/go: The Go programming language", "url": "https://github.com/golang/go"},
+	{"type": "page", "title": "", "url": "about:blank"}
+]`
+
+// newDevToolsServer sert body sur /json
+func newDevToolsServer(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestDevToolsActiveTab(t *testing.T) {
+	ts := newDevToolsServer(t, devToolsTargets)
+	d := NewDevToolsSource(ts.URL + "/")
+
+	for _, tc := range []struct {
+		name        string
+		windowTitle string
+		want        string
+	}{
+		{"titre de l'onglet en préfixe", "golang/go: The Go programming language - Google Chrome", "https://github.com/golang/go"},
+		{"titre exact", "Go Documentation", "https://go.dev/doc/"},
+		{"outils de développement ignorés", "DevTools - github.com/golang/go - Google Chrome", ""},
+		{"pages d'extension ignorées", "Gestionnaire de mots de passe - Google Chrome", ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := d.ActiveTab(tc.windowTitle)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("ActiveTab(%q) = %q, %q attendue", tc.windowTitle, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDevToolsNoTab(t *testing.T) {
+	ts := newDevToolsServer(t, `[{"type": "page", "title": "DevTools", "url": "devtools://devtools/inspector.html"}]`)
+	got, err := NewDevToolsSource(ts.URL).ActiveTab("DevTools")
+	if err != nil || got != "" {
+		t.Errorf("ActiveTab = %q, %v ; aucun onglet attendu", got, err)
+	}
+}
+
+func TestDevToolsAttach(t *testing.T) {
+	ts := newDevToolsServer(t, devToolsTargets)
+	d := NewDevToolsSource(ts.URL)
+
+	w := &WindowInfo{AppName: "Google Chrome", WindowTitle: "golang/go: The Go programming language - Google Chrome"}
+	if err := d.Attach(w); err != nil {
+		t.Fatal(err)
+	}
+	if w.URL != "https://github.com/golang/go" || w.Domain != "github.com" {
+		t.Errorf("URL %q, domaine %q ; https://github.com/golang/go et github.com attendus", w.URL, w.Domain)
+	}
+
+	// Ni les autres navigateurs ni une URL déjà connue ne sont interrogés
+	for _, w := range []*WindowInfo{
+		{AppName: "Firefox", WindowTitle: "Go Documentation"},
+		{AppName: "chromium", WindowTitle: "Go Documentation", URL: "https://example.com/"},
+	} {
+		want := w.URL
+		if err := d.Attach(w); err != nil {
+			t.Fatal(err)
+		}
+		if w.URL != want {
+			t.Errorf("%s: URL %q, %q attendue", w.AppName, w.URL, want)
+		}
+	}
+}
+
+func TestDevToolsTimeout(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	d := NewDevToolsSource(ts.URL)
+	d.client.Timeout = 50 * time.Millisecond
+	start := time.Now()
+	if _, err := d.ActiveTab("Go Documentation"); err == nil {
+		t.Fatal("erreur attendue pour un navigateur qui ne répond pas")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("ActiveTab a attendu %v malgré le timeout", elapsed)
+	}
+}
+
+func TestDevToolsUnreachable(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	endpoint := ts.URL
+	ts.Close()
+
+	w := &WindowInfo{AppName: "Google Chrome", WindowTitle: "Go Documentation"}
+	if err := NewDevToolsSource(endpoint).Attach(w); err == nil {
+		t.Fatal("erreur attendue pour un endpoint injoignable")
+	}
+	if w.URL != "" || w.Domain != "" {
+		t.Errorf("URL %q, domaine %q ; aucune attendue", w.URL, w.Domain)
+	}
+
+	// Un endpoint qui n'est pas DevTools répond 404
+	ts = newDevToolsServer(t, devToolsTargets)
+	if _, err := NewDevToolsSource(ts.URL + "/autre").ActiveTab("Go Documentation"); err == nil {
+		t.Fatal("erreur attendue pour un statut 404")
+	}
+}
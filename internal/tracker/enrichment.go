@@ -0,0 +1,167 @@
+package tracker
+
+import (
+	"sync"
+
+	"trackmytime/internal/documents"
+	"trackmytime/internal/entities"
+	"trackmytime/internal/gitinfo"
+	"trackmytime/internal/privacy"
+	"trackmytime/internal/rules"
+)
+
+var (
+	rulesMu    sync.RWMutex
+	ruleEngine = newEngine(rules.Defaults())
+
+	// siteMemory retient les sites déduits confirmés par l'apprentissage
+	siteMemory = rules.NewSiteMemory()
+
+	extractor = entities.MustCompile(entities.Defaults())
+
+	// privacyPolicy détecte la navigation privée même sans règle
+	privacyPolicy = privacy.MustCompile(nil)
+)
+
+func newEngine(rs []rules.Rule) *rules.Engine {
+	engine := rules.MustCompile(rs)
+	engine.UseSiteMemory(siteMemory)
+	return engine
+}
+
+// SetRules remplace les règles d'enrichissement évaluées par Enrich.
+// Les règles désactivées sont ignorées ; en cas d'erreur les règles
+// précédentes restent en place.
+func SetRules(rs []rules.Rule) error {
+	engine, err := rules.Compile(rs)
+	if err != nil {
+		return err
+	}
+	engine.UseSiteMemory(siteMemory)
+
+	rulesMu.Lock()
+	ruleEngine = engine
+	rulesMu.Unlock()
+	return nil
+}
+
+// SetEntityPatterns remplace les motifs d'extraction d'entités ; en cas
+// d'erreur les motifs précédents restent en place
+func SetEntityPatterns(ps []entities.Pattern) error {
+	e, err := entities.Compile(ps)
+	if err != nil {
+		return err
+	}
+
+	rulesMu.Lock()
+	extractor = e
+	rulesMu.Unlock()
+	return nil
+}
+
+// SetPrivacyRules remplace les règles de confidentialité appliquées par
+// Protect ; en cas d'erreur les règles précédentes restent en place
+func SetPrivacyRules(rs []privacy.Rule) error {
+	p, err := privacy.Compile(rs)
+	if err != nil {
+		return err
+	}
+
+	rulesMu.Lock()
+	privacyPolicy = p
+	rulesMu.Unlock()
+	return nil
+}
+
+// Protect applique les règles de confidentialité à la fenêtre, avant son
+// enrichissement : titre, URL, commande et dossier sont masqués ou effacés
+// en place, et le dépôt git aussi avec privacy.ActionAppOnly. Avec
+// privacy.ActionDrop, la fenêtre ne doit pas être enregistrée.
+func (w *WindowInfo) Protect() privacy.Decision {
+	in := w.input()
+	decision := Protect(&in)
+	w.WindowTitle = in.WindowTitle
+	w.URL = in.URL
+	w.Command = in.Command
+	w.WorkingDir = in.WorkingDir
+	w.SSHHost = in.SSHHost
+	if w.URL == "" {
+		w.Domain = ""
+	}
+	if decision.Action == privacy.ActionAppOnly {
+		w.Repo = gitinfo.Repo{}
+	}
+	return decision
+}
+
+// Protect applique les règles de confidentialité courantes à ce que décrit
+// in (onglet, heartbeat, commande de shell...), modifié en place comme par
+// privacy.Policy.Apply
+func Protect(in *rules.Input) privacy.Decision {
+	rulesMu.RLock()
+	p := privacyPolicy
+	rulesMu.RUnlock()
+
+	return p.Apply(in)
+}
+
+// ConfirmSites marque des sites déduits comme confirmés : ils sont ensuite
+// retenus même quand seul le dernier segment du titre les désigne
+func ConfirmSites(names ...string) {
+	siteMemory.Confirm(names...)
+}
+
+// Enrich évalue les règles d'enrichissement sur la fenêtre
+func (w *WindowInfo) Enrich() rules.Result {
+	rulesMu.RLock()
+	engine := ruleEngine
+	rulesMu.RUnlock()
+
+	return engine.Evaluate(w.input())
+}
+
+// EnrichWith évalue les règles rs à la place des règles courantes, par
+// exemple pour essayer des règles avant de les enregistrer
+func (w *WindowInfo) EnrichWith(rs []rules.Rule) (rules.Result, error) {
+	engine, err := rules.Compile(rs)
+	if err != nil {
+		return rules.Result{}, err
+	}
+	engine.UseSiteMemory(siteMemory)
+	return engine.Evaluate(w.input()), nil
+}
+
+// Entities extrait les tickets, dépôts, pull et merge requests du titre et
+// de l'URL de la fenêtre
+func (w *WindowInfo) Entities() []entities.Entity {
+	rulesMu.RLock()
+	e := extractor
+	rulesMu.RUnlock()
+
+	return e.Extract(w.WindowTitle, w.URL)
+}
+
+// Document retourne le fichier ouvert si la fenêtre est celle d'un éditeur
+// (VSCode, Cursor, JetBrains, Vim, Emacs), ou un Document vide
+func (w *WindowInfo) Document() documents.Document {
+	doc, _ := documents.Detect(w.AppName, w.WindowTitle)
+	return doc
+}
+
+func (w *WindowInfo) input() rules.Input {
+	return rules.Input{
+		AppName:     w.AppName,
+		WindowTitle: w.WindowTitle,
+		ProcessPath: w.ProcessPath,
+		URL:         w.URL,
+		Command:     w.Command,
+		WorkingDir:  w.WorkingDir,
+		SSHHost:     w.SSHHost,
+	}
+}
+
+// GetEnrichedName extrait un nom contextualisé (site, projet...) selon les
+// règles d'enrichissement, ou retourne le nom de l'application
+func (w *WindowInfo) GetEnrichedName() string {
+	return w.Enrich().EnrichedName
+}
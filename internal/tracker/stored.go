@@ -0,0 +1,88 @@
+package tracker
+
+import (
+	"slices"
+	"sort"
+
+	"trackmytime/internal/documents"
+	"trackmytime/internal/entities"
+	"trackmytime/internal/privacy"
+	"trackmytime/internal/storage"
+)
+
+// StoredWindow retourne la fenêtre enregistrée d'une activité
+func StoredWindow(a storage.Activity) WindowInfo {
+	return WindowInfo{
+		AppName:     a.AppName,
+		WindowTitle: a.WindowTitle,
+		ProcessPath: a.ProcessPath,
+		URL:         a.URL,
+		Command:     a.Command,
+		WorkingDir:  a.WorkingDir,
+		SSHHost:     a.SSHHost,
+		Repo:        a.Repo,
+	}
+}
+
+// Reenrich réévalue les règles d'enrichissement sur la fenêtre enregistrée
+// d'une activité et retourne l'activité réenrichie ; true si les règles
+// l'ignoreraient
+func Reenrich(before storage.Activity) (storage.Activity, bool) {
+	return reenrich(before, StoredWindow(before))
+}
+
+// Reprotect applique les règles de confidentialité puis d'enrichissement à
+// une activité enregistrée, comme à une fenêtre avant son enregistrement ;
+// retourne l'activité protégée et réenrichie, la décision, et true si les
+// règles d'enrichissement l'ignoreraient. Avec privacy.ActionDrop,
+// l'activité est retournée inchangée et doit être supprimée.
+func Reprotect(before storage.Activity) (storage.Activity, privacy.Decision, bool) {
+	w := StoredWindow(before)
+	decision := w.Protect()
+	if decision.Action == privacy.ActionDrop {
+		return before, decision, false
+	}
+	after, ignored := reenrich(before, w)
+	if decision.Action == privacy.ActionAppOnly {
+		// Le fichier apporté par les heartbeats WakaTime décrit aussi le contenu
+		after.Document = documents.Document{}
+	}
+	return after, decision, ignored
+}
+
+// reenrich retourne l'activité before réenrichie d'après la fenêtre w, avec
+// son titre, son URL, sa commande et son dépôt
+func reenrich(before storage.Activity, w WindowInfo) (storage.Activity, bool) {
+	enriched := w.Enrich()
+
+	after := before
+	after.WindowTitle = w.WindowTitle
+	after.URL = w.URL
+	after.Command = w.Command
+	after.WorkingDir = w.WorkingDir
+	after.SSHHost = w.SSHHost
+	after.Repo = w.Repo
+	after.EnrichedName = enriched.EnrichedName
+	after.Category = enriched.Category
+	after.Project = enriched.Project
+	after.IsIdle = enriched.Idle
+	after.InferredSite, after.Confidence = "", nil
+	if site := enriched.Site; site != nil {
+		after.InferredSite = site.Name
+		after.Confidence = &site.Confidence
+	}
+	after.Tags = nil
+	for _, tag := range enriched.Tags {
+		if name, err := storage.NormalizeTag(tag); err == nil && !slices.Contains(after.Tags, name) {
+			after.Tags = append(after.Tags, name)
+		}
+	}
+	sort.Strings(after.Tags)
+	after.Entities = w.Entities()
+	entities.Sort(after.Entities)
+	// Un fichier absent du titre vient des heartbeats WakaTime : il est conservé
+	if doc := w.Document(); doc.FileName != "" || before.Document.FileName == "" {
+		after.Document = doc
+	}
+	return after, enriched.Ignored
+}
@@ -0,0 +1,261 @@
+package tracker
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// terminalApps sont les émulateurs de terminal dont on résout le processus
+// au premier plan (noms de processus Linux)
+var terminalApps = map[string]bool{
+	"gnome-terminal-server": true,
+	"gnome-terminal":        true,
+	"kgx":                   true, // GNOME Console
+	"konsole":               true,
+	"xfce4-terminal":        true,
+	"mate-terminal":         true,
+	"lxterminal":            true,
+	"qterminal":             true,
+	"tilix":                 true,
+	"terminator":            true,
+	"guake":                 true,
+	"tilda":                 true,
+	"terminology":           true,
+	"alacritty":             true,
+	"kitty":                 true,
+	"wezterm-gui":           true,
+	"foot":                  true,
+	"ghostty":               true,
+	"rio":                   true,
+	"xterm":                 true,
+	"urxvt":                 true,
+	"st":                    true,
+}
+
+// IsTerminal indique si appName est un émulateur de terminal reconnu
+func IsTerminal(appName string) bool {
+	return terminalApps[strings.ToLower(appName)]
+}
+
+// procStat contient les champs utiles de /proc/<pid>/stat
+type procStat struct {
+	pid       int
+	comm      string
+	ppid      int
+	tty       int // tty_nr, 0 = pas de terminal de contrôle
+	tpgid     int // groupe de processus au premier plan du terminal
+	startTime uint64
+}
+
+// readProcStat lit /proc/<pid>/stat
+func readProcStat(pid int) (procStat, bool) {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return procStat{}, false
+	}
+	// Le nom (comm) est entre parenthèses et peut contenir des espaces
+	s := string(data)
+	open, end := strings.IndexByte(s, '('), strings.LastIndexByte(s, ')')
+	if open < 0 || end < open {
+		return procStat{}, false
+	}
+	fields := strings.Fields(s[end+1:])
+	if len(fields) < 20 {
+		return procStat{}, false
+	}
+
+	st := procStat{pid: pid, comm: s[open+1 : end]}
+	st.ppid, _ = strconv.Atoi(fields[1])
+	st.tty, _ = strconv.Atoi(fields[4])
+	st.tpgid, _ = strconv.Atoi(fields[5])
+	st.startTime, _ = strconv.ParseUint(fields[19], 10, 64)
+	return st, true
+}
+
+// procCmdline retourne les arguments d'un processus
+func procCmdline(pid int) []string {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/cmdline")
+	if err != nil {
+		return nil
+	}
+	return strings.Split(strings.TrimRight(string(data), "\x00"), "\x00")
+}
+
+// resolveTerminal renseigne la commande au premier plan du terminal dont
+// l'émulateur a le PID donné, son dossier courant et, pour ssh, l'hôte
+// distant. Quand l'émulateur a plusieurs onglets, l'onglet retenu est celui
+// dont la commande ou le dossier apparaît dans le titre, sinon celui dont la
+// commande au premier plan a démarré le plus récemment.
+func (w *WindowInfo) resolveTerminal(pid int) {
+	// Groupes au premier plan des terminaux ouverts par l'émulateur : seuls
+	// ses descendants sont lus, pas tout /proc
+	emulator, ok := readProcStat(pid)
+	if !ok {
+		return
+	}
+	foreground := map[int]bool{}
+	queue := []int{pid}
+	for len(queue) > 0 {
+		parent := queue[0]
+		queue = queue[1:]
+		for _, p := range childPIDs(parent) {
+			child, ok := readProcStat(p)
+			if !ok {
+				continue
+			}
+			if child.tty != 0 && child.tty != emulator.tty && child.tpgid > 0 {
+				foreground[child.tpgid] = true
+			} else {
+				queue = append(queue, child.pid)
+			}
+		}
+	}
+
+	var best procStat
+	bestScore := -1
+	for leader := range foreground {
+		st, ok := readProcStat(leader)
+		if !ok {
+			continue
+		}
+		score := 0
+		cwd, _ := os.Readlink("/proc/" + strconv.Itoa(leader) + "/cwd")
+		if strings.Contains(w.WindowTitle, st.comm) || (cwd != "" && strings.Contains(w.WindowTitle, filepath.Base(cwd))) {
+			score = 1
+		}
+		if score > bestScore || (score == bestScore && st.startTime > best.startTime) {
+			best, bestScore = st, score
+		}
+	}
+	if bestScore < 0 {
+		return
+	}
+
+	w.Command = best.comm
+	w.WorkingDir, _ = os.Readlink("/proc/" + strconv.Itoa(best.pid) + "/cwd")
+	if best.comm == "ssh" {
+		w.SSHHost = sshHost(procCmdline(best.pid))
+	}
+}
+
+// childPIDs retourne les processus enfants de pid, d'après
+// /proc/<pid>/task/*/children, ou d'après la table des processus en cache si
+// le noyau ne fournit pas ces fichiers (CONFIG_PROC_CHILDREN)
+func childPIDs(pid int) []int {
+	dir := "/proc/" + strconv.Itoa(pid) + "/task/"
+	if _, err := os.Stat(dir + strconv.Itoa(pid) + "/children"); err != nil {
+		return processTable.children(pid)
+	}
+	tasks, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var pids []int
+	for _, task := range tasks {
+		data, err := os.ReadFile(dir + task.Name() + "/children")
+		if err != nil {
+			continue
+		}
+		for _, field := range strings.Fields(string(data)) {
+			if child, err := strconv.Atoi(field); err == nil {
+				pids = append(pids, child)
+			}
+		}
+	}
+	return pids
+}
+
+// processTreeTTL est la durée de validité de la table des processus : un
+// onglet ouvert entre deux relevés est vu au plus tard après ce délai
+const processTreeTTL = 10 * time.Second
+
+// processTable est la table des processus, relue au plus une fois par
+// processTreeTTL quand /proc/<pid>/task/*/children n'existe pas
+var processTable processTree
+
+// processTree associe chaque processus à ses enfants
+type processTree struct {
+	mu      sync.Mutex
+	expires time.Time
+	byPID   map[int][]int
+}
+
+// children retourne les enfants de pid, en relisant /proc si la table a expiré
+func (t *processTree) children(pid int) []int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if t.byPID == nil || !now.Before(t.expires) {
+		entries, err := os.ReadDir("/proc")
+		if err != nil {
+			return nil
+		}
+		t.byPID = map[int][]int{}
+		for _, e := range entries {
+			p, err := strconv.Atoi(e.Name())
+			if err != nil {
+				continue
+			}
+			if st, ok := readProcStat(p); ok {
+				t.byPID[st.ppid] = append(t.byPID[st.ppid], p)
+			}
+		}
+		t.expires = now.Add(processTreeTTL)
+	}
+	return t.byPID[pid]
+}
+
+// sshOptionsWithValue sont les options de ssh suivies d'une valeur
+const sshOptionsWithValue = "BbcDEeFIiJLlmOoPpQRSWw"
+
+// sshHost extrait l'hôte distant des arguments de ssh : "ssh -p 2222
+// deploy@web-1 uptime" ou "ssh -vp 2222 web-1" → "web-1"
+func sshHost(args []string) string {
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			if i+1 < len(args) {
+				return hostOf(args[i+1])
+			}
+			return ""
+		}
+		if strings.HasPrefix(arg, "-") && len(arg) > 1 {
+			// Les options peuvent être groupées ("-vp 2222") : la première
+			// suivie d'une valeur prend le reste du groupe ("-p2222") ou,
+			// en fin de groupe, l'argument suivant
+			for j := 1; j < len(arg); j++ {
+				if strings.IndexByte(sshOptionsWithValue, arg[j]) >= 0 {
+					if j == len(arg)-1 {
+						i++
+					}
+					break
+				}
+			}
+			continue
+		}
+		return hostOf(arg)
+	}
+	return ""
+}
+
+// hostOf retire l'utilisateur, le schéma et le port d'une destination ssh
+func hostOf(dest string) string {
+	dest = strings.TrimPrefix(dest, "ssh://")
+	if at := strings.LastIndexByte(dest, '@'); at >= 0 {
+		dest = dest[at+1:]
+	}
+	if strings.HasPrefix(dest, "[") {
+		if end := strings.IndexByte(dest, ']'); end > 0 {
+			return dest[1:end]
+		}
+	}
+	if host, _, ok := strings.Cut(dest, ":"); ok && strings.Count(dest, ":") == 1 {
+		dest = host
+	}
+	return strings.TrimSuffix(dest, "/")
+}
@@ -0,0 +1,24 @@
+package tracker
+
+import "testing"
+
+func TestSSHHost(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"ssh", "web-1"}, "web-1"},
+		{[]string{"ssh", "-p", "2222", "deploy@web-1", "uptime"}, "web-1"},
+		{[]string{"ssh", "-p2222", "web-1"}, "web-1"},
+		{[]string{"ssh", "-vp", "2222", "web-1"}, "web-1"},
+		{[]string{"ssh", "-vp2222", "web-1"}, "web-1"},
+		{[]string{"ssh", "-At", "web-1"}, "web-1"},
+		{[]string{"ssh", "-i", "~/.ssh/id_ed25519", "-v", "ssh://deploy@web-1:2222"}, "web-1"},
+		{[]string{"ssh", "--", "web-1"}, "web-1"},
+		{[]string{"ssh", "-p", "2222"}, ""},
+	} {
+		if got := sshHost(tc.args); got != tc.want {
+			t.Errorf("sshHost(%q) = %q, %q attendu", tc.args, got, tc.want)
+		}
+	}
+}
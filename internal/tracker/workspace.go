@@ -0,0 +1,137 @@
+package tracker
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"trackmytime/internal/gitinfo"
+)
+
+// repoCache évite de relire .git à chaque relevé ; un changement de branche
+// est vu au plus 10 secondes plus tard
+var repoCache = gitinfo.NewCache(10 * time.Second)
+
+// resolveRepo renseigne le dépôt git du dossier de travail de la fenêtre
+func (w *WindowInfo) resolveRepo() {
+	// Pour ssh, le dossier courant est local et non celui de la session distante
+	if w.SSHHost != "" {
+		return
+	}
+	if dir := w.workspaceDir(); dir != "" {
+		w.Repo, _ = repoCache.Lookup(dir)
+	}
+}
+
+var (
+	// "main.go (~/code/trackmytime) - NVIM"
+	vimDir = regexp.MustCompile(`\(([~/][^)]*|[A-Za-z]:\\[^)]*)\) - (?i:n?vim|gvim|macvim)\d*$`)
+	// "trackmytime [~/code/trackmytime] – db.go"
+	jetBrainsDir = regexp.MustCompile(`\[([~/][^\]]*|[A-Za-z]:\\[^\]]*)\]`)
+	// Éditeurs dérivés de VSCode, dont les titres ne contiennent que le nom du dossier
+	vscodeApps = regexp.MustCompile(`Code|Cursor|VSCodium|Windsurf`)
+	// Séparateurs des segments d'un titre VSCode
+	vscodeSeparators = regexp.MustCompile(` [—-] `)
+)
+
+// workspaceDir retourne le dossier de travail de la fenêtre : dossier
+// courant du terminal, sinon dossier affiché dans le titre de Vim ou
+// JetBrains, sinon dossier VSCode dont le nom apparaît dans le titre
+func (w *WindowInfo) workspaceDir() string {
+	if w.WorkingDir != "" {
+		return w.WorkingDir
+	}
+	if m := vimDir.FindStringSubmatch(w.WindowTitle); m != nil {
+		return expandHome(m[1])
+	}
+	if m := jetBrainsDir.FindStringSubmatch(w.WindowTitle); m != nil {
+		return expandHome(m[1])
+	}
+	if !vscodeApps.MatchString(w.AppName) {
+		return ""
+	}
+
+	// Le premier segment est le fichier ouvert, sauf sans fichier ouvert
+	folders := vscodeFolders()
+	segments := vscodeSeparators.Split(strings.TrimPrefix(w.WindowTitle, "● "), -1)
+	for _, segment := range append(segments[1:], segments[0]) {
+		if dir := folders[strings.TrimSpace(segment)]; dir != "" {
+			return dir
+		}
+	}
+	return ""
+}
+
+// expandHome remplace le ~ initial par le dossier personnel
+func expandHome(path string) string {
+	if rest, found := strings.CutPrefix(path, "~"); found {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+// vscodeProducts sont les dossiers de configuration des éditeurs dérivés de VSCode
+var vscodeProducts = []string{"Code", "Code - Insiders", "Cursor", "VSCodium", "Windsurf"}
+
+var vscodeCache struct {
+	sync.Mutex
+	folders map[string]string
+	expires time.Time
+}
+
+// vscodeFolders retourne les dossiers ouverts récemment dans VSCode et ses
+// dérivés, indexés par nom ; la liste est relue au plus une fois par minute
+func vscodeFolders() map[string]string {
+	vscodeCache.Lock()
+	defer vscodeCache.Unlock()
+	if time.Now().Before(vscodeCache.expires) {
+		return vscodeCache.folders
+	}
+
+	folders := map[string]string{}
+	if configDir, err := os.UserConfigDir(); err == nil {
+		for _, product := range vscodeProducts {
+			matches, _ := filepath.Glob(filepath.Join(configDir, product, "User", "workspaceStorage", "*", "workspace.json"))
+			for _, path := range matches {
+				if dir := workspaceFolder(path); dir != "" {
+					folders[filepath.Base(dir)] = dir
+				}
+			}
+		}
+	}
+	vscodeCache.folders = folders
+	vscodeCache.expires = time.Now().Add(time.Minute)
+	return folders
+}
+
+// workspaceFolder lit le dossier local d'un workspace.json de VSCode ; vide
+// pour les espaces multi-dossiers et les dossiers distants
+func workspaceFolder(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	var workspace struct {
+		Folder string `json:"folder"`
+	}
+	if json.Unmarshal(data, &workspace) != nil {
+		return ""
+	}
+	u, err := url.Parse(workspace.Folder)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	dir := u.Path
+	if runtime.GOOS == "windows" {
+		dir = filepath.FromSlash(strings.TrimPrefix(dir, "/"))
+	}
+	return dir
+}
@@ -0,0 +1,188 @@
+package vault
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/zalando/go-keyring"
+)
+
+// Source est l'emplacement de la clé
+type Source string
+
+const (
+	SourceKeyring    Source = "keyring"    // clé aléatoire dans le trousseau du système
+	SourcePassphrase Source = "passphrase" // clé dérivée d'une phrase de passe lue dans un fichier
+)
+
+// keyringService est le service des entrées du trousseau, une par clé
+const keyringService = "trackmytime"
+
+// passphraseIterations est le nombre d'itérations PBKDF2-SHA256
+const passphraseIterations = 600_000
+
+// checkValue est chiffrée à la création de la clé pour la vérifier à l'ouverture
+const checkValue = "trackmytime"
+
+// Settings décrivent la clé en vigueur ; elles sont enregistrées en base,
+// sans le secret
+type Settings struct {
+	KeyID          string    `json:"key_id"`
+	Source         Source    `json:"source"`
+	PassphraseFile string    `json:"passphrase_file,omitempty"`
+	Salt           []byte    `json:"salt,omitempty"`
+	Check          string    `json:"check"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// NewKey crée une clé. Avec SourceKeyring, un secret aléatoire est ajouté
+// au trousseau (à retirer par Forget si la clé n'est finalement pas
+// utilisée) ; avec SourcePassphrase, la clé est dérivée de la phrase de
+// passe du fichier et d'un sel aléatoire.
+func NewKey(source Source, passphraseFile string) (Settings, *Cipher, error) {
+	s := Settings{KeyID: newKeyID(), Source: source, CreatedAt: time.Now()}
+	var secret []byte
+	switch source {
+	case SourceKeyring:
+		secret = make([]byte, KeySize)
+		rand.Read(secret)
+		err := keyring.Set(keyringService, s.keyringUser(), base64.StdEncoding.EncodeToString(secret))
+		if err != nil {
+			return Settings{}, nil, fmt.Errorf("trousseau du système indisponible: %w", err)
+		}
+	case SourcePassphrase:
+		if err := checkPassphraseFile(passphraseFile); err != nil {
+			return Settings{}, nil, err
+		}
+		s.PassphraseFile = passphraseFile
+		s.Salt = make([]byte, 16)
+		rand.Read(s.Salt)
+		var err error
+		if secret, err = s.derive(); err != nil {
+			return Settings{}, nil, err
+		}
+	default:
+		return Settings{}, nil, fmt.Errorf("source de clé inconnue %q (keyring, passphrase)", source)
+	}
+
+	c, err := New(s.KeyID, secret)
+	if err != nil {
+		s.Forget()
+		return Settings{}, nil, err
+	}
+	s.Check = c.Seal(checkValue)
+	return s, c, nil
+}
+
+// Unlock lit la clé à sa source et vérifie qu'elle déchiffre la valeur de
+// contrôle
+func (s Settings) Unlock() (*Cipher, error) {
+	var secret []byte
+	switch s.Source {
+	case SourceKeyring:
+		encoded, err := keyring.Get(keyringService, s.keyringUser())
+		if err != nil {
+			return nil, fmt.Errorf("clé %s introuvable dans le trousseau du système: %w", s.KeyID, err)
+		}
+		if secret, err = base64.StdEncoding.DecodeString(encoded); err != nil {
+			return nil, fmt.Errorf("clé %s du trousseau illisible: %w", s.KeyID, err)
+		}
+	case SourcePassphrase:
+		var err error
+		if secret, err = s.derive(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("source de clé inconnue %q", s.Source)
+	}
+
+	c, err := New(s.KeyID, secret)
+	if err != nil {
+		return nil, err
+	}
+	if plain, err := c.Open(s.Check); err != nil || plain != checkValue {
+		if s.Source == SourcePassphrase {
+			return nil, fmt.Errorf("phrase de passe de %s incorrecte pour la clé %s", s.PassphraseFile, s.KeyID)
+		}
+		return nil, fmt.Errorf("clé %s du trousseau incorrecte", s.KeyID)
+	}
+	return c, nil
+}
+
+// Forget retire la clé du trousseau ; sans effet pour une phrase de passe,
+// dont le fichier reste à la charge de l'utilisateur
+func (s Settings) Forget() error {
+	if s.Source != SourceKeyring {
+		return nil
+	}
+	err := keyring.Delete(keyringService, s.keyringUser())
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// Describe décrit la source de la clé pour l'affichage
+func (s Settings) Describe() string {
+	if s.Source == SourcePassphrase {
+		return "phrase de passe (" + s.PassphraseFile + ")"
+	}
+	return "trousseau du système"
+}
+
+func (s Settings) keyringUser() string {
+	return "database-key-" + s.KeyID
+}
+
+// derive calcule la clé à partir de la phrase de passe et du sel
+func (s Settings) derive() ([]byte, error) {
+	passphrase, err := readPassphrase(s.PassphraseFile)
+	if err != nil {
+		return nil, err
+	}
+	return pbkdf2.Key(sha256.New, passphrase, s.Salt, passphraseIterations, KeySize)
+}
+
+// readPassphrase lit la phrase de passe, sans le saut de ligne final
+func readPassphrase(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("phrase de passe illisible: %w", err)
+	}
+	passphrase := strings.TrimRight(string(data), "\r\n")
+	if passphrase == "" {
+		return "", fmt.Errorf("phrase de passe vide: %s", path)
+	}
+	return passphrase, nil
+}
+
+// checkPassphraseFile refuse une phrase de passe trop courte ou lisible par
+// d'autres utilisateurs
+func checkPassphraseFile(path string) error {
+	if path == "" {
+		return errors.New("fichier de phrase de passe requis")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("phrase de passe illisible: %w", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o077 != 0 {
+		return fmt.Errorf("%s est lisible par d'autres utilisateurs (chmod 600)", path)
+	}
+	passphrase, err := readPassphrase(path)
+	if err != nil {
+		return err
+	}
+	if len([]rune(passphrase)) < 12 {
+		return errors.New("phrase de passe trop courte (12 caractères minimum)")
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+// Package vault chiffre au repos les titres de fenêtres, les chemins des
+// processus et les URL enregistrés en base (AES-256-GCM). La clé est gardée dans le
+// trousseau du système ou dérivée d'une phrase de passe lue dans un fichier.
+// Un index HMAC des titres permet de les regrouper en SQL sans les déchiffrer.
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Prefix précède toute valeur chiffrée : "enc:v1:<clé>:<base64>"
+const Prefix = "enc:v1:"
+
+// KeySize est la taille en octets des clés
+const KeySize = 32
+
+// Cipher chiffre et déchiffre avec une clé identifiée. Un Cipher nil laisse
+// les valeurs en clair.
+type Cipher struct {
+	id    string
+	aead  cipher.AEAD
+	index []byte
+}
+
+// New prépare le chiffrement avec la clé secret d'identifiant id. Les clés
+// de chiffrement et d'index en sont dérivées séparément (HKDF).
+func New(id string, secret []byte) (*Cipher, error) {
+	if len(secret) != KeySize {
+		return nil, fmt.Errorf("clé de %d octets, %d attendus", len(secret), KeySize)
+	}
+	if id == "" || strings.Contains(id, ":") {
+		return nil, fmt.Errorf("identifiant de clé invalide: %q", id)
+	}
+	encKey, err := hkdf.Key(sha256.New, secret, nil, "trackmytime encryption", KeySize)
+	if err != nil {
+		return nil, err
+	}
+	indexKey, err := hkdf.Key(sha256.New, secret, nil, "trackmytime title index", KeySize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{id: id, aead: aead, index: indexKey}, nil
+}
+
+// KeyID retourne l'identifiant de la clé (vide si c est nil)
+func (c *Cipher) KeyID() string {
+	if c == nil {
+		return ""
+	}
+	return c.id
+}
+
+// Seal chiffre une valeur. Une valeur vide, ou toute valeur si c est nil,
+// est retournée telle quelle.
+func (c *Cipher) Seal(plain string) string {
+	if c == nil || plain == "" {
+		return plain
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	rand.Read(nonce)
+	sealed := c.aead.Seal(nonce, nonce, []byte(plain), []byte(c.id))
+	return Prefix + c.id + ":" + base64.RawStdEncoding.EncodeToString(sealed)
+}
+
+// Open déchiffre une valeur produite par Seal. Une valeur en clair
+// (enregistrée avant l'activation du chiffrement) est retournée telle quelle.
+func (c *Cipher) Open(stored string) (string, error) {
+	if !IsSealed(stored) {
+		return stored, nil
+	}
+	id, encoded, ok := strings.Cut(strings.TrimPrefix(stored, Prefix), ":")
+	if !ok {
+		return "", errors.New("valeur chiffrée malformée")
+	}
+	if c == nil {
+		return "", fmt.Errorf("valeur chiffrée avec la clé %s, chiffrement non configuré", id)
+	}
+	if id != c.id {
+		return "", fmt.Errorf("valeur chiffrée avec la clé %s, clé actuelle %s", id, c.id)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return "", errors.New("valeur chiffrée malformée")
+	}
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plain, err := c.aead.Open(nil, nonce, ciphertext, []byte(c.id))
+	if err != nil {
+		return "", fmt.Errorf("déchiffrement impossible avec la clé %s: %w", c.id, err)
+	}
+	return string(plain), nil
+}
+
+// Index retourne l'empreinte d'un titre pour la clé : des titres identiques
+// ont le même index, sans que l'index révèle le titre. Vide si c est nil ou
+// si le titre est vide.
+func (c *Cipher) Index(plain string) string {
+	if c == nil || plain == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, c.index)
+	mac.Write([]byte(plain))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// IsSealed indique si une valeur enregistrée est chiffrée
+func IsSealed(stored string) bool {
+	return strings.HasPrefix(stored, Prefix)
+}
+
+// newKeyID retourne un identifiant de clé aléatoire
+func newKeyID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
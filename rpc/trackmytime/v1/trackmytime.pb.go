@@ -0,0 +1,1443 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: trackmytime/v1/trackmytime.proto
+
+// API RPC de TrackMyTime : mêmes capacités que l'API REST /api/v1, servie en
+// gRPC, gRPC-Web et Connect sur le port de l'agent (HTTP/2 en clair via h2c).
+
+package trackmytimev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type PeriodKind int32
+
+const (
+	// Équivaut à PERIOD_KIND_TODAY
+	PeriodKind_PERIOD_KIND_UNSPECIFIED PeriodKind = 0
+	PeriodKind_PERIOD_KIND_TODAY       PeriodKind = 1
+	PeriodKind_PERIOD_KIND_WEEK        PeriodKind = 2
+	PeriodKind_PERIOD_KIND_MONTH       PeriodKind = 3
+	PeriodKind_PERIOD_KIND_CUSTOM      PeriodKind = 4
+)
+
+// Enum value maps for PeriodKind.
+var (
+	PeriodKind_name = map[int32]string{
+		0: "PERIOD_KIND_UNSPECIFIED",
+		1: "PERIOD_KIND_TODAY",
+		2: "PERIOD_KIND_WEEK",
+		3: "PERIOD_KIND_MONTH",
+		4: "PERIOD_KIND_CUSTOM",
+	}
+	PeriodKind_value = map[string]int32{
+		"PERIOD_KIND_UNSPECIFIED": 0,
+		"PERIOD_KIND_TODAY":       1,
+		"PERIOD_KIND_WEEK":        2,
+		"PERIOD_KIND_MONTH":       3,
+		"PERIOD_KIND_CUSTOM":      4,
+	}
+)
+
+func (x PeriodKind) Enum() *PeriodKind {
+	p := new(PeriodKind)
+	*p = x
+	return p
+}
+
+func (x PeriodKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PeriodKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_trackmytime_v1_trackmytime_proto_enumTypes[0].Descriptor()
+}
+
+func (PeriodKind) Type() protoreflect.EnumType {
+	return &file_trackmytime_v1_trackmytime_proto_enumTypes[0]
+}
+
+func (x PeriodKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PeriodKind.Descriptor instead.
+func (PeriodKind) EnumDescriptor() ([]byte, []int) {
+	return file_trackmytime_v1_trackmytime_proto_rawDescGZIP(), []int{0}
+}
+
+type Granularity int32
+
+const (
+	Granularity_GRANULARITY_UNSPECIFIED Granularity = 0
+	Granularity_GRANULARITY_HOUR        Granularity = 1
+	Granularity_GRANULARITY_DAY         Granularity = 2
+)
+
+// Enum value maps for Granularity.
+var (
+	Granularity_name = map[int32]string{
+		0: "GRANULARITY_UNSPECIFIED",
+		1: "GRANULARITY_HOUR",
+		2: "GRANULARITY_DAY",
+	}
+	Granularity_value = map[string]int32{
+		"GRANULARITY_UNSPECIFIED": 0,
+		"GRANULARITY_HOUR":        1,
+		"GRANULARITY_DAY":         2,
+	}
+)
+
+func (x Granularity) Enum() *Granularity {
+	p := new(Granularity)
+	*p = x
+	return p
+}
+
+func (x Granularity) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Granularity) Descriptor() protoreflect.EnumDescriptor {
+	return file_trackmytime_v1_trackmytime_proto_enumTypes[1].Descriptor()
+}
+
+func (Granularity) Type() protoreflect.EnumType {
+	return &file_trackmytime_v1_trackmytime_proto_enumTypes[1]
+}
+
+func (x Granularity) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Granularity.Descriptor instead.
+func (Granularity) EnumDescriptor() ([]byte, []int) {
+	return file_trackmytime_v1_trackmytime_proto_rawDescGZIP(), []int{1}
+}
+
+// Période demandée
+type Period struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Kind  PeriodKind             `protobuf:"varint,1,opt,name=kind,proto3,enum=trackmytime.v1.PeriodKind" json:"kind,omitempty"`
+	// Dates YYYY-MM-DD, uniquement pour PERIOD_KIND_CUSTOM (end_date incluse)
+	StartDate     string `protobuf:"bytes,2,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
+	EndDate       string `protobuf:"bytes,3,opt,name=end_date,json=endDate,proto3" json:"end_date,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Period) Reset() {
+	*x = Period{}
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Period) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Period) ProtoMessage() {}
+
+func (x *Period) ProtoReflect() protoreflect.Message {
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Period.ProtoReflect.Descriptor instead.
+func (*Period) Descriptor() ([]byte, []int) {
+	return file_trackmytime_v1_trackmytime_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Period) GetKind() PeriodKind {
+	if x != nil {
+		return x.Kind
+	}
+	return PeriodKind_PERIOD_KIND_UNSPECIFIED
+}
+
+func (x *Period) GetStartDate() string {
+	if x != nil {
+		return x.StartDate
+	}
+	return ""
+}
+
+func (x *Period) GetEndDate() string {
+	if x != nil {
+		return x.EndDate
+	}
+	return ""
+}
+
+// Période effective d'une réponse (end exclue)
+type PeriodInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Start         *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=start,proto3" json:"start,omitempty"`
+	End           *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=end,proto3" json:"end,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PeriodInfo) Reset() {
+	*x = PeriodInfo{}
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PeriodInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PeriodInfo) ProtoMessage() {}
+
+func (x *PeriodInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PeriodInfo.ProtoReflect.Descriptor instead.
+func (*PeriodInfo) Descriptor() ([]byte, []int) {
+	return file_trackmytime_v1_trackmytime_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PeriodInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PeriodInfo) GetStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Start
+	}
+	return nil
+}
+
+func (x *PeriodInfo) GetEnd() *timestamppb.Timestamp {
+	if x != nil {
+		return x.End
+	}
+	return nil
+}
+
+type CurrentActivity struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// false si aucune activité n'est en cours (utilisateur inactif)
+	Active          bool                   `protobuf:"varint,1,opt,name=active,proto3" json:"active,omitempty"`
+	AppName         string                 `protobuf:"bytes,2,opt,name=app_name,json=appName,proto3" json:"app_name,omitempty"`
+	EnrichedName    string                 `protobuf:"bytes,3,opt,name=enriched_name,json=enrichedName,proto3" json:"enriched_name,omitempty"`
+	WindowTitle     string                 `protobuf:"bytes,4,opt,name=window_title,json=windowTitle,proto3" json:"window_title,omitempty"`
+	ProcessPath     string                 `protobuf:"bytes,5,opt,name=process_path,json=processPath,proto3" json:"process_path,omitempty"`
+	StartTime       *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	DurationSeconds int64                  `protobuf:"varint,7,opt,name=duration_seconds,json=durationSeconds,proto3" json:"duration_seconds,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CurrentActivity) Reset() {
+	*x = CurrentActivity{}
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CurrentActivity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CurrentActivity) ProtoMessage() {}
+
+func (x *CurrentActivity) ProtoReflect() protoreflect.Message {
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CurrentActivity.ProtoReflect.Descriptor instead.
+func (*CurrentActivity) Descriptor() ([]byte, []int) {
+	return file_trackmytime_v1_trackmytime_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CurrentActivity) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+func (x *CurrentActivity) GetAppName() string {
+	if x != nil {
+		return x.AppName
+	}
+	return ""
+}
+
+func (x *CurrentActivity) GetEnrichedName() string {
+	if x != nil {
+		return x.EnrichedName
+	}
+	return ""
+}
+
+func (x *CurrentActivity) GetWindowTitle() string {
+	if x != nil {
+		return x.WindowTitle
+	}
+	return ""
+}
+
+func (x *CurrentActivity) GetProcessPath() string {
+	if x != nil {
+		return x.ProcessPath
+	}
+	return ""
+}
+
+func (x *CurrentActivity) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *CurrentActivity) GetDurationSeconds() int64 {
+	if x != nil {
+		return x.DurationSeconds
+	}
+	return 0
+}
+
+type Activity struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	AppName         string                 `protobuf:"bytes,2,opt,name=app_name,json=appName,proto3" json:"app_name,omitempty"`
+	EnrichedName    string                 `protobuf:"bytes,3,opt,name=enriched_name,json=enrichedName,proto3" json:"enriched_name,omitempty"`
+	WindowTitle     string                 `protobuf:"bytes,4,opt,name=window_title,json=windowTitle,proto3" json:"window_title,omitempty"`
+	ProcessPath     string                 `protobuf:"bytes,5,opt,name=process_path,json=processPath,proto3" json:"process_path,omitempty"`
+	StartTime       *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime         *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	DurationSeconds int64                  `protobuf:"varint,8,opt,name=duration_seconds,json=durationSeconds,proto3" json:"duration_seconds,omitempty"`
+	IsIdle          bool                   `protobuf:"varint,9,opt,name=is_idle,json=isIdle,proto3" json:"is_idle,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Activity) Reset() {
+	*x = Activity{}
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Activity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Activity) ProtoMessage() {}
+
+func (x *Activity) ProtoReflect() protoreflect.Message {
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Activity.ProtoReflect.Descriptor instead.
+func (*Activity) Descriptor() ([]byte, []int) {
+	return file_trackmytime_v1_trackmytime_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Activity) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Activity) GetAppName() string {
+	if x != nil {
+		return x.AppName
+	}
+	return ""
+}
+
+func (x *Activity) GetEnrichedName() string {
+	if x != nil {
+		return x.EnrichedName
+	}
+	return ""
+}
+
+func (x *Activity) GetWindowTitle() string {
+	if x != nil {
+		return x.WindowTitle
+	}
+	return ""
+}
+
+func (x *Activity) GetProcessPath() string {
+	if x != nil {
+		return x.ProcessPath
+	}
+	return ""
+}
+
+func (x *Activity) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *Activity) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *Activity) GetDurationSeconds() int64 {
+	if x != nil {
+		return x.DurationSeconds
+	}
+	return 0
+}
+
+func (x *Activity) GetIsIdle() bool {
+	if x != nil {
+		return x.IsIdle
+	}
+	return false
+}
+
+type AppStat struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AppName       string                 `protobuf:"bytes,1,opt,name=app_name,json=appName,proto3" json:"app_name,omitempty"`
+	TotalSeconds  int64                  `protobuf:"varint,2,opt,name=total_seconds,json=totalSeconds,proto3" json:"total_seconds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AppStat) Reset() {
+	*x = AppStat{}
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AppStat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppStat) ProtoMessage() {}
+
+func (x *AppStat) ProtoReflect() protoreflect.Message {
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppStat.ProtoReflect.Descriptor instead.
+func (*AppStat) Descriptor() ([]byte, []int) {
+	return file_trackmytime_v1_trackmytime_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *AppStat) GetAppName() string {
+	if x != nil {
+		return x.AppName
+	}
+	return ""
+}
+
+func (x *AppStat) GetTotalSeconds() int64 {
+	if x != nil {
+		return x.TotalSeconds
+	}
+	return 0
+}
+
+type EnrichedStat struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	TotalSeconds  int64                  `protobuf:"varint,2,opt,name=total_seconds,json=totalSeconds,proto3" json:"total_seconds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnrichedStat) Reset() {
+	*x = EnrichedStat{}
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnrichedStat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnrichedStat) ProtoMessage() {}
+
+func (x *EnrichedStat) ProtoReflect() protoreflect.Message {
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnrichedStat.ProtoReflect.Descriptor instead.
+func (*EnrichedStat) Descriptor() ([]byte, []int) {
+	return file_trackmytime_v1_trackmytime_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *EnrichedStat) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *EnrichedStat) GetTotalSeconds() int64 {
+	if x != nil {
+		return x.TotalSeconds
+	}
+	return 0
+}
+
+type AppGroup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AppName       string                 `protobuf:"bytes,1,opt,name=app_name,json=appName,proto3" json:"app_name,omitempty"`
+	TotalSeconds  int64                  `protobuf:"varint,2,opt,name=total_seconds,json=totalSeconds,proto3" json:"total_seconds,omitempty"`
+	Children      []*EnrichedStat        `protobuf:"bytes,3,rep,name=children,proto3" json:"children,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AppGroup) Reset() {
+	*x = AppGroup{}
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AppGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppGroup) ProtoMessage() {}
+
+func (x *AppGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppGroup.ProtoReflect.Descriptor instead.
+func (*AppGroup) Descriptor() ([]byte, []int) {
+	return file_trackmytime_v1_trackmytime_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *AppGroup) GetAppName() string {
+	if x != nil {
+		return x.AppName
+	}
+	return ""
+}
+
+func (x *AppGroup) GetTotalSeconds() int64 {
+	if x != nil {
+		return x.TotalSeconds
+	}
+	return 0
+}
+
+func (x *AppGroup) GetChildren() []*EnrichedStat {
+	if x != nil {
+		return x.Children
+	}
+	return nil
+}
+
+type GetCurrentActivityRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCurrentActivityRequest) Reset() {
+	*x = GetCurrentActivityRequest{}
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCurrentActivityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCurrentActivityRequest) ProtoMessage() {}
+
+func (x *GetCurrentActivityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCurrentActivityRequest.ProtoReflect.Descriptor instead.
+func (*GetCurrentActivityRequest) Descriptor() ([]byte, []int) {
+	return file_trackmytime_v1_trackmytime_proto_rawDescGZIP(), []int{7}
+}
+
+type GetCurrentActivityResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Activity      *CurrentActivity       `protobuf:"bytes,1,opt,name=activity,proto3" json:"activity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCurrentActivityResponse) Reset() {
+	*x = GetCurrentActivityResponse{}
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCurrentActivityResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCurrentActivityResponse) ProtoMessage() {}
+
+func (x *GetCurrentActivityResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCurrentActivityResponse.ProtoReflect.Descriptor instead.
+func (*GetCurrentActivityResponse) Descriptor() ([]byte, []int) {
+	return file_trackmytime_v1_trackmytime_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetCurrentActivityResponse) GetActivity() *CurrentActivity {
+	if x != nil {
+		return x.Activity
+	}
+	return nil
+}
+
+type GetStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Period        *Period                `protobuf:"bytes,1,opt,name=period,proto3" json:"period,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsRequest) Reset() {
+	*x = GetStatsRequest{}
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsRequest) ProtoMessage() {}
+
+func (x *GetStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetStatsRequest) Descriptor() ([]byte, []int) {
+	return file_trackmytime_v1_trackmytime_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetStatsRequest) GetPeriod() *Period {
+	if x != nil {
+		return x.Period
+	}
+	return nil
+}
+
+type GetStatsResponse struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Period             *PeriodInfo            `protobuf:"bytes,1,opt,name=period,proto3" json:"period,omitempty"`
+	TotalActivities    int32                  `protobuf:"varint,2,opt,name=total_activities,json=totalActivities,proto3" json:"total_activities,omitempty"`
+	TotalActiveSeconds int64                  `protobuf:"varint,3,opt,name=total_active_seconds,json=totalActiveSeconds,proto3" json:"total_active_seconds,omitempty"`
+	TotalIdleSeconds   int64                  `protobuf:"varint,4,opt,name=total_idle_seconds,json=totalIdleSeconds,proto3" json:"total_idle_seconds,omitempty"`
+	// Triées par durée décroissante
+	Apps          []*AppStat `protobuf:"bytes,5,rep,name=apps,proto3" json:"apps,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsResponse) Reset() {
+	*x = GetStatsResponse{}
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsResponse) ProtoMessage() {}
+
+func (x *GetStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetStatsResponse) Descriptor() ([]byte, []int) {
+	return file_trackmytime_v1_trackmytime_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetStatsResponse) GetPeriod() *PeriodInfo {
+	if x != nil {
+		return x.Period
+	}
+	return nil
+}
+
+func (x *GetStatsResponse) GetTotalActivities() int32 {
+	if x != nil {
+		return x.TotalActivities
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetTotalActiveSeconds() int64 {
+	if x != nil {
+		return x.TotalActiveSeconds
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetTotalIdleSeconds() int64 {
+	if x != nil {
+		return x.TotalIdleSeconds
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetApps() []*AppStat {
+	if x != nil {
+		return x.Apps
+	}
+	return nil
+}
+
+type GetGroupedStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Period        *Period                `protobuf:"bytes,1,opt,name=period,proto3" json:"period,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetGroupedStatsRequest) Reset() {
+	*x = GetGroupedStatsRequest{}
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetGroupedStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetGroupedStatsRequest) ProtoMessage() {}
+
+func (x *GetGroupedStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetGroupedStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetGroupedStatsRequest) Descriptor() ([]byte, []int) {
+	return file_trackmytime_v1_trackmytime_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetGroupedStatsRequest) GetPeriod() *Period {
+	if x != nil {
+		return x.Period
+	}
+	return nil
+}
+
+type GetGroupedStatsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Period        *PeriodInfo            `protobuf:"bytes,1,opt,name=period,proto3" json:"period,omitempty"`
+	Groups        []*AppGroup            `protobuf:"bytes,2,rep,name=groups,proto3" json:"groups,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetGroupedStatsResponse) Reset() {
+	*x = GetGroupedStatsResponse{}
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetGroupedStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetGroupedStatsResponse) ProtoMessage() {}
+
+func (x *GetGroupedStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetGroupedStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetGroupedStatsResponse) Descriptor() ([]byte, []int) {
+	return file_trackmytime_v1_trackmytime_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetGroupedStatsResponse) GetPeriod() *PeriodInfo {
+	if x != nil {
+		return x.Period
+	}
+	return nil
+}
+
+func (x *GetGroupedStatsResponse) GetGroups() []*AppGroup {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+type GetTimelineRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Period        *Period                `protobuf:"bytes,1,opt,name=period,proto3" json:"period,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTimelineRequest) Reset() {
+	*x = GetTimelineRequest{}
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTimelineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTimelineRequest) ProtoMessage() {}
+
+func (x *GetTimelineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTimelineRequest.ProtoReflect.Descriptor instead.
+func (*GetTimelineRequest) Descriptor() ([]byte, []int) {
+	return file_trackmytime_v1_trackmytime_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetTimelineRequest) GetPeriod() *Period {
+	if x != nil {
+		return x.Period
+	}
+	return nil
+}
+
+type GetTimelineResponse struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Period      *PeriodInfo            `protobuf:"bytes,1,opt,name=period,proto3" json:"period,omitempty"`
+	Granularity Granularity            `protobuf:"varint,2,opt,name=granularity,proto3,enum=trackmytime.v1.Granularity" json:"granularity,omitempty"`
+	Labels      []string               `protobuf:"bytes,3,rep,name=labels,proto3" json:"labels,omitempty"`
+	// Temps actif par intervalle, aligné sur labels
+	Seconds       []int64 `protobuf:"varint,4,rep,packed,name=seconds,proto3" json:"seconds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTimelineResponse) Reset() {
+	*x = GetTimelineResponse{}
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTimelineResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTimelineResponse) ProtoMessage() {}
+
+func (x *GetTimelineResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTimelineResponse.ProtoReflect.Descriptor instead.
+func (*GetTimelineResponse) Descriptor() ([]byte, []int) {
+	return file_trackmytime_v1_trackmytime_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetTimelineResponse) GetPeriod() *PeriodInfo {
+	if x != nil {
+		return x.Period
+	}
+	return nil
+}
+
+func (x *GetTimelineResponse) GetGranularity() Granularity {
+	if x != nil {
+		return x.Granularity
+	}
+	return Granularity_GRANULARITY_UNSPECIFIED
+}
+
+func (x *GetTimelineResponse) GetLabels() []string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *GetTimelineResponse) GetSeconds() []int64 {
+	if x != nil {
+		return x.Seconds
+	}
+	return nil
+}
+
+type ListActivitiesRequest struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Period       *Period                `protobuf:"bytes,1,opt,name=period,proto3" json:"period,omitempty"`
+	AppName      string                 `protobuf:"bytes,2,opt,name=app_name,json=appName,proto3" json:"app_name,omitempty"`
+	EnrichedName string                 `protobuf:"bytes,3,opt,name=enriched_name,json=enrichedName,proto3" json:"enriched_name,omitempty"`
+	IncludeIdle  bool                   `protobuf:"varint,4,opt,name=include_idle,json=includeIdle,proto3" json:"include_idle,omitempty"`
+	// 0 = pas de limite
+	Limit         int32 `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListActivitiesRequest) Reset() {
+	*x = ListActivitiesRequest{}
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListActivitiesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListActivitiesRequest) ProtoMessage() {}
+
+func (x *ListActivitiesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListActivitiesRequest.ProtoReflect.Descriptor instead.
+func (*ListActivitiesRequest) Descriptor() ([]byte, []int) {
+	return file_trackmytime_v1_trackmytime_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ListActivitiesRequest) GetPeriod() *Period {
+	if x != nil {
+		return x.Period
+	}
+	return nil
+}
+
+func (x *ListActivitiesRequest) GetAppName() string {
+	if x != nil {
+		return x.AppName
+	}
+	return ""
+}
+
+func (x *ListActivitiesRequest) GetEnrichedName() string {
+	if x != nil {
+		return x.EnrichedName
+	}
+	return ""
+}
+
+func (x *ListActivitiesRequest) GetIncludeIdle() bool {
+	if x != nil {
+		return x.IncludeIdle
+	}
+	return false
+}
+
+func (x *ListActivitiesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListActivitiesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Period        *PeriodInfo            `protobuf:"bytes,1,opt,name=period,proto3" json:"period,omitempty"`
+	Activities    []*Activity            `protobuf:"bytes,2,rep,name=activities,proto3" json:"activities,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListActivitiesResponse) Reset() {
+	*x = ListActivitiesResponse{}
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListActivitiesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListActivitiesResponse) ProtoMessage() {}
+
+func (x *ListActivitiesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListActivitiesResponse.ProtoReflect.Descriptor instead.
+func (*ListActivitiesResponse) Descriptor() ([]byte, []int) {
+	return file_trackmytime_v1_trackmytime_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListActivitiesResponse) GetPeriod() *PeriodInfo {
+	if x != nil {
+		return x.Period
+	}
+	return nil
+}
+
+func (x *ListActivitiesResponse) GetActivities() []*Activity {
+	if x != nil {
+		return x.Activities
+	}
+	return nil
+}
+
+type StreamActivityRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamActivityRequest) Reset() {
+	*x = StreamActivityRequest{}
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamActivityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamActivityRequest) ProtoMessage() {}
+
+func (x *StreamActivityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamActivityRequest.ProtoReflect.Descriptor instead.
+func (*StreamActivityRequest) Descriptor() ([]byte, []int) {
+	return file_trackmytime_v1_trackmytime_proto_rawDescGZIP(), []int{17}
+}
+
+type StreamActivityResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Activity      *CurrentActivity       `protobuf:"bytes,1,opt,name=activity,proto3" json:"activity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamActivityResponse) Reset() {
+	*x = StreamActivityResponse{}
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamActivityResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamActivityResponse) ProtoMessage() {}
+
+func (x *StreamActivityResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trackmytime_v1_trackmytime_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamActivityResponse.ProtoReflect.Descriptor instead.
+func (*StreamActivityResponse) Descriptor() ([]byte, []int) {
+	return file_trackmytime_v1_trackmytime_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *StreamActivityResponse) GetActivity() *CurrentActivity {
+	if x != nil {
+		return x.Activity
+	}
+	return nil
+}
+
+var File_trackmytime_v1_trackmytime_proto protoreflect.FileDescriptor
+
+const file_trackmytime_v1_trackmytime_proto_rawDesc = "" +
+	"\n" +
+	" trackmytime/v1/trackmytime.proto\x12\x0etrackmytime.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"r\n" +
+	"\x06Period\x12.\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\x1a.trackmytime.v1.PeriodKindR\x04kind\x12\x1d\n" +
+	"\n" +
+	"start_date\x18\x02 \x01(\tR\tstartDate\x12\x19\n" +
+	"\bend_date\x18\x03 \x01(\tR\aendDate\"\x80\x01\n" +
+	"\n" +
+	"PeriodInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x120\n" +
+	"\x05start\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x05start\x12,\n" +
+	"\x03end\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x03end\"\x95\x02\n" +
+	"\x0fCurrentActivity\x12\x16\n" +
+	"\x06active\x18\x01 \x01(\bR\x06active\x12\x19\n" +
+	"\bapp_name\x18\x02 \x01(\tR\aappName\x12#\n" +
+	"\renriched_name\x18\x03 \x01(\tR\fenrichedName\x12!\n" +
+	"\fwindow_title\x18\x04 \x01(\tR\vwindowTitle\x12!\n" +
+	"\fprocess_path\x18\x05 \x01(\tR\vprocessPath\x129\n" +
+	"\n" +
+	"start_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x12)\n" +
+	"\x10duration_seconds\x18\a \x01(\x03R\x0fdurationSeconds\"\xd6\x02\n" +
+	"\bActivity\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x19\n" +
+	"\bapp_name\x18\x02 \x01(\tR\aappName\x12#\n" +
+	"\renriched_name\x18\x03 \x01(\tR\fenrichedName\x12!\n" +
+	"\fwindow_title\x18\x04 \x01(\tR\vwindowTitle\x12!\n" +
+	"\fprocess_path\x18\x05 \x01(\tR\vprocessPath\x129\n" +
+	"\n" +
+	"start_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12)\n" +
+	"\x10duration_seconds\x18\b \x01(\x03R\x0fdurationSeconds\x12\x17\n" +
+	"\ais_idle\x18\t \x01(\bR\x06isIdle\"I\n" +
+	"\aAppStat\x12\x19\n" +
+	"\bapp_name\x18\x01 \x01(\tR\aappName\x12#\n" +
+	"\rtotal_seconds\x18\x02 \x01(\x03R\ftotalSeconds\"G\n" +
+	"\fEnrichedStat\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12#\n" +
+	"\rtotal_seconds\x18\x02 \x01(\x03R\ftotalSeconds\"\x84\x01\n" +
+	"\bAppGroup\x12\x19\n" +
+	"\bapp_name\x18\x01 \x01(\tR\aappName\x12#\n" +
+	"\rtotal_seconds\x18\x02 \x01(\x03R\ftotalSeconds\x128\n" +
+	"\bchildren\x18\x03 \x03(\v2\x1c.trackmytime.v1.EnrichedStatR\bchildren\"\x1b\n" +
+	"\x19GetCurrentActivityRequest\"Y\n" +
+	"\x1aGetCurrentActivityResponse\x12;\n" +
+	"\bactivity\x18\x01 \x01(\v2\x1f.trackmytime.v1.CurrentActivityR\bactivity\"A\n" +
+	"\x0fGetStatsRequest\x12.\n" +
+	"\x06period\x18\x01 \x01(\v2\x16.trackmytime.v1.PeriodR\x06period\"\xfe\x01\n" +
+	"\x10GetStatsResponse\x122\n" +
+	"\x06period\x18\x01 \x01(\v2\x1a.trackmytime.v1.PeriodInfoR\x06period\x12)\n" +
+	"\x10total_activities\x18\x02 \x01(\x05R\x0ftotalActivities\x120\n" +
+	"\x14total_active_seconds\x18\x03 \x01(\x03R\x12totalActiveSeconds\x12,\n" +
+	"\x12total_idle_seconds\x18\x04 \x01(\x03R\x10totalIdleSeconds\x12+\n" +
+	"\x04apps\x18\x05 \x03(\v2\x17.trackmytime.v1.AppStatR\x04apps\"H\n" +
+	"\x16GetGroupedStatsRequest\x12.\n" +
+	"\x06period\x18\x01 \x01(\v2\x16.trackmytime.v1.PeriodR\x06period\"\x7f\n" +
+	"\x17GetGroupedStatsResponse\x122\n" +
+	"\x06period\x18\x01 \x01(\v2\x1a.trackmytime.v1.PeriodInfoR\x06period\x120\n" +
+	"\x06groups\x18\x02 \x03(\v2\x18.trackmytime.v1.AppGroupR\x06groups\"D\n" +
+	"\x12GetTimelineRequest\x12.\n" +
+	"\x06period\x18\x01 \x01(\v2\x16.trackmytime.v1.PeriodR\x06period\"\xba\x01\n" +
+	"\x13GetTimelineResponse\x122\n" +
+	"\x06period\x18\x01 \x01(\v2\x1a.trackmytime.v1.PeriodInfoR\x06period\x12=\n" +
+	"\vgranularity\x18\x02 \x01(\x0e2\x1b.trackmytime.v1.GranularityR\vgranularity\x12\x16\n" +
+	"\x06labels\x18\x03 \x03(\tR\x06labels\x12\x18\n" +
+	"\aseconds\x18\x04 \x03(\x03R\aseconds\"\xc0\x01\n" +
+	"\x15ListActivitiesRequest\x12.\n" +
+	"\x06period\x18\x01 \x01(\v2\x16.trackmytime.v1.PeriodR\x06period\x12\x19\n" +
+	"\bapp_name\x18\x02 \x01(\tR\aappName\x12#\n" +
+	"\renriched_name\x18\x03 \x01(\tR\fenrichedName\x12!\n" +
+	"\finclude_idle\x18\x04 \x01(\bR\vincludeIdle\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\"\x86\x01\n" +
+	"\x16ListActivitiesResponse\x122\n" +
+	"\x06period\x18\x01 \x01(\v2\x1a.trackmytime.v1.PeriodInfoR\x06period\x128\n" +
+	"\n" +
+	"activities\x18\x02 \x03(\v2\x18.trackmytime.v1.ActivityR\n" +
+	"activities\"\x17\n" +
+	"\x15StreamActivityRequest\"U\n" +
+	"\x16StreamActivityResponse\x12;\n" +
+	"\bactivity\x18\x01 \x01(\v2\x1f.trackmytime.v1.CurrentActivityR\bactivity*\x85\x01\n" +
+	"\n" +
+	"PeriodKind\x12\x1b\n" +
+	"\x17PERIOD_KIND_UNSPECIFIED\x10\x00\x12\x15\n" +
+	"\x11PERIOD_KIND_TODAY\x10\x01\x12\x14\n" +
+	"\x10PERIOD_KIND_WEEK\x10\x02\x12\x15\n" +
+	"\x11PERIOD_KIND_MONTH\x10\x03\x12\x16\n" +
+	"\x12PERIOD_KIND_CUSTOM\x10\x04*U\n" +
+	"\vGranularity\x12\x1b\n" +
+	"\x17GRANULARITY_UNSPECIFIED\x10\x00\x12\x14\n" +
+	"\x10GRANULARITY_HOUR\x10\x01\x12\x13\n" +
+	"\x0fGRANULARITY_DAY\x10\x022\xd0\x04\n" +
+	"\x12TrackMyTimeService\x12k\n" +
+	"\x12GetCurrentActivity\x12).trackmytime.v1.GetCurrentActivityRequest\x1a*.trackmytime.v1.GetCurrentActivityResponse\x12M\n" +
+	"\bGetStats\x12\x1f.trackmytime.v1.GetStatsRequest\x1a .trackmytime.v1.GetStatsResponse\x12b\n" +
+	"\x0fGetGroupedStats\x12&.trackmytime.v1.GetGroupedStatsRequest\x1a'.trackmytime.v1.GetGroupedStatsResponse\x12V\n" +
+	"\vGetTimeline\x12\".trackmytime.v1.GetTimelineRequest\x1a#.trackmytime.v1.GetTimelineResponse\x12_\n" +
+	"\x0eListActivities\x12%.trackmytime.v1.ListActivitiesRequest\x1a&.trackmytime.v1.ListActivitiesResponse\x12a\n" +
+	"\x0eStreamActivity\x12%.trackmytime.v1.StreamActivityRequest\x1a&.trackmytime.v1.StreamActivityResponse0\x01B.Z,trackmytime/rpc/trackmytime/v1;trackmytimev1b\x06proto3"
+
+var (
+	file_trackmytime_v1_trackmytime_proto_rawDescOnce sync.Once
+	file_trackmytime_v1_trackmytime_proto_rawDescData []byte
+)
+
+func file_trackmytime_v1_trackmytime_proto_rawDescGZIP() []byte {
+	file_trackmytime_v1_trackmytime_proto_rawDescOnce.Do(func() {
+		file_trackmytime_v1_trackmytime_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_trackmytime_v1_trackmytime_proto_rawDesc), len(file_trackmytime_v1_trackmytime_proto_rawDesc)))
+	})
+	return file_trackmytime_v1_trackmytime_proto_rawDescData
+}
+
+var file_trackmytime_v1_trackmytime_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_trackmytime_v1_trackmytime_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
+var file_trackmytime_v1_trackmytime_proto_goTypes = []any{
+	(PeriodKind)(0),                    // 0: trackmytime.v1.PeriodKind
+	(Granularity)(0),                   // 1: trackmytime.v1.Granularity
+	(*Period)(nil),                     // 2: trackmytime.v1.Period
+	(*PeriodInfo)(nil),                 // 3: trackmytime.v1.PeriodInfo
+	(*CurrentActivity)(nil),            // 4: trackmytime.v1.CurrentActivity
+	(*Activity)(nil),                   // 5: trackmytime.v1.Activity
+	(*AppStat)(nil),                    // 6: trackmytime.v1.AppStat
+	(*EnrichedStat)(nil),               // 7: trackmytime.v1.EnrichedStat
+	(*AppGroup)(nil),                   // 8: trackmytime.v1.AppGroup
+	(*GetCurrentActivityRequest)(nil),  // 9: trackmytime.v1.GetCurrentActivityRequest
+	(*GetCurrentActivityResponse)(nil), // 10: trackmytime.v1.GetCurrentActivityResponse
+	(*GetStatsRequest)(nil),            // 11: trackmytime.v1.GetStatsRequest
+	(*GetStatsResponse)(nil),           // 12: trackmytime.v1.GetStatsResponse
+	(*GetGroupedStatsRequest)(nil),     // 13: trackmytime.v1.GetGroupedStatsRequest
+	(*GetGroupedStatsResponse)(nil),    // 14: trackmytime.v1.GetGroupedStatsResponse
+	(*GetTimelineRequest)(nil),         // 15: trackmytime.v1.GetTimelineRequest
+	(*GetTimelineResponse)(nil),        // 16: trackmytime.v1.GetTimelineResponse
+	(*ListActivitiesRequest)(nil),      // 17: trackmytime.v1.ListActivitiesRequest
+	(*ListActivitiesResponse)(nil),     // 18: trackmytime.v1.ListActivitiesResponse
+	(*StreamActivityRequest)(nil),      // 19: trackmytime.v1.StreamActivityRequest
+	(*StreamActivityResponse)(nil),     // 20: trackmytime.v1.StreamActivityResponse
+	(*timestamppb.Timestamp)(nil),      // 21: google.protobuf.Timestamp
+}
+var file_trackmytime_v1_trackmytime_proto_depIdxs = []int32{
+	0,  // 0: trackmytime.v1.Period.kind:type_name -> trackmytime.v1.PeriodKind
+	21, // 1: trackmytime.v1.PeriodInfo.start:type_name -> google.protobuf.Timestamp
+	21, // 2: trackmytime.v1.PeriodInfo.end:type_name -> google.protobuf.Timestamp
+	21, // 3: trackmytime.v1.CurrentActivity.start_time:type_name -> google.protobuf.Timestamp
+	21, // 4: trackmytime.v1.Activity.start_time:type_name -> google.protobuf.Timestamp
+	21, // 5: trackmytime.v1.Activity.end_time:type_name -> google.protobuf.Timestamp
+	7,  // 6: trackmytime.v1.AppGroup.children:type_name -> trackmytime.v1.EnrichedStat
+	4,  // 7: trackmytime.v1.GetCurrentActivityResponse.activity:type_name -> trackmytime.v1.CurrentActivity
+	2,  // 8: trackmytime.v1.GetStatsRequest.period:type_name -> trackmytime.v1.Period
+	3,  // 9: trackmytime.v1.GetStatsResponse.period:type_name -> trackmytime.v1.PeriodInfo
+	6,  // 10: trackmytime.v1.GetStatsResponse.apps:type_name -> trackmytime.v1.AppStat
+	2,  // 11: trackmytime.v1.GetGroupedStatsRequest.period:type_name -> trackmytime.v1.Period
+	3,  // 12: trackmytime.v1.GetGroupedStatsResponse.period:type_name -> trackmytime.v1.PeriodInfo
+	8,  // 13: trackmytime.v1.GetGroupedStatsResponse.groups:type_name -> trackmytime.v1.AppGroup
+	2,  // 14: trackmytime.v1.GetTimelineRequest.period:type_name -> trackmytime.v1.Period
+	3,  // 15: trackmytime.v1.GetTimelineResponse.period:type_name -> trackmytime.v1.PeriodInfo
+	1,  // 16: trackmytime.v1.GetTimelineResponse.granularity:type_name -> trackmytime.v1.Granularity
+	2,  // 17: trackmytime.v1.ListActivitiesRequest.period:type_name -> trackmytime.v1.Period
+	3,  // 18: trackmytime.v1.ListActivitiesResponse.period:type_name -> trackmytime.v1.PeriodInfo
+	5,  // 19: trackmytime.v1.ListActivitiesResponse.activities:type_name -> trackmytime.v1.Activity
+	4,  // 20: trackmytime.v1.StreamActivityResponse.activity:type_name -> trackmytime.v1.CurrentActivity
+	9,  // 21: trackmytime.v1.TrackMyTimeService.GetCurrentActivity:input_type -> trackmytime.v1.GetCurrentActivityRequest
+	11, // 22: trackmytime.v1.TrackMyTimeService.GetStats:input_type -> trackmytime.v1.GetStatsRequest
+	13, // 23: trackmytime.v1.TrackMyTimeService.GetGroupedStats:input_type -> trackmytime.v1.GetGroupedStatsRequest
+	15, // 24: trackmytime.v1.TrackMyTimeService.GetTimeline:input_type -> trackmytime.v1.GetTimelineRequest
+	17, // 25: trackmytime.v1.TrackMyTimeService.ListActivities:input_type -> trackmytime.v1.ListActivitiesRequest
+	19, // 26: trackmytime.v1.TrackMyTimeService.StreamActivity:input_type -> trackmytime.v1.StreamActivityRequest
+	10, // 27: trackmytime.v1.TrackMyTimeService.GetCurrentActivity:output_type -> trackmytime.v1.GetCurrentActivityResponse
+	12, // 28: trackmytime.v1.TrackMyTimeService.GetStats:output_type -> trackmytime.v1.GetStatsResponse
+	14, // 29: trackmytime.v1.TrackMyTimeService.GetGroupedStats:output_type -> trackmytime.v1.GetGroupedStatsResponse
+	16, // 30: trackmytime.v1.TrackMyTimeService.GetTimeline:output_type -> trackmytime.v1.GetTimelineResponse
+	18, // 31: trackmytime.v1.TrackMyTimeService.ListActivities:output_type -> trackmytime.v1.ListActivitiesResponse
+	20, // 32: trackmytime.v1.TrackMyTimeService.StreamActivity:output_type -> trackmytime.v1.StreamActivityResponse
+	27, // [27:33] is the sub-list for method output_type
+	21, // [21:27] is the sub-list for method input_type
+	21, // [21:21] is the sub-list for extension type_name
+	21, // [21:21] is the sub-list for extension extendee
+	0,  // [0:21] is the sub-list for field type_name
+}
+
+func init() { file_trackmytime_v1_trackmytime_proto_init() }
+func file_trackmytime_v1_trackmytime_proto_init() {
+	if File_trackmytime_v1_trackmytime_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_trackmytime_v1_trackmytime_proto_rawDesc), len(file_trackmytime_v1_trackmytime_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   19,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_trackmytime_v1_trackmytime_proto_goTypes,
+		DependencyIndexes: file_trackmytime_v1_trackmytime_proto_depIdxs,
+		EnumInfos:         file_trackmytime_v1_trackmytime_proto_enumTypes,
+		MessageInfos:      file_trackmytime_v1_trackmytime_proto_msgTypes,
+	}.Build()
+	File_trackmytime_v1_trackmytime_proto = out.File
+	file_trackmytime_v1_trackmytime_proto_goTypes = nil
+	file_trackmytime_v1_trackmytime_proto_depIdxs = nil
+}
@@ -0,0 +1,268 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: trackmytime/v1/trackmytime.proto
+
+// API RPC de TrackMyTime : mêmes capacités que l'API REST /api/v1, servie en
+// gRPC, gRPC-Web et Connect sur le port de l'agent (HTTP/2 en clair via h2c).
+package trackmytimev1connect
+
+import (
+	connect "connectrpc.com/connect"
+	context "context"
+	errors "errors"
+	http "net/http"
+	strings "strings"
+	v1 "trackmytime/rpc/trackmytime/v1"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect.IsAtLeastVersion1_13_0
+
+const (
+	// TrackMyTimeServiceName is the fully-qualified name of the TrackMyTimeService service.
+	TrackMyTimeServiceName = "trackmytime.v1.TrackMyTimeService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// TrackMyTimeServiceGetCurrentActivityProcedure is the fully-qualified name of the
+	// TrackMyTimeService's GetCurrentActivity RPC.
+	TrackMyTimeServiceGetCurrentActivityProcedure = "/trackmytime.v1.TrackMyTimeService/GetCurrentActivity"
+	// TrackMyTimeServiceGetStatsProcedure is the fully-qualified name of the TrackMyTimeService's
+	// GetStats RPC.
+	TrackMyTimeServiceGetStatsProcedure = "/trackmytime.v1.TrackMyTimeService/GetStats"
+	// TrackMyTimeServiceGetGroupedStatsProcedure is the fully-qualified name of the
+	// TrackMyTimeService's GetGroupedStats RPC.
+	TrackMyTimeServiceGetGroupedStatsProcedure = "/trackmytime.v1.TrackMyTimeService/GetGroupedStats"
+	// TrackMyTimeServiceGetTimelineProcedure is the fully-qualified name of the TrackMyTimeService's
+	// GetTimeline RPC.
+	TrackMyTimeServiceGetTimelineProcedure = "/trackmytime.v1.TrackMyTimeService/GetTimeline"
+	// TrackMyTimeServiceListActivitiesProcedure is the fully-qualified name of the TrackMyTimeService's
+	// ListActivities RPC.
+	TrackMyTimeServiceListActivitiesProcedure = "/trackmytime.v1.TrackMyTimeService/ListActivities"
+	// TrackMyTimeServiceStreamActivityProcedure is the fully-qualified name of the TrackMyTimeService's
+	// StreamActivity RPC.
+	TrackMyTimeServiceStreamActivityProcedure = "/trackmytime.v1.TrackMyTimeService/StreamActivity"
+)
+
+// TrackMyTimeServiceClient is a client for the trackmytime.v1.TrackMyTimeService service.
+type TrackMyTimeServiceClient interface {
+	// Activité en cours
+	GetCurrentActivity(context.Context, *connect.Request[v1.GetCurrentActivityRequest]) (*connect.Response[v1.GetCurrentActivityResponse], error)
+	// Temps actif par application
+	GetStats(context.Context, *connect.Request[v1.GetStatsRequest]) (*connect.Response[v1.GetStatsResponse], error)
+	// Temps actif par application puis par nom enrichi
+	GetGroupedStats(context.Context, *connect.Request[v1.GetGroupedStatsRequest]) (*connect.Response[v1.GetGroupedStatsResponse], error)
+	// Temps actif par heure (une journée) ou par jour
+	GetTimeline(context.Context, *connect.Request[v1.GetTimelineRequest]) (*connect.Response[v1.GetTimelineResponse], error)
+	// Activités brutes filtrées, les plus récentes d'abord
+	ListActivities(context.Context, *connect.Request[v1.ListActivitiesRequest]) (*connect.Response[v1.ListActivitiesResponse], error)
+	// Activité courante puis chacun de ses changements
+	StreamActivity(context.Context, *connect.Request[v1.StreamActivityRequest]) (*connect.ServerStreamForClient[v1.StreamActivityResponse], error)
+}
+
+// NewTrackMyTimeServiceClient constructs a client for the trackmytime.v1.TrackMyTimeService
+// service. By default, it uses the Connect protocol with the binary Protobuf Codec, asks for
+// gzipped responses, and sends uncompressed requests. To use the gRPC or gRPC-Web protocols, supply
+// the connect.WithGRPC() or connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewTrackMyTimeServiceClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) TrackMyTimeServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	trackMyTimeServiceMethods := v1.File_trackmytime_v1_trackmytime_proto.Services().ByName("TrackMyTimeService").Methods()
+	return &trackMyTimeServiceClient{
+		getCurrentActivity: connect.NewClient[v1.GetCurrentActivityRequest, v1.GetCurrentActivityResponse](
+			httpClient,
+			baseURL+TrackMyTimeServiceGetCurrentActivityProcedure,
+			connect.WithSchema(trackMyTimeServiceMethods.ByName("GetCurrentActivity")),
+			connect.WithClientOptions(opts...),
+		),
+		getStats: connect.NewClient[v1.GetStatsRequest, v1.GetStatsResponse](
+			httpClient,
+			baseURL+TrackMyTimeServiceGetStatsProcedure,
+			connect.WithSchema(trackMyTimeServiceMethods.ByName("GetStats")),
+			connect.WithClientOptions(opts...),
+		),
+		getGroupedStats: connect.NewClient[v1.GetGroupedStatsRequest, v1.GetGroupedStatsResponse](
+			httpClient,
+			baseURL+TrackMyTimeServiceGetGroupedStatsProcedure,
+			connect.WithSchema(trackMyTimeServiceMethods.ByName("GetGroupedStats")),
+			connect.WithClientOptions(opts...),
+		),
+		getTimeline: connect.NewClient[v1.GetTimelineRequest, v1.GetTimelineResponse](
+			httpClient,
+			baseURL+TrackMyTimeServiceGetTimelineProcedure,
+			connect.WithSchema(trackMyTimeServiceMethods.ByName("GetTimeline")),
+			connect.WithClientOptions(opts...),
+		),
+		listActivities: connect.NewClient[v1.ListActivitiesRequest, v1.ListActivitiesResponse](
+			httpClient,
+			baseURL+TrackMyTimeServiceListActivitiesProcedure,
+			connect.WithSchema(trackMyTimeServiceMethods.ByName("ListActivities")),
+			connect.WithClientOptions(opts...),
+		),
+		streamActivity: connect.NewClient[v1.StreamActivityRequest, v1.StreamActivityResponse](
+			httpClient,
+			baseURL+TrackMyTimeServiceStreamActivityProcedure,
+			connect.WithSchema(trackMyTimeServiceMethods.ByName("StreamActivity")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// trackMyTimeServiceClient implements TrackMyTimeServiceClient.
+type trackMyTimeServiceClient struct {
+	getCurrentActivity *connect.Client[v1.GetCurrentActivityRequest, v1.GetCurrentActivityResponse]
+	getStats           *connect.Client[v1.GetStatsRequest, v1.GetStatsResponse]
+	getGroupedStats    *connect.Client[v1.GetGroupedStatsRequest, v1.GetGroupedStatsResponse]
+	getTimeline        *connect.Client[v1.GetTimelineRequest, v1.GetTimelineResponse]
+	listActivities     *connect.Client[v1.ListActivitiesRequest, v1.ListActivitiesResponse]
+	streamActivity     *connect.Client[v1.StreamActivityRequest, v1.StreamActivityResponse]
+}
+
+// GetCurrentActivity calls trackmytime.v1.TrackMyTimeService.GetCurrentActivity.
+func (c *trackMyTimeServiceClient) GetCurrentActivity(ctx context.Context, req *connect.Request[v1.GetCurrentActivityRequest]) (*connect.Response[v1.GetCurrentActivityResponse], error) {
+	return c.getCurrentActivity.CallUnary(ctx, req)
+}
+
+// GetStats calls trackmytime.v1.TrackMyTimeService.GetStats.
+func (c *trackMyTimeServiceClient) GetStats(ctx context.Context, req *connect.Request[v1.GetStatsRequest]) (*connect.Response[v1.GetStatsResponse], error) {
+	return c.getStats.CallUnary(ctx, req)
+}
+
+// GetGroupedStats calls trackmytime.v1.TrackMyTimeService.GetGroupedStats.
+func (c *trackMyTimeServiceClient) GetGroupedStats(ctx context.Context, req *connect.Request[v1.GetGroupedStatsRequest]) (*connect.Response[v1.GetGroupedStatsResponse], error) {
+	return c.getGroupedStats.CallUnary(ctx, req)
+}
+
+// GetTimeline calls trackmytime.v1.TrackMyTimeService.GetTimeline.
+func (c *trackMyTimeServiceClient) GetTimeline(ctx context.Context, req *connect.Request[v1.GetTimelineRequest]) (*connect.Response[v1.GetTimelineResponse], error) {
+	return c.getTimeline.CallUnary(ctx, req)
+}
+
+// ListActivities calls trackmytime.v1.TrackMyTimeService.ListActivities.
+func (c *trackMyTimeServiceClient) ListActivities(ctx context.Context, req *connect.Request[v1.ListActivitiesRequest]) (*connect.Response[v1.ListActivitiesResponse], error) {
+	return c.listActivities.CallUnary(ctx, req)
+}
+
+// StreamActivity calls trackmytime.v1.TrackMyTimeService.StreamActivity.
+func (c *trackMyTimeServiceClient) StreamActivity(ctx context.Context, req *connect.Request[v1.StreamActivityRequest]) (*connect.ServerStreamForClient[v1.StreamActivityResponse], error) {
+	return c.streamActivity.CallServerStream(ctx, req)
+}
+
+// TrackMyTimeServiceHandler is an implementation of the trackmytime.v1.TrackMyTimeService service.
+type TrackMyTimeServiceHandler interface {
+	// Activité en cours
+	GetCurrentActivity(context.Context, *connect.Request[v1.GetCurrentActivityRequest]) (*connect.Response[v1.GetCurrentActivityResponse], error)
+	// Temps actif par application
+	GetStats(context.Context, *connect.Request[v1.GetStatsRequest]) (*connect.Response[v1.GetStatsResponse], error)
+	// Temps actif par application puis par nom enrichi
+	GetGroupedStats(context.Context, *connect.Request[v1.GetGroupedStatsRequest]) (*connect.Response[v1.GetGroupedStatsResponse], error)
+	// Temps actif par heure (une journée) ou par jour
+	GetTimeline(context.Context, *connect.Request[v1.GetTimelineRequest]) (*connect.Response[v1.GetTimelineResponse], error)
+	// Activités brutes filtrées, les plus récentes d'abord
+	ListActivities(context.Context, *connect.Request[v1.ListActivitiesRequest]) (*connect.Response[v1.ListActivitiesResponse], error)
+	// Activité courante puis chacun de ses changements
+	StreamActivity(context.Context, *connect.Request[v1.StreamActivityRequest], *connect.ServerStream[v1.StreamActivityResponse]) error
+}
+
+// NewTrackMyTimeServiceHandler builds an HTTP handler from the service implementation. It returns
+// the path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewTrackMyTimeServiceHandler(svc TrackMyTimeServiceHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	trackMyTimeServiceMethods := v1.File_trackmytime_v1_trackmytime_proto.Services().ByName("TrackMyTimeService").Methods()
+	trackMyTimeServiceGetCurrentActivityHandler := connect.NewUnaryHandler(
+		TrackMyTimeServiceGetCurrentActivityProcedure,
+		svc.GetCurrentActivity,
+		connect.WithSchema(trackMyTimeServiceMethods.ByName("GetCurrentActivity")),
+		connect.WithHandlerOptions(opts...),
+	)
+	trackMyTimeServiceGetStatsHandler := connect.NewUnaryHandler(
+		TrackMyTimeServiceGetStatsProcedure,
+		svc.GetStats,
+		connect.WithSchema(trackMyTimeServiceMethods.ByName("GetStats")),
+		connect.WithHandlerOptions(opts...),
+	)
+	trackMyTimeServiceGetGroupedStatsHandler := connect.NewUnaryHandler(
+		TrackMyTimeServiceGetGroupedStatsProcedure,
+		svc.GetGroupedStats,
+		connect.WithSchema(trackMyTimeServiceMethods.ByName("GetGroupedStats")),
+		connect.WithHandlerOptions(opts...),
+	)
+	trackMyTimeServiceGetTimelineHandler := connect.NewUnaryHandler(
+		TrackMyTimeServiceGetTimelineProcedure,
+		svc.GetTimeline,
+		connect.WithSchema(trackMyTimeServiceMethods.ByName("GetTimeline")),
+		connect.WithHandlerOptions(opts...),
+	)
+	trackMyTimeServiceListActivitiesHandler := connect.NewUnaryHandler(
+		TrackMyTimeServiceListActivitiesProcedure,
+		svc.ListActivities,
+		connect.WithSchema(trackMyTimeServiceMethods.ByName("ListActivities")),
+		connect.WithHandlerOptions(opts...),
+	)
+	trackMyTimeServiceStreamActivityHandler := connect.NewServerStreamHandler(
+		TrackMyTimeServiceStreamActivityProcedure,
+		svc.StreamActivity,
+		connect.WithSchema(trackMyTimeServiceMethods.ByName("StreamActivity")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/trackmytime.v1.TrackMyTimeService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case TrackMyTimeServiceGetCurrentActivityProcedure:
+			trackMyTimeServiceGetCurrentActivityHandler.ServeHTTP(w, r)
+		case TrackMyTimeServiceGetStatsProcedure:
+			trackMyTimeServiceGetStatsHandler.ServeHTTP(w, r)
+		case TrackMyTimeServiceGetGroupedStatsProcedure:
+			trackMyTimeServiceGetGroupedStatsHandler.ServeHTTP(w, r)
+		case TrackMyTimeServiceGetTimelineProcedure:
+			trackMyTimeServiceGetTimelineHandler.ServeHTTP(w, r)
+		case TrackMyTimeServiceListActivitiesProcedure:
+			trackMyTimeServiceListActivitiesHandler.ServeHTTP(w, r)
+		case TrackMyTimeServiceStreamActivityProcedure:
+			trackMyTimeServiceStreamActivityHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedTrackMyTimeServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedTrackMyTimeServiceHandler struct{}
+
+func (UnimplementedTrackMyTimeServiceHandler) GetCurrentActivity(context.Context, *connect.Request[v1.GetCurrentActivityRequest]) (*connect.Response[v1.GetCurrentActivityResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("trackmytime.v1.TrackMyTimeService.GetCurrentActivity is not implemented"))
+}
+
+func (UnimplementedTrackMyTimeServiceHandler) GetStats(context.Context, *connect.Request[v1.GetStatsRequest]) (*connect.Response[v1.GetStatsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("trackmytime.v1.TrackMyTimeService.GetStats is not implemented"))
+}
+
+func (UnimplementedTrackMyTimeServiceHandler) GetGroupedStats(context.Context, *connect.Request[v1.GetGroupedStatsRequest]) (*connect.Response[v1.GetGroupedStatsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("trackmytime.v1.TrackMyTimeService.GetGroupedStats is not implemented"))
+}
+
+func (UnimplementedTrackMyTimeServiceHandler) GetTimeline(context.Context, *connect.Request[v1.GetTimelineRequest]) (*connect.Response[v1.GetTimelineResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("trackmytime.v1.TrackMyTimeService.GetTimeline is not implemented"))
+}
+
+func (UnimplementedTrackMyTimeServiceHandler) ListActivities(context.Context, *connect.Request[v1.ListActivitiesRequest]) (*connect.Response[v1.ListActivitiesResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("trackmytime.v1.TrackMyTimeService.ListActivities is not implemented"))
+}
+
+func (UnimplementedTrackMyTimeServiceHandler) StreamActivity(context.Context, *connect.Request[v1.StreamActivityRequest], *connect.ServerStream[v1.StreamActivityResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("trackmytime.v1.TrackMyTimeService.StreamActivity is not implemented"))
+}
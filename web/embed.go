@@ -0,0 +1,9 @@
+// Package web embarque les fichiers du dashboard dans le binaire.
+package web
+
+import "embed"
+
+// Assets contient index.html et le dossier static/
+//
+//go:embed index.html static
+var Assets embed.FS
@@ -1,6 +1,7 @@
 // TrackMyTime Dashboard with Tailwind
 
-const API_BASE = 'http://localhost:8787';
+const API_BASE = window.location.origin;
+const TOKEN_STORAGE_KEY = 'trackmytime_token';
 const REFRESH_INTERVAL = 5000;
 
 let currentPeriod = 'today';
@@ -40,18 +41,25 @@ const BUTTON_CLASSES = {
 // ============================================
 
 /**
- * Build API endpoint based on current period
- * @param {string} baseEndpoint - Base endpoint path (e.g., '/stats')
+ * Build API v1 endpoint with the current period parameters
+ * @param {string} path - Endpoint path under /api/v1 (e.g., '/stats')
  * @returns {string} Complete endpoint with period parameters
  */
-function buildEndpoint(baseEndpoint) {
-    const periodMap = {
-        'today': '/today',
-        'week': '/week',
-        'month': '/month',
-        'custom': `/custom?start=${customStart}&end=${customEnd}`
-    };
-    return `${baseEndpoint}${periodMap[currentPeriod] || '/today'}`;
+function buildEndpoint(path) {
+    let endpoint = `/api/v1${path}?period=${currentPeriod}`;
+    if (currentPeriod === 'custom') {
+        endpoint += `&start=${customStart}&end=${customEnd}`;
+    }
+    return endpoint;
+}
+
+/**
+ * Convert the v1 apps list to an { app_name: seconds } map
+ * @param {Array} apps - Apps returned by /api/v1/stats
+ * @returns {Object} Seconds by app name
+ */
+function appsToMap(apps) {
+    return Object.fromEntries((apps || []).map(app => [app.app_name, app.total_seconds]));
 }
 
 /**
@@ -151,21 +159,41 @@ document.addEventListener('DOMContentLoaded', () => {
 // API Functions
 // ============================================
 
+/**
+ * Get the API token, taken from ?token= on first visit then kept in localStorage
+ * @returns {string|null} Bearer token
+ */
+function getAPIToken() {
+    const params = new URLSearchParams(window.location.search);
+    const token = params.get('token');
+    if (token) {
+        localStorage.setItem(TOKEN_STORAGE_KEY, token);
+        // Retirer le jeton de la barre d'adresse et de l'historique
+        params.delete('token');
+        const query = params.toString();
+        window.history.replaceState(null, '', window.location.pathname + (query ? `?${query}` : ''));
+        return token;
+    }
+    return localStorage.getItem(TOKEN_STORAGE_KEY);
+}
+
 async function fetchAPI(endpoint) {
     try {
-        const response = await fetch(`${API_BASE}${endpoint}`);
+        const token = getAPIToken();
+        const headers = token ? { 'Authorization': `Bearer ${token}` } : {};
+        const response = await fetch(`${API_BASE}${endpoint}`, { headers });
         if (!response.ok) throw new Error(`HTTP ${response.status}`);
         return await response.json();
     } catch (error) {
         console.error(`Erreur API (${endpoint}):`, error);
-        updateStatus('offline', 'Hors ligne');
+        updateStatus('offline', error.message === 'HTTP 401' ? 'Jeton requis' : 'Hors ligne');
         throw error;
     }
 }
 
 async function checkAPIHealth() {
     try {
-        await fetchAPI('/health');
+        await fetchAPI('/api/v1/health');
         updateStatus('online', 'En ligne');
     } catch (error) {
         updateStatus('offline', 'Hors ligne');
@@ -202,10 +230,10 @@ async function refreshDashboard() {
 
 async function updateCurrentActivity() {
     try {
-        const data = await fetchAPI('/activity/current');
+        const data = await fetchAPI('/api/v1/activity/current');
         const currentApp = document.getElementById('current-app');
         
-        if (data.status === 'no activity') {
+        if (!data.active) {
             currentApp.textContent = '--';
         } else {
             currentApp.textContent = data.app_name;
@@ -220,11 +248,12 @@ async function updateStats() {
     try {
         const endpoint = buildEndpoint('/stats');
         const data = await fetchAPI(endpoint);
+        const statsByApp = appsToMap(data.apps);
         
         // Update stats values using cached DOM
         if (DOM.totalTime) DOM.totalTime.textContent = formatDuration(data.total_active_seconds);
         if (DOM.idleTime) DOM.idleTime.textContent = formatDuration(data.total_idle_seconds || 0);
-        if (DOM.appsCount) DOM.appsCount.textContent = Object.keys(data.stats_by_app).length;
+        if (DOM.appsCount) DOM.appsCount.textContent = Object.keys(statsByApp).length;
         
         // Update labels based on period using helper
         const config = getPeriodConfig();
@@ -233,7 +262,7 @@ async function updateStats() {
         if (DOM.idleTimeLabel) DOM.idleTimeLabel.textContent = config.idleTimeLabel;
         if (DOM.appsCountLabel) DOM.appsCountLabel.textContent = config.appsLabel;
         
-        updateDonutChart(data.stats_by_app);
+        updateDonutChart(statsByApp);
         await updateTimelineChart();
         
     } catch (error) {
@@ -244,16 +273,12 @@ async function updateStats() {
 async function updateTopApps() {
     try {
         if (isGroupedView) {
-            let endpoint = `/api/stats/grouped?period=${currentPeriod}`;
-            if (currentPeriod === 'custom') {
-                endpoint += `&start=${customStart}&end=${customEnd}`;
-            }
-            const data = await fetchAPI(endpoint);
+            const data = await fetchAPI(buildEndpoint('/stats/grouped'));
             renderGroupedApps(data.groups);
         } else {
             const endpoint = buildEndpoint('/stats');
             const data = await fetchAPI(endpoint);
-            renderFlatApps(data.stats_by_app);
+            renderFlatApps(appsToMap(data.apps));
         }
     } catch (error) {
         console.error('Échec de la mise à jour des top apps:', error);
@@ -519,13 +544,8 @@ function updateDonutChart(stats) {
 
 async function updateTimelineChart() {
     try {
-        let url = '/api/stats/hourly?period=' + currentPeriod;
-        if (currentPeriod === 'custom') {
-            url += `&start=${customStart}&end=${customEnd}`;
-        }
-        
-        const data = await fetchAPI(url);
-        const timelineData = data.timeline_data || data.hourly_data || [];
+        const data = await fetchAPI(buildEndpoint('/stats/timeline'));
+        const timelineData = data.seconds || [];
         const labels = data.labels || Array.from({length: 24}, (_, i) => `${String(i).padStart(2, '0')}:00`);
         
         const minutes = timelineData.map(seconds => Math.round(seconds / 60));
@@ -545,9 +565,10 @@ async function updateTimelineChart() {
 // ============================================
 
 function exportData(format) {
-    let url = `${API_BASE}/export/aggregated?period=${currentPeriod}&format=${format}`;
-    if (currentPeriod === 'custom') {
-        url += `&start=${customStart}&end=${customEnd}`;
+    let url = `${API_BASE}${buildEndpoint('/export')}&aggregated=true&format=${format}`;
+    const token = getAPIToken();
+    if (token) {
+        url += `&token=${encodeURIComponent(token)}`;
     }
     const link = document.createElement('a');
     link.href = url;